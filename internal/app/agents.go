@@ -0,0 +1,151 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/session"
+)
+
+type AgentsCmd struct {
+	ID   string `arg:"" help:"Parent session ID or prefix"`
+	Full bool   `help:"Show full prompts and results instead of the first lines"`
+}
+
+type agentsOutput struct {
+	Session *session.Session      `json:"session"`
+	Agents  []*session.AgentSpawn `json:"agents"`
+}
+
+func (cmd *AgentsCmd) Run(globals *Globals) error {
+	s, err := findSession(globals, cmd.ID)
+	if err != nil {
+		return err
+	}
+	spawns, err := session.LinkSpawns(s)
+	if err != nil {
+		return fmt.Errorf("link subagents: %w", err)
+	}
+
+	if globals.JSON {
+		if spawns == nil {
+			spawns = []*session.AgentSpawn{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(agentsOutput{Session: s, Agents: spawns})
+	}
+
+	fmt.Println()
+	fmt.Printf("  %s  %s %s\n", output.Bold(s.ShortID), projectLabel(s), output.Dim("— "+output.Truncate(sessionTitle(s), 60)))
+	if len(spawns) == 0 {
+		fmt.Println()
+		fmt.Println("  No subagents spawned in this session.")
+		return nil
+	}
+	fmt.Println("  " + output.Dim(agentTotals(spawns)))
+	fmt.Println()
+	printAgentTree(spawns, "  ", cmd.Full)
+	fmt.Println()
+	return nil
+}
+
+// agentTotals summarises a spawn tree: count, summed runtime, and tokens.
+func agentTotals(spawns []*session.AgentSpawn) string {
+	var n, tokens int
+	var total time.Duration
+	var walk func([]*session.AgentSpawn)
+	walk = func(list []*session.AgentSpawn) {
+		for _, sp := range list {
+			n++
+			tokens += sp.Tokens()
+			total += sp.Duration()
+			walk(sp.Children)
+		}
+	}
+	walk(spawns)
+	parts := []string{fmt.Sprintf("%d %s", n, plural(n, "agent", "agents"))}
+	if total > 0 {
+		parts = append(parts, output.FormatDuration(total)+" agent time")
+	}
+	if tokens > 0 {
+		parts = append(parts, formatInt(tokens)+" tokens")
+	}
+	return strings.Join(parts, " · ")
+}
+
+func printAgentTree(spawns []*session.AgentSpawn, indent string, full bool) {
+	for i, sp := range spawns {
+		branch, cont := "├─ ", "│  "
+		if i == len(spawns)-1 {
+			branch, cont = "└─ ", "   "
+		}
+		kind := sp.SubagentType
+		if kind == "" {
+			kind = "agent"
+		}
+		fmt.Printf("%s%s%s %s  %s\n", indent, output.Dim(branch), output.Bold(kind), sp.Description, output.Dim(agentSummary(sp)))
+
+		body := indent + output.Dim(cont) + "   "
+		if sp.Agent != nil {
+			fmt.Printf("%s%s %s\n", body, output.Dim("Agent:"), output.Cyan(sp.Agent.ShortID))
+		} else {
+			fmt.Printf("%s%s %s\n", body, output.Dim("Agent:"), output.Dim("(transcript not found)"))
+		}
+		printAgentText(body, "Prompt:", sp.Prompt, full)
+		printAgentText(body, "Result:", sp.Result, full)
+		if len(sp.Children) > 0 {
+			printAgentTree(sp.Children, indent+output.Dim(cont)+"   ", full)
+		}
+		if i < len(spawns)-1 {
+			fmt.Println(indent + output.Dim("│"))
+		}
+	}
+}
+
+func printAgentText(indent, label, text string, full bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if !full {
+		text = output.Truncate(strings.Join(strings.Fields(text), " "), 200)
+		fmt.Printf("%s%s %s\n", indent, output.Dim(label), text)
+		return
+	}
+	lines := strings.Split(text, "\n")
+	fmt.Printf("%s%s %s\n", indent, output.Dim(label), lines[0])
+	pad := strings.Repeat(" ", len(label)+1)
+	for _, line := range lines[1:] {
+		fmt.Printf("%s%s%s\n", indent, pad, line)
+	}
+}
+
+// agentLabel names a spawn in transcript headings: short agent ID, type,
+// and the Task description.
+func agentLabel(sp *session.AgentSpawn) string {
+	label := "`" + sp.Agent.ShortID + "`"
+	if sp.SubagentType != "" {
+		label += " · " + sp.SubagentType
+	}
+	if sp.Description != "" {
+		label += " — " + sp.Description
+	}
+	return label
+}
+
+// agentSummary is the one-line outcome of a spawn: status, duration, tokens.
+func agentSummary(sp *session.AgentSpawn) string {
+	parts := []string{sp.Status}
+	if d := sp.Duration(); d > 0 {
+		parts = append(parts, output.FormatDuration(d))
+	}
+	if t := sp.Tokens(); t > 0 {
+		parts = append(parts, formatInt(t)+" tokens")
+	}
+	return strings.Join(parts, " · ")
+}
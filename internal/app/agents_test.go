@@ -0,0 +1,145 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeAgentFixtures(t *testing.T, home string) {
+	t.Helper()
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-agents")
+	subDir := filepath.Join(projDir, "par11111-2222-3333-4444-555555555555", "subagents")
+	if err := os.MkdirAll(subDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, filepath.Join(projDir, "par11111-2222-3333-4444-555555555555.jsonl"), []string{
+		`{"type":"user","cwd":"/Users/test/agents","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"survey the code"}}`,
+		`{"type":"assistant","timestamp":"2026-03-01T10:00:05Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Task","input":{"subagent_type":"Explore","description":"Find loaders","prompt":"Find config loaders"}}]}}`,
+		`{"type":"user","timestamp":"2026-03-01T10:01:05Z","toolUseResult":{"agentId":"abc123","status":"completed","totalDurationMs":60000,"totalTokens":4200},"message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"Loaders live in config/"}]}}`,
+		`{"type":"assistant","timestamp":"2026-03-01T10:01:10Z","message":{"role":"assistant","content":"The loaders are in config/."}}`,
+	})
+	writeLines(t, filepath.Join(subDir, "agent-abc123.jsonl"), []string{
+		`{"type":"user","isSidechain":true,"cwd":"/Users/test/agents","timestamp":"2026-03-01T10:00:06Z","message":{"role":"user","content":"Find config loaders"}}`,
+		`{"type":"assistant","timestamp":"2026-03-01T10:01:00Z","message":{"role":"assistant","content":"Found loadConfig in config/load.go"}}`,
+	})
+}
+
+func TestAgentsCmd_JSON(t *testing.T) {
+	home := setupFixtures(t)
+	writeAgentFixtures(t, home)
+
+	out := captureStdout(t, func() {
+		cmd := &AgentsCmd{ID: "par11111"}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var data struct {
+		Agents []struct {
+			AgentID     string `json:"agent_id"`
+			Status      string `json:"status"`
+			DurationMS  int64  `json:"duration_ms"`
+			TotalTokens int    `json:"total_tokens"`
+			Result      string `json:"result"`
+			Agent       *struct {
+				ID string `json:"id"`
+			} `json:"agent"`
+		} `json:"agents"`
+	}
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(data.Agents) != 1 {
+		t.Fatalf("got %d agents, want 1", len(data.Agents))
+	}
+	a := data.Agents[0]
+	if a.AgentID != "abc123" || a.Status != "completed" || a.DurationMS != 60000 || a.TotalTokens != 4200 {
+		t.Errorf("agent = %+v", a)
+	}
+	if a.Agent == nil || a.Agent.ID != "agent-abc123" {
+		t.Errorf("transcript not linked: %+v", a.Agent)
+	}
+}
+
+func TestExportCmd_InlinesAgents(t *testing.T) {
+	home := setupFixtures(t)
+	writeAgentFixtures(t, home)
+
+	out := captureStdout(t, func() {
+		cmd := &ExportCmd{ID: "par11111", Role: "user,assistant", Agents: true}
+		if err := cmd.Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	spawn := strings.Index(out, "**Task**: Find loaders")
+	inlined := strings.Index(out, "> ### Subagent `agent-ab")
+	reply := strings.Index(out, "The loaders are in config/.")
+	if spawn < 0 || inlined < 0 || reply < 0 {
+		t.Fatalf("missing spawn, subagent, or reply:\n%s", out)
+	}
+	if !(spawn < inlined && inlined < reply) {
+		t.Errorf("subagent not inlined at its spawn point:\n%s", out)
+	}
+	if !strings.Contains(out, "> Found loadConfig in config/load.go") {
+		t.Errorf("subagent transcript not quoted:\n%s", out)
+	}
+
+	jsonOut := captureStdout(t, func() {
+		cmd := &ExportCmd{ID: "par11111", Role: "user,assistant", Agents: true}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var data exportJSONOutput
+	if err := json.Unmarshal([]byte(jsonOut), &data); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	var nested int
+	for _, m := range data.Messages {
+		for _, a := range m.Agents {
+			nested += len(a.Messages)
+		}
+	}
+	if nested != 2 {
+		t.Errorf("nested agent messages = %d, want 2", nested)
+	}
+}
+
+func TestSearchCmd_GroupByParent(t *testing.T) {
+	home := setupFixtures(t)
+	writeAgentFixtures(t, home)
+
+	out := captureStdout(t, func() {
+		cmd := &SearchCmd{Query: "loadConfig", MaxMatches: 3, GroupBy: "parent"}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var groups []struct {
+		ID      string `json:"id"`
+		Matches []any  `json:"matches"`
+		Agents  []struct {
+			ID       string `json:"id"`
+			ParentID string `json:"parent_id"`
+			Matches  []any  `json:"matches"`
+		} `json:"agents"`
+	}
+	if err := json.Unmarshal([]byte(out), &groups); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("got %d groups, want 1:\n%s", len(groups), out)
+	}
+	g := groups[0]
+	if g.ID != "par11111-2222-3333-4444-555555555555" || len(g.Matches) != 0 {
+		t.Errorf("group = %s with %d own matches, want the parent with none", g.ID, len(g.Matches))
+	}
+	if len(g.Agents) != 1 || g.Agents[0].ID != "agent-abc123" || g.Agents[0].ParentID != g.ID || len(g.Agents[0].Matches) == 0 {
+		t.Errorf("agents = %+v", g.Agents)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/andyhtran/cct/internal/index"
@@ -172,6 +173,52 @@ func TestSearchCmd_JSON(t *testing.T) {
 	}
 }
 
+func TestSearchCmd_In(t *testing.T) {
+	setupFixtures(t)
+
+	run := func(in ...string) []index.SearchResult {
+		t.Helper()
+		out := captureStdout(t, func() {
+			if err := (&SearchCmd{Query: "database", In: in}).Run(&Globals{JSON: true}); err != nil {
+				t.Fatal(err)
+			}
+		})
+		var results []index.SearchResult
+		if strings.HasPrefix(strings.TrimSpace(out), "[") {
+			if err := json.Unmarshal([]byte(out), &results); err != nil {
+				t.Fatalf("invalid JSON output: %v\n%s", err, out)
+			}
+		}
+		return results
+	}
+
+	for _, r := range run("responses") {
+		for _, m := range r.Matches {
+			if m.Role != "assistant" {
+				t.Errorf("--in responses matched role %q", m.Role)
+			}
+		}
+	}
+	if got := run("tool-output"); len(got) != 0 {
+		t.Errorf("fixture has no tool output, got %d results", len(got))
+	}
+	if err := (&SearchCmd{Query: "database", In: []string{"logs"}}).Run(&Globals{}); err == nil {
+		t.Error("unknown --in value should fail")
+	}
+}
+
+func TestParseToolOutputLimit(t *testing.T) {
+	for in, want := range map[string]int{"512": 512, "8k": 8 << 10, "8KB": 8 << 10, "1m": 1 << 20, "off": index.NoToolOutput, "full": index.FullToolOutput} {
+		got, err := parseToolOutputLimit(in)
+		if err != nil || got != want {
+			t.Errorf("parseToolOutputLimit(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	if _, err := parseToolOutputLimit("lots"); err == nil {
+		t.Error("expected an error for a non-size")
+	}
+}
+
 func TestSearchCmd_NoResults(t *testing.T) {
 	setupFixtures(t)
 
@@ -251,7 +298,7 @@ func TestStatsCmd_JSON(t *testing.T) {
 	setupFixtures(t)
 
 	globals := &Globals{JSON: true}
-	cmd := &StatsCmd{}
+	cmd := &StatsSummaryCmd{}
 
 	out := captureStdout(t, func() {
 		if err := cmd.Run(globals); err != nil {
@@ -499,6 +546,211 @@ func TestExportCmd_FullIncludesToolResults(t *testing.T) {
 	}
 }
 
+func TestExportCmd_Thinking(t *testing.T) {
+	home := setupFixtures(t)
+
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-myproject")
+	sessionLines := []string{
+		`{"type":"user","message":{"role":"user","content":"pick a cache"},"cwd":"/Users/test/myproject","sessionId":"thnk1234-5678-9abc-def0-555555555555","timestamp":"2026-03-01T10:00:00Z"}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"thinking","thinking":"LRU fits: entries never expire.","signature":"x"},{"type":"redacted_thinking","data":"opaque"}]},"timestamp":"2026-03-01T10:00:04Z"}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Using an LRU."}]},"timestamp":"2026-03-01T10:00:05Z"}`,
+	}
+	writeLines(t, filepath.Join(projDir, "thnk1234-5678-9abc-def0-555555555555.jsonl"), sessionLines)
+
+	out := captureStdout(t, func() {
+		if err := (&ExportCmd{ID: "thnk1234", Role: "user,assistant"}).Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if strings.Contains(out, "never expire") {
+		t.Error("thinking should be left out by default")
+	}
+
+	out = captureStdout(t, func() {
+		if err := (&ExportCmd{ID: "thnk1234", Role: "user,assistant", Thinking: true}).Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "## Thinking\n\n> LRU fits: entries never expire.") || strings.Contains(out, "opaque") {
+		t.Errorf("--thinking should quote thinking and skip redacted blocks:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		if err := (&ExportCmd{ID: "thnk1234", Role: "user,assistant", Thinking: true}).Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var result exportJSONOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatal(err)
+	}
+	var roles []string
+	for _, m := range result.Messages {
+		roles = append(roles, m.Role)
+	}
+	if strings.Join(roles, ",") != "user,thinking,assistant" {
+		t.Errorf("roles = %v, want user, thinking, assistant", roles)
+	}
+}
+
+func TestExportCmd_AroundSearchRef(t *testing.T) {
+	home := setupFixtures(t)
+
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-myproject")
+	sessionLines := []string{
+		`{"type":"user","uuid":"u0000000-0000","message":{"role":"user","content":"start"},"cwd":"/Users/test/myproject","timestamp":"2026-03-01T10:00:00Z"}`,
+	}
+	for i := 1; i <= 9; i++ {
+		text := fmt.Sprintf("step %d", i)
+		if i == 5 {
+			text = "we decided on sqlite for the queue"
+		}
+		role := "assistant"
+		if i%2 == 0 {
+			role = "user"
+		}
+		sessionLines = append(sessionLines, fmt.Sprintf(`{"type":"%s","uuid":"u%d000000-0000","message":{"role":"%s","content":"%s"},"timestamp":"2026-03-01T10:0%d:00Z"}`, role, i, role, text, i))
+	}
+	writeLines(t, filepath.Join(projDir, "rref1234-5678-9abc-def0-777777777777.jsonl"), sessionLines)
+
+	out := captureStdout(t, func() {
+		if err := (&SearchCmd{Query: "sqlite", Session: "rref1234", MaxMatches: 3}).Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var results []struct {
+		Matches []struct {
+			Ref  string `json:"ref"`
+			UUID string `json:"uuid"`
+		} `json:"matches"`
+	}
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(results) != 1 || len(results[0].Matches) != 1 {
+		t.Fatalf("results = %+v", results)
+	}
+	ref := results[0].Matches[0].Ref
+	if ref != "rref1234#u5000000" || results[0].Matches[0].UUID != "u5000000-0000" {
+		t.Fatalf("ref = %q", ref)
+	}
+
+	out = captureStdout(t, func() {
+		if err := (&ExportCmd{Around: ref, Context: 1, Role: "user,assistant"}).Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var result exportJSONOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for _, m := range result.Messages {
+		texts = append(texts, m.Text)
+	}
+	if strings.Join(texts, "|") != "step 4|we decided on sqlite for the queue|step 6" {
+		t.Errorf("--around = %q", texts)
+	}
+
+	// The ref as the ID does the same; a ref in another session is refused.
+	out = captureStdout(t, func() {
+		if err := (&ExportCmd{ID: ref, Context: 0, Role: "user,assistant"}).Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "we decided on sqlite") || strings.Contains(out, "step 4") {
+		t.Errorf("export <ref> --context 0:\n%s", out)
+	}
+	if err := (&ExportCmd{ID: "abcd1234", Around: ref, Role: "user,assistant"}).Run(&Globals{}); err == nil {
+		t.Error("expected an error for --around in a different session")
+	}
+	if err := (&ExportCmd{Around: ref, Chain: true, Role: "user,assistant"}).Run(&Globals{}); err == nil {
+		t.Error("expected an error for --around with --chain")
+	}
+}
+
+func TestExportCmd_Range(t *testing.T) {
+	home := setupFixtures(t)
+
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-myproject")
+	writeLines(t, filepath.Join(projDir, "rng01234-5678-9abc-def0-888888888888.jsonl"), []string{
+		`{"type":"user","uuid":"r1000000-0000","message":{"role":"user","content":"first prompt"},"cwd":"/Users/test/myproject","timestamp":"2026-03-01T10:00:00Z"}`,
+		`{"type":"assistant","uuid":"r2000000-0000","message":{"role":"assistant","content":[{"type":"text","text":"reply one"}]},"timestamp":"2026-03-01T10:01:00Z"}`,
+		`{"type":"user","uuid":"r3000000-0000","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"tool output"}]},"timestamp":"2026-03-01T10:02:00Z"}`,
+		`{"type":"assistant","uuid":"r4000000-0000","message":{"role":"assistant","content":[{"type":"text","text":"reply two"}]},"timestamp":"2026-03-01T10:03:00Z"}`,
+		`{"type":"user","uuid":"r5000000-0000","message":{"role":"user","content":"second prompt"},"timestamp":"2026-03-01T10:04:00Z"}`,
+		`{"type":"assistant","uuid":"r6000000-0000","message":{"role":"assistant","content":[{"type":"text","text":"reply three"}]},"timestamp":"2026-03-01T10:05:00Z"}`,
+		`{"type":"user","uuid":"r7000000-0000","message":{"role":"user","content":"third prompt"},"timestamp":"2026-03-01T10:06:00Z"}`,
+		`{"type":"assistant","uuid":"r8000000-0000","message":{"role":"assistant","content":[{"type":"text","text":"reply four"}]},"timestamp":"2026-03-01T10:07:00Z"}`,
+	})
+
+	exportTexts := func(cmd ExportCmd) []string {
+		t.Helper()
+		cmd.ID, cmd.Role = "rng01234", "user,assistant"
+		out := captureStdout(t, func() {
+			if err := cmd.Run(&Globals{JSON: true}); err != nil {
+				t.Fatal(err)
+			}
+		})
+		var result exportJSONOutput
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatal(err)
+		}
+		var texts []string
+		for _, m := range result.Messages {
+			texts = append(texts, fmt.Sprintf("%d:%s", m.Index, m.Text))
+		}
+		return texts
+	}
+	clock := func(s string) string {
+		ts, _ := time.Parse(time.RFC3339, s)
+		return ts.Local().Format("15:04")
+	}
+
+	tests := []struct {
+		name string
+		cmd  ExportCmd
+		want string
+	}{
+		// Numbering counts the skipped tool result, as message_count does.
+		{"from to", ExportCmd{From: 2, To: 5}, "2:reply one|4:reply two|5:second prompt"},
+		{"since until", ExportCmd{Since: clock("2026-03-01T10:03:00Z"), Until: clock("2026-03-01T10:06:00Z")}, "4:reply two|5:second prompt|6:reply three"},
+		{"turn", ExportCmd{Turn: 1}, "1:first prompt|2:reply one|4:reply two"},
+		{"last turn", ExportCmd{Turn: -1}, "7:third prompt|8:reply four"},
+		{"uuids", ExportCmd{FromUUID: "r4", ToUUID: "r6"}, "4:reply two|5:second prompt|6:reply three"},
+		{"with limit", ExportCmd{From: 4, Limit: 2}, "7:third prompt|8:reply four"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(exportTexts(tt.cmd), "|"); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	out := captureStdout(t, func() {
+		if err := (&ExportCmd{ID: "rng01234", Role: "user,assistant", Turn: 2}).Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "second prompt") || !strings.Contains(out, "reply three") || strings.Contains(out, "reply two") {
+		t.Errorf("markdown --turn 2:\n%s", out)
+	}
+	out = captureStdout(t, func() {
+		if err := (&ExportCmd{ID: "rng01234", Role: "user,assistant", Render: true, From: 7}).Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "third prompt") || strings.Contains(out, "second prompt") {
+		t.Errorf("--render --from 7:\n%s", out)
+	}
+
+	for _, bad := range []ExportCmd{{From: 5, To: 2}, {From: 9}, {Since: "nope"}, {FromUUID: "ffff"}, {Turn: 1, Chain: true}, {FromUUID: "r4", Chain: true}} {
+		bad.ID, bad.Role = "rng01234", "user,assistant"
+		if err := bad.Run(&Globals{}); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
+
 func TestExportCmd_HintOnSkippedToolBlocks(t *testing.T) {
 	home := setupFixtures(t)
 
@@ -585,6 +837,20 @@ func TestResumeCmd_DryRun(t *testing.T) {
 	}
 }
 
+func TestResumeCmd_DryRunConfigDirTrailingSlash(t *testing.T) {
+	home := setupFixtures(t)
+	t.Setenv("CLAUDE_CONFIG_DIR", filepath.Join(home, ".claude")+"/")
+
+	out := captureStdout(t, func() {
+		if err := (&ResumeCmd{ID: "abcd1234", DryRun: true}).Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if strings.Contains(out, "CLAUDE_CONFIG_DIR=") {
+		t.Errorf("the current config dir shouldn't be set again: %q", out)
+	}
+}
+
 func TestShellQuote(t *testing.T) {
 	tests := []struct {
 		input string
@@ -27,6 +27,7 @@ func (cmd *BackupSweepCmd) Run(globals *Globals) error {
 	opts := backup.Options{
 		IncludeAgents: !cmd.NoAgents,
 		IncludeActive: cmd.IncludeActive,
+		Root:          globals.Root,
 	}
 	if !cmd.Quiet && !globals.JSON {
 		opts.Progress = os.Stderr
@@ -52,7 +53,7 @@ func (cmd *BackupSweepCmd) Run(globals *Globals) error {
 type BackupStatusCmd struct{}
 
 func (cmd *BackupStatusCmd) Run(globals *Globals) error {
-	status, err := backup.BuildStatus()
+	status, err := backup.BuildStatus(globals.Root)
 	if err != nil {
 		return fmt.Errorf("status: %w", err)
 	}
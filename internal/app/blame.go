@@ -0,0 +1,314 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andyhtran/cct/internal/index"
+	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/session"
+)
+
+type BlameCmd struct {
+	Target string `arg:"" help:"Commit SHA, or a file path with an optional :line"`
+	Limit  int    `short:"n" help:"Max sessions to show when falling back to file edits" default:"5"`
+}
+
+// Blame match kinds, strongest first.
+const (
+	blameCommit  = "commit"         // SHA recorded from git's summary line
+	blameSubject = "commit-subject" // quiet commit matched by subject and time
+	blameEdit    = "file-edit"      // edits to the file inside the commit's window
+)
+
+type blameResult struct {
+	Match    string           `json:"match"`
+	SHA      string           `json:"sha,omitempty"`
+	Subject  string           `json:"subject,omitempty"`
+	Path     string           `json:"path,omitempty"`
+	Edits    int              `json:"edits,omitempty"`
+	Session  *session.Session `json:"session"`
+	Position session.Position `json:"position"`
+}
+
+var shaRe = regexp.MustCompile(`^[0-9a-fA-F]{4,40}$`)
+
+// uncommitted is the all-zero SHA git blame reports for working-tree lines.
+const uncommitted = "0000000000000000000000000000000000000000"
+
+func (cmd *BlameCmd) Run(globals *Globals) error {
+	path, line, sha, err := parseBlameTarget(cmd.Target)
+	if err != nil {
+		return err
+	}
+
+	idx, err := index.Open()
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+	defer func() { _ = idx.Close() }()
+
+	b := &blamer{idx: idx, root: globals.Root, limit: cmd.Limit}
+	var results []blameResult
+	if sha != "" {
+		dir, _ := os.Getwd()
+		results, err = b.commit(dir, sha, nil)
+	} else {
+		results, err = b.file(path, line)
+	}
+	if err != nil {
+		return err
+	}
+
+	for i := range results {
+		r := &results[i]
+		if pos, err := session.PositionAt(r.Session.FilePath, r.Position.Offset); err == nil {
+			r.Position = pos
+		}
+	}
+
+	if globals.JSON {
+		if results == nil {
+			results = []blameResult{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+
+	if len(results) == 0 {
+		fmt.Println("  No session found for " + cmd.Target + ".")
+		return nil
+	}
+	if b.note != "" {
+		fmt.Println()
+		fmt.Println("  " + output.Dim(b.note))
+	}
+	for _, r := range results {
+		printBlameResult(r)
+	}
+	fmt.Println()
+	return nil
+}
+
+// parseBlameTarget splits a blame argument into a file path and line, or a
+// commit SHA. An existing file wins over a SHA-shaped name.
+func parseBlameTarget(target string) (path string, line int, sha string, err error) {
+	if i := strings.LastIndex(target, ":"); i > 0 {
+		if n, convErr := strconv.Atoi(target[i+1:]); convErr == nil && n > 0 {
+			if _, statErr := os.Stat(target[:i]); statErr == nil {
+				abs, err := filepath.Abs(target[:i])
+				return abs, n, "", err
+			}
+		}
+	}
+	if info, statErr := os.Stat(target); statErr == nil && !info.IsDir() {
+		abs, err := filepath.Abs(target)
+		return abs, 0, "", err
+	}
+	if shaRe.MatchString(target) {
+		return "", 0, strings.ToLower(target), nil
+	}
+	return "", 0, "", fmt.Errorf("%q is neither an existing file nor a commit SHA", target)
+}
+
+type blamer struct {
+	idx   *index.Index
+	root  string
+	limit int
+	note  string // explains a fallback match, printed above the results
+}
+
+// file blames path (and line, when > 0) via git, falling back to the most
+// recent edits when the file or line isn't committed.
+func (b *blamer) file(path string, line int) ([]blameResult, error) {
+	dir := filepath.Dir(path)
+	var sha string
+	if line > 0 {
+		out, err := git(dir, "blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", line, line), "--", path)
+		if err == nil {
+			sha, _, _ = strings.Cut(out, " ")
+			// A boundary commit can carry a leading ^.
+			sha = strings.TrimPrefix(sha, "^")
+		}
+	} else if out, err := git(dir, "log", "-1", "--format=%H", "--", path); err == nil {
+		sha = out
+	}
+
+	if sha == "" || sha == uncommitted {
+		b.note = "Not committed yet; showing the latest sessions that edited this file."
+		edits, err := b.idx.FileEdits(index.FileEditFilter{Root: b.root, Path: path, Limit: 200})
+		if err != nil {
+			return nil, err
+		}
+		return groupEdits(edits, b.limit), nil
+	}
+	return b.commit(dir, sha, []string{path})
+}
+
+// commit finds the session behind sha: first by recorded SHA, then by
+// subject for quiet commits, then by edits to the commit's files (or
+// paths, when given) between the previous commit and this one.
+func (b *blamer) commit(dir, sha string, paths []string) ([]blameResult, error) {
+	if full, err := git(dir, "rev-parse", "--verify", "--quiet", sha+"^{commit}"); err == nil {
+		sha = full
+	}
+
+	commits, err := b.idx.CommitsBySHA(b.root, sha)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) > 0 {
+		return commitResults(commits, blameCommit), nil
+	}
+
+	out, err := git(dir, "show", "-s", "--format=%ct%x00%s", sha)
+	if err != nil {
+		return nil, nil
+	}
+	ctStr, subject, _ := strings.Cut(out, "\x00")
+	ct, _ := strconv.ParseInt(ctStr, 10, 64)
+	committed := time.Unix(ct, 0)
+
+	commits, err = b.idx.CommitsBySubject(b.root, subject, committed.Add(-10*time.Minute), committed.Add(10*time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) > 0 {
+		return commitResults(commits, blameSubject), nil
+	}
+
+	top, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, nil
+	}
+	if paths == nil {
+		names, err := git(dir, "show", "--name-only", "--format=", sha)
+		if err != nil {
+			return nil, nil
+		}
+		for _, name := range strings.Split(names, "\n") {
+			if name != "" {
+				paths = append(paths, filepath.Join(top, name))
+			}
+		}
+	}
+
+	// The window opens at the previous commit touching these paths; edits
+	// before it were already committed elsewhere.
+	since := committed.Add(-24 * time.Hour)
+	logArgs := append([]string{"log", "-1", "--format=%ct", sha + "^", "--"}, paths...)
+	if prev, err := git(dir, logArgs...); err == nil && prev != "" {
+		if n, err := strconv.ParseInt(prev, 10, 64); err == nil {
+			since = time.Unix(n, 0)
+		}
+	}
+	until := committed.Add(time.Minute)
+
+	var edits []index.FileEditRow
+	for _, p := range paths {
+		rel, _ := filepath.Rel(top, p)
+		rows, err := b.idx.FileEdits(index.FileEditFilter{Root: b.root, Path: p, Suffix: rel, Since: since, Until: until})
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, rows...)
+	}
+	b.note = fmt.Sprintf("No session recorded commit %s; showing sessions that edited its files between %s and %s.",
+		shortSHA(sha), since.Local().Format("2006-01-02 15:04"), committed.Local().Format("2006-01-02 15:04"))
+	results := groupEdits(edits, b.limit)
+	for i := range results {
+		results[i].SHA = sha
+		results[i].Subject = subject
+	}
+	return results, nil
+}
+
+func commitResults(commits []index.CommitRow, match string) []blameResult {
+	var out []blameResult
+	for _, c := range commits {
+		out = append(out, blameResult{
+			Match:    match,
+			SHA:      c.SHA,
+			Subject:  c.Subject,
+			Session:  c.Session,
+			Position: session.Position{Offset: c.Offset},
+		})
+	}
+	return out
+}
+
+// groupEdits collapses edits (newest first) to one result per session,
+// pointing at that session's latest edit.
+func groupEdits(edits []index.FileEditRow, limit int) []blameResult {
+	var out []blameResult
+	seen := map[string]int{}
+	for _, e := range edits {
+		key := e.Session.Root + "/" + e.Session.ID
+		if i, ok := seen[key]; ok {
+			out[i].Edits++
+			continue
+		}
+		if limit > 0 && len(out) >= limit {
+			continue
+		}
+		seen[key] = len(out)
+		out = append(out, blameResult{
+			Match:    blameEdit,
+			Path:     e.Path,
+			Edits:    1,
+			Session:  e.Session,
+			Position: session.Position{Offset: e.Offset},
+		})
+	}
+	return out
+}
+
+// git runs a git subcommand in dir and returns its trimmed stdout.
+func git(dir string, args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func printBlameResult(r blameResult) {
+	s := r.Session
+	fmt.Println()
+	switch r.Match {
+	case blameEdit:
+		fmt.Printf("  %s   %s %s\n", output.Dim("Edited:"), r.Path,
+			output.Dim(fmt.Sprintf("(%d %s)", r.Edits, plural(r.Edits, "edit", "edits"))))
+	default:
+		label := shortSHA(r.SHA)
+		if label == "" {
+			label = "(quiet commit)"
+		}
+		fmt.Printf("  %s   %s %s\n", output.Dim("Commit:"), output.Bold(label), r.Subject)
+	}
+	fmt.Printf("  %s  %s  %s %s\n", output.Dim("Session:"), output.Bold(s.ShortID), projectLabel(s),
+		output.Dim("— "+output.Truncate(sessionTitle(s), 60)))
+
+	where := fmt.Sprintf("#%d", r.Position.Message)
+	if !r.Position.At.IsZero() {
+		where += " at " + r.Position.At.Local().Format("2006-01-02 15:04:05")
+	}
+	fmt.Printf("  %s  %s %s\n", output.Dim("Message:"), where, output.Dim(fmt.Sprintf("(byte offset %d)", r.Position.Offset)))
+	if r.Position.Prompt != "" {
+		fmt.Printf("  %s   %s\n", output.Dim("Prompt:"), output.Truncate(r.Position.Prompt, 300))
+	}
+	fmt.Printf("  %s\n", output.Cyan("cct export "+s.ShortID))
+}
@@ -0,0 +1,146 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func runBlame(t *testing.T, target string) []blameResult {
+	t.Helper()
+	out := captureStdout(t, func() {
+		if err := (&BlameCmd{Target: target, Limit: 5}).Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var results []blameResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	return results
+}
+
+func TestBlameCmd_RecordedSHA(t *testing.T) {
+	home := setupFixtures(t)
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-myproject")
+	lines := []string{
+		`{"type":"user","message":{"role":"user","content":"tidy the parser and commit"},"cwd":"/Users/test/myproject","timestamp":"2026-02-03T10:00:00Z"}`,
+		`{"type":"assistant","timestamp":"2026-02-03T10:00:05Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"c1","name":"Bash","input":{"command":"git commit -am \"Tidy parser\""}}]}}`,
+		`{"type":"user","timestamp":"2026-02-03T10:00:06Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"c1","content":"[main 0badc0de1] Tidy parser\n 1 file changed"}]}}`,
+	}
+	original := filepath.Join(projDir, "blam1111-2222-3333-4444-555555555555.jsonl")
+	writeLines(t, original, lines)
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(original, old, old); err != nil {
+		t.Fatal(err)
+	}
+	// A resumed file replays the commit; it stays credited to the original.
+	writeLines(t, filepath.Join(projDir, "blam2222-2222-3333-4444-555555555555.jsonl"), append(lines,
+		`{"type":"user","message":{"role":"user","content":"carry on"},"timestamp":"2026-02-04T10:00:00Z"}`,
+	))
+
+	results := runBlame(t, "0badc0de1f2e3d4c")
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	r := results[0]
+	if r.Match != blameCommit || r.SHA != "0badc0de1" || r.Subject != "Tidy parser" {
+		t.Errorf("result = %+v", r)
+	}
+	if r.Session.ShortID != "blam1111" || r.Position.Message != 2 || r.Position.Prompt != "tidy the parser and commit" {
+		t.Errorf("session/position = %s / %+v", r.Session.ShortID, r.Position)
+	}
+
+	if results := runBlame(t, "feedface"); len(results) != 0 {
+		t.Errorf("unknown SHA: got %+v", results)
+	}
+}
+
+func TestBlameCmd_FileEditFallback(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	home := setupFixtures(t)
+
+	repo := filepath.Join(t.TempDir(), "repo")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(repo, "main.go")
+	commitAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	gitRun := func(args ...string) {
+		t.Helper()
+		c := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		c.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com",
+			"GIT_AUTHOR_DATE="+commitAt.Format(time.RFC3339), "GIT_COMMITTER_DATE="+commitAt.Format(time.RFC3339))
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	gitRun("init", "-q")
+	if err := os.WriteFile(file, []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun("add", "main.go")
+	gitRun("commit", "-q", "-m", "Add main")
+
+	// The session edited the file 5 minutes before the (manual) commit.
+	editAt := commitAt.Add(-5 * time.Minute).UTC().Format(time.RFC3339)
+	projDir := filepath.Join(home, ".claude", "projects", "-tmp-repo")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	lines := []string{
+		`{"type":"user","message":{"role":"user","content":"write a main package"},"cwd":"` + repo + `","timestamp":"` + editAt + `"}`,
+		`{"type":"assistant","timestamp":"` + editAt + `","message":{"role":"assistant","content":[{"type":"tool_use","id":"w1","name":"Write","input":{"file_path":"` + file + `","content":"package main"}}]}}`,
+		`{"type":"user","timestamp":"` + editAt + `","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"w1","content":"ok"}]}}`,
+	}
+	original := filepath.Join(projDir, "edit1111-2222-3333-4444-555555555555.jsonl")
+	writeLines(t, original, lines)
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(original, old, old); err != nil {
+		t.Fatal(err)
+	}
+	// A resumed file replaying the edit doesn't add a session or an edit.
+	writeLines(t, filepath.Join(projDir, "edit2222-2222-3333-4444-555555555555.jsonl"), lines)
+
+	results := runBlame(t, file+":3")
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	r := results[0]
+	if r.Match != blameEdit || r.Path != file || r.Subject != "Add main" || r.Session.ShortID != "edit1111" || r.Edits != 1 {
+		t.Errorf("result = %+v", r)
+	}
+	if r.Position.Prompt != "write a main package" {
+		t.Errorf("prompt = %q", r.Position.Prompt)
+	}
+}
+
+func TestParseBlameTarget(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "abc1234")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if path, line, sha, err := parseBlameTarget(file + ":12"); err != nil || path != file || line != 12 || sha != "" {
+		t.Errorf("path:line = %q %d %q %v", path, line, sha, err)
+	}
+	if path, _, sha, err := parseBlameTarget(file); err != nil || path != file || sha != "" {
+		t.Errorf("existing SHA-named file = %q %q %v", path, sha, err)
+	}
+	if _, _, sha, err := parseBlameTarget("DEADBEEF"); err != nil || sha != "deadbeef" {
+		t.Errorf("sha = %q %v", sha, err)
+	}
+	if _, _, _, err := parseBlameTarget("no/such/file.go"); err == nil {
+		t.Error("missing file: want error")
+	}
+}
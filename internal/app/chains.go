@@ -0,0 +1,62 @@
+package app
+
+import "github.com/andyhtran/cct/internal/session"
+
+// loadChain returns the resume chain holding s, oldest first, or nil when
+// s stands alone or the index can't be opened.
+func loadChain(globals *Globals, s *session.Session) []*session.Session {
+	idx := openIndexForRead(globals)
+	if idx == nil {
+		return nil
+	}
+	defer func() { _ = idx.Close() }()
+	chain, err := idx.ChainOf(s.Root, s.ID)
+	if err != nil {
+		return nil
+	}
+	return chain
+}
+
+// setChainLinks fills s.Predecessors and s.Successors from its chain.
+func setChainLinks(s *session.Session, chain []*session.Session) {
+	after := false
+	for _, c := range chain {
+		switch {
+		case c.ID == s.ID:
+			after = true
+		case after:
+			s.Successors = append(s.Successors, c.ID)
+		default:
+			s.Predecessors = append(s.Predecessors, c.ID)
+		}
+	}
+}
+
+// collapseChains keeps one row per resume chain — its latest session,
+// with the rest listed as predecessors — at the position of the chain's
+// first row in sessions.
+func collapseChains(sessions []*session.Session, chains [][]*session.Session) []*session.Session {
+	chainOf := map[string][]*session.Session{}
+	for _, chain := range chains {
+		for _, s := range chain {
+			chainOf[s.Root+"/"+s.ID] = chain
+		}
+	}
+	var out []*session.Session
+	done := map[*session.Session]bool{}
+	for _, s := range sessions {
+		chain, ok := chainOf[s.Root+"/"+s.ID]
+		if !ok {
+			out = append(out, s)
+			continue
+		}
+		latest := chain[len(chain)-1]
+		if done[latest] {
+			continue
+		}
+		done[latest] = true
+		setChainLinks(latest, chain)
+		out = append(out, latest)
+	}
+	return out
+}
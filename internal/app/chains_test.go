@@ -0,0 +1,99 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andyhtran/cct/internal/session"
+)
+
+// writeChainFixtures writes one conversation split across two files: the
+// second replays the first's messages, as `claude --resume` does, then
+// continues.
+func writeChainFixtures(t *testing.T, home string) {
+	t.Helper()
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-chain")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	first := []string{
+		`{"type":"user","uuid":"u1","parentUuid":null,"cwd":"/Users/test/chain","sessionId":"chain111-0000-0000-0000-000000000000","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"start the migration"}}`,
+		`{"type":"assistant","uuid":"a1","parentUuid":"u1","timestamp":"2026-03-01T10:00:05Z","message":{"role":"assistant","content":"Migration started."}}`,
+	}
+	writeLines(t, filepath.Join(projDir, "chain111-0000-0000-0000-000000000000.jsonl"), first)
+	writeLines(t, filepath.Join(projDir, "chain222-0000-0000-0000-000000000000.jsonl"), append(slices.Clone(first),
+		`{"type":"user","uuid":"u2","parentUuid":"a1","cwd":"/Users/test/chain","sessionId":"chain222-0000-0000-0000-000000000000","timestamp":"2026-03-02T09:00:00Z","message":{"role":"user","content":"finish the migration"}}`,
+		`{"type":"assistant","uuid":"a2","parentUuid":"u2","timestamp":"2026-03-02T09:00:05Z","message":{"role":"assistant","content":"Migration finished."}}`,
+	))
+}
+
+func TestInfoCmd_Chain(t *testing.T) {
+	home := setupFixtures(t)
+	writeChainFixtures(t, home)
+
+	out := captureStdout(t, func() {
+		cmd := &InfoCmd{ID: "chain111"}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var s session.Session
+	if err := json.Unmarshal([]byte(out), &s); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(s.Predecessors) != 0 || len(s.Successors) != 1 || s.Successors[0] != "chain222-0000-0000-0000-000000000000" {
+		t.Errorf("predecessors = %v, successors = %v", s.Predecessors, s.Successors)
+	}
+}
+
+func TestListCmd_CollapseChains(t *testing.T) {
+	home := setupFixtures(t)
+	writeChainFixtures(t, home)
+
+	out := captureStdout(t, func() {
+		cmd := &ListCmd{All: true, CollapseChains: true}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var sessions []session.Session
+	if err := json.Unmarshal([]byte(out), &sessions); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	var chained []session.Session
+	for _, s := range sessions {
+		if strings.HasPrefix(s.ID, "chain") {
+			chained = append(chained, s)
+		}
+	}
+	if len(chained) != 1 || chained[0].ID != "chain222-0000-0000-0000-000000000000" || len(chained[0].Predecessors) != 1 {
+		t.Errorf("chain rows = %+v", chained)
+	}
+}
+
+func TestExportCmd_Chain(t *testing.T) {
+	home := setupFixtures(t)
+	writeChainFixtures(t, home)
+
+	out := captureStdout(t, func() {
+		cmd := &ExportCmd{ID: "chain222", Role: "user,assistant", Chain: true}
+		if err := cmd.Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if n := strings.Count(out, "Migration started."); n != 1 {
+		t.Errorf("replayed message exported %d times, want 1:\n%s", n, out)
+	}
+	started := strings.Index(out, "Migration started.")
+	resumed := strings.Index(out, "*Resumed as session `chain222`*")
+	finished := strings.Index(out, "Migration finished.")
+	if !(started >= 0 && started < resumed && resumed < finished) {
+		t.Errorf("chain not exported in order:\n%s", out)
+	}
+}
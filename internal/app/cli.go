@@ -8,6 +8,7 @@ import (
 
 	"github.com/alecthomas/kong"
 
+	"github.com/andyhtran/cct/internal/paths"
 	"github.com/andyhtran/cct/internal/skill"
 )
 
@@ -22,25 +23,38 @@ type CLI struct {
 
 	Version kong.VersionFlag `short:"v" help:"Show version"`
 
-	Default     DefaultCmd   `cmd:"" default:"noargs" hidden:""`
-	List        ListCmd      `cmd:"" help:"List recent sessions"`
-	Search      SearchCmd    `cmd:"" help:"Search session content\n\nJSON fields: id, short_id, project_name, project_path, created, modified, first_prompt, git_branch, message_count, matches, score\n\nExample: cct search 'query' --json | jq '.[] | {short_id, project_name, created}'"`
-	Info        InfoCmd      `cmd:"" help:"Show session metadata and first prompt"`
-	Resume      ResumeCmd    `cmd:"" help:"Resume a session (auto-switches directory)"`
-	Export      ExportCmd    `cmd:"" help:"Export session messages (with filtering)"`
-	View        ViewCmd      `cmd:"" help:"View session in interactive TUI"`
-	Plans       PlansCmd     `cmd:"" help:"Browse and search plans"`
-	Stats       StatsCmd     `cmd:"" help:"Session statistics"`
-	Changelog   ChangelogCmd `cmd:"" aliases:"log" help:"Show Claude Code changelog\n\nFetches the upstream CHANGELOG.md from the claude-code GitHub repo (cached locally for 6h). Use this to look up recent features, behavior changes, and disable flags.\n\nExamples:\n  cct changelog                              # Latest release only\n  cct changelog 2.1.111                      # A specific version\n  cct changelog --since 2.1.100 --all        # Every change since 2.1.100\n  cct changelog --search 'disable|opt.?out'  # Grep across all entries\n  cct changelog --refresh                    # Force re-fetch from GitHub"`
-	VersionInfo VersionCmd   `cmd:"" name:"version" help:"Show version information"`
-	Schema      SchemaCmd    `cmd:"" help:"Show CLI schema as JSON (for tooling)"`
-	Index       IndexCmd     `cmd:"" help:"Manage search index"`
-	Backup      BackupCmd    `cmd:"" help:"Back up session JSONL files (guards against upstream cleanup bugs).\n\nRun 'cct backup sweep' periodically (cron, shell hook, or manually).\ncct never modifies ~/.claude/settings.json."`
-	Skill       SkillCmd     `cmd:"" help:"Manage the cct Claude Code skill (install/uninstall/status/nudge)"`
+	Default     DefaultCmd     `cmd:"" default:"noargs" hidden:""`
+	List        ListCmd        `cmd:"" help:"List recent sessions"`
+	Search      SearchCmd      `cmd:"" help:"Search session content\n\nJSON fields: id, short_id, project_name, project_path, created, modified, first_prompt, git_branch, message_count, matches, score\n\nExample: cct search 'query' --json | jq '.[] | {short_id, project_name, created}'"`
+	Info        InfoCmd        `cmd:"" help:"Show session metadata and first prompt"`
+	Resume      ResumeCmd      `cmd:"" help:"Resume a session (auto-switches directory)"`
+	Export      ExportCmd      `cmd:"" help:"Export session messages (with filtering)"`
+	View        ViewCmd        `cmd:"" help:"View session in interactive TUI"`
+	Plans       PlansCmd       `cmd:"" help:"Browse and search plans"`
+	Stats       StatsCmd       `cmd:"" help:"Session statistics"`
+	Report      ReportCmd      `cmd:"" help:"Markdown standup report of recent work, grouped by project and branch"`
+	Blame       BlameCmd       `cmd:"" help:"Find the session behind a commit SHA or a file[:line]"`
+	Agents      AgentsCmd      `cmd:"" help:"Tree of subagents a session spawned, with prompt, duration, tokens, and result"`
+	Diff        DiffCmd        `cmd:"" help:"Replay a session's Edit, MultiEdit, and Write calls as a git apply-able patch"`
+	Snapshots   SnapshotsCmd   `cmd:"" help:"List a session's file-history checkpoints and restore one into a directory"`
+	Todos       TodosCmd       `cmd:"" help:"A session's final TodoWrite list with completion times, or unfinished todos across sessions (--open)"`
+	History     HistoryCmd     `cmd:"" help:"Search Claude Code's global prompt history (history.jsonl), linking each prompt to its session"`
+	Prompts     PromptsCmd     `cmd:"" help:"Prompts you keep retyping: grouped, ranked by reuse, copyable, and savable as slash commands"`
+	Failures    FailuresCmd    `cmd:"" help:"Tool errors, API errors, interrupts, and permission denials across sessions, grouped by error signature, project, or day"`
+	Permissions PermissionsCmd `cmd:"" help:"Suggest permissions.allow rules from the Bash and MCP calls you keep approving, and flag repeated denials (never writes settings)"`
+	Links       LinksCmd       `cmd:"" help:"Find URLs from prompts, replies, WebFetch, and WebSearch, or count them per domain"`
+	Snippets    SnippetsCmd    `cmd:"" help:"Search fenced code blocks from assistant replies by text, language, or defined name, and print or save one raw"`
+	Changelog   ChangelogCmd   `cmd:"" aliases:"log" help:"Show Claude Code changelog\n\nFetches the upstream CHANGELOG.md from the claude-code GitHub repo (cached locally for 6h). Use this to look up recent features, behavior changes, and disable flags.\n\nExamples:\n  cct changelog                              # Latest release only\n  cct changelog 2.1.111                      # A specific version\n  cct changelog --since 2.1.100 --all        # Every change since 2.1.100\n  cct changelog --search 'disable|opt.?out'  # Grep across all entries\n  cct changelog --refresh                    # Force re-fetch from GitHub"`
+	VersionInfo VersionCmd     `cmd:"" name:"version" help:"Show version information"`
+	Schema      SchemaCmd      `cmd:"" help:"Show CLI schema as JSON (for tooling)"`
+	Index       IndexCmd       `cmd:"" help:"Manage search index"`
+	Backup      BackupCmd      `cmd:"" help:"Back up session JSONL files (guards against upstream cleanup bugs).\n\nRun 'cct backup sweep' periodically (cron, shell hook, or manually).\ncct never modifies ~/.claude/settings.json."`
+	Skill       SkillCmd       `cmd:"" help:"Manage the cct Claude Code skill (install/uninstall/status/nudge)"`
 }
 
 type Globals struct {
-	JSON bool `help:"Output as JSON" name:"json"`
+	JSON bool   `help:"Output as JSON" name:"json"`
+	Root string `help:"Only use sessions from this Claude data root (see CCT_ROOTS)" name:"root" placeholder:"NAME"`
 }
 
 func Run(version string) int {
@@ -67,6 +81,13 @@ func Run(version string) int {
 		return 1
 	}
 
+	if cli.Root != "" {
+		if _, ok := paths.LookupRoot(cli.Root); !ok {
+			fmt.Fprintf(os.Stderr, "cct: unknown root %q (configured: %s)\n", cli.Root, strings.Join(paths.RootNames(), ", "))
+			return 1
+		}
+	}
+
 	// Skip skill side effects for `cct skill *` (would be circular) and for
 	// `cct schema` (output is consumed by tooling that expects clean stdout).
 	selected := ctx.Command()
@@ -0,0 +1,88 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andyhtran/cct/internal/session"
+)
+
+func writeCompactFixture(t *testing.T, home string) {
+	t.Helper()
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-compact")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, filepath.Join(projDir, "cmpct111-0000-0000-0000-000000000000.jsonl"), []string{
+		`{"type":"user","cwd":"/Users/test/compact","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"build the parser"}}`,
+		`{"type":"assistant","timestamp":"2026-03-01T10:00:05Z","message":{"role":"assistant","content":"Parser scaffolded."}}`,
+		`{"type":"system","subtype":"compact_boundary","timestamp":"2026-03-01T11:00:00Z","compactMetadata":{"trigger":"auto","preTokens":168968,"postTokens":11638,"durationMs":49246}}`,
+		`{"type":"user","isCompactSummary":true,"timestamp":"2026-03-01T11:00:01Z","message":{"role":"user","content":"Summary: the parser is scaffolded."}}`,
+		`{"type":"user","timestamp":"2026-03-01T11:01:00Z","message":{"role":"user","content":"now add error recovery"}}`,
+		`{"type":"assistant","timestamp":"2026-03-01T11:01:05Z","message":{"role":"assistant","content":"Error recovery added."}}`,
+	})
+}
+
+func TestExportCmd_Compaction(t *testing.T) {
+	home := setupFixtures(t)
+	writeCompactFixture(t, home)
+
+	out := captureStdout(t, func() {
+		cmd := &ExportCmd{ID: "cmpct111", Role: "user,assistant"}
+		if err := cmd.Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	divider := strings.Index(out, "## Context compacted")
+	if divider < 0 || !strings.Contains(out, "auto · 169k → 12k tokens · 49s") {
+		t.Fatalf("missing compaction divider:\n%s", out)
+	}
+	if strings.Count(out, "Summary: the parser is scaffolded.") != 1 || strings.Contains(out, "## User\n\nSummary:") {
+		t.Errorf("summary should appear once, under the divider:\n%s", out)
+	}
+	if !(strings.Index(out, "Parser scaffolded.") < divider && divider < strings.Index(out, "Error recovery added.")) {
+		t.Errorf("divider out of place:\n%s", out)
+	}
+
+	after := captureStdout(t, func() {
+		cmd := &ExportCmd{ID: "cmpct111", Role: "user,assistant", AfterLastCompact: true}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var data exportJSONOutput
+	if err := json.Unmarshal([]byte(after), &data); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(data.Messages) != 3 {
+		t.Fatalf("got %d messages after the last compaction, want 3", len(data.Messages))
+	}
+	first := data.Messages[0]
+	if first.Role != "compact" || first.Compaction == nil || first.Compaction.PreTokens != 168968 || first.Text != "Summary: the parser is scaffolded." {
+		t.Errorf("first message = %+v", first)
+	}
+}
+
+func TestInfoCmd_Compactions(t *testing.T) {
+	home := setupFixtures(t)
+	writeCompactFixture(t, home)
+
+	out := captureStdout(t, func() {
+		cmd := &InfoCmd{ID: "cmpct111"}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var s session.Session
+	if err := json.Unmarshal([]byte(out), &s); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(s.Compactions) != 1 || s.Compactions[0].Trigger != "auto" || s.Compactions[0].DurationMS != 49246 {
+		t.Errorf("compactions = %+v", s.Compactions)
+	}
+}
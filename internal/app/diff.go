@@ -0,0 +1,338 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/patch"
+	"github.com/andyhtran/cct/internal/session"
+)
+
+type DiffCmd struct {
+	ID     string `arg:"" help:"Session ID or prefix"`
+	From   int    `help:"First message number to include (the #N cct blame reports)"`
+	To     int    `help:"Last message number to include (0 = through the end)"`
+	Output string `short:"o" help:"Write the patch to a file instead of stdout"`
+	Check  bool   `help:"Check the patch applies to the working tree at the session's project path"`
+}
+
+type diffFile struct {
+	Path      string `json:"path"`
+	Status    string `json:"status"` // "created" or "modified"
+	Edits     int    `json:"edits"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	// Approximate is set when some lines were only recorded with tabs
+	// shown as spaces and their whitespace had to be guessed.
+	Approximate bool `json:"approximate,omitempty"`
+}
+
+// diffSkip is a change left out of the patch, and why.
+type diffSkip struct {
+	Path    string `json:"path"`
+	Tool    string `json:"tool"`
+	Message int    `json:"message"`
+	Reason  string `json:"reason"`
+}
+
+type diffCheck struct {
+	Dir            string `json:"dir"`
+	Applies        bool   `json:"applies"`
+	AlreadyApplied bool   `json:"already_applied"`
+	Output         string `json:"output,omitempty"`
+}
+
+type diffResult struct {
+	Session *session.Session `json:"session"`
+	Files   []diffFile       `json:"files"`
+	Skipped []diffSkip       `json:"skipped,omitempty"`
+	Patch   string           `json:"patch"`
+	Check   *diffCheck       `json:"check,omitempty"`
+}
+
+func (cmd *DiffCmd) Run(globals *Globals) error {
+	match, err := findSession(globals, cmd.ID)
+	if err != nil {
+		return err
+	}
+	ops, err := session.ReadFileOps(match.FilePath)
+	if err != nil {
+		return fmt.Errorf("read session: %w", err)
+	}
+
+	res := diffResult{Session: match, Files: []diffFile{}}
+	files := map[string]*patch.File{}
+	edits := map[string]int{}
+	for _, op := range ops {
+		if op.Message < cmd.From || (cmd.To > 0 && op.Message > cmd.To) {
+			continue
+		}
+		f, ok := files[op.Path]
+		if !ok {
+			f = patch.NewFile(op.Path)
+			files[op.Path] = f
+		}
+		if err := applyOp(f, op); err != nil {
+			res.Skipped = append(res.Skipped, diffSkip{Path: op.Path, Tool: op.Tool, Message: op.Message, Reason: err.Error()})
+			continue
+		}
+		if op.Tool != "Read" {
+			edits[op.Path]++
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var b strings.Builder
+	for _, p := range paths {
+		f := files[p]
+		if !f.Changed() {
+			continue
+		}
+		name, ok := projectRelative(match.ProjectPath, p)
+		if !ok {
+			res.Skipped = append(res.Skipped, diffSkip{Path: p, Reason: "outside the project directory"})
+			continue
+		}
+		if err := f.WriteDiff(&b, name, 3); err != nil {
+			return err
+		}
+		df := diffFile{Path: name, Status: "modified", Edits: edits[p]}
+		if f.Created() {
+			df.Status = "created"
+		}
+		df.Additions, df.Deletions = f.Stat()
+		df.Approximate = f.Approximate()
+		res.Files = append(res.Files, df)
+	}
+	res.Patch = b.String()
+
+	if cmd.Check && res.Patch != "" {
+		res.Check = checkPatch(match.ProjectPath, res.Patch)
+	}
+
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+
+	if res.Patch != "" {
+		if cmd.Output != "" {
+			if err := os.WriteFile(cmd.Output, []byte(res.Patch), 0o644); err != nil {
+				return err
+			}
+		} else {
+			fmt.Print(res.Patch)
+		}
+	}
+	printDiffSummary(res)
+
+	if res.Check != nil && !res.Check.Applies && !res.Check.AlreadyApplied {
+		return fmt.Errorf("patch does not apply to %s", res.Check.Dir)
+	}
+	return nil
+}
+
+// applyOp replays one tool call onto f. The hunks Claude Code recorded
+// place each change; the tool input, and the lines Reads returned, supply
+// the exact bytes where the recorded hunks only show tabs as spaces.
+func applyOp(f *patch.File, op *session.FileOp) error {
+	if orig, ok := op.Result["originalFile"].(string); ok {
+		f.SetOriginal(orig)
+	}
+	hunks, recorded := resultHunks(op.Result)
+	_, err := f.Content()
+	known := err == nil
+
+	switch op.Tool {
+	case "Read":
+		file, _ := op.Result["file"].(map[string]any)
+		if content, ok := file["content"].(string); ok {
+			f.Observe(intField(file, "startLine"), content, intField(file, "totalLines"))
+		}
+		return nil
+
+	case "Write":
+		content, _ := op.Input["content"].(string)
+		switch {
+		case op.Result["type"] == "create" || (!recorded && f.Created()):
+			f.Create(content)
+			return nil
+		case !recorded:
+			return errors.New("no recorded patch for an overwrite")
+		}
+		if err := f.ApplyAll(hunks); err != nil {
+			return err
+		}
+		f.ObserveAll(content)
+		return nil
+
+	case "Edit":
+		oldText, _ := op.Input["old_string"].(string)
+		newText, _ := op.Input["new_string"].(string)
+		all, _ := op.Input["replace_all"].(bool)
+		if known || !recorded {
+			if err := f.Replace(oldText, newText, all); err == nil || !recorded {
+				return err
+			}
+		}
+		if all {
+			return f.ApplyAll(hunks)
+		}
+		return f.ApplyEdit(hunks, oldText, newText)
+
+	case "MultiEdit":
+		if known || !recorded {
+			if err := replaceEach(f, op.Input); err == nil || !recorded {
+				return err
+			}
+		}
+		return f.ApplyAll(hunks)
+	}
+	return fmt.Errorf("unsupported tool %s", op.Tool)
+}
+
+// replaceEach applies a MultiEdit's edits in order, all or nothing.
+func replaceEach(f *patch.File, input map[string]any) error {
+	list, _ := input["edits"].([]any)
+	saved := *f
+	for _, item := range list {
+		e, _ := item.(map[string]any)
+		oldText, _ := e["old_string"].(string)
+		newText, _ := e["new_string"].(string)
+		all, _ := e["replace_all"].(bool)
+		if err := f.Replace(oldText, newText, all); err != nil {
+			*f = saved
+			return err
+		}
+	}
+	return nil
+}
+
+func intField(m map[string]any, key string) int {
+	n, _ := m[key].(float64)
+	return int(n)
+}
+
+// resultHunks reads toolUseResult.structuredPatch, which holds lines in
+// display form. ok is false when the result has none, as in logs written
+// before Claude Code recorded it; an empty list means no change.
+func resultHunks(result map[string]any) ([]patch.Hunk, bool) {
+	raw, ok := result["structuredPatch"].([]any)
+	if !ok {
+		return nil, false
+	}
+	hunks := make([]patch.Hunk, 0, len(raw))
+	for _, item := range raw {
+		m, _ := item.(map[string]any)
+		h := patch.Hunk{Display: true}
+		if n, ok := m["oldStart"].(float64); ok {
+			h.OldStart = int(n)
+		}
+		if n, ok := m["oldLines"].(float64); ok {
+			h.OldLines = int(n)
+		}
+		if n, ok := m["newStart"].(float64); ok {
+			h.NewStart = int(n)
+		}
+		if n, ok := m["newLines"].(float64); ok {
+			h.NewLines = int(n)
+		}
+		lines, _ := m["lines"].([]any)
+		for _, l := range lines {
+			if s, ok := l.(string); ok {
+				h.Lines = append(h.Lines, s)
+			}
+		}
+		hunks = append(hunks, h)
+	}
+	return hunks, true
+}
+
+// projectRelative returns path relative to the project directory, as git
+// apply wants, or false when it lies outside.
+func projectRelative(project, path string) (string, bool) {
+	if project == "" || !filepath.IsAbs(path) {
+		return "", false
+	}
+	rel, err := filepath.Rel(project, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// checkPatch runs git apply --check against dir, and when that fails,
+// checks whether the patch is already applied there.
+func checkPatch(dir, p string) *diffCheck {
+	check := &diffCheck{Dir: dir}
+	run := func(args ...string) (string, error) {
+		c := exec.Command("git", append([]string{"-C", dir, "apply", "--check"}, args...)...)
+		c.Stdin = strings.NewReader(p)
+		var out bytes.Buffer
+		c.Stdout, c.Stderr = &out, &out
+		err := c.Run()
+		return strings.TrimSpace(out.String()), err
+	}
+	out, err := run("-")
+	if err == nil {
+		check.Applies = true
+		return check
+	}
+	check.Output = out
+	if _, err := run("--reverse", "-"); err == nil {
+		check.AlreadyApplied = true
+	}
+	return check
+}
+
+// printDiffSummary reports the files in the patch and anything skipped on
+// stderr, so stdout stays a clean patch for git apply.
+func printDiffSummary(res diffResult) {
+	w := os.Stderr
+	if len(res.Files) == 0 && len(res.Skipped) == 0 {
+		_, _ = fmt.Fprintln(w, "No file changes in this session.")
+		return
+	}
+	for _, f := range res.Files {
+		status := ""
+		if f.Status == "created" {
+			status = output.Dim(" (new)")
+		}
+		if f.Approximate {
+			status += output.Dim(" (whitespace guessed)")
+		}
+		_, _ = fmt.Fprintf(w, "  %s%s  +%d -%d %s\n", f.Path, status, f.Additions, f.Deletions,
+			output.Dim(fmt.Sprintf("from %d %s", f.Edits, plural(f.Edits, "edit", "edits"))))
+	}
+	for _, s := range res.Skipped {
+		where := ""
+		if s.Message > 0 {
+			where = fmt.Sprintf(" (%s, message #%d)", s.Tool, s.Message)
+		}
+		_, _ = fmt.Fprintf(w, "  %s %s%s: %s\n", output.Dim("skipped"), s.Path, where, s.Reason)
+	}
+	if c := res.Check; c != nil {
+		switch {
+		case c.Applies:
+			_, _ = fmt.Fprintf(w, "Patch applies cleanly to %s\n", c.Dir)
+		case c.AlreadyApplied:
+			_, _ = fmt.Fprintf(w, "Patch is already applied in %s\n", c.Dir)
+		default:
+			_, _ = fmt.Fprintf(w, "Patch does not apply to %s:\n%s\n", c.Dir, c.Output)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeDiffFixture records a session that reads main.go, edits it, creates
+// new.go, and makes one failed edit, in a real project directory holding
+// main.go as it stood before the session.
+func writeDiffFixture(t *testing.T, home string) string {
+	t.Helper()
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, "main.go"), []byte("package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	projDir := filepath.Join(home, ".claude", "projects", "-tmp-diff")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	main := filepath.Join(project, "main.go")
+	writeLines(t, filepath.Join(projDir, "diff1111-0000-0000-0000-000000000000.jsonl"), []string{
+		`{"type":"user","cwd":"` + project + `","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"say hello"}}`,
+		`{"type":"assistant","timestamp":"2026-03-01T10:00:01Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Read","input":{"file_path":"` + main + `"}}]}}`,
+		`{"type":"user","timestamp":"2026-03-01T10:00:02Z","toolUseResult":{"type":"text","file":{"filePath":"` + main + `","content":"package main\n\nfunc main() {\n\tprintln(\"hi\")\n}","startLine":1,"numLines":5,"totalLines":5}},"message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"..."}]}}`,
+		`{"type":"assistant","timestamp":"2026-03-01T10:00:03Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Edit","input":{"file_path":"` + main + `","old_string":"println(\"hi\")","new_string":"println(\"hello\")"}}]}}`,
+		`{"type":"user","timestamp":"2026-03-01T10:00:04Z","toolUseResult":{"filePath":"` + main + `","originalFile":null,"structuredPatch":[{"oldStart":3,"oldLines":3,"newStart":3,"newLines":3,"lines":[" func main() {","-  println(\"hi\")","+  println(\"hello\")"," }"]}]},"message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t2","content":"ok"}]}}`,
+		`{"type":"assistant","timestamp":"2026-03-01T10:00:05Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t3","name":"Write","input":{"file_path":"` + filepath.Join(project, "new.go") + `","content":"package main\n\nvar x = 1\n"}}]}}`,
+		`{"type":"user","timestamp":"2026-03-01T10:00:06Z","toolUseResult":{"type":"create","filePath":"` + filepath.Join(project, "new.go") + `","content":"package main\n\nvar x = 1\n","structuredPatch":[]},"message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t3","content":"ok"}]}}`,
+		`{"type":"assistant","timestamp":"2026-03-01T10:00:07Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t4","name":"Edit","input":{"file_path":"` + main + `","old_string":"missing","new_string":"x"}}]}}`,
+		`{"type":"user","timestamp":"2026-03-01T10:00:08Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t4","is_error":true,"content":"String to replace not found"}]}}`,
+	})
+	return project
+}
+
+func TestDiffCmd(t *testing.T) {
+	home := setupFixtures(t)
+	writeDiffFixture(t, home)
+
+	out := captureStdout(t, func() {
+		cmd := &DiffCmd{ID: "diff1111"}
+		if err := cmd.Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	for _, want := range []string{
+		"diff --git a/main.go b/main.go\n",
+		"-\tprintln(\"hi\")\n+\tprintln(\"hello\")\n",
+		"diff --git a/new.go b/new.go\nnew file mode 100644\n--- /dev/null\n+++ b/new.go\n",
+		"+var x = 1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("patch missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "missing") {
+		t.Errorf("failed edit leaked into the patch:\n%s", out)
+	}
+
+	// Only the Write is at message #6 or later.
+	out = captureStdout(t, func() {
+		cmd := &DiffCmd{ID: "diff1111", From: 6}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var res diffResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(res.Files) != 1 || res.Files[0].Path != "new.go" || res.Files[0].Status != "created" || res.Files[0].Additions != 3 {
+		t.Errorf("files = %+v", res.Files)
+	}
+}
+
+func TestDiffCmd_Check(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	home := setupFixtures(t)
+	project := writeDiffFixture(t, home)
+	if err := exec.Command("git", "-C", project, "init", "-q").Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() {
+		cmd := &DiffCmd{ID: "diff1111", Check: true}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var res diffResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if res.Check == nil || !res.Check.Applies {
+		t.Fatalf("check = %+v, want the patch to apply", res.Check)
+	}
+	for _, f := range res.Files {
+		if f.Approximate {
+			t.Errorf("%s approximate, want exact after the Read", f.Path)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"strings"
 	"time"
@@ -14,7 +15,7 @@ import (
 )
 
 type ExportCmd struct {
-	ID                 string `arg:"" help:"Session ID or prefix"`
+	ID                 string `arg:"" optional:"" help:"Session ID or prefix, or a match ref from cct search (same as --around)"`
 	Full               bool   `help:"Show everything (no truncation, include tool results)"`
 	Short              bool   `help:"Compact output (truncate messages to 500 chars)"`
 	Render             bool   `help:"Render with syntax highlighting (styled terminal output)"`
@@ -25,42 +26,97 @@ type ExportCmd struct {
 	MaxToolChars       int    `help:"Truncate tool result text to N chars (0=no limit)" default:"2000" name:"max-tool-chars"`
 	IncludeToolResults bool   `help:"Include tool result content" name:"include-tool-results"`
 	Search             string `short:"s" help:"Filter messages containing this text (case-insensitive)"`
+	Agents             bool   `help:"Inline subagent transcripts at the Task call that spawned them"`
+	Chain              bool   `help:"Export the whole resume chain as one transcript, skipping replayed messages"`
+	AfterLastCompact   bool   `help:"Start at the last context compaction: its summary and what followed" name:"after-last-compact"`
+	Thinking           bool   `help:"Include the model's thinking blocks, set apart from its replies"`
+	Around             string `help:"Only the messages around this match ref from cct search (<session>#<message>)" placeholder:"REF"`
+	Context            int    `help:"Messages to keep on each side of --around" default:"10"`
+	From               int    `help:"First message to export, numbered from 1 as in the session's message count" placeholder:"N"`
+	To                 int    `help:"Last message to export" placeholder:"N"`
+	Since              string `help:"Only messages from this time: 14:00 (on the session's first day), 2026-01-31 14:00, 30m, or RFC3339"`
+	Until              string `help:"Only messages before this time (same forms as --since)"`
+	Turn               int    `help:"Only the Nth typed prompt and what followed it, up to the next; negative counts from the end (--turn=-1)" placeholder:"N"`
+	FromUUID           string `help:"Start at the message with this uuid (or prefix)" name:"from-uuid" placeholder:"UUID"`
+	ToUUID             string `help:"End at the message with this uuid (or prefix)" name:"to-uuid" placeholder:"UUID"`
+}
+
+// exportOptions is the resolved form of ExportCmd's flags.
+type exportOptions struct {
+	roles              map[string]bool
+	maxChars           int
+	maxToolChars       int
+	limit              int
+	includeToolResults bool
+	search             string
+	spawns             map[string]*session.AgentSpawn // keyed by tool_use_id; nil unless --agents
+	chain              []*session.Session             // resume chain, oldest first; nil unless --chain
+	seen               map[string]bool                // message uuids already exported from the chain
+	afterLastCompact   bool
+	thinking           bool
+	selection          session.Selection
 }
 
 func (cmd *ExportCmd) Run(globals *Globals) error {
-	match, err := session.FindByPrefixFull(cmd.ID)
+	match, selection, err := cmd.resolve(globals)
 	if err != nil {
 		return err
 	}
 
-	maxChars := cmd.MaxChars
-	maxToolChars := cmd.MaxToolChars
-	includeToolResults := cmd.IncludeToolResults
+	opts := exportOptions{
+		roles:              parseRoles(cmd.Role),
+		maxChars:           cmd.MaxChars,
+		maxToolChars:       cmd.MaxToolChars,
+		limit:              cmd.Limit,
+		includeToolResults: cmd.IncludeToolResults,
+		search:             cmd.Search,
+		afterLastCompact:   cmd.AfterLastCompact,
+		thinking:           cmd.Thinking,
+		selection:          selection,
+	}
 	if cmd.Full {
-		maxChars = 0
-		maxToolChars = 0
-		includeToolResults = true
+		opts.maxChars = 0
+		opts.maxToolChars = 0
+		opts.includeToolResults = true
 	}
 	if cmd.Short {
-		maxChars = 500
+		opts.maxChars = 500
+	}
+	if cmd.Chain {
+		if cmd.Render {
+			return fmt.Errorf("--chain can't be combined with --render")
+		}
+		opts.chain = loadChain(globals, match)
+	}
+	if cmd.Agents {
+		opts.spawns = map[string]*session.AgentSpawn{}
+		for _, s := range exportSessions(match, opts) {
+			spawns, err := session.LinkSpawns(s)
+			if err != nil {
+				return fmt.Errorf("link subagents: %w", err)
+			}
+			maps.Copy(opts.spawns, session.SpawnsByToolUse(spawns))
+		}
 	}
-
-	roles := parseRoles(cmd.Role)
 
 	if globals.JSON {
-		return cmd.exportJSON(match, roles, maxChars, maxToolChars, includeToolResults, cmd.Search)
+		return cmd.exportJSON(match, opts)
 	}
 
 	if cmd.Render {
 		return render.RenderSession(match, render.Options{
-			MaxChars:           maxChars,
-			MaxToolChars:       maxToolChars,
-			IncludeToolResults: includeToolResults,
-			Limit:              cmd.Limit,
+			MaxChars:           opts.maxChars,
+			MaxToolChars:       opts.maxToolChars,
+			IncludeToolResults: opts.includeToolResults,
+			Limit:              opts.limit,
+			Agents:             opts.spawns,
+			AfterLastCompact:   opts.afterLastCompact,
+			Thinking:           opts.thinking,
+			Selection:          opts.selection,
 		})
 	}
 
-	md, stats, err := renderMarkdown(match, roles, maxChars, maxToolChars, cmd.Limit, includeToolResults, cmd.Search)
+	md, stats, err := renderMarkdown(match, opts)
 	if err != nil {
 		return err
 	}
@@ -79,6 +135,88 @@ func (cmd *ExportCmd) Run(globals *Globals) error {
 	return nil
 }
 
+// resolve finds the session to export and the messages to keep. A ref
+// passed as the ID works like --around; an ID and --around must name the
+// same session.
+func (cmd *ExportCmd) resolve(globals *Globals) (*session.Session, session.Selection, error) {
+	var sel session.Selection
+	around := cmd.Around
+	if around == "" && strings.Contains(cmd.ID, "#") {
+		around = cmd.ID
+	}
+	if around == "" {
+		if cmd.ID == "" {
+			return nil, sel, fmt.Errorf("export needs a session ID or --around <ref>")
+		}
+		s, err := findSession(globals, cmd.ID)
+		if err != nil {
+			return nil, sel, err
+		}
+		return s, sel, cmd.resolveRange(s, &sel)
+	}
+
+	if cmd.Chain {
+		return nil, sel, fmt.Errorf("a match ref points into one session file and can't be combined with --chain")
+	}
+	s, anchor, err := findSessionRef(globals, around)
+	if err != nil {
+		return nil, sel, err
+	}
+	if anchor == nil {
+		return nil, sel, fmt.Errorf("--around needs a match ref (<session>#<message>), not a session ID")
+	}
+	if id, _ := session.SplitRef(cmd.ID); cmd.ID != "" && !strings.HasPrefix(s.ID, id) {
+		return nil, sel, fmt.Errorf("--around %s is in session %s, not %s", around, s.ShortID, cmd.ID)
+	}
+	sel.Around = anchor
+	sel.Context = cmd.Context
+	return s, sel, cmd.resolveRange(s, &sel)
+}
+
+// resolveRange adds the --from/--to, --since/--until, --turn, and
+// --from-uuid/--to-uuid bounds to sel. Clock times are on the day the
+// session started.
+func (cmd *ExportCmd) resolveRange(s *session.Session, sel *session.Selection) error {
+	if cmd.From < 0 || cmd.To < 0 {
+		return fmt.Errorf("--from and --to are message numbers, counting from 1")
+	}
+	if cmd.To > 0 && cmd.From > cmd.To {
+		return fmt.Errorf("--from %d is after --to %d", cmd.From, cmd.To)
+	}
+	if cmd.Chain && (cmd.From > 0 || cmd.To > 0 || cmd.Turn != 0 || cmd.FromUUID != "" || cmd.ToUUID != "") {
+		return fmt.Errorf("--from, --to, --turn, --from-uuid, and --to-uuid point into one session file and can't be combined with --chain")
+	}
+	sel.From, sel.To, sel.Turn = cmd.From, cmd.To, cmd.Turn
+
+	day := s.FirstMessageAt
+	if day.IsZero() {
+		day = s.Created
+	}
+	now := time.Now()
+	var err error
+	if sel.Since, err = parseClockTime(cmd.Since, day, now); err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+	if sel.Until, err = parseClockTime(cmd.Until, day, now); err != nil {
+		return fmt.Errorf("--until: %w", err)
+	}
+	if !sel.Since.IsZero() && !sel.Until.IsZero() && !sel.Since.Before(sel.Until) {
+		return fmt.Errorf("--since must be before --until")
+	}
+
+	if cmd.FromUUID != "" {
+		if sel.Start, err = session.LocateAnchor(s.FilePath, cmd.FromUUID); err != nil {
+			return fmt.Errorf("--from-uuid: %w", err)
+		}
+	}
+	if cmd.ToUUID != "" {
+		if sel.End, err = session.LocateAnchor(s.FilePath, cmd.ToUUID); err != nil {
+			return fmt.Errorf("--to-uuid: %w", err)
+		}
+	}
+	return nil
+}
+
 type exportStats struct {
 	toolBlocksSkipped int
 	messagesTruncated int
@@ -107,12 +245,72 @@ func parseRoles(roleStr string) map[string]bool {
 	return roles
 }
 
-func renderMarkdown(s *session.Session, roles map[string]bool, maxChars, maxToolChars, limit int, includeToolResults bool, searchFilter string) (string, exportStats, error) {
-	f, err := os.Open(s.FilePath)
+// exportSessions is the session files an export reads: the resume chain
+// with --chain, otherwise just s.
+func exportSessions(s *session.Session, opts exportOptions) []*session.Session {
+	if len(opts.chain) > 0 {
+		return opts.chain
+	}
+	return []*session.Session{s}
+}
+
+// exportMessages collects the messages of every exported session file in
+// order, then keeps the last opts.limit. Across a chain, messages a resumed
+// file replays from an earlier one are skipped by uuid.
+func exportMessages(s *session.Session, opts exportOptions) ([]exportMessage, exportStats, error) {
+	if len(opts.chain) > 0 {
+		opts.seen = map[string]bool{}
+	}
+	var messages []exportMessage
+	var stats exportStats
+	for _, es := range exportSessions(s, opts) {
+		f, err := os.Open(es.FilePath)
+		if err != nil {
+			return nil, stats, fmt.Errorf("cannot open session file: %w", err)
+		}
+		msgs, st := collectMessages(f, opts)
+		_ = f.Close()
+		for i := range msgs {
+			msgs[i].session = es
+		}
+		messages = append(messages, msgs...)
+		stats.toolBlocksSkipped += st.toolBlocksSkipped
+	}
+	if opts.afterLastCompact {
+		messages = afterLastCompact(messages)
+	}
+	marks := make([]session.MessageMark, len(messages))
+	for i, m := range messages {
+		marks[i] = m.mark
+	}
+	lo, hi, err := opts.selection.Window(marks)
 	if err != nil {
-		return "", exportStats{}, fmt.Errorf("cannot open session file: %w", err)
+		return nil, stats, err
+	}
+	messages = messages[lo:hi]
+	if opts.limit > 0 && len(messages) > opts.limit {
+		messages = messages[len(messages)-opts.limit:]
+	}
+	return messages, stats, nil
+}
+
+// afterLastCompact drops the messages before the last compaction divider,
+// leaving what the model still had in context. Without a compaction the
+// whole conversation is in context, so nothing is dropped.
+func afterLastCompact(messages []exportMessage) []exportMessage {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].compaction != nil {
+			return messages[i:]
+		}
+	}
+	return messages
+}
+
+func renderMarkdown(s *session.Session, opts exportOptions) (string, exportStats, error) {
+	messages, stats, err := exportMessages(s, opts)
+	if err != nil {
+		return "", stats, err
 	}
-	defer func() { _ = f.Close() }()
 
 	var b strings.Builder
 
@@ -127,53 +325,141 @@ func renderMarkdown(s *session.Session, roles map[string]bool, maxChars, maxTool
 		fmt.Fprintf(&b, "- **Created**: %s\n", s.Created.Local().Format("2006-01-02 15:04:05"))
 	}
 	fmt.Fprintf(&b, "- **Messages**: %d\n", s.MessageCount)
+	if len(opts.chain) > 0 {
+		ids := make([]string, len(opts.chain))
+		for i, c := range opts.chain {
+			ids[i] = "`" + c.ShortID + "`"
+		}
+		fmt.Fprintf(&b, "- **Chain**: %s\n", strings.Join(ids, " → "))
+	}
 	b.WriteString("\n---\n\n")
 
-	messages, stats := collectMessages(f, roles, includeToolResults, searchFilter, maxToolChars)
-
-	if limit > 0 && len(messages) > limit {
-		messages = messages[len(messages)-limit:]
-	}
+	writeMarkdownMessages(&b, messages, opts, &stats, "##")
+	return b.String(), stats, nil
+}
 
+// writeMarkdownMessages writes messages under headings at level heading,
+// inlining any linked subagent transcript after the message that spawned
+// it as a blockquote one heading level deeper.
+func writeMarkdownMessages(b *strings.Builder, messages []exportMessage, opts exportOptions, stats *exportStats, heading string) {
+	var prev *session.Session
 	for _, msg := range messages {
+		if prev != nil && msg.session != prev {
+			fmt.Fprintf(b, "*Resumed as session `%s`*\n\n---\n\n", msg.session.ShortID)
+		}
+		prev = msg.session
+
 		text := msg.text
-		if maxChars > 0 && len(text) > maxChars {
-			text = output.TruncateWithCount(text, maxChars)
+		if opts.maxChars > 0 && len(text) > opts.maxChars {
+			text = output.TruncateWithCount(text, opts.maxChars)
 			stats.messagesTruncated++
 		}
 
-		if msg.role == "user" {
-			b.WriteString("## User\n\n")
-		} else {
-			b.WriteString("## Assistant\n\n")
+		if msg.compaction != nil {
+			writeMarkdownCompaction(b, msg, text, heading)
+			continue
+		}
+		switch msg.role {
+		case "user":
+			b.WriteString(heading + " User\n\n")
+		case "thinking":
+			// Quoted so the reasoning reads apart from the reply.
+			b.WriteString(heading + " Thinking\n\n")
+			text = render.Quote(text)
+		default:
+			b.WriteString(heading + " Assistant\n\n")
 		}
 		b.WriteString(text)
-		b.WriteString("\n\n---\n\n")
+		b.WriteString("\n\n")
+
+		for _, id := range msg.toolUseIDs {
+			if sp, ok := opts.spawns[id]; ok {
+				writeMarkdownAgent(b, sp, opts, stats, heading+"#")
+			}
+		}
+		if heading == "##" {
+			b.WriteString("---\n\n")
+		}
 	}
+}
 
-	return b.String(), stats, nil
+// writeMarkdownCompaction writes a compaction as a divider, with the
+// generated summary that replaced the earlier context folded underneath.
+func writeMarkdownCompaction(b *strings.Builder, msg exportMessage, summary, heading string) {
+	b.WriteString(heading + " Context compacted\n\n")
+	var meta []string
+	if !msg.timestamp.IsZero() {
+		meta = append(meta, msg.timestamp.Local().Format("2006-01-02 15:04"))
+	}
+	if label := msg.compaction.Label(); label != "" {
+		meta = append(meta, label)
+	}
+	if len(meta) > 0 {
+		fmt.Fprintf(b, "*%s*\n\n", strings.Join(meta, " · "))
+	}
+	if summary != "" {
+		b.WriteString("<details>\n<summary>Summary carried into the new context</summary>\n\n")
+		b.WriteString(summary)
+		b.WriteString("\n\n</details>\n\n")
+	}
+	if heading == "##" {
+		b.WriteString("---\n\n")
+	}
+}
+
+func writeMarkdownAgent(b *strings.Builder, sp *session.AgentSpawn, opts exportOptions, stats *exportStats, heading string) {
+	f, err := os.Open(sp.Agent.FilePath)
+	if err != nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+	messages, agentStats := collectMessages(f, opts)
+	stats.toolBlocksSkipped += agentStats.toolBlocksSkipped
+
+	var inner strings.Builder
+	fmt.Fprintf(&inner, "%s Subagent %s\n\n", heading, agentLabel(sp))
+	writeMarkdownMessages(&inner, messages, opts, stats, heading+"#")
+	fmt.Fprintf(&inner, "*%s*\n", agentSummary(sp))
+
+	for _, line := range strings.Split(strings.TrimRight(inner.String(), "\n"), "\n") {
+		if line == "" {
+			b.WriteString(">\n")
+			continue
+		}
+		b.WriteString("> " + line + "\n")
+	}
+	b.WriteString("\n")
 }
 
 type exportMessage struct {
-	role      string
-	text      string
-	timestamp time.Time
+	role       string
+	text       string
+	timestamp  time.Time
+	toolUseIDs []string
+	session    *session.Session // file the message came from; set by exportMessages
+	compaction *session.Compaction
+	mark       session.MessageMark // the record the message came from
 }
 
-func collectMessages(r io.Reader, roles map[string]bool, includeToolResults bool, searchFilter string, maxToolChars int) ([]exportMessage, exportStats) {
+func collectMessages(r io.Reader, opts exportOptions) ([]exportMessage, exportStats) {
 	scanner := session.NewOffsetScanner(r)
 	var messages []exportMessage
 	var stats exportStats
-	searchLower := strings.ToLower(searchFilter)
+	searchLower := strings.ToLower(opts.search)
+	marker := session.NewMarker(opts.selection.Turn != 0)
 
 	for scanner.Scan() {
 		line := scanner.Bytes()
 		lineType := session.FastExtractType(line)
+		marker.Observe(lineType, line)
 
-		if lineType != "user" && lineType != "assistant" {
+		if lineType != "user" && lineType != "assistant" && lineType != "system" {
 			continue
 		}
-		if !roles[lineType] {
+		// Compaction records are kept whatever the role filter: they mark
+		// where the earlier context was replaced by a summary.
+		compaction := session.MayBeCompaction(line)
+		if !compaction && !opts.roles[lineType] {
 			continue
 		}
 
@@ -181,25 +467,65 @@ func collectMessages(r io.Reader, roles map[string]bool, includeToolResults bool
 		if json.Unmarshal(line, &obj) != nil {
 			continue
 		}
+		uuid, _ := obj["uuid"].(string)
+		if opts.seen != nil && uuid != "" {
+			if opts.seen[uuid] {
+				continue
+			}
+			opts.seen[uuid] = true
+		}
+		if compaction {
+			switch {
+			case session.IsCompactBoundary(obj):
+				c := session.NewCompaction(obj)
+				messages = append(messages, exportMessage{role: "compact", timestamp: c.At, compaction: c, mark: marker.Mark(uuid, scanner.Offset(), c.At)})
+				continue
+			case session.IsCompactSummary(obj):
+				summary := session.CompactSummaryText(obj)
+				if n := len(messages); n > 0 && messages[n-1].compaction != nil && messages[n-1].text == "" {
+					messages[n-1].text = summary
+				} else {
+					ts := session.ParseTimestamp(obj)
+					messages = append(messages, exportMessage{role: "compact", text: summary, timestamp: ts, compaction: &session.Compaction{At: ts}, mark: marker.Mark(uuid, scanner.Offset(), ts)})
+				}
+				continue
+			}
+			if lineType == "system" || !opts.roles[lineType] {
+				continue
+			}
+		}
+
+		ts := session.ParseTimestamp(obj)
+		mark := marker.Mark(uuid, scanner.Offset(), ts)
+		if opts.thinking && lineType == "assistant" {
+			for _, thought := range session.ThinkingBlocks(obj) {
+				if opts.search == "" || strings.Contains(strings.ToLower(thought), searchLower) {
+					messages = append(messages, exportMessage{role: "thinking", text: thought, timestamp: ts, mark: mark})
+				}
+			}
+		}
 
-		text, skipped := extractContent(obj, includeToolResults, maxToolChars)
+		text, skipped := extractContent(obj, opts.includeToolResults, opts.maxToolChars)
 		stats.toolBlocksSkipped += skipped
 
 		if text == "" {
 			continue
 		}
 
-		if searchFilter != "" && !strings.Contains(strings.ToLower(text), searchLower) {
+		if opts.search != "" && !strings.Contains(strings.ToLower(text), searchLower) {
 			continue
 		}
 
-		ts := session.ParseTimestamp(obj)
-
-		messages = append(messages, exportMessage{
+		msg := exportMessage{
 			role:      lineType,
 			text:      text,
 			timestamp: ts,
-		})
+			mark:      mark,
+		}
+		if len(opts.spawns) > 0 {
+			msg.toolUseIDs = session.ToolUseIDs(obj)
+		}
+		messages = append(messages, msg)
 	}
 
 	return messages, stats
@@ -262,47 +588,45 @@ func extractContent(obj map[string]any, includeToolResults bool, maxToolChars in
 
 type exportJSONOutput struct {
 	Session  *session.Session    `json:"session"`
+	Chain    []*session.Session  `json:"chain,omitempty"`
 	Messages []exportJSONMessage `json:"messages"`
 }
 
 type exportJSONMessage struct {
-	Role      string `json:"role"`
-	Timestamp string `json:"timestamp,omitempty"`
-	Text      string `json:"text"`
+	SessionID string            `json:"session_id,omitempty"` // with --chain
+	Index     int               `json:"index,omitempty"`      // message number, for --from/--to
+	UUID      string            `json:"uuid,omitempty"`       // of the record, for --from-uuid/--to-uuid
+	Role      string            `json:"role"`
+	Timestamp string            `json:"timestamp,omitempty"`
+	Text      string            `json:"text"`
+	Agents    []exportJSONAgent `json:"agents,omitempty"`
+	// Compaction is set on role "compact" dividers; Text is the summary.
+	Compaction *session.Compaction `json:"compaction,omitempty"`
 }
 
-func (cmd *ExportCmd) exportJSON(s *session.Session, roles map[string]bool, maxChars, maxToolChars int, includeToolResults bool, searchFilter string) error {
-	f, err := os.Open(s.FilePath)
-	if err != nil {
-		return fmt.Errorf("cannot open session file: %w", err)
-	}
-	defer func() { _ = f.Close() }()
-
-	messages, _ := collectMessages(f, roles, includeToolResults, searchFilter, maxToolChars)
-
-	if cmd.Limit > 0 && len(messages) > cmd.Limit {
-		messages = messages[len(messages)-cmd.Limit:]
-	}
+// exportJSONAgent is a subagent transcript nested under the message whose
+// Task call spawned it.
+type exportJSONAgent struct {
+	ToolUseID    string              `json:"tool_use_id"`
+	AgentID      string              `json:"agent_id"`
+	SubagentType string              `json:"subagent_type,omitempty"`
+	Description  string              `json:"description,omitempty"`
+	Status       string              `json:"status"`
+	DurationMS   int64               `json:"duration_ms,omitempty"`
+	TotalTokens  int                 `json:"total_tokens,omitempty"`
+	Messages     []exportJSONMessage `json:"messages"`
+}
 
-	var jsonMessages []exportJSONMessage
-	for _, msg := range messages {
-		text := msg.text
-		if maxChars > 0 && len(text) > maxChars {
-			text = output.TruncateWithCount(text, maxChars)
-		}
-		jm := exportJSONMessage{
-			Role: msg.role,
-			Text: text,
-		}
-		if !msg.timestamp.IsZero() {
-			jm.Timestamp = msg.timestamp.Format(time.RFC3339)
-		}
-		jsonMessages = append(jsonMessages, jm)
+func (cmd *ExportCmd) exportJSON(s *session.Session, opts exportOptions) error {
+	messages, _, err := exportMessages(s, opts)
+	if err != nil {
+		return err
 	}
 
 	out := exportJSONOutput{
 		Session:  s,
-		Messages: jsonMessages,
+		Chain:    opts.chain,
+		Messages: jsonMessages(messages, opts),
 	}
 
 	var w io.Writer = os.Stdout
@@ -319,3 +643,56 @@ func (cmd *ExportCmd) exportJSON(s *session.Session, roles map[string]bool, maxC
 	enc.SetIndent("", "  ")
 	return enc.Encode(out)
 }
+
+func jsonMessages(messages []exportMessage, opts exportOptions) []exportJSONMessage {
+	var out []exportJSONMessage
+	for _, msg := range messages {
+		text := msg.text
+		if opts.maxChars > 0 && len(text) > opts.maxChars {
+			text = output.TruncateWithCount(text, opts.maxChars)
+		}
+		jm := exportJSONMessage{
+			Index:      msg.mark.Index,
+			UUID:       msg.mark.UUID,
+			Role:       msg.role,
+			Text:       text,
+			Compaction: msg.compaction,
+		}
+		if len(opts.chain) > 0 && msg.session != nil {
+			jm.SessionID = msg.session.ID
+		}
+		if !msg.timestamp.IsZero() {
+			jm.Timestamp = msg.timestamp.Format(time.RFC3339)
+		}
+		for _, id := range msg.toolUseIDs {
+			if sp, ok := opts.spawns[id]; ok {
+				jm.Agents = append(jm.Agents, jsonAgent(sp, opts))
+			}
+		}
+		out = append(out, jm)
+	}
+	return out
+}
+
+func jsonAgent(sp *session.AgentSpawn, opts exportOptions) exportJSONAgent {
+	a := exportJSONAgent{
+		ToolUseID:    sp.ToolUseID,
+		AgentID:      sp.AgentID,
+		SubagentType: sp.SubagentType,
+		Description:  sp.Description,
+		Status:       sp.Status,
+		DurationMS:   sp.Duration().Milliseconds(),
+		TotalTokens:  sp.Tokens(),
+		Messages:     []exportJSONMessage{},
+	}
+	f, err := os.Open(sp.Agent.FilePath)
+	if err != nil {
+		return a
+	}
+	defer func() { _ = f.Close() }()
+	messages, _ := collectMessages(f, opts)
+	if jm := jsonMessages(messages, opts); jm != nil {
+		a.Messages = jm
+	}
+	return a
+}
@@ -0,0 +1,401 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andyhtran/cct/internal/index"
+	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/session"
+)
+
+type FailuresCmd struct {
+	Query   string   `arg:"" optional:"" help:"Only failures whose error text, tool, or command contains this"`
+	Kind    []string `short:"k" help:"Only these kinds, comma-separated: tool, api, interrupt, denied" placeholder:"KIND"`
+	By      string   `help:"Group by error signature (default), project, or day" default:"signature" enum:"signature,project,day"`
+	List    bool     `short:"l" help:"List each failure, newest first, instead of grouping"`
+	Project string   `short:"p" help:"Filter by project name"`
+	Since   string   `help:"Only failures at or after this time (today, yesterday, 7d, 12h, 2026-01-31)"`
+	Until   string   `help:"Only failures before this time (same forms as --since)"`
+	Limit   int      `short:"n" help:"Max rows (0=no limit)" default:"20"`
+	Agents  bool     `help:"Include failures inside sub-agent sessions"`
+}
+
+// failureKinds maps the --kind names to the kinds the index records.
+var failureKinds = map[string]string{
+	"tool":      session.FailureToolError,
+	"api":       session.FailureAPIError,
+	"interrupt": session.FailureInterrupt,
+	"denied":    session.FailureDenied,
+}
+
+// failureKindOrder is the order kinds are counted and shown in.
+var failureKindOrder = []string{
+	session.FailureToolError,
+	session.FailureAPIError,
+	session.FailureInterrupt,
+	session.FailureDenied,
+}
+
+func failureKindLabel(kind string) string {
+	for name, k := range failureKinds {
+		if k == kind {
+			return name
+		}
+	}
+	return kind
+}
+
+type failureEntry struct {
+	session.Failure
+	SessionID string `json:"session_id"`
+	ShortID   string `json:"short_id"`
+	Project   string `json:"project"`
+}
+
+// failureGroup is the failures sharing a signature, project, or day.
+// Example is the most recent of them.
+type failureGroup struct {
+	Key      string         `json:"key"`
+	Kind     string         `json:"kind,omitempty"`
+	Tools    []string       `json:"tools,omitempty"`
+	Count    int            `json:"count"`
+	Kinds    map[string]int `json:"kinds"`
+	Sessions int            `json:"sessions"`
+	Projects int            `json:"projects"`
+	First    time.Time      `json:"first,omitzero"`
+	Last     time.Time      `json:"last,omitzero"`
+	Example  failureEntry   `json:"example"`
+
+	sessions map[string]bool
+	projects map[string]bool
+	tools    map[string]int
+}
+
+type failuresData struct {
+	Window   string          `json:"window"`
+	Total    int             `json:"total"`
+	Kinds    map[string]int  `json:"kinds"`
+	Sessions int             `json:"sessions"`
+	Groups   []*failureGroup `json:"groups,omitempty"`
+	Failures []failureEntry  `json:"failures,omitempty"`
+}
+
+func (cmd *FailuresCmd) Run(globals *Globals) error {
+	kinds, err := parseFailureKinds(cmd.Kind)
+	if err != nil {
+		return err
+	}
+	w, err := parseTimeWindow(cmd.Since, cmd.Until, time.Now())
+	if err != nil {
+		return err
+	}
+	idx, err := index.Open()
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+	defer func() { _ = idx.Close() }()
+
+	rows, err := idx.Failures(index.EventFilter{
+		Root:          globals.Root,
+		ProjectFilter: cmd.Project,
+		IncludeAgents: cmd.Agents,
+		Since:         w.Since,
+		Until:         w.Until,
+	})
+	if err != nil {
+		return fmt.Errorf("failures: %w", err)
+	}
+
+	entries := filterFailures(rows, kinds, cmd.Query)
+	data := &failuresData{Window: describeWindow(cmd.Since, cmd.Until), Kinds: map[string]int{}}
+	sessions := map[string]bool{}
+	for _, e := range entries {
+		data.Total++
+		data.Kinds[e.Kind]++
+		sessions[e.SessionID] = true
+	}
+	data.Sessions = len(sessions)
+	if cmd.List {
+		data.Failures = entries
+		slices.Reverse(data.Failures)
+		if cmd.Limit > 0 && len(data.Failures) > cmd.Limit {
+			data.Failures = data.Failures[:cmd.Limit]
+		}
+	} else {
+		data.Groups = groupFailures(entries, cmd.By)
+		if cmd.Limit > 0 && len(data.Groups) > cmd.Limit {
+			data.Groups = data.Groups[:cmd.Limit]
+		}
+	}
+
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	}
+
+	if data.Total == 0 {
+		fmt.Println("  No failures found.")
+		return nil
+	}
+	printFailuresHeader(data)
+	if cmd.List {
+		printFailureList(data.Failures, cmd.Query)
+	} else {
+		printFailureGroups(data.Groups, cmd.By, cmd.Query)
+	}
+	fmt.Println()
+	return nil
+}
+
+func parseFailureKinds(names []string) (map[string]bool, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	kinds := map[string]bool{}
+	for _, name := range names {
+		kind, ok := failureKinds[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown failure kind %q (want tool, api, interrupt, or denied)", name)
+		}
+		kinds[kind] = true
+	}
+	return kinds, nil
+}
+
+// filterFailures narrows rows to kinds and query.
+func filterFailures(rows []index.FailureRow, kinds map[string]bool, query string) []failureEntry {
+	query = strings.ToLower(query)
+	var out []failureEntry
+	for _, r := range rows {
+		f := r.Failure
+		if kinds != nil && !kinds[f.Kind] {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(f.Text+"\x00"+f.Tool+"\x00"+f.Input), query) {
+			continue
+		}
+		out = append(out, failureEntry{
+			Failure:   f,
+			SessionID: r.Session.ID,
+			ShortID:   r.Session.ShortID,
+			Project:   r.Session.ProjectName,
+		})
+	}
+	return out
+}
+
+// groupFailures groups entries (oldest first) by by: "signature" sorts the
+// most frequent first, "project" likewise, and "day" the latest day first.
+func groupFailures(entries []failureEntry, by string) []*failureGroup {
+	groups := map[string]*failureGroup{}
+	var order []*failureGroup
+	for _, e := range entries {
+		var key, label string
+		switch by {
+		case "project":
+			key, label = e.Project, e.Project
+		case "day":
+			day := ""
+			if !e.At.IsZero() {
+				day = e.At.Local().Format("2006-01-02")
+			}
+			key, label = day, day
+		default:
+			key, label = e.Kind+"\x00"+e.Signature, e.Signature
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &failureGroup{
+				Key:      label,
+				Kinds:    map[string]int{},
+				sessions: map[string]bool{},
+				projects: map[string]bool{},
+				tools:    map[string]int{},
+			}
+			if by == "signature" {
+				g.Kind = e.Kind
+			}
+			groups[key] = g
+			order = append(order, g)
+		}
+		g.Count++
+		g.Kinds[e.Kind]++
+		g.sessions[e.SessionID] = true
+		g.projects[e.Project] = true
+		if e.Tool != "" {
+			g.tools[e.Tool]++
+		}
+		if !e.At.IsZero() && (g.First.IsZero() || e.At.Before(g.First)) {
+			g.First = e.At
+		}
+		if !e.At.Before(g.Last) {
+			g.Last = e.At
+			g.Example = e
+		}
+	}
+
+	for _, g := range order {
+		g.Sessions = len(g.sessions)
+		g.Projects = len(g.projects)
+		for t := range g.tools {
+			g.Tools = append(g.Tools, t)
+		}
+		sort.SliceStable(g.Tools, func(i, j int) bool {
+			a, b := g.Tools[i], g.Tools[j]
+			if g.tools[a] != g.tools[b] {
+				return g.tools[a] > g.tools[b]
+			}
+			return a < b
+		})
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if by == "day" {
+			return a.Key > b.Key
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Last.After(b.Last)
+	})
+	return order
+}
+
+func printFailuresHeader(data *failuresData) {
+	var parts []string
+	for _, k := range failureKindOrder {
+		if n := data.Kinds[k]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", formatInt(n), failureKindLabel(k)))
+		}
+	}
+	fmt.Println()
+	fmt.Printf("  %s  %s  %s\n", output.Pad("Failures:", 10, output.Dim), formatInt(data.Total),
+		output.Dim(fmt.Sprintf("(%d %s, %s)", data.Sessions, plural(data.Sessions, "session", "sessions"), data.Window)))
+	fmt.Printf("  %s  %s\n", output.Pad("Kinds:", 10, output.Dim), strings.Join(parts, ", "))
+	fmt.Println()
+}
+
+// toolsLabel names the tool a group's failures came from, or the most
+// frequent one and how many others.
+func toolsLabel(tools []string) string {
+	switch len(tools) {
+	case 0:
+		return "-"
+	case 1:
+		return tools[0]
+	default:
+		return fmt.Sprintf("%s +%d", tools[0], len(tools)-1)
+	}
+}
+
+func printFailureGroups(groups []*failureGroup, by, query string) {
+	if by == "signature" {
+		tbl := output.NewTable(query,
+			output.Fixed("COUNT", 6),
+			output.Fixed("KIND", 9),
+			output.Flex("TOOL", 30, 10),
+			output.Fixed("SESSIONS", 8),
+			output.Fixed("LAST", 6),
+			output.Flex("SIGNATURE", 0, 30),
+		)
+		tbl.PrintHeader()
+		for _, g := range groups {
+			tbl.Row(
+				[]string{
+					formatInt(g.Count),
+					failureKindLabel(g.Kind),
+					output.Truncate(toolsLabel(g.Tools), tbl.ColWidth(2)),
+					formatInt(g.Sessions),
+					output.FormatAge(g.Last),
+					output.Truncate(g.Key, tbl.LastColWidth()),
+				},
+				[]func(string) string{output.Bold, nil, nil, output.Dim, output.Dim, nil},
+			)
+		}
+		fmt.Println()
+		fmt.Printf("  %s\n", output.Dim("cct failures <text> --list shows each occurrence with its session"))
+		return
+	}
+
+	heading := "PROJECT"
+	if by == "day" {
+		heading = "DAY"
+	}
+	tbl := output.NewTable(query,
+		output.Flex(heading, 30, 10),
+		output.Fixed("COUNT", 6),
+		output.Fixed("TOOL", 6),
+		output.Fixed("API", 5),
+		output.Fixed("INTERRUPT", 9),
+		output.Fixed("DENIED", 6),
+		output.Fixed("SESSIONS", 8),
+		output.Flex("TOP TOOL", 0, 10),
+	)
+	tbl.PrintHeader()
+	for _, g := range groups {
+		key := g.Key
+		if key == "" {
+			key = "-"
+		}
+		tbl.Row(
+			[]string{
+				output.Truncate(key, tbl.ColWidth(0)),
+				formatInt(g.Count),
+				formatFailureCount(g.Kinds[session.FailureToolError]),
+				formatFailureCount(g.Kinds[session.FailureAPIError]),
+				formatFailureCount(g.Kinds[session.FailureInterrupt]),
+				formatFailureCount(g.Kinds[session.FailureDenied]),
+				formatInt(g.Sessions),
+				output.Truncate(toolsLabel(g.Tools), tbl.LastColWidth()),
+			},
+			[]func(string) string{output.Bold, output.Bold, nil, nil, nil, nil, output.Dim, output.Dim},
+		)
+	}
+}
+
+func formatFailureCount(n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return formatInt(n)
+}
+
+func printFailureList(entries []failureEntry, query string) {
+	tbl := output.NewTable(query,
+		output.Fixed("SESSION", 8),
+		output.Flex("PROJECT", 20, 10),
+		output.Fixed("AGE", 6),
+		output.Fixed("KIND", 9),
+		output.Flex("TOOL", 20, 8),
+		output.Flex("ERROR", 0, 30),
+	)
+	tbl.PrintHeader()
+	for _, e := range entries {
+		text := e.Text
+		if e.Input != "" {
+			text = e.Input + " → " + text
+		}
+		tool := e.Tool
+		if tool == "" {
+			tool = "-"
+		}
+		tbl.Row(
+			[]string{
+				e.ShortID,
+				output.Truncate(e.Project, tbl.ColWidth(1)),
+				output.FormatAge(e.At),
+				failureKindLabel(e.Kind),
+				output.Truncate(tool, tbl.ColWidth(4)),
+				output.Truncate(strings.Join(strings.Fields(text), " "), tbl.LastColWidth()),
+			},
+			[]func(string) string{output.Dim, output.Bold, output.Dim, nil, nil, output.Dim},
+		)
+	}
+}
@@ -0,0 +1,98 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mcpFailure(id, at, session string) []string {
+	return []string{
+		`{"type":"assistant","timestamp":"` + at + `","message":{"role":"assistant","content":[{"type":"tool_use","id":"` + id + `","name":"mcp__jira__get_issue","input":{}}]}}`,
+		`{"type":"user","timestamp":"` + at + `","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"` + id + `","is_error":true,"content":"MCP error -32000: session ` + session + ` closed"}]}}`,
+	}
+}
+
+func writeFailureFixtures(t *testing.T, home string) {
+	t.Helper()
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-flaky")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	first := append([]string{
+		`{"type":"user","cwd":"/Users/test/flaky","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"look up PROJ-1"}}`,
+	}, mcpFailure("m1", "2026-03-01T10:00:01Z", "a1b2c3d4e5")...)
+	first = append(first,
+		`{"type":"assistant","timestamp":"2026-03-01T10:00:05Z","isApiErrorMessage":true,"message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"API Error: 529 {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\"}}"}]}}`,
+	)
+	original := filepath.Join(projDir, "fail3333-0000-0000-0000-000000000000.jsonl")
+	writeLines(t, original, first)
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(original, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// A resumed copy of the first session replays its failure; it must not
+	// count twice, and stays credited to the original.
+	second := append([]string{
+		`{"type":"user","cwd":"/Users/test/flaky","timestamp":"2026-03-02T09:00:00Z","message":{"role":"user","content":"try again"}}`,
+	}, mcpFailure("m1", "2026-03-01T10:00:01Z", "a1b2c3d4e5")...)
+	second = append(second, mcpFailure("m2", "2026-03-02T09:00:01Z", "ffee99887766")...)
+	second = append(second,
+		`{"type":"user","timestamp":"2026-03-02T09:01:00Z","message":{"role":"user","content":"[Request interrupted by user]"}}`,
+	)
+	writeLines(t, filepath.Join(projDir, "fail2222-0000-0000-0000-000000000000.jsonl"), second)
+}
+
+func TestFailuresCmd(t *testing.T) {
+	home := setupFixtures(t)
+	writeFailureFixtures(t, home)
+
+	run := func(cmd *FailuresCmd) failuresData {
+		t.Helper()
+		out := captureStdout(t, func() {
+			if err := cmd.Run(&Globals{JSON: true}); err != nil {
+				t.Fatal(err)
+			}
+		})
+		var data failuresData
+		if err := json.Unmarshal([]byte(out), &data); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		return data
+	}
+
+	data := run(&FailuresCmd{Project: "flaky", By: "signature", Limit: 20})
+	if data.Total != 4 || data.Kinds["tool_error"] != 2 || data.Kinds["api_error"] != 1 || data.Kinds["interrupt"] != 1 {
+		t.Fatalf("total = %d, kinds = %v", data.Total, data.Kinds)
+	}
+	top := data.Groups[0]
+	if top.Key != "MCP error -<n>: session <id> closed" || top.Count != 2 || top.Sessions != 2 ||
+		len(top.Tools) != 1 || top.Tools[0] != "mcp__jira__get_issue" || top.Example.ShortID != "fail2222" {
+		t.Errorf("top group = %+v", top)
+	}
+
+	data = run(&FailuresCmd{Project: "flaky", By: "day", Kind: []string{"tool", "api"}, Limit: 20})
+	if len(data.Groups) != 2 || data.Groups[0].Key != "2026-03-02" || data.Groups[1].Count != 2 {
+		t.Errorf("day groups = %+v", data.Groups)
+	}
+
+	data = run(&FailuresCmd{Query: "ffee", List: true, Limit: 20})
+	if len(data.Failures) != 1 || data.Failures[0].SessionID != "fail2222-0000-0000-0000-000000000000" || data.Failures[0].Offset == 0 {
+		t.Errorf("list = %+v", data.Failures)
+	}
+
+	data = run(&FailuresCmd{Query: "a1b2", List: true, Limit: 20})
+	if len(data.Failures) != 1 || data.Failures[0].ShortID != "fail3333" {
+		t.Errorf("replayed failure = %+v, want one, from fail3333", data.Failures)
+	}
+
+	if err := (&FailuresCmd{Kind: []string{"bogus"}}).Run(&Globals{}); err == nil || !strings.Contains(err.Error(), "bogus") {
+		t.Errorf("err = %v, want unknown kind", err)
+	}
+}
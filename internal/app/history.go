@@ -0,0 +1,145 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/andyhtran/cct/internal/index"
+	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/paths"
+	"github.com/andyhtran/cct/internal/session"
+)
+
+type HistoryCmd struct {
+	Query   string `arg:"" optional:"" help:"Search query (omit for the most recent prompts)"`
+	Project string `short:"p" help:"Filter by project path"`
+	Since   string `help:"Only prompts sent at or after this time (today, yesterday, 7d, 2026-01-31)"`
+	Until   string `help:"Only prompts sent before this time (same forms as --since)"`
+	Limit   int    `short:"n" help:"Max results (0=no limit)" default:"25"`
+}
+
+// historyEntry is a prompt history row for --json. Location is "live" or
+// "backup" for a linked session, "missing" when none was found.
+type historyEntry struct {
+	Prompt    string           `json:"prompt"`
+	Project   string           `json:"project"`
+	At        time.Time        `json:"at"`
+	Root      string           `json:"root,omitempty"`
+	SessionID string           `json:"session_id,omitempty"`
+	Link      string           `json:"link,omitempty"`
+	Location  string           `json:"location"`
+	Session   *session.Session `json:"session,omitempty"`
+}
+
+func (cmd *HistoryCmd) Run(globals *Globals) error {
+	w, err := parseTimeWindow(cmd.Since, cmd.Until, time.Now())
+	if err != nil {
+		return err
+	}
+	idx, err := index.Open()
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+	defer func() { _ = idx.Close() }()
+
+	rows, err := idx.SearchHistory(index.HistoryFilter{
+		Root:          globals.Root,
+		Query:         cmd.Query,
+		ProjectFilter: cmd.Project,
+		Since:         w.Since,
+		Until:         w.Until,
+		Limit:         cmd.Limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	entries := make([]historyEntry, 0, len(rows))
+	for _, r := range rows {
+		e := historyEntry{
+			Prompt:    r.Display,
+			Project:   r.Project,
+			At:        r.At,
+			Root:      r.Root,
+			SessionID: r.SessionID,
+			Link:      r.Link,
+			Location:  "missing",
+			Session:   r.Session,
+		}
+		if r.Session != nil {
+			e.SessionID = r.Session.ID
+			e.Location = "live"
+			if strings.HasPrefix(r.Session.FilePath, paths.BackupDir()) {
+				e.Location = "backup"
+			}
+		}
+		entries = append(entries, e)
+	}
+
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		if cmd.Query != "" {
+			fmt.Printf("No prompts matching %q in the prompt history.\n", cmd.Query)
+		} else {
+			fmt.Println("No prompt history found.")
+		}
+		return nil
+	}
+
+	tbl := output.NewTable(cmd.Query,
+		output.Fixed("SESSION", 16),
+		output.Flex("PROJECT", 25, 15),
+		output.Fixed("AGE", 6),
+		output.Flex("PROMPT", 0, 30),
+	)
+	fmt.Println()
+	tbl.PrintHeader()
+	missing := 0
+	for _, e := range entries {
+		id := "-"
+		switch {
+		case e.Session != nil && e.Link == "time":
+			id = "~" + e.Session.ShortID
+		case e.Session != nil:
+			id = e.Session.ShortID
+		case e.SessionID != "":
+			id = session.ShortID(e.SessionID)
+		}
+		switch e.Location {
+		case "backup":
+			id += " bak"
+		case "missing":
+			missing++
+			if e.SessionID != "" {
+				id += " gone"
+			}
+		}
+		prompt := strings.Join(strings.Fields(e.Prompt), " ")
+		tbl.Row(
+			[]string{
+				id,
+				output.Truncate(filepath.Base(e.Project), tbl.ColWidth(1)),
+				output.FormatAge(e.At),
+				output.Truncate(prompt, tbl.LastColWidth()),
+			},
+			[]func(string) string{output.Dim, output.Bold, output.Dim, output.Dim},
+		)
+	}
+	fmt.Println()
+	fmt.Printf("  %s\n", output.Dim("~ session matched by project and time · bak restored from cct backup · gone no session file left"))
+	if missing > 0 {
+		fmt.Printf("  %s\n", output.Dim(fmt.Sprintf("%d %s no session on disk; the prompt text is all that remains (cct backup guards future sessions)",
+			missing, plural(missing, "prompt has", "prompts have"))))
+	}
+	fmt.Println()
+	return nil
+}
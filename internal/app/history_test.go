@@ -0,0 +1,54 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHistoryCmd(t *testing.T) {
+	home := setupFixtures(t)
+	// 1769932800000 is 2026-02-01T08:00:00Z, when the fixture session ran.
+	history := strings.Join([]string{
+		`{"display":"fix the database bug","pastedContents":{},"timestamp":1769932800000,"project":"/Users/test/myproject","sessionId":"abcd1234-5678-9abc-def0-111111111111"}`,
+		`{"display":"refactor the database layer","pastedContents":{},"timestamp":1769940000000,"project":"/Users/test/lost","sessionId":"0dead000-0000-0000-0000-000000000000"}`,
+		`{"display":"unrelated prompt","pastedContents":{},"timestamp":1769940060000,"project":"/Users/test/lost"}`,
+	}, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(home, ".claude", "history.jsonl"), []byte(history), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() {
+		cmd := &HistoryCmd{Query: "database", Limit: 25}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var entries []historyEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].Location != "missing" || entries[0].SessionID != "0dead000-0000-0000-0000-000000000000" {
+		t.Errorf("lost prompt = %+v", entries[0])
+	}
+	if entries[1].Location != "live" || entries[1].Session == nil || entries[1].Session.ShortID != "abcd1234" {
+		t.Errorf("live prompt = %+v", entries[1])
+	}
+
+	out = captureStdout(t, func() {
+		cmd := &HistoryCmd{Query: "database", Limit: 25}
+		if err := cmd.Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "0dead000 gone") || !strings.Contains(out, "1 prompt has no session on disk") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,9 +15,11 @@ import (
 )
 
 type IndexCmd struct {
-	Sync    IndexSyncCmd    `cmd:"" help:"Sync index with latest sessions"`
-	Rebuild IndexRebuildCmd `cmd:"" help:"Rebuild index from scratch"`
-	Status  IndexStatusCmd  `cmd:"" help:"Show index status"`
+	Sync       IndexSyncCmd       `cmd:"" help:"Sync index with latest sessions"`
+	Rebuild    IndexRebuildCmd    `cmd:"" help:"Rebuild index from scratch"`
+	Status     IndexStatusCmd     `cmd:"" help:"Show index status"`
+	Thinking   IndexThinkingCmd   `cmd:"" help:"Index thinking blocks so search can match them (off by default)"`
+	ToolOutput IndexToolOutputCmd `cmd:"" name:"tool-output" help:"Show or set how much of each tool's output is indexed"`
 }
 
 type IndexSyncCmd struct {
@@ -132,6 +135,57 @@ func (cmd *IndexStatusCmd) Run(globals *Globals) error {
 	if !status.LastSyncTime.IsZero() {
 		fmt.Printf("Last sync: %s\n", output.FormatAge(status.LastSyncTime))
 	}
+	if status.Thinking {
+		fmt.Println("Thinking: indexed")
+	}
+	return nil
+}
+
+type IndexThinkingCmd struct {
+	State string `arg:"" enum:"on,off,status" help:"on, off, or status"`
+}
+
+func (cmd *IndexThinkingCmd) Run(globals *Globals) error {
+	if cmd.State == "status" {
+		state := "off"
+		if index.ThinkingEnabled() {
+			state = "on"
+		}
+		if globals.JSON {
+			return jsonEncode(map[string]string{"thinking": state})
+		}
+		fmt.Println(state)
+		return nil
+	}
+
+	on := cmd.State == "on"
+	if on == index.ThinkingEnabled() {
+		if !globals.JSON {
+			fmt.Printf("Thinking indexing is already %s\n", cmd.State)
+		}
+		return nil
+	}
+	if err := index.SetThinkingEnabled(on); err != nil {
+		return err
+	}
+
+	// Every session is re-indexed to add or drop its thinking text.
+	idx, err := index.Open()
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+	defer func() { _ = idx.Close() }()
+	if _, err := idx.SyncWithProgress(true, true, os.Stderr); err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+	if globals.JSON {
+		return jsonEncode(map[string]string{"thinking": cmd.State})
+	}
+	if on {
+		fmt.Println("Thinking blocks are indexed; search matches them as [t]")
+	} else {
+		fmt.Println("Thinking blocks are no longer indexed")
+	}
 	return nil
 }
 
@@ -165,3 +219,100 @@ func formatSyncResult(r *index.SyncResult) string {
 	}
 	return summary
 }
+
+type IndexToolOutputCmd struct {
+	Tool  string `arg:"" optional:"" help:"Tool name (as in cct stats tools), or * for every tool not listed"`
+	Limit string `arg:"" optional:"" help:"Bytes indexed per call (e.g. 512, 8k, 1m), off, full, or default"`
+}
+
+func (cmd *IndexToolOutputCmd) Run(globals *Globals) error {
+	if cmd.Limit == "" {
+		policy, err := index.LoadToolOutputPolicy()
+		if err != nil {
+			return err
+		}
+		tools := policy.Tools()
+		if cmd.Tool != "" {
+			tools = []string{cmd.Tool}
+		}
+		if globals.JSON {
+			out := map[string]int{}
+			for _, t := range tools {
+				out[t] = policy.Limit(t)
+			}
+			return jsonEncode(out)
+		}
+		for _, t := range tools {
+			fmt.Printf("%-12s %s\n", t, formatToolOutputLimit(policy.Limit(t)))
+		}
+		return nil
+	}
+	if cmd.Tool == "" {
+		return fmt.Errorf("name a tool to set its limit")
+	}
+
+	reset := cmd.Limit == "default"
+	var limit int
+	if !reset {
+		var err error
+		if limit, err = parseToolOutputLimit(cmd.Limit); err != nil {
+			return err
+		}
+	}
+	if err := index.SetToolOutputLimit(cmd.Tool, limit, reset); err != nil {
+		return err
+	}
+
+	// Every session is re-indexed under the new policy.
+	idx, err := index.Open()
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+	defer func() { _ = idx.Close() }()
+	if _, err := idx.SyncWithProgress(true, true, os.Stderr); err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+
+	policy, err := index.LoadToolOutputPolicy()
+	if err != nil {
+		return err
+	}
+	if globals.JSON {
+		return jsonEncode(map[string]int{cmd.Tool: policy.Limit(cmd.Tool)})
+	}
+	fmt.Printf("%s output: %s indexed per call\n", cmd.Tool, formatToolOutputLimit(policy.Limit(cmd.Tool)))
+	return nil
+}
+
+// parseToolOutputLimit reads a byte count with an optional k or m suffix,
+// or off (none) or full (no cap).
+func parseToolOutputLimit(s string) (int, error) {
+	switch strings.ToLower(s) {
+	case "off":
+		return index.NoToolOutput, nil
+	case "full":
+		return index.FullToolOutput, nil
+	}
+	num, mult := strings.TrimSuffix(strings.ToLower(s), "b"), 1
+	switch {
+	case strings.HasSuffix(num, "k"):
+		num, mult = strings.TrimSuffix(num, "k"), 1<<10
+	case strings.HasSuffix(num, "m"):
+		num, mult = strings.TrimSuffix(num, "m"), 1<<20
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid limit %q: use a size like 4096 or 8k, off, full, or default", s)
+	}
+	return n * mult, nil
+}
+
+func formatToolOutputLimit(n int) string {
+	switch n {
+	case index.NoToolOutput:
+		return "off"
+	case index.FullToolOutput:
+		return "full"
+	}
+	return output.FormatBytes(int64(n))
+}
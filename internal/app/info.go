@@ -4,21 +4,36 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/paths"
 	"github.com/andyhtran/cct/internal/session"
 )
 
 type InfoCmd struct {
-	ID string `arg:"" help:"Session ID or prefix"`
+	ID   string        `arg:"" help:"Session ID or prefix"`
+	Idle time.Duration `help:"Gap between messages that ends a work segment" default:"15m"`
 }
 
 func (cmd *InfoCmd) Run(globals *Globals) error {
-	match, err := session.FindByPrefixFull(cmd.ID)
+	match, err := findSessionFull(globals, cmd.ID)
 	if err != nil {
 		return err
 	}
 
+	if times, err := session.ReadMessageTimes(match.FilePath); err == nil {
+		segs := session.Segments(times, cmd.Idle)
+		match.ActiveSeconds = int64(session.ActiveTime(segs).Seconds())
+		match.WorkSegments = len(segs)
+	}
+	if compactions, err := session.ReadCompactions(match.FilePath); err == nil {
+		match.Compactions = compactions
+	}
+	chain := loadChain(globals, match)
+	setChainLinks(match, chain)
+
 	if globals.JSON {
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
@@ -45,6 +60,9 @@ func (cmd *InfoCmd) Run(globals *Globals) error {
 	if match.ProjectPath != "" {
 		fmt.Printf("  %s  %s\n", output.Dim("Project:"), output.Bold(match.ProjectPath))
 	}
+	if match.Root != "" && len(paths.Roots()) > 1 {
+		fmt.Printf("  %s     %s\n", output.Dim("Root:"), match.Root)
+	}
 	if match.GitBranch != "" {
 		fmt.Printf("  %s   %s\n", output.Dim("Branch:"), match.GitBranch)
 	}
@@ -52,10 +70,26 @@ func (cmd *InfoCmd) Run(globals *Globals) error {
 		fmt.Printf("  %s  %s\n", output.Dim("Created:"), match.Created.Local().Format("2006-01-02 15:04:05"))
 	}
 	fmt.Printf("  %s %s (%s)\n", output.Dim("Modified:"), match.Modified.Local().Format("2006-01-02 15:04:05"), output.FormatAge(match.Modified))
+	if d := match.Duration(); d > 0 {
+		fmt.Printf("  %s %s\n", output.Dim("Duration:"), output.FormatDuration(d))
+	}
+	if match.WorkSegments > 0 {
+		active := time.Duration(match.ActiveSeconds) * time.Second
+		segments := "segments"
+		if match.WorkSegments == 1 {
+			segments = "segment"
+		}
+		fmt.Printf("  %s   %s %s\n", output.Dim("Active:"), output.FormatDuration(active),
+			output.Dim(fmt.Sprintf("of %s wall-clock, %d work %s (idle > %s)",
+				output.FormatDuration(match.Duration()), match.WorkSegments, segments, output.FormatDuration(cmd.Idle))))
+	}
 	fmt.Printf("  %s %s\n", output.Dim("Messages:"), fmt.Sprintf("%d", match.MessageCount))
 	if match.Model != "" {
 		fmt.Printf("  %s    %s\n", output.Dim("Model:"), match.Model)
 	}
+	if match.Version != "" {
+		fmt.Printf("  %s  %s\n", output.Dim("Version:"), match.Version)
+	}
 	if match.ContextTokens > 0 {
 		window := session.ContextWindow(match.Model)
 		pct := float64(match.ContextTokens) / float64(window) * 100
@@ -66,10 +100,41 @@ func (cmd *InfoCmd) Run(globals *Globals) error {
 		}
 		fmt.Printf("  %s  %s\n", output.Dim("Context:"), line)
 	}
+	if n := len(match.Compactions); n > 0 {
+		last := match.Compactions[n-1]
+		line := fmt.Sprintf("%d %s", n, plural(n, "time", "times"))
+		if !last.At.IsZero() {
+			line += output.Dim(", last " + output.FormatAge(last.At) + " ago")
+		}
+		fmt.Printf("  %s %s %s\n", output.Dim("Compacts:"), line,
+			output.Dim("(cct export "+match.ShortID+" --after-last-compact)"))
+		for _, c := range match.Compactions {
+			at := "unknown time    "
+			if !c.At.IsZero() {
+				at = c.At.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Printf("            %s  %s\n", output.Dim(at), c.Label())
+		}
+	}
 	if match.TotalOutputTokens > 0 {
 		fmt.Printf("  %s   %s\n", output.Dim("Output:"), formatInt(match.TotalOutputTokens))
 	}
 	fmt.Printf("  %s   %s\n", output.Dim("Prompt:"), prompt)
+	if len(chain) > 1 {
+		fmt.Printf("  %s    %s %s\n", output.Dim("Chain:"), fmt.Sprintf("%d of %d sessions", len(match.Predecessors)+1, len(chain)),
+			output.Dim("(cct export "+match.ShortID+" --chain)"))
+		for _, c := range chain {
+			if c.ID == match.ID {
+				continue
+			}
+			arrow := "→"
+			if slices.Contains(match.Predecessors, c.ID) {
+				arrow = "←"
+			}
+			fmt.Printf("            %s %s  %s %s\n", output.Dim(arrow), output.Bold(c.ShortID),
+				output.Truncate(sessionTitle(c), 50), output.Dim("("+output.FormatAge(c.End())+")"))
+		}
+	}
 	fmt.Println()
 	fmt.Printf("  %s\n", output.Cyan(fmt.Sprintf("cct resume %s", match.ShortID)))
 	fmt.Println()
@@ -0,0 +1,288 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andyhtran/cct/internal/index"
+	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/session"
+)
+
+type LinksCmd struct {
+	Query   string   `arg:"" optional:"" help:"A domain (matches its subdomains too), or text to find in the URL, page title, search query, or surrounding line"`
+	Domains bool     `short:"d" help:"Count links per domain instead of listing them"`
+	Source  []string `short:"s" help:"Only links from these sources, comma-separated: prompt, reply, fetch, search" placeholder:"SOURCE"`
+	Project string   `short:"p" help:"Filter by project name"`
+	Since   string   `help:"Only links seen at or after this time (today, yesterday, 7d, 2026-01-31)"`
+	Until   string   `help:"Only links seen before this time (same forms as --since)"`
+	Limit   int      `short:"n" help:"Max rows (0=no limit)" default:"30"`
+	Agents  bool     `help:"Include links from sub-agent sessions"`
+}
+
+// linkSources maps the --source names to the sources the index records.
+var linkSources = map[string]string{
+	"prompt": session.LinkFromPrompt,
+	"reply":  session.LinkFromReply,
+	"fetch":  "WebFetch",
+	"search": "WebSearch",
+}
+
+// linkEntry is one URL with every time it was seen. The session, offset,
+// and context are from the latest sighting.
+type linkEntry struct {
+	URL       string    `json:"url"`
+	Domain    string    `json:"domain"`
+	Title     string    `json:"title,omitempty"`
+	Sources   []string  `json:"sources"`
+	Count     int       `json:"count"`
+	Sessions  int       `json:"sessions"`
+	First     time.Time `json:"first,omitzero"`
+	Last      time.Time `json:"last,omitzero"`
+	SessionID string    `json:"session_id"`
+	ShortID   string    `json:"short_id"`
+	Project   string    `json:"project"`
+	Offset    int64     `json:"offset"`
+	Context   string    `json:"context,omitempty"`
+
+	sessions map[string]bool
+}
+
+type domainEntry struct {
+	Domain   string    `json:"domain"`
+	Links    int       `json:"links"`
+	Mentions int       `json:"mentions"`
+	Sessions int       `json:"sessions"`
+	Last     time.Time `json:"last,omitzero"`
+
+	urls     map[string]bool
+	sessions map[string]bool
+}
+
+func (cmd *LinksCmd) Run(globals *Globals) error {
+	sources := map[string]bool{}
+	for _, name := range cmd.Source {
+		src, ok := linkSources[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return fmt.Errorf("unknown link source %q (want prompt, reply, fetch, or search)", name)
+		}
+		sources[src] = true
+	}
+	w, err := parseTimeWindow(cmd.Since, cmd.Until, time.Now())
+	if err != nil {
+		return err
+	}
+	idx, err := index.Open()
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+	defer func() { _ = idx.Close() }()
+
+	rows, err := idx.Links(index.EventFilter{
+		Root:          globals.Root,
+		ProjectFilter: cmd.Project,
+		IncludeAgents: cmd.Agents,
+		Since:         w.Since,
+		Until:         w.Until,
+	}, cmd.Query)
+	if err != nil {
+		return fmt.Errorf("links: %w", err)
+	}
+	rows = filterLinks(rows, sources, cmd.Query)
+
+	if cmd.Domains {
+		domains := countDomains(rows)
+		if cmd.Limit > 0 && len(domains) > cmd.Limit {
+			domains = domains[:cmd.Limit]
+		}
+		if globals.JSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(domains)
+		}
+		if len(domains) == 0 {
+			fmt.Println("No links found.")
+			return nil
+		}
+		printDomains(domains)
+		return nil
+	}
+
+	entries := groupLinks(rows)
+	if cmd.Limit > 0 && len(entries) > cmd.Limit {
+		entries = entries[:cmd.Limit]
+	}
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+	if len(entries) == 0 {
+		if cmd.Query != "" {
+			fmt.Printf("No links matching %q.\n", cmd.Query)
+		} else {
+			fmt.Println("No links found.")
+		}
+		return nil
+	}
+	printLinks(entries, cmd.Query)
+	return nil
+}
+
+// looksLikeDomain reports whether query names a host ("github.com")
+// rather than text to search for.
+func looksLikeDomain(query string) bool {
+	return strings.Contains(query, ".") && !strings.ContainsAny(query, " /:")
+}
+
+// filterLinks keeps rows from sources (all when empty) and narrows a
+// domain query to that domain and its subdomains.
+func filterLinks(rows []index.LinkRow, sources map[string]bool, query string) []index.LinkRow {
+	domain := ""
+	if looksLikeDomain(query) {
+		domain = strings.TrimPrefix(strings.ToLower(query), "www.")
+	}
+	var out []index.LinkRow
+	for _, r := range rows {
+		l := r.Link
+		if len(sources) > 0 && !sources[l.Source] {
+			continue
+		}
+		if domain != "" && l.Domain != domain && !strings.HasSuffix(l.Domain, "."+domain) {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// groupLinks folds rows (oldest first) into one entry per URL, most
+// recently seen first.
+func groupLinks(rows []index.LinkRow) []*linkEntry {
+	byURL := map[string]*linkEntry{}
+	var out []*linkEntry
+	for _, r := range rows {
+		l := r.Link
+		e, ok := byURL[l.URL]
+		if !ok {
+			e = &linkEntry{URL: l.URL, Domain: l.Domain, First: l.At, sessions: map[string]bool{}}
+			byURL[l.URL] = e
+			out = append(out, e)
+		}
+		e.Count++
+		e.sessions[r.SessionID] = true
+		if !strings.Contains(strings.Join(e.Sources, ","), l.Source) {
+			e.Sources = append(e.Sources, l.Source)
+		}
+		if l.Title != "" {
+			e.Title = l.Title
+		}
+		if !l.At.Before(e.Last) {
+			e.Last = l.At
+			e.SessionID = r.SessionID
+			e.ShortID = session.ShortID(r.SessionID)
+			e.Project = r.Project
+			e.Offset = l.Offset
+			if l.Context != "" {
+				e.Context = l.Context
+			}
+		}
+	}
+	for _, e := range out {
+		e.Sessions = len(e.sessions)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Last.After(out[j].Last) })
+	return out
+}
+
+// countDomains tallies rows per domain, most mentioned first.
+func countDomains(rows []index.LinkRow) []*domainEntry {
+	byDomain := map[string]*domainEntry{}
+	var out []*domainEntry
+	for _, r := range rows {
+		l := r.Link
+		d, ok := byDomain[l.Domain]
+		if !ok {
+			d = &domainEntry{Domain: l.Domain, urls: map[string]bool{}, sessions: map[string]bool{}}
+			byDomain[l.Domain] = d
+			out = append(out, d)
+		}
+		d.Mentions++
+		d.urls[l.URL] = true
+		d.sessions[r.SessionID] = true
+		if l.At.After(d.Last) {
+			d.Last = l.At
+		}
+	}
+	for _, d := range out {
+		d.Links = len(d.urls)
+		d.Sessions = len(d.sessions)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Mentions != out[j].Mentions {
+			return out[i].Mentions > out[j].Mentions
+		}
+		return out[i].Domain < out[j].Domain
+	})
+	return out
+}
+
+func printLinks(entries []*linkEntry, query string) {
+	tbl := output.NewTable(query,
+		output.Fixed("SESSION", 8),
+		output.Fixed("AGE", 6),
+		output.Fixed("SOURCE", 9),
+		output.Fixed("SEEN", 4),
+		output.Flex("URL", 70, 30),
+		output.Flex("TITLE / CONTEXT", 0, 20),
+	)
+	fmt.Println()
+	tbl.PrintHeader()
+	for _, e := range entries {
+		label := e.Title
+		if label == "" {
+			label = e.Context
+		}
+		tbl.Row(
+			[]string{
+				e.ShortID,
+				output.FormatAge(e.Last),
+				strings.Join(e.Sources, ","),
+				formatInt(e.Count),
+				output.Truncate(e.URL, tbl.ColWidth(4)),
+				output.Truncate(label, tbl.LastColWidth()),
+			},
+			[]func(string) string{output.Dim, output.Dim, nil, output.Dim, output.Bold, output.Dim},
+		)
+	}
+	fmt.Println()
+}
+
+func printDomains(domains []*domainEntry) {
+	tbl := output.NewTable("",
+		output.Flex("DOMAIN", 50, 20),
+		output.Fixed("MENTIONS", 8),
+		output.Fixed("LINKS", 6),
+		output.Fixed("SESSIONS", 8),
+		output.Flex("LAST", 0, 6),
+	)
+	fmt.Println()
+	tbl.PrintHeader()
+	for _, d := range domains {
+		tbl.Row(
+			[]string{
+				output.Truncate(d.Domain, tbl.ColWidth(0)),
+				formatInt(d.Mentions),
+				formatInt(d.Links),
+				formatInt(d.Sessions),
+				output.FormatAge(d.Last),
+			},
+			[]func(string) string{output.Bold, nil, nil, output.Dim, output.Dim},
+		)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,100 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLinksCmd(t *testing.T) {
+	home := setupFixtures(t)
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-links")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	prompt := `{"type":"user","cwd":"/Users/test/links","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"read https://sqlite.org/fts5.html and https://github.com/org/repo"}}`
+	original := filepath.Join(projDir, "link3333-0000-0000-0000-000000000000.jsonl")
+	writeLines(t, original, []string{
+		prompt,
+		`{"type":"assistant","timestamp":"2026-03-01T10:00:05Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"f1","name":"WebFetch","input":{"url":"https://www.sqlite.org/lang.html","prompt":"summarize"}}]}}`,
+	})
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(original, old, old); err != nil {
+		t.Fatal(err)
+	}
+	// A resumed session replays the first prompt before adding its own.
+	writeLines(t, filepath.Join(projDir, "link2222-0000-0000-0000-000000000000.jsonl"), []string{
+		prompt,
+		`{"type":"user","cwd":"/Users/test/links","timestamp":"2026-03-02T10:00:00Z","message":{"role":"user","content":"again https://sqlite.org/fts5.html"}}`,
+	})
+
+	run := func(cmd *LinksCmd) string {
+		return captureStdout(t, func() {
+			if err := cmd.Run(&Globals{JSON: true}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	var links []struct {
+		URL      string   `json:"url"`
+		Sources  []string `json:"sources"`
+		Count    int      `json:"count"`
+		Sessions int      `json:"sessions"`
+		ShortID  string   `json:"short_id"`
+	}
+	out := run(&LinksCmd{Query: "sqlite.org", Project: "links", Limit: 30})
+	if err := json.Unmarshal([]byte(out), &links); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(links) != 2 {
+		t.Fatalf("got %d links, want 2:\n%s", len(links), out)
+	}
+	if l := links[0]; l.URL != "https://sqlite.org/fts5.html" || l.Count != 2 || l.Sessions != 2 || l.ShortID != "link2222" {
+		t.Errorf("links[0] = %+v", l)
+	}
+	if l := links[1]; l.URL != "https://www.sqlite.org/lang.html" || len(l.Sources) != 1 || l.Sources[0] != "WebFetch" {
+		t.Errorf("links[1] = %+v", l)
+	}
+
+	// The replayed link stays credited to the original session.
+	out = run(&LinksCmd{Query: "github.com", Project: "links", Limit: 30})
+	links = nil
+	if err := json.Unmarshal([]byte(out), &links); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(links) != 1 || links[0].Count != 1 || links[0].ShortID != "link3333" {
+		t.Errorf("replayed link = %+v, want one mention from link3333", links)
+	}
+
+	out = run(&LinksCmd{Source: []string{"fetch"}, Project: "links", Limit: 30})
+	links = nil
+	if err := json.Unmarshal([]byte(out), &links); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(links) != 1 || links[0].URL != "https://www.sqlite.org/lang.html" {
+		t.Errorf("--source fetch = %+v", links)
+	}
+
+	var domains []struct {
+		Domain   string `json:"domain"`
+		Links    int    `json:"links"`
+		Mentions int    `json:"mentions"`
+		Sessions int    `json:"sessions"`
+	}
+	out = run(&LinksCmd{Domains: true, Project: "links", Limit: 30})
+	if err := json.Unmarshal([]byte(out), &domains); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(domains) != 2 || domains[0].Domain != "sqlite.org" || domains[0].Links != 2 || domains[0].Mentions != 3 || domains[0].Sessions != 2 {
+		t.Errorf("domains = %+v", domains)
+	}
+
+	if err := (&LinksCmd{Source: []string{"bogus"}}).Run(&Globals{}); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+}
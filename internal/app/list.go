@@ -6,35 +6,40 @@ import (
 	"os"
 	"sort"
 
+	"github.com/andyhtran/cct/internal/index"
 	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/paths"
 	"github.com/andyhtran/cct/internal/session"
 )
 
 type DefaultCmd struct{}
 
 func (cmd *DefaultCmd) Run(globals *Globals) error {
-	return listSessions(globals, "", 5, false, true, false)
+	return listSessions(globals, "", 5, "", false, true, false, false)
 }
 
 type ListCmd struct {
-	Project string `short:"p" help:"Filter by project name"`
-	Limit   int    `short:"n" help:"Max results" default:"15"`
-	All     bool   `short:"a" help:"Show all results"`
-	Agents  bool   `help:"Include sub-agent sessions"`
+	Project        string `short:"p" help:"Filter by project name"`
+	Limit          int    `short:"n" help:"Max results" default:"15"`
+	All            bool   `short:"a" help:"Show all results"`
+	Agents         bool   `help:"Include sub-agent sessions"`
+	Sort           string `help:"Sort order: recency (default), tokens (peak context), messages, duration, size" default:"recency" enum:"recency,tokens,messages,duration,size"`
+	CollapseChains bool   `help:"One row per resume chain: its latest session, with the count of earlier ones" name:"collapse-chains"`
 }
 
 func (cmd *ListCmd) Run(globals *Globals) error {
-	return listSessions(globals, cmd.Project, cmd.Limit, cmd.All, false, cmd.Agents)
+	return listSessions(globals, cmd.Project, cmd.Limit, cmd.Sort, cmd.All, false, cmd.Agents, cmd.CollapseChains)
 }
 
-func listSessions(globals *Globals, project string, limit int, showAll, compact bool, includeAgents bool) error {
-	sessions := session.ScanAll(project, false, includeAgents)
-	sort.Slice(sessions, func(i, j int) bool {
-		return sessions[i].Modified.After(sessions[j].Modified)
-	})
-
-	if !showAll && limit > 0 && len(sessions) > limit {
-		sessions = sessions[:limit]
+func listSessions(globals *Globals, project string, limit int, sortBy string, showAll, compact, includeAgents, collapse bool) error {
+	if showAll {
+		limit = 0
+	}
+	var sessions []*session.Session
+	if collapse {
+		sessions = loadCollapsedSessions(globals, project, limit, sortBy, includeAgents)
+	} else {
+		sessions = loadSessions(globals, project, limit, sortBy, includeAgents)
 	}
 
 	if len(sessions) == 0 {
@@ -52,6 +57,63 @@ func listSessions(globals *Globals, project string, limit int, showAll, compact
 	return nil
 }
 
+// projectLabel is the PROJECT column value. With several roots configured,
+// sessions outside the primary root carry a "(root)" suffix so identically
+// named projects in different accounts can be told apart.
+func projectLabel(s *session.Session) string {
+	if s.Root == "" || len(paths.Roots()) < 2 || s.Root == paths.Roots()[0].Name {
+		return s.ProjectName
+	}
+	return s.ProjectName + " (" + s.Root + ")"
+}
+
+// loadSessions returns sessions ordered by sortBy, capped at limit (0 = all).
+// The index serves the common case; a full scan of the session files is
+// the fallback when the index can't be opened or queried. The scan only
+// parses metadata, so it always orders by recency.
+func loadSessions(globals *Globals, project string, limit int, sortBy string, includeAgents bool) []*session.Session {
+	if idx := openIndexForRead(globals); idx != nil {
+		defer func() { _ = idx.Close() }()
+		sessions, err := idx.ListSessions(index.ListOptions{
+			Root:          globals.Root,
+			ProjectFilter: project,
+			IncludeAgents: includeAgents,
+			Limit:         limit,
+			SortBy:        sortBy,
+		})
+		if err == nil {
+			return sessions
+		}
+	}
+
+	sessions := session.ScanAll(globals.Root, project, false, includeAgents)
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Modified.After(sessions[j].Modified)
+	})
+	if limit > 0 && len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return sessions
+}
+
+// loadCollapsedSessions is loadSessions with each resume chain folded into
+// its latest session. Chains come from the index; without it, sessions are
+// listed uncollapsed.
+func loadCollapsedSessions(globals *Globals, project string, limit int, sortBy string, includeAgents bool) []*session.Session {
+	sessions := loadSessions(globals, project, 0, sortBy, includeAgents)
+	if idx := openIndexForRead(globals); idx != nil {
+		chains, err := idx.Chains(globals.Root)
+		_ = idx.Close()
+		if err == nil {
+			sessions = collapseChains(sessions, chains)
+		}
+	}
+	if limit > 0 && len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return sessions
+}
+
 const maxResumeHints = 3
 
 func printResumeHints(sessions []*session.Session) {
@@ -100,10 +162,14 @@ func printSessionTable(sessions []*session.Session, compact bool) {
 			}
 			prompt = "[" + s.AgentType + "] " + summary
 		}
+		id := s.ShortID
+		if n := len(s.Predecessors); n > 0 {
+			id += fmt.Sprintf(" +%d", n)
+		}
 		tbl.Row(
 			[]string{
-				s.ShortID,
-				output.Truncate(s.ProjectName, tbl.ColWidth(1)),
+				id,
+				output.Truncate(projectLabel(s), tbl.ColWidth(1)),
 				output.Truncate(s.GitBranch, tbl.ColWidth(2)),
 				output.FormatAge(s.Modified),
 				output.Truncate(prompt, tbl.LastColWidth()),
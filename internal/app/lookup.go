@@ -0,0 +1,69 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/andyhtran/cct/internal/index"
+	"github.com/andyhtran/cct/internal/session"
+)
+
+// openIndexForRead opens the search index for a read-only command. It
+// returns nil when the index is unavailable so callers can fall back to
+// scanning session files. The first run builds the whole index, so say so
+// on stderr rather than appearing to hang.
+func openIndexForRead(globals *Globals) *index.Index {
+	idx, err := index.Open()
+	if err != nil {
+		return nil
+	}
+	if !globals.JSON {
+		if status, err := idx.Status(); err == nil && status.TotalSessions == 0 {
+			fmt.Fprintln(os.Stderr, "Building search index...")
+		}
+	}
+	return idx
+}
+
+// findSession resolves a session ID, prefix, or /rename title through the
+// index. It scans session files instead when the index is unavailable or
+// the lookup fails for reasons other than not-found or ambiguity.
+func findSession(globals *Globals, id string) (*session.Session, error) {
+	idx := openIndexForRead(globals)
+	if idx == nil {
+		return session.FindByPrefix(globals.Root, id)
+	}
+	defer func() { _ = idx.Close() }()
+
+	s, err := idx.FindByPrefix(globals.Root, id)
+	if err != nil && !errors.Is(err, session.ErrNotFound) && !errors.Is(err, session.ErrMultipleMatches) {
+		return session.FindByPrefix(globals.Root, id)
+	}
+	return s, err
+}
+
+// findSessionRef resolves a session ID or a message ref from cct search
+// (<session>#<message>). The anchor is nil for a plain session ID.
+func findSessionRef(globals *Globals, ref string) (*session.Session, *session.Anchor, error) {
+	id, anchor := session.SplitRef(ref)
+	s, err := findSession(globals, id)
+	if err != nil || anchor == "" {
+		return s, nil, err
+	}
+	a, err := session.LocateAnchor(s.FilePath, anchor)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, a, nil
+}
+
+// findSessionFull is findSession followed by a full parse of the resolved
+// file, for commands that need token usage or an exact message count.
+func findSessionFull(globals *Globals, id string) (*session.Session, error) {
+	s, err := findSession(globals, id)
+	if err != nil {
+		return nil, err
+	}
+	return session.LoadFull(s), nil
+}
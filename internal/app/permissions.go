@@ -0,0 +1,228 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/andyhtran/cct/internal/index"
+	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/paths"
+	"github.com/andyhtran/cct/internal/permissions"
+	"github.com/andyhtran/cct/internal/session"
+)
+
+type PermissionsCmd struct {
+	Project     string `short:"p" help:"Filter by project name"`
+	Since       string `help:"Only calls at or after this time (today, yesterday, 7d, 12h, 2026-01-31)"`
+	Until       string `help:"Only calls before this time (same forms as --since)"`
+	MinRuns     int    `help:"Suggest a rule only after this many calls needed it" default:"5"`
+	MinSessions int    `help:"Suggest a rule only after calls in this many sessions needed it" default:"2"`
+	MinDenials  int    `help:"Flag a command once it was denied this many times" default:"2"`
+	Limit       int    `short:"n" help:"Max suggestions (0=no limit)" default:"25"`
+	Agents      bool   `help:"Include calls made inside sub-agent sessions"`
+}
+
+type permissionsData struct {
+	Window string `json:"window"`
+	*permissions.Report
+	Snippet map[string]any `json:"snippet"`
+}
+
+func (cmd *PermissionsCmd) Run(globals *Globals) error {
+	w, err := parseTimeWindow(cmd.Since, cmd.Until, time.Now())
+	if err != nil {
+		return err
+	}
+	idx, err := index.Open()
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+	defer func() { _ = idx.Close() }()
+
+	rows, err := idx.ToolCalls(index.EventFilter{
+		Root:          globals.Root,
+		ProjectFilter: cmd.Project,
+		IncludeAgents: cmd.Agents,
+		Since:         w.Since,
+		Until:         w.Until,
+	})
+	if err != nil {
+		return fmt.Errorf("tool calls: %w", err)
+	}
+
+	calls := permissionCalls(rows)
+	allowed, err := loadAllowRules(globals.Root, calls)
+	if err != nil {
+		return fmt.Errorf("read settings: %w", err)
+	}
+	report := permissions.Analyze(calls, allowed, permissions.Thresholds{
+		MinRuns:     cmd.MinRuns,
+		MinSessions: cmd.MinSessions,
+		MinDenials:  cmd.MinDenials,
+	})
+	if cmd.Limit > 0 && len(report.Suggested) > cmd.Limit {
+		report.Suggested = report.Suggested[:cmd.Limit]
+	}
+	data := permissionsData{
+		Window:  describeWindow(cmd.Since, cmd.Until),
+		Report:  report,
+		Snippet: permissions.Snippet(report.Suggested),
+	}
+
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	}
+
+	if report.Calls == 0 && len(report.Denied) == 0 {
+		fmt.Println("  No Bash or MCP calls found.")
+		return nil
+	}
+	printPermissions(data)
+	return nil
+}
+
+// loadAllowRules reads the allow rules a suggestion would duplicate: the
+// user-level settings of every root (or only the --root one), and the
+// shared and local project settings of each directory calls ran in.
+func loadAllowRules(rootName string, calls []permissions.Call) (permissions.AllowRules, error) {
+	var allowed permissions.AllowRules
+	var err error
+	allowed.User, err = permissions.LoadAllowRules(settingsPaths(rootName)...)
+	if err != nil {
+		return allowed, err
+	}
+	allowed.Projects = map[string][]string{}
+	for _, c := range calls {
+		if _, ok := allowed.Projects[c.Project]; ok || c.Project == "" {
+			continue
+		}
+		rules, err := permissions.LoadAllowRules(
+			filepath.Join(c.Project, ".claude", "settings.json"),
+			filepath.Join(c.Project, ".claude", "settings.local.json"),
+		)
+		if err != nil {
+			return allowed, err
+		}
+		allowed.Projects[c.Project] = rules
+	}
+	return allowed, nil
+}
+
+// settingsPaths lists the user-level settings files: every root's, or
+// only the --root one's.
+func settingsPaths(rootName string) []string {
+	var out []string
+	for _, r := range paths.Roots() {
+		if rootName == "" || r.Name == rootName {
+			out = append(out, r.SettingsPath())
+		}
+	}
+	return out
+}
+
+// permissionCalls converts the indexed Bash and MCP calls.
+func permissionCalls(rows []index.ToolCallRow) []permissions.Call {
+	var out []permissions.Call
+	for _, r := range rows {
+		if r.Name != "Bash" && session.MCPServer(r.Name) == "" {
+			continue
+		}
+		out = append(out, permissions.Call{
+			Tool:      r.Name,
+			Target:    r.Target,
+			SessionID: r.SessionID,
+			Project:   r.ProjectPath,
+			At:        r.CalledAt,
+			Ran:       r.HasResult && !r.Denied,
+			Denied:    r.Denied,
+		})
+	}
+	return out
+}
+
+func printPermissions(data permissionsData) {
+	report := data.Report
+	fmt.Println()
+	fmt.Printf("  %s  %s  %s\n", output.Pad("Calls:", 8, output.Dim), formatInt(report.Calls),
+		output.Dim(fmt.Sprintf("Bash and MCP calls that ran (%d %s, %s)", report.Sessions, plural(report.Sessions, "session", "sessions"), data.Window)))
+	fmt.Println()
+
+	if len(report.Suggested) == 0 {
+		fmt.Println("  No new allow rules to suggest.")
+	} else {
+		tbl := output.NewTable("",
+			output.Flex("RULE", 45, 20),
+			output.Fixed("RUNS", 6),
+			output.Fixed("SESSIONS", 8),
+			output.Fixed("LAST", 6),
+			output.Flex("EXAMPLE", 0, 20),
+		)
+		tbl.PrintHeader()
+		for _, s := range report.Suggested {
+			tbl.Row(
+				[]string{
+					output.Truncate(s.Rule, tbl.ColWidth(0)),
+					formatInt(s.Runs),
+					formatInt(s.Sessions),
+					output.FormatAge(s.Last),
+					output.Truncate(formatPermissionExample(s.Examples), tbl.LastColWidth()),
+				},
+				[]func(string) string{output.Bold, nil, output.Dim, output.Dim, output.Dim},
+			)
+		}
+		fmt.Println()
+		fmt.Printf("  %s\n\n", output.Dim("Paste into ~/.claude/settings.json or a project's .claude/settings.json (cct never writes settings):"))
+		snippet, _ := json.MarshalIndent(data.Snippet, "  ", "  ")
+		fmt.Printf("  %s\n", snippet)
+	}
+	if report.AlreadyAllowed > 0 {
+		fmt.Println()
+		fmt.Printf("  %s\n", output.Dim(fmt.Sprintf("%d frequent %s already allowed in settings.json left out",
+			report.AlreadyAllowed, plural(report.AlreadyAllowed, "rule", "rules"))))
+	}
+
+	if len(report.Denied) > 0 {
+		fmt.Println()
+		fmt.Println("  " + output.Bold("Denied Repeatedly"))
+		fmt.Println()
+		tbl := output.NewTable("",
+			output.Flex("RULE / TOOL", 45, 20),
+			output.Fixed("DENIED", 6),
+			output.Fixed("SESSIONS", 8),
+			output.Fixed("LAST", 6),
+			output.Flex("EXAMPLE", 0, 20),
+		)
+		tbl.PrintHeader()
+		for _, d := range report.Denied {
+			tbl.Row(
+				[]string{
+					output.Truncate(d.Rule, tbl.ColWidth(0)),
+					formatInt(d.Denied),
+					formatInt(d.Sessions),
+					output.FormatAge(d.Last),
+					output.Truncate(formatPermissionExample(d.Examples), tbl.LastColWidth()),
+				},
+				[]func(string) string{output.Bold, nil, output.Dim, output.Dim, output.Dim},
+			)
+		}
+	}
+	fmt.Println()
+}
+
+func formatPermissionExample(examples []permissions.Example) string {
+	if len(examples) == 0 {
+		return ""
+	}
+	e := examples[0]
+	cmd := strings.Join(strings.Fields(e.Command), " ")
+	if cmd == "" {
+		return session.ShortID(e.SessionID)
+	}
+	return session.ShortID(e.SessionID) + "  " + cmd
+}
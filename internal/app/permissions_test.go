@@ -0,0 +1,108 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func bashCall(id, at, command, result string, isError bool) []string {
+	return []string{
+		`{"type":"assistant","timestamp":"` + at + `","message":{"role":"assistant","content":[{"type":"tool_use","id":"` + id + `","name":"Bash","input":{"command":"` + command + `"}}]}}`,
+		fmt.Sprintf(`{"type":"user","timestamp":"%s","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"%s","is_error":%t,"content":"%s"}]}}`, at, id, isError, result),
+	}
+}
+
+func TestPermissionsCmd(t *testing.T) {
+	home := setupFixtures(t)
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-perm")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	projPath := filepath.Join(home, "code", "perm")
+	for n, id := range []string{"perm1111", "perm2222"} {
+		day := fmt.Sprintf("2026-03-0%d", n+1)
+		lines := []string{`{"type":"user","cwd":"` + projPath + `","timestamp":"` + day + `T10:00:00Z","message":{"role":"user","content":"run the checks"}}`}
+		for i := range 3 {
+			at := fmt.Sprintf("%sT10:0%d:00Z", day, i+1)
+			lines = append(lines, bashCall(fmt.Sprintf("%s-%d", id, i), at, "go test ./... && git status", "ok", false)...)
+		}
+		lines = append(lines, bashCall(id+"-rm", day+"T10:05:00Z", "rm -rf build", "", false)...)
+		lines = append(lines, bashCall(id+"-push", day+"T11:00:00Z", "git push --force", "The user doesn't want to proceed with this tool use.", true)...)
+		writeLines(t, filepath.Join(projDir, id+"-0000-0000-0000-000000000000.jsonl"), lines)
+	}
+	settings := `{"permissions":{"allow":["Bash(git status:*)"]}}`
+	if err := os.WriteFile(filepath.Join(home, ".claude", "settings.json"), []byte(settings), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func() string {
+		return captureStdout(t, func() {
+			cmd := &PermissionsCmd{Project: "perm", MinRuns: 3, MinSessions: 2, MinDenials: 2, Limit: 25}
+			if err := cmd.Run(&Globals{JSON: true}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	out := run()
+	var data struct {
+		Calls       int `json:"calls"`
+		Suggestions []struct {
+			Rule     string `json:"rule"`
+			Runs     int    `json:"runs"`
+			Sessions int    `json:"sessions"`
+		} `json:"suggestions"`
+		AlreadyAllowed int `json:"already_allowed"`
+		Denied         []struct {
+			Rule   string `json:"rule"`
+			Denied int    `json:"denied"`
+		} `json:"denied"`
+		Snippet struct {
+			Permissions struct {
+				Allow []string `json:"allow"`
+			} `json:"permissions"`
+		} `json:"snippet"`
+	}
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if data.Calls != 8 || data.AlreadyAllowed != 1 {
+		t.Errorf("calls = %d, already allowed = %d", data.Calls, data.AlreadyAllowed)
+	}
+	if len(data.Suggestions) != 1 || data.Suggestions[0].Rule != "Bash(go test:*)" || data.Suggestions[0].Runs != 6 || data.Suggestions[0].Sessions != 2 {
+		t.Errorf("suggestions = %+v", data.Suggestions)
+	}
+	if strings.Join(data.Snippet.Permissions.Allow, ",") != "Bash(go test:*)" {
+		t.Errorf("snippet = %+v", data.Snippet)
+	}
+	if len(data.Denied) != 1 || data.Denied[0].Rule != "Bash(git push:*)" || data.Denied[0].Denied != 2 {
+		t.Errorf("denied = %+v", data.Denied)
+	}
+
+	after, err := os.ReadFile(filepath.Join(home, ".claude", "settings.json"))
+	if err != nil || string(after) != settings {
+		t.Errorf("settings.json changed: %s (%v)", after, err)
+	}
+
+	// The project's own settings cover the calls made in it.
+	if err := os.MkdirAll(filepath.Join(projPath, ".claude"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	local := `{"permissions":{"allow":["Bash(go test:*)"]}}`
+	if err := os.WriteFile(filepath.Join(projPath, ".claude", "settings.local.json"), []byte(local), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data.Suggestions = nil
+	out = run()
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(data.Suggestions) != 0 || data.AlreadyAllowed != 2 {
+		t.Errorf("with project settings: suggestions = %+v, already allowed = %d", data.Suggestions, data.AlreadyAllowed)
+	}
+}
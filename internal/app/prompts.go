@@ -0,0 +1,437 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andyhtran/cct/internal/index"
+	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/paths"
+	"github.com/andyhtran/cct/internal/prompts"
+)
+
+type PromptsCmd struct {
+	List   PromptsListCmd   `cmd:"" default:"withargs" help:"Prompts you keep retyping, near-duplicates grouped, most reused first (default when no subcommand)"`
+	Show   PromptsShowCmd   `cmd:"" help:"Print a prompt's full text"`
+	Copy   PromptsCopyCmd   `cmd:"" help:"Copy a prompt to the clipboard"`
+	Save   PromptsSaveCmd   `cmd:"" help:"Save a prompt to your library under a name"`
+	Saved  PromptsSavedCmd  `cmd:"" help:"List the prompt library"`
+	Remove PromptsRemoveCmd `cmd:"" aliases:"rm" help:"Remove a prompt from the library"`
+	Export PromptsExportCmd `cmd:"" help:"Write library prompts as Claude Code slash-command markdown files"`
+}
+
+// promptFilter selects the prompts that get grouped. List, show, copy, and
+// save share it so an ID printed by one resolves in the others.
+type promptFilter struct {
+	Project    string  `short:"p" help:"Filter by project name"`
+	Since      string  `help:"Only prompts typed at or after this time (today, yesterday, 7d, 2026-01-31)"`
+	Until      string  `help:"Only prompts typed before this time (same forms as --since)"`
+	MinLength  int     `help:"Ignore prompts shorter than this many characters" default:"30"`
+	Similarity float64 `help:"How alike two prompts must be to group (0-1, word-pair overlap)" default:"0.6"`
+}
+
+type PromptsListCmd struct {
+	Query   string `arg:"" optional:"" help:"Only prompts containing every word of this query"`
+	MinUses int    `help:"Only prompts typed at least this many times (default 2, or 1 with a query)"`
+	Limit   int    `short:"n" help:"Max prompts (0=no limit)" default:"25"`
+	promptFilter
+}
+
+type PromptsShowCmd struct {
+	Ref string `arg:"" help:"Prompt ID (from cct prompts) or library name"`
+	promptFilter
+}
+
+type PromptsCopyCmd struct {
+	Ref string `arg:"" help:"Prompt ID (from cct prompts) or library name"`
+	promptFilter
+}
+
+type PromptsSaveCmd struct {
+	Ref         string `arg:"" help:"Prompt ID (from cct prompts)"`
+	Name        string `arg:"" help:"Library name; also the slash command name on export (lowercase letters, digits, - and _)"`
+	Description string `short:"d" help:"One-line description (shown by /help once exported)"`
+	Force       bool   `short:"f" help:"Replace a saved prompt with the same name"`
+	promptFilter
+}
+
+type PromptsSavedCmd struct{}
+
+type PromptsRemoveCmd struct {
+	Name string `arg:"" help:"Library name"`
+}
+
+type PromptsExportCmd struct {
+	Names []string `arg:"" optional:"" help:"Library names to export (default: all)"`
+	To    string   `required:"" help:"Directory for the <name>.md files, e.g. .claude/commands (project) or ~/.claude/commands (personal)"`
+	Force bool     `short:"f" help:"Overwrite existing files"`
+}
+
+func (cmd *PromptsListCmd) Run(globals *Globals) error {
+	clusters, err := loadPromptClusters(globals, cmd.promptFilter)
+	if err != nil {
+		return err
+	}
+	minUses := cmd.MinUses
+	if minUses == 0 {
+		minUses = 2
+		if cmd.Query != "" {
+			minUses = 1
+		}
+	}
+	out := []*prompts.Cluster{}
+	for _, c := range clusters {
+		if c.Count < minUses || (cmd.Query != "" && !c.Contains(cmd.Query)) {
+			continue
+		}
+		out = append(out, c)
+		if cmd.Limit > 0 && len(out) >= cmd.Limit {
+			break
+		}
+	}
+
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	if len(out) == 0 {
+		if minUses > 1 {
+			fmt.Printf("No prompt typed %d or more times. Try --min-uses 1.\n", minUses)
+		} else {
+			fmt.Println("No matching prompts.")
+		}
+		return nil
+	}
+	tbl := output.NewTable(cmd.Query,
+		output.Fixed("ID", 8),
+		output.Fixed("USES", 4),
+		output.Fixed("SESS", 4),
+		output.Fixed("LAST", 6),
+		output.Flex("PROMPT", 0, 30),
+	)
+	fmt.Println()
+	tbl.PrintHeader()
+	for _, c := range out {
+		text := strings.Join(strings.Fields(c.Text), " ")
+		if c.Variants > 1 {
+			text = fmt.Sprintf("(%d variants) %s", c.Variants, text)
+		}
+		tbl.Row(
+			[]string{
+				c.ID,
+				fmt.Sprintf("%d", c.Count),
+				fmt.Sprintf("%d", c.Sessions),
+				output.FormatAge(c.Last),
+				output.Truncate(text, tbl.LastColWidth()),
+			},
+			[]func(string) string{output.Bold, output.Bold, output.Dim, output.Dim, output.Dim},
+		)
+	}
+	fmt.Println()
+	fmt.Printf("  %s\n\n", output.Cyan("cct prompts show <id> · cct prompts copy <id> · cct prompts save <id> <name>"))
+	return nil
+}
+
+func (cmd *PromptsShowCmd) Run(globals *Globals) error {
+	text, found, err := resolvePrompt(globals, cmd.Ref, cmd.promptFilter)
+	if err != nil {
+		return err
+	}
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(found)
+	}
+	fmt.Println(strings.TrimRight(text, "\n"))
+	return nil
+}
+
+func (cmd *PromptsCopyCmd) Run(globals *Globals) error {
+	text, _, err := resolvePrompt(globals, cmd.Ref, cmd.promptFilter)
+	if err != nil {
+		return err
+	}
+	if err := copyToClipboard(text); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Copied %d characters to the clipboard.\n", utf8.RuneCountInString(text))
+	return nil
+}
+
+func (cmd *PromptsSaveCmd) Run(globals *Globals) error {
+	if !prompts.ValidName(cmd.Name) {
+		return fmt.Errorf("invalid name %q: use lowercase letters, digits, - and _", cmd.Name)
+	}
+	lib, err := prompts.LoadLibrary(paths.PromptLibraryPath())
+	if err != nil {
+		return err
+	}
+	if _, exists := lib.Get(cmd.Name); exists && !cmd.Force {
+		return fmt.Errorf("%q is already in the library (use --force to replace it)", cmd.Name)
+	}
+	clusters, err := loadPromptClusters(globals, cmd.promptFilter)
+	if err != nil {
+		return err
+	}
+	c, err := findCluster(clusters, cmd.Ref)
+	if err != nil {
+		return err
+	}
+	saved := &prompts.Saved{
+		Name:        cmd.Name,
+		Description: cmd.Description,
+		Text:        c.Text,
+		SavedAt:     time.Now().UTC(),
+		Uses:        c.Count,
+		Sessions:    c.Sessions,
+	}
+	lib.Put(saved)
+	if err := lib.Save(); err != nil {
+		return err
+	}
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(saved)
+	}
+	fmt.Printf("Saved %s to %s\n", output.Bold(cmd.Name), paths.PromptLibraryPath())
+	return nil
+}
+
+func (cmd *PromptsSavedCmd) Run(globals *Globals) error {
+	lib, err := prompts.LoadLibrary(paths.PromptLibraryPath())
+	if err != nil {
+		return err
+	}
+	if globals.JSON {
+		if lib.Prompts == nil {
+			lib.Prompts = []*prompts.Saved{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(lib.Prompts)
+	}
+	if len(lib.Prompts) == 0 {
+		fmt.Println("The prompt library is empty. Save one with: cct prompts save <id> <name>")
+		return nil
+	}
+	tbl := output.NewTable("",
+		output.Flex("NAME", 20, 12),
+		output.Fixed("SAVED", 6),
+		output.Flex("PROMPT", 0, 30),
+	)
+	fmt.Println()
+	tbl.PrintHeader()
+	for _, p := range lib.Prompts {
+		text := p.Description
+		if text == "" {
+			text = strings.Join(strings.Fields(p.Text), " ")
+		}
+		tbl.Row(
+			[]string{
+				output.Truncate(p.Name, tbl.ColWidth(0)),
+				output.FormatAge(p.SavedAt),
+				output.Truncate(text, tbl.LastColWidth()),
+			},
+			[]func(string) string{output.Bold, output.Dim, output.Dim},
+		)
+	}
+	fmt.Println()
+	return nil
+}
+
+func (cmd *PromptsRemoveCmd) Run(globals *Globals) error {
+	lib, err := prompts.LoadLibrary(paths.PromptLibraryPath())
+	if err != nil {
+		return err
+	}
+	if !lib.Remove(cmd.Name) {
+		return fmt.Errorf("no saved prompt %q", cmd.Name)
+	}
+	if err := lib.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %s\n", cmd.Name)
+	return nil
+}
+
+func (cmd *PromptsExportCmd) Run(globals *Globals) error {
+	lib, err := prompts.LoadLibrary(paths.PromptLibraryPath())
+	if err != nil {
+		return err
+	}
+	selected := lib.Prompts
+	if len(cmd.Names) > 0 {
+		selected = nil
+		for _, name := range cmd.Names {
+			p, ok := lib.Get(name)
+			if !ok {
+				return fmt.Errorf("no saved prompt %q", name)
+			}
+			selected = append(selected, p)
+		}
+	}
+	if len(selected) == 0 {
+		return errors.New("the prompt library is empty")
+	}
+
+	dir := cmd.To
+	if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+		dir = filepath.Join(os.Getenv("HOME"), rest)
+	}
+	for _, p := range selected {
+		dest := filepath.Join(dir, p.Name+".md")
+		if _, err := os.Stat(dest); err == nil && !cmd.Force {
+			return fmt.Errorf("%s exists (use --force to overwrite)", dest)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	written := []string{}
+	for _, p := range selected {
+		dest := filepath.Join(dir, p.Name+".md")
+		if err := os.WriteFile(dest, []byte(p.SlashCommand()), 0o644); err != nil {
+			return err
+		}
+		written = append(written, dest)
+	}
+
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(written)
+	}
+	for i, dest := range written {
+		fmt.Printf("  %s  %s\n", dest, output.Dim("/"+selected[i].Name))
+	}
+	return nil
+}
+
+// loadPromptClusters groups the indexed human prompts that pass f.
+// Sub-agent sessions are left out: their prompts were written by an agent.
+func loadPromptClusters(globals *Globals, f promptFilter) ([]*prompts.Cluster, error) {
+	w, err := parseTimeWindow(f.Since, f.Until, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	idx, err := index.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open index: %w", err)
+	}
+	defer func() { _ = idx.Close() }()
+
+	rows, err := idx.HumanPrompts(index.EventFilter{
+		Root:          globals.Root,
+		ProjectFilter: f.Project,
+		Since:         w.Since,
+		Until:         w.Until,
+	})
+	if err != nil {
+		return nil, err
+	}
+	uses := make([]prompts.Use, 0, len(rows))
+	seen := map[string]bool{}
+	for _, r := range rows {
+		// Claude Code logs interrupts as user text; nobody typed them.
+		if strings.HasPrefix(r.Text, "[Request interrupted") || utf8.RuneCountInString(r.Text) < f.MinLength {
+			continue
+		}
+		// A resumed session's file repeats the earlier prompts; count the
+		// one typed, not each copy.
+		if !r.At.IsZero() {
+			key := r.Root + "\x00" + r.At.Format(time.RFC3339Nano) + "\x00" + r.Text
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		uses = append(uses, prompts.Use{
+			Text:      r.Text,
+			Root:      r.Root,
+			SessionID: r.SessionID,
+			Project:   r.Project,
+			At:        r.At,
+			Offset:    r.Offset,
+		})
+	}
+	return prompts.Group(uses, f.Similarity), nil
+}
+
+// resolvePrompt finds ref in the library, then among the grouped prompts.
+// found is the *prompts.Saved or *prompts.Cluster, for --json.
+func resolvePrompt(globals *Globals, ref string, f promptFilter) (text string, found any, err error) {
+	lib, err := prompts.LoadLibrary(paths.PromptLibraryPath())
+	if err != nil {
+		return "", nil, err
+	}
+	if p, ok := lib.Get(ref); ok {
+		return p.Text, p, nil
+	}
+	clusters, err := loadPromptClusters(globals, f)
+	if err != nil {
+		return "", nil, err
+	}
+	c, err := findCluster(clusters, ref)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.Text, c, nil
+}
+
+func findCluster(clusters []*prompts.Cluster, id string) (*prompts.Cluster, error) {
+	if len(id) < 4 {
+		return nil, fmt.Errorf("prompt ID %q is too short (use at least 4 characters)", id)
+	}
+	var found *prompts.Cluster
+	for _, c := range clusters {
+		if c.Matches(id) {
+			if found != nil {
+				return nil, fmt.Errorf("prompt ID %q is ambiguous", id)
+			}
+			found = c
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no prompt %q (IDs come from cct prompts; library names from cct prompts saved)", id)
+	}
+	return found, nil
+}
+
+// copyToClipboard pipes text to the platform's clipboard tool.
+func copyToClipboard(text string) error {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-copy"})
+		}
+		candidates = append(candidates,
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xsel", "--clipboard", "--input"})
+	}
+	for _, args := range candidates {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		c := exec.Command(args[0], args[1:]...)
+		c.Stdin = strings.NewReader(text)
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		return nil
+	}
+	return errors.New("no clipboard tool found (pbcopy, wl-copy, xclip, or xsel); use cct prompts show instead")
+}
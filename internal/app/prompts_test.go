@@ -0,0 +1,148 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andyhtran/cct/internal/prompts"
+)
+
+func writePromptFixtures(t *testing.T, home string) {
+	t.Helper()
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-prompts")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for i, text := range []string{
+		"Review this diff for bugs, security issues, and missing tests.",
+		"please review this diff for bugs, security issues and missing tests",
+		"Review this diff for bugs, security issues, and missing tests.",
+	} {
+		id := string(rune('1'+i)) + "eee0000-0000-0000-0000-000000000000"
+		at := "2026-03-0" + string(rune('1'+i)) + "T10:00:00Z"
+		writeLines(t, filepath.Join(projDir, id+".jsonl"), []string{
+			`{"type":"user","cwd":"/Users/test/prompts","timestamp":"` + at + `","message":{"role":"user","content":` + jsonString(text) + `}}`,
+			`{"type":"user","timestamp":"` + at + `","message":{"role":"user","content":"[Request interrupted by user]"}}`,
+			`{"type":"user","timestamp":"` + at + `","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"x","content":"Review this diff for bugs, security issues, and missing tests."}]}}`,
+		})
+	}
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func TestPromptsCmd(t *testing.T) {
+	home := setupFixtures(t)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+	writePromptFixtures(t, home)
+	filter := promptFilter{MinLength: 30, Similarity: prompts.DefaultThreshold}
+
+	out := captureStdout(t, func() {
+		cmd := &PromptsListCmd{Limit: 25, promptFilter: filter}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var clusters []prompts.Cluster
+	if err := json.Unmarshal([]byte(out), &clusters); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(clusters) != 1 {
+		t.Fatalf("got %d clusters, want 1 (short and one-off prompts hidden):\n%s", len(clusters), out)
+	}
+	c := clusters[0]
+	if c.Count != 3 || c.Sessions != 3 || c.Variants != 2 {
+		t.Errorf("cluster = count %d, sessions %d, variants %d; want 3, 3, 2", c.Count, c.Sessions, c.Variants)
+	}
+
+	out = captureStdout(t, func() {
+		cmd := &PromptsListCmd{Query: "database", Limit: 25, promptFilter: promptFilter{MinLength: 1, Similarity: prompts.DefaultThreshold}}
+		if err := cmd.Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "fix the database bug") {
+		t.Errorf("query should list one-off prompts:\n%s", out)
+	}
+
+	save := &PromptsSaveCmd{Ref: c.ID[:6], Name: "review", Description: "Review the diff", promptFilter: filter}
+	captureStdout(t, func() {
+		if err := save.Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err := save.Run(&Globals{}); err == nil {
+		t.Error("saving over an existing name without --force should fail")
+	}
+
+	out = captureStdout(t, func() {
+		cmd := &PromptsShowCmd{Ref: "review", promptFilter: filter}
+		if err := cmd.Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if strings.TrimSpace(out) != c.Text {
+		t.Errorf("show = %q, want %q", out, c.Text)
+	}
+
+	dir := filepath.Join(home, "commands")
+	export := &PromptsExportCmd{To: dir}
+	captureStdout(t, func() {
+		if err := export.Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	data, err := os.ReadFile(filepath.Join(dir, "review.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "---\ndescription: Review the diff\n---\n\n" + c.Text + "\n"; string(data) != want {
+		t.Errorf("review.md =\n%s\nwant\n%s", data, want)
+	}
+	if err := export.Run(&Globals{}); err == nil {
+		t.Error("export over existing files without --force should fail")
+	}
+
+	captureStdout(t, func() {
+		if err := (&PromptsRemoveCmd{Name: "review"}).Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err := (&PromptsRemoveCmd{Name: "review"}).Run(&Globals{}); err == nil {
+		t.Error("removing a missing prompt should fail")
+	}
+}
+
+func TestPromptsCmd_ResumedCopiesCountOnce(t *testing.T) {
+	home := setupFixtures(t)
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-resumed")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	typed := `{"type":"user","uuid":"p1","cwd":"/Users/test/resumed","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"deploy the staging stack and run the smoke tests"}}`
+	// The original file and two resumes of it, each replaying the prompt.
+	for _, id := range []string{"1fff", "2fff", "3fff"} {
+		writeLines(t, filepath.Join(projDir, id+"0000-0000-0000-0000-000000000000.jsonl"), []string{typed})
+	}
+
+	out := captureStdout(t, func() {
+		cmd := &PromptsListCmd{Query: "staging", Limit: 25, promptFilter: promptFilter{MinLength: 1, Similarity: prompts.DefaultThreshold}}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var clusters []prompts.Cluster
+	if err := json.Unmarshal([]byte(out), &clusters); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(clusters) != 1 || clusters[0].Count != 1 || clusters[0].Sessions != 1 {
+		t.Errorf("clusters = %+v, want one prompt used once in one session", clusters)
+	}
+}
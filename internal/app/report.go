@@ -0,0 +1,325 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andyhtran/cct/internal/index"
+	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/session"
+)
+
+type ReportCmd struct {
+	Since   string        `help:"Start of the report window (today, yesterday, 7d, 2026-01-31, ...)" default:"yesterday"`
+	Until   string        `help:"End of the report window, exclusive (same forms as --since)"`
+	Project string        `short:"p" help:"Filter by project name"`
+	Max     int           `help:"Max files, commands, and commits listed per group (0=no limit)" default:"10"`
+	Idle    time.Duration `help:"Gap between messages that ends a work segment" default:"15m"`
+	Agents  bool          `help:"Include sub-agent sessions"`
+}
+
+type reportSession struct {
+	ID            string `json:"id"`
+	ShortID       string `json:"short_id"`
+	Title         string `json:"title"`
+	ActiveSeconds int64  `json:"active_seconds"`
+}
+
+type reportGroup struct {
+	Project       string           `json:"project"`
+	ProjectPath   string           `json:"project_path"`
+	Branch        string           `json:"branch,omitempty"`
+	ActiveSeconds int64            `json:"active_seconds"`
+	Sessions      []reportSession  `json:"sessions"`
+	Commits       []session.Commit `json:"commits"`
+	FilesEdited   []string         `json:"files_edited"`
+	Commands      []string         `json:"commands"`
+
+	segments []session.Segment
+	seen     map[string]bool
+}
+
+type reportData struct {
+	Window        string         `json:"window"`
+	Since         time.Time      `json:"since,omitzero"`
+	Until         time.Time      `json:"until,omitzero"`
+	ActiveSeconds int64          `json:"active_seconds"`
+	Groups        []*reportGroup `json:"groups"`
+}
+
+func (cmd *ReportCmd) Run(globals *Globals) error {
+	window, err := parseTimeWindow(cmd.Since, cmd.Until, time.Now())
+	if err != nil {
+		return err
+	}
+
+	idx, err := index.Open()
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+	defer func() { _ = idx.Close() }()
+
+	sessions, err := idx.ListSessions(index.ListOptions{
+		Root:          globals.Root,
+		ProjectFilter: cmd.Project,
+		IncludeAgents: cmd.Agents,
+		ActiveSince:   window.Since,
+		ActiveUntil:   window.Until,
+	})
+	if err != nil {
+		return fmt.Errorf("list sessions: %w", err)
+	}
+
+	data := &reportData{Window: describeWindow(cmd.Since, cmd.Until), Since: window.Since, Until: window.Until}
+	groups := map[string]*reportGroup{}
+	var all []session.Segment
+	for _, s := range sessions {
+		activity, err := readSessionActivity(s.FilePath, window)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", s.ShortID, err)
+			continue
+		}
+		if len(activity.times) == 0 {
+			continue
+		}
+
+		key := s.ProjectPath + "\x00" + s.GitBranch
+		g, ok := groups[key]
+		if !ok {
+			g = &reportGroup{Project: s.ProjectName, ProjectPath: s.ProjectPath, Branch: s.GitBranch, seen: map[string]bool{}}
+			groups[key] = g
+		}
+		segs := session.Segments(activity.times, cmd.Idle)
+		g.segments = append(g.segments, segs...)
+		all = append(all, segs...)
+		g.Sessions = append(g.Sessions, reportSession{
+			ID:            s.ID,
+			ShortID:       s.ShortID,
+			Title:         sessionTitle(s),
+			ActiveSeconds: int64(session.ActiveTime(segs).Seconds()),
+		})
+		g.addCalls(activity.calls)
+	}
+
+	for _, g := range groups {
+		g.ActiveSeconds = int64(session.ActiveTime(session.MergeSegments(g.segments)).Seconds())
+		sort.SliceStable(g.Sessions, func(i, j int) bool {
+			return g.Sessions[i].ActiveSeconds > g.Sessions[j].ActiveSeconds
+		})
+		data.Groups = append(data.Groups, g)
+	}
+	sort.Slice(data.Groups, func(i, j int) bool {
+		a, b := data.Groups[i], data.Groups[j]
+		if a.ActiveSeconds != b.ActiveSeconds {
+			return a.ActiveSeconds > b.ActiveSeconds
+		}
+		return a.Project+a.Branch < b.Project+b.Branch
+	})
+	data.ActiveSeconds = int64(session.ActiveTime(session.MergeSegments(all)).Seconds())
+
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	}
+	writeReport(os.Stdout, data, cmd.Max)
+	return nil
+}
+
+func sessionTitle(s *session.Session) string {
+	if s.CustomTitle != "" {
+		return s.CustomTitle
+	}
+	if s.FirstPrompt != "" {
+		return output.Truncate(s.FirstPrompt, 100)
+	}
+	return "[no prompt]"
+}
+
+// addCalls folds a session's in-window tool calls into the group, keeping
+// first-seen order and dropping repeats.
+func (g *reportGroup) addCalls(calls []*session.ToolCall) {
+	add := func(list *[]string, kind, v string) {
+		if v == "" || g.seen[kind+v] {
+			return
+		}
+		g.seen[kind+v] = true
+		*list = append(*list, v)
+	}
+	for _, c := range calls {
+		if path := session.EditedPath(c); path != "" {
+			if !c.IsError {
+				add(&g.FilesEdited, "file:", relativeTo(g.ProjectPath, path))
+			}
+			continue
+		}
+		if c.Name != "Bash" {
+			continue
+		}
+		if session.IsGitCommit(c) {
+			for _, commit := range session.CommitsFromCall(c) {
+				key := "commit:" + commit.SHA + commit.Subject
+				if !g.seen[key] {
+					g.seen[key] = true
+					g.Commits = append(g.Commits, commit)
+				}
+			}
+			continue
+		}
+		command, _ := c.Input["command"].(string)
+		command, _, _ = strings.Cut(strings.TrimSpace(command), "\n")
+		add(&g.Commands, "cmd:", command)
+	}
+}
+
+// relativeTo shortens path to be relative to dir when it lies inside it.
+func relativeTo(dir, path string) string {
+	if dir == "" || path == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+type sessionActivity struct {
+	times []time.Time
+	calls []*session.ToolCall
+}
+
+// readSessionActivity returns the message timestamps and tool calls of a
+// session file that fall inside w. Commit output is kept so commits can be
+// read from git's summary line.
+func readSessionActivity(path string, w timeWindow) (*sessionActivity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	tracker := session.NewToolTracker()
+	tracker.KeepOutput = session.IsGitCommit
+	out := &sessionActivity{}
+	scanner := session.NewOffsetScanner(f)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if t := session.FastExtractType(line); t != "user" && t != "assistant" {
+			continue
+		}
+		ts := session.FastExtractTimestamp(line)
+		if !ts.IsZero() && w.Contains(ts) {
+			out.times = append(out.times, ts)
+		}
+		var obj map[string]any
+		if json.Unmarshal(line, &obj) != nil {
+			continue
+		}
+		tracker.Observe(obj, scanner.Offset())
+	}
+	for _, c := range tracker.Calls() {
+		if !c.CalledAt.IsZero() && w.Contains(c.CalledAt) {
+			out.calls = append(out.calls, c)
+		}
+	}
+	return out, scanner.Err()
+}
+
+// writeReport renders data as markdown for pasting into a standup channel.
+func writeReport(w io.Writer, data *reportData, maxItems int) {
+	_, _ = fmt.Fprintf(w, "# Report: %s\n\n", reportRange(data))
+	if len(data.Groups) == 0 {
+		_, _ = fmt.Fprintln(w, "No sessions in this window.")
+		return
+	}
+	_, _ = fmt.Fprintf(w, "Active time: %s across %d %s.\n",
+		output.FormatDuration(seconds(data.ActiveSeconds)), len(data.Groups), plural(len(data.Groups), "project/branch", "projects/branches"))
+
+	for _, g := range data.Groups {
+		heading := g.Project
+		if heading == "" {
+			heading = "(unknown project)"
+		}
+		if g.Branch != "" {
+			heading += " (`" + g.Branch + "`)"
+		}
+		_, _ = fmt.Fprintf(w, "\n## %s — %s active\n\n", heading, output.FormatDuration(seconds(g.ActiveSeconds)))
+
+		for _, s := range g.Sessions {
+			_, _ = fmt.Fprintf(w, "- %s (`%s`, %s)\n", s.Title, s.ShortID, output.FormatDuration(seconds(s.ActiveSeconds)))
+		}
+
+		if len(g.Commits) > 0 {
+			var lines []string
+			for _, c := range g.Commits {
+				if c.SHA == "" {
+					lines = append(lines, c.Subject)
+					continue
+				}
+				lines = append(lines, fmt.Sprintf("`%s` %s", shortSHA(c.SHA), c.Subject))
+			}
+			writeReportList(w, "Commits", lines, maxItems)
+		}
+		writeReportList(w, "Files edited", codeSpans(g.FilesEdited), maxItems)
+		writeReportList(w, "Commands", codeSpans(g.Commands), maxItems)
+	}
+}
+
+func writeReportList(w io.Writer, title string, items []string, maxItems int) {
+	if len(items) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "\n**%s**\n", title)
+	shown := items
+	if maxItems > 0 && len(items) > maxItems {
+		shown = items[:maxItems]
+	}
+	for _, item := range shown {
+		_, _ = fmt.Fprintf(w, "- %s\n", item)
+	}
+	if more := len(items) - len(shown); more > 0 {
+		_, _ = fmt.Fprintf(w, "- … and %d more\n", more)
+	}
+}
+
+func codeSpans(items []string) []string {
+	out := make([]string, len(items))
+	for i, s := range items {
+		out[i] = "`" + output.Truncate(strings.ReplaceAll(s, "`", "'"), 100) + "`"
+	}
+	return out
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+func reportRange(data *reportData) string {
+	const layout = "Mon Jan 2 15:04"
+	switch {
+	case !data.Since.IsZero() && !data.Until.IsZero():
+		return data.Since.Local().Format(layout) + " – " + data.Until.Local().Format(layout)
+	case !data.Since.IsZero():
+		return data.Since.Local().Format(layout) + " – now"
+	case !data.Until.IsZero():
+		return "until " + data.Until.Local().Format(layout)
+	default:
+		return data.Window
+	}
+}
@@ -0,0 +1,83 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportCmd(t *testing.T) {
+	home := setupFixtures(t)
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-standup")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	base := time.Now().Add(-2 * time.Hour).UTC()
+	ts := func(d time.Duration) string { return base.Add(d).Format(time.RFC3339) }
+	old := time.Now().AddDate(0, 0, -10).UTC().Format(time.RFC3339)
+	writeLines(t, filepath.Join(projDir, "stand111-2222-3333-4444-555555555555.jsonl"), []string{
+		// Before the window: must not leak into the report.
+		`{"type":"user","message":{"role":"user","content":"fix login"},"cwd":"/Users/test/standup","gitBranch":"feat/login","timestamp":"` + old + `"}`,
+		`{"type":"assistant","timestamp":"` + old + `","message":{"role":"assistant","content":[{"type":"tool_use","id":"o1","name":"Edit","input":{"file_path":"/Users/test/standup/old.go"}}]}}`,
+		`{"type":"user","message":{"role":"user","content":"continue"},"cwd":"/Users/test/standup","gitBranch":"feat/login","timestamp":"` + ts(0) + `"}`,
+		`{"type":"assistant","timestamp":"` + ts(time.Minute) + `","message":{"role":"assistant","content":[` +
+			`{"type":"tool_use","id":"a","name":"Edit","input":{"file_path":"/Users/test/standup/auth/login.go"}},` +
+			`{"type":"tool_use","id":"b","name":"Bash","input":{"command":"go test ./auth/..."}},` +
+			`{"type":"tool_use","id":"c","name":"Bash","input":{"command":"git commit -am \"Fix login redirect\""}}]}}`,
+		`{"type":"user","timestamp":"` + ts(3*time.Minute) + `","message":{"role":"user","content":[` +
+			`{"type":"tool_result","tool_use_id":"a","content":"ok"},` +
+			`{"type":"tool_result","tool_use_id":"b","content":"ok"},` +
+			`{"type":"tool_result","tool_use_id":"c","content":"[feat/login 9f8e7d6] Fix login redirect\n 1 file changed"}]}}`,
+		`{"type":"assistant","timestamp":"` + ts(10*time.Minute) + `","message":{"role":"assistant","content":"done"}}`,
+	})
+
+	out := captureStdout(t, func() {
+		cmd := &ReportCmd{Since: "1d", Project: "standup", Idle: 15 * time.Minute}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var data reportData
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(data.Groups) != 1 {
+		t.Fatalf("got %d groups, want 1", len(data.Groups))
+	}
+	g := data.Groups[0]
+	if g.Project != "standup" || g.Branch != "feat/login" || g.ActiveSeconds != 600 {
+		t.Errorf("group = %s/%s %ds", g.Project, g.Branch, g.ActiveSeconds)
+	}
+	if len(g.FilesEdited) != 1 || g.FilesEdited[0] != "auth/login.go" {
+		t.Errorf("files = %v", g.FilesEdited)
+	}
+	if len(g.Commands) != 1 || g.Commands[0] != "go test ./auth/..." {
+		t.Errorf("commands = %v", g.Commands)
+	}
+	if len(g.Commits) != 1 || g.Commits[0].SHA != "9f8e7d6" || g.Commits[0].Subject != "Fix login redirect" {
+		t.Errorf("commits = %+v", g.Commits)
+	}
+
+	var buf bytes.Buffer
+	writeReport(&buf, &data, 10)
+	md := buf.String()
+	for _, want := range []string{"## standup (`feat/login`) — 10m active", "`9f8e7d6` Fix login redirect", "- `auth/login.go`", "(`stand111`, 10m)"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestWriteReportList_Truncates(t *testing.T) {
+	var buf bytes.Buffer
+	writeReportList(&buf, "Files edited", []string{"a", "b", "c"}, 2)
+	if got := buf.String(); !strings.Contains(got, "- b\n- … and 1 more") || strings.Contains(got, "- c") {
+		t.Errorf("got:\n%s", got)
+	}
+}
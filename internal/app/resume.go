@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 
@@ -21,7 +22,7 @@ func shellQuote(s string) string {
 
 // checkBackupOnly warns when the session was resolved through the backup
 // mirror because the live JSONL is gone. DiscoverFilesWithBackups lets live
-// paths win, so a FilePath under BackupProjectsDir means live is (or was)
+// paths win, so a FilePath under the backup dir means live is (or was)
 // missing — but the index can lag behind a just-completed restore, so we
 // re-stat the canonical source path before nudging. Manifest load errors
 // collapse to "not in backup" — a parse failure should never block the user
@@ -29,11 +30,11 @@ func shellQuote(s string) string {
 // ErrNotExist also falls through: resume isn't the place to surface
 // permission or FS issues.
 func checkBackupOnly(match *session.Session) error {
-	if !strings.HasPrefix(match.FilePath, paths.BackupProjectsDir()) {
+	if !strings.HasPrefix(match.FilePath, paths.BackupDir()) {
 		return nil
 	}
 	manifest, _ := backup.LoadManifest(paths.BackupManifestPath())
-	entry, ok := manifest.Entries[match.ID]
+	entry, key, ok := manifest.Lookup(backup.EntryKey(match.Root, match.ID))
 	if !ok {
 		return nil
 	}
@@ -42,19 +43,31 @@ func checkBackupOnly(match *session.Session) error {
 	}
 	fmt.Fprintf(os.Stderr,
 		"Session %s is preserved in your cct backup but missing from\n"+
-			"~/.claude/projects/. Restore it first:\n\n"+
+			"%s. Restore it first:\n\n"+
 			"    cct backup restore %s\n",
-		match.ShortID, match.ID)
+		match.ShortID, filepath.Dir(filepath.Dir(entry.SourcePath)), key)
 	return &ExitError{Code: 1}
 }
 
+// rootConfigDir returns the CLAUDE_CONFIG_DIR claude needs to find the
+// session, or "" when the session lives in the directory claude already uses.
+func rootConfigDir(match *session.Session) string {
+	r, ok := paths.LookupRoot(match.Root)
+	if !ok || r.Dir == filepath.Clean(paths.ClaudeDir()) {
+		return ""
+	}
+	return r.Dir
+}
+
 type ResumeCmd struct {
-	ID     string `arg:"" help:"Session ID or prefix"`
+	ID     string `arg:"" help:"Session ID or prefix, or a match ref from cct search"`
 	DryRun bool   `help:"Print command instead of executing" name:"dry-run"`
 }
 
 func (cmd *ResumeCmd) Run(globals *Globals) error {
-	match, err := session.FindByPrefixFull(cmd.ID)
+	// claude --resume always opens at the end, so a ref's message is moot.
+	id, _ := session.SplitRef(cmd.ID)
+	match, err := findSession(globals, id)
 	if err != nil {
 		return err
 	}
@@ -63,11 +76,17 @@ func (cmd *ResumeCmd) Run(globals *Globals) error {
 		return err
 	}
 
+	configDir := rootConfigDir(match)
+
 	if cmd.DryRun {
+		command := "claude --resume " + match.ID
+		if configDir != "" {
+			command = "CLAUDE_CONFIG_DIR=" + shellQuote(configDir) + " " + command
+		}
 		if match.ProjectPath != "" {
-			fmt.Printf("cd %s && claude --resume %s\n", shellQuote(match.ProjectPath), match.ID)
+			fmt.Printf("cd %s && %s\n", shellQuote(match.ProjectPath), command)
 		} else {
-			fmt.Printf("claude --resume %s\n", match.ID)
+			fmt.Println(command)
 		}
 		return nil
 	}
@@ -115,6 +134,9 @@ func (cmd *ResumeCmd) Run(globals *Globals) error {
 	if dir != "" {
 		c.Dir = dir
 	}
+	if configDir != "" {
+		c.Env = append(os.Environ(), "CLAUDE_CONFIG_DIR="+configDir)
+	}
 	c.Stdin = os.Stdin
 	c.Stdout = os.Stdout
 	c.Stderr = os.Stderr
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/andyhtran/cct/internal/index"
 	"github.com/andyhtran/cct/internal/output"
@@ -11,8 +13,32 @@ import (
 )
 
 var roleTag = map[string]string{
-	"user":      "[u]",
-	"assistant": "[a]",
+	"user":        "[u]",
+	"assistant":   "[a]",
+	"thinking":    "[t]",
+	"tool_result": "[r]",
+}
+
+// searchScopes maps each --in value to the match roles it covers. Agent
+// descriptions are the prompt an agent was given.
+var searchScopes = map[string][]string{
+	"prompts":     {"user", "description"},
+	"responses":   {"assistant"},
+	"thinking":    {"thinking"},
+	"tool-output": {session.RoleToolResult},
+}
+
+// searchRoles resolves --in values to match roles; none means every role.
+func searchRoles(scopes []string) ([]string, error) {
+	var roles []string
+	for _, scope := range scopes {
+		r, ok := searchScopes[scope]
+		if !ok {
+			return nil, fmt.Errorf("unknown --in %q (use prompts, responses, thinking, or tool-output)", scope)
+		}
+		roles = append(roles, r...)
+	}
+	return roles, nil
 }
 
 func formatMatchRole(m session.Match) string {
@@ -26,9 +52,18 @@ func formatMatchRole(m session.Match) string {
 	return output.Dim(tag) + " " + m.Snippet
 }
 
+// matchRef is what the REF column shows for a match: its message ref, or
+// the session's short ID for hits outside the transcript.
+func matchRef(s *session.Session, m session.Match) string {
+	if m.Ref != "" {
+		return m.Ref
+	}
+	return s.ShortID
+}
+
 func makeSearchTable(query string) *output.Table {
 	return output.NewTable(query,
-		output.Fixed("SESSION", 16),
+		output.Fixed("REF", 18),
 		output.Flex("PROJECT", 25, 15),
 		output.Fixed("AGE", 6),
 		output.Flex("MATCH", 0, 30),
@@ -36,22 +71,37 @@ func makeSearchTable(query string) *output.Table {
 }
 
 type SearchCmd struct {
-	Query      string `arg:"" help:"Search query"`
-	Project    string `short:"p" help:"Filter by project name"`
-	Session    string `short:"s" help:"Search within a specific session (ID or prefix)"`
-	Limit      int    `short:"n" help:"Max results (0=no limit)" default:"25"`
-	All        bool   `short:"a" help:"Show all results"`
-	MaxMatches int    `short:"m" help:"Max matches per session" default:"3"`
-	Context    int    `short:"C" help:"Extra context characters for snippets" default:"0"`
-	Sort       string `help:"Sort order: recency (default), relevance" default:"recency" enum:"recency,relevance"`
-	NoAgents   bool   `help:"Exclude sub-agent sessions" name:"no-agents"`
-	Sync       bool   `help:"Force index sync before searching"`
+	Query      string   `arg:"" help:"Search query"`
+	Project    string   `short:"p" help:"Filter by project name"`
+	Session    string   `short:"s" help:"Search within a specific session (ID or prefix)"`
+	Limit      int      `short:"n" help:"Max results (0=no limit)" default:"25"`
+	All        bool     `short:"a" help:"Show all results"`
+	MaxMatches int      `short:"m" help:"Max matches per session" default:"3"`
+	Context    int      `short:"C" help:"Extra context characters for snippets" default:"0"`
+	Sort       string   `help:"Sort order: recency (default), relevance, tokens (peak context), messages, duration, size" default:"recency" enum:"recency,relevance,tokens,messages,duration,size"`
+	NoAgents   bool     `help:"Exclude sub-agent sessions" name:"no-agents"`
+	GroupBy    string   `help:"Group results: 'parent' folds sub-agent matches under the session that spawned them" name:"group-by" enum:"none,parent" default:"none"`
+	Sync       bool     `help:"Force index sync before searching"`
+	In         []string `help:"Only match in these parts of sessions (comma-separated): prompts, responses, thinking, tool-output" placeholder:"PART"`
+}
+
+// searchGroup is one session's result with the matches of the sub-agents
+// it spawned nested underneath (search --group-by parent). When only its
+// agents matched, the session's own Matches are empty.
+type searchGroup struct {
+	index.SearchResult
+	Agents []index.SearchResult `json:"agents"`
 }
 
 func (cmd *SearchCmd) Run(globals *Globals) error {
+	roles, err := searchRoles(cmd.In)
+	if err != nil {
+		return err
+	}
+
 	// Single-session search mode uses streaming (no index needed)
 	if cmd.Session != "" {
-		return cmd.runSessionSearch(globals)
+		return cmd.runSessionSearch(globals, roles)
 	}
 
 	idx, err := index.Open()
@@ -84,11 +134,13 @@ func (cmd *SearchCmd) Run(globals *Globals) error {
 	results, total, err := idx.Search(index.SearchOptions{
 		Query:         cmd.Query,
 		ProjectFilter: cmd.Project,
+		Root:          globals.Root,
 		IncludeAgents: includeAgents,
 		MaxResults:    limit,
 		MaxMatches:    cmd.MaxMatches,
 		SnippetWidth:  tbl.LastColWidth() + cmd.Context,
 		SortBy:        cmd.Sort,
+		Roles:         roles,
 	})
 	if err != nil {
 		return fmt.Errorf("search: %w", err)
@@ -110,6 +162,27 @@ func (cmd *SearchCmd) Run(globals *Globals) error {
 		fmt.Fprintf(os.Stderr, "Showing %d of %d results (use --all or -n to adjust)\n", len(results), total)
 	}
 
+	if cmd.GroupBy == "parent" {
+		groups := groupByParent(idx, results)
+		if globals.JSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(groups)
+		}
+		fmt.Printf("\n  Found %d session(s) matching %q in %d conversation(s)\n", total, cmd.Query, len(groups))
+		fmt.Println()
+		tbl.PrintHeader()
+		sessions := make([]*session.Session, 0, len(groups))
+		for _, g := range groups {
+			printSearchGroup(g, tbl)
+			sessions = append(sessions, g.Session)
+		}
+		fmt.Println()
+		printResumeHints(sessions)
+		fmt.Println()
+		return nil
+	}
+
 	if globals.JSON {
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
@@ -127,19 +200,39 @@ func (cmd *SearchCmd) Run(globals *Globals) error {
 		sessions = append(sessions, r.Session)
 	}
 	printResumeHints(sessions)
+	printRefHint(results)
 	fmt.Println()
 	return nil
 }
 
+// printRefHint shows how to open the first match with a message ref where
+// it matched.
+func printRefHint(results []index.SearchResult) {
+	for _, r := range results {
+		for _, m := range r.Matches {
+			if m.Ref != "" {
+				fmt.Printf("  %s\n", output.Cyan(fmt.Sprintf("cct view %s · cct export --around %s", m.Ref, m.Ref)))
+				return
+			}
+		}
+	}
+}
+
 // runSessionSearch searches within a specific session using streaming (for -s flag)
-func (cmd *SearchCmd) runSessionSearch(globals *Globals) error {
-	s, err := session.FindByPrefix(cmd.Session)
+func (cmd *SearchCmd) runSessionSearch(globals *Globals, roles []string) error {
+	s, err := findSession(globals, cmd.Session)
 	if err != nil {
 		return err
 	}
 
 	tbl := makeSearchTable(cmd.Query)
 	results := session.SearchFiles([]string{s.FilePath}, cmd.Query, tbl.LastColWidth()+cmd.Context, cmd.MaxMatches)
+	if len(roles) > 0 {
+		results = slices.DeleteFunc(results, func(r *session.SearchResult) bool {
+			r.Matches = slices.DeleteFunc(r.Matches, func(m session.Match) bool { return !slices.Contains(roles, m.Role) })
+			return len(r.Matches) == 0
+		})
+	}
 
 	if len(results) == 0 {
 		fmt.Printf("  No matches for %q in session %s\n", cmd.Query, s.ShortID)
@@ -171,7 +264,7 @@ func printResults(results []index.SearchResult, tbl *output.Table) {
 }
 
 func printSessionMatches(s *session.Session, matches []session.Match, tbl *output.Table) {
-	projectName := s.ProjectName
+	projectName := projectLabel(s)
 	if s.IsAgent {
 		projectName += " (agent)"
 	}
@@ -179,11 +272,87 @@ func printSessionMatches(s *session.Session, matches []session.Match, tbl *outpu
 		display := formatMatchRole(m)
 		if i == 0 {
 			tbl.Row(
-				[]string{s.ShortID, output.Truncate(projectName, tbl.ColWidth(1)), output.FormatAge(s.Modified), display},
+				[]string{matchRef(s, m), output.Truncate(projectName, tbl.ColWidth(1)), output.FormatAge(s.Modified), display},
 				[]func(string) string{output.Dim, output.Bold, output.Dim, nil},
 			)
 		} else {
-			tbl.Continuation(display)
+			tbl.Row([]string{matchRef(s, m), "", "", display}, []func(string) string{output.Dim, nil, nil, nil})
+		}
+	}
+}
+
+// groupByParent folds each sub-agent result into a group for its parent
+// session, keeping the rank of whichever member matched first. A parent
+// that didn't match itself is loaded from the index; agents whose parent
+// isn't indexed stay as groups of their own.
+func groupByParent(idx *index.Index, results []index.SearchResult) []*searchGroup {
+	var groups []*searchGroup
+	byKey := map[string]*searchGroup{}
+	for _, r := range results {
+		s := r.Session
+		if !s.IsAgent || s.ParentID == "" {
+			key := s.Root + "/" + s.ID
+			if g, ok := byKey[key]; ok {
+				g.SearchResult = r
+				continue
+			}
+			g := &searchGroup{SearchResult: r, Agents: []index.SearchResult{}}
+			byKey[key] = g
+			groups = append(groups, g)
+			continue
+		}
+
+		key := s.Root + "/" + s.ParentID
+		g, ok := byKey[key]
+		if !ok {
+			parent, err := idx.Session(s.Root, s.ParentID)
+			if err != nil {
+				g = &searchGroup{SearchResult: r, Agents: []index.SearchResult{}}
+				byKey[s.Root+"/"+s.ID] = g
+				groups = append(groups, g)
+				continue
+			}
+			g = &searchGroup{
+				SearchResult: index.SearchResult{Session: parent, Matches: []session.Match{}},
+				Agents:       []index.SearchResult{},
+			}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.Agents = append(g.Agents, r)
+	}
+	return groups
+}
+
+func printSearchGroup(g *searchGroup, tbl *output.Table) {
+	if len(g.Matches) > 0 {
+		printSessionMatches(g.Session, g.Matches, tbl)
+	} else {
+		tbl.Row(
+			[]string{
+				g.Session.ShortID, output.Truncate(projectLabel(g.Session), tbl.ColWidth(1)), output.FormatAge(g.Session.Modified),
+				output.Dim(output.Truncate(sessionTitle(g.Session), tbl.LastColWidth())),
+			},
+			[]func(string) string{output.Dim, output.Bold, output.Dim, nil},
+		)
+	}
+	for _, a := range g.Agents {
+		label := a.Session.AgentType
+		if a.Session.AgentDescription != "" {
+			label = strings.TrimPrefix(label+" — "+a.Session.AgentDescription, " — ")
+		}
+		if label == "" {
+			label = "(agent)"
+		}
+		for i, m := range a.Matches {
+			if i > 0 {
+				tbl.Row([]string{matchRef(a.Session, m), "", "", formatMatchRole(m)}, []func(string) string{output.Dim, nil, nil, nil})
+				continue
+			}
+			tbl.Row(
+				[]string{matchRef(a.Session, m), output.Truncate("↳ "+label, tbl.ColWidth(1)), output.FormatAge(a.Session.Modified), formatMatchRole(m)},
+				[]func(string) string{output.Dim, output.Dim, output.Dim, nil},
+			)
 		}
 	}
 }
@@ -0,0 +1,267 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/paths"
+	"github.com/andyhtran/cct/internal/session"
+)
+
+type SnapshotsCmd struct {
+	List    SnapshotsListCmd    `cmd:"" default:"withargs" help:"List a session's checkpoints and the files they cover (default when no subcommand)"`
+	Restore SnapshotsRestoreCmd `cmd:"" help:"Write the files saved at a checkpoint into a directory"`
+}
+
+type SnapshotsListCmd struct {
+	ID string `arg:"" help:"Session ID or prefix"`
+}
+
+type SnapshotsRestoreCmd struct {
+	ID         string `arg:"" help:"Session ID or prefix"`
+	Checkpoint string `arg:"" help:"Checkpoint number (from cct snapshots) or message ID prefix"`
+	To         string `required:"" help:"Directory to write the files into; must be empty or not exist, and outside the project"`
+}
+
+type snapshotsOutput struct {
+	Session     *session.Session      `json:"session"`
+	Checkpoints []*session.Checkpoint `json:"checkpoints"`
+}
+
+type restoredFile struct {
+	Path string `json:"path"`
+	Dest string `json:"dest"`
+}
+
+type restoreSkip struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+type restoreOutput struct {
+	Session    *session.Session    `json:"session"`
+	Checkpoint *session.Checkpoint `json:"checkpoint"`
+	Dir        string              `json:"dir"`
+	Restored   []restoredFile      `json:"restored"`
+	Skipped    []restoreSkip       `json:"skipped,omitempty"`
+}
+
+func (cmd *SnapshotsListCmd) Run(globals *Globals) error {
+	match, err := findSession(globals, cmd.ID)
+	if err != nil {
+		return err
+	}
+	checkpoints, err := session.ReadCheckpoints(match.FilePath)
+	if err != nil {
+		return fmt.Errorf("read session: %w", err)
+	}
+
+	if globals.JSON {
+		if checkpoints == nil {
+			checkpoints = []*session.Checkpoint{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(snapshotsOutput{Session: match, Checkpoints: checkpoints})
+	}
+
+	if len(checkpoints) == 0 {
+		fmt.Println("No checkpoints recorded in this session.")
+		return nil
+	}
+	dir := fileHistoryDir(match)
+	fmt.Printf("\n  %s %s %s\n\n", output.Bold(fmt.Sprintf("%d %s", len(checkpoints), plural(len(checkpoints), "checkpoint", "checkpoints"))),
+		output.Dim("in"), sessionTitle(match))
+	for i, cp := range checkpoints {
+		at := "unknown time    "
+		if !cp.At.IsZero() {
+			at = cp.At.Local().Format("2006-01-02 15:04")
+		}
+		where := ""
+		if cp.Message > 0 {
+			where = output.Dim(fmt.Sprintf(" #%d", cp.Message))
+		}
+		prompt := cp.Prompt
+		if prompt == "" {
+			prompt = output.Dim("[no prompt]")
+		}
+		fmt.Printf("  %s  %s%s  %s\n", output.Bold(fmt.Sprintf("%3d", i+1)), output.Dim(at), where, output.Truncate(prompt, 60))
+		for _, sf := range cp.Files {
+			note := ""
+			switch {
+			case sf.BackupFile == "":
+				note = " (not created yet)"
+			case !fileExists(filepath.Join(dir, sf.BackupFile)):
+				note = " (backup missing)"
+			case sf.Version > 0:
+				note = fmt.Sprintf(" v%d", sf.Version)
+			}
+			fmt.Printf("         %s%s\n", displayPath(match.ProjectPath, sf.Path), output.Dim(note))
+		}
+	}
+	fmt.Println()
+	fmt.Printf("  %s\n\n", output.Cyan(fmt.Sprintf("cct snapshots restore %s <n> --to <dir>", match.ShortID)))
+	return nil
+}
+
+func (cmd *SnapshotsRestoreCmd) Run(globals *Globals) error {
+	match, err := findSession(globals, cmd.ID)
+	if err != nil {
+		return err
+	}
+	checkpoints, err := session.ReadCheckpoints(match.FilePath)
+	if err != nil {
+		return fmt.Errorf("read session: %w", err)
+	}
+	cp, err := pickCheckpoint(checkpoints, cmd.Checkpoint)
+	if err != nil {
+		return err
+	}
+
+	to, err := filepath.Abs(cmd.To)
+	if err != nil {
+		return err
+	}
+	if match.ProjectPath != "" {
+		if _, inside := projectRelative(match.ProjectPath, to); inside {
+			return fmt.Errorf("--to %s is inside the project %s; restore into a separate directory", to, match.ProjectPath)
+		}
+	}
+	if entries, err := os.ReadDir(to); err == nil && len(entries) > 0 {
+		return fmt.Errorf("--to %s is not empty", to)
+	}
+
+	res := restoreOutput{Session: match, Checkpoint: cp, Dir: to, Restored: []restoredFile{}}
+	src := fileHistoryDir(match)
+	for _, sf := range cp.Files {
+		if sf.BackupFile == "" {
+			res.Skipped = append(res.Skipped, restoreSkip{Path: sf.Path, Reason: "did not exist at this checkpoint"})
+			continue
+		}
+		dest := filepath.Join(to, filepath.FromSlash(restoreName(match.ProjectPath, sf.Path)))
+		if _, ok := projectRelative(to, dest); !ok {
+			res.Skipped = append(res.Skipped, restoreSkip{Path: sf.Path, Reason: "path escapes the restore directory"})
+			continue
+		}
+		if err := copyFile(filepath.Join(src, sf.BackupFile), dest); err != nil {
+			reason := err.Error()
+			if errors.Is(err, os.ErrNotExist) {
+				reason = "backup missing from " + src
+			}
+			res.Skipped = append(res.Skipped, restoreSkip{Path: sf.Path, Reason: reason})
+			continue
+		}
+		res.Restored = append(res.Restored, restoredFile{Path: sf.Path, Dest: dest})
+	}
+
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+
+	fmt.Printf("Restored %d %s from checkpoint %s to %s\n", len(res.Restored), plural(len(res.Restored), "file", "files"),
+		cmd.Checkpoint, to)
+	for _, f := range res.Restored {
+		fmt.Printf("  %s\n", displayPath(match.ProjectPath, f.Path))
+	}
+	for _, s := range res.Skipped {
+		fmt.Printf("  %s %s: %s\n", output.Dim("skipped"), displayPath(match.ProjectPath, s.Path), s.Reason)
+	}
+	if len(res.Restored) == 0 && len(res.Skipped) > 0 {
+		return errors.New("no files restored")
+	}
+	return nil
+}
+
+// pickCheckpoint resolves a 1-based checkpoint number or a message ID prefix.
+func pickCheckpoint(checkpoints []*session.Checkpoint, ref string) (*session.Checkpoint, error) {
+	if len(checkpoints) == 0 {
+		return nil, errors.New("no checkpoints recorded in this session")
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(checkpoints) {
+			return nil, fmt.Errorf("checkpoint %d out of range (1-%d)", n, len(checkpoints))
+		}
+		return checkpoints[n-1], nil
+	}
+	var found *session.Checkpoint
+	for _, cp := range checkpoints {
+		if strings.HasPrefix(cp.MessageID, ref) {
+			if found != nil {
+				return nil, fmt.Errorf("checkpoint %q is ambiguous", ref)
+			}
+			found = cp
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no checkpoint %q", ref)
+	}
+	return found, nil
+}
+
+// fileHistoryDir is where Claude Code keeps a session's checkpoint backups.
+func fileHistoryDir(s *session.Session) string {
+	root := paths.Root{Dir: paths.ClaudeDir()}
+	if r, ok := paths.LookupRoot(s.Root); ok {
+		root = r
+	}
+	return filepath.Join(root.FileHistoryDir(), s.ID)
+}
+
+// restoreName is where a tracked file lands under the restore directory:
+// its project-relative path, or for files outside the project, the
+// absolute path without its leading separator. Relative paths in the
+// snapshot are taken as relative to the project.
+func restoreName(project, path string) string {
+	if !filepath.IsAbs(path) && project != "" {
+		path = filepath.Join(project, path)
+	}
+	if rel, ok := projectRelative(project, path); ok {
+		return rel
+	}
+	return strings.TrimLeft(filepath.ToSlash(filepath.Clean(path)), "/")
+}
+
+func displayPath(project, path string) string {
+	if rel, ok := projectRelative(project, path); ok {
+		return rel
+	}
+	return path
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,85 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const snapID = "snap1111-0000-0000-0000-000000000000"
+
+func writeSnapshotFixture(t *testing.T, home string) {
+	t.Helper()
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-snap")
+	backups := filepath.Join(home, ".claude", "file-history", snapID)
+	for _, d := range []string{projDir, backups} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(backups, "aaa@v1"), []byte("package main // before\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, filepath.Join(projDir, snapID+".jsonl"), []string{
+		`{"type":"file-history-snapshot","messageId":"m1","snapshot":{"messageId":"m1","trackedFileBackups":{},"timestamp":"2026-03-01T10:00:00Z"},"isSnapshotUpdate":false}`,
+		`{"type":"user","uuid":"m1","cwd":"/Users/test/snap","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"rewrite main"}}`,
+		`{"type":"assistant","uuid":"a1","timestamp":"2026-03-01T10:00:05Z","message":{"role":"assistant","content":"Rewritten."}}`,
+		`{"type":"file-history-snapshot","messageId":"m2","snapshot":{"messageId":"m2","trackedFileBackups":{"/Users/test/snap/cmd/main.go":{"backupFileName":"aaa@v1","version":1,"backupTime":"2026-03-01T10:01:00Z"},"/Users/test/snap/new.go":{"backupFileName":null,"version":1,"backupTime":"2026-03-01T10:01:00Z"}},"timestamp":"2026-03-01T10:01:00Z"},"isSnapshotUpdate":false}`,
+		`{"type":"user","uuid":"m2","timestamp":"2026-03-01T10:01:00Z","message":{"role":"user","content":"undo that"}}`,
+	})
+}
+
+func TestSnapshotsListCmd(t *testing.T) {
+	home := setupFixtures(t)
+	writeSnapshotFixture(t, home)
+
+	out := captureStdout(t, func() {
+		cmd := &SnapshotsListCmd{ID: "snap1111"}
+		if err := cmd.Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	for _, want := range []string{"2 checkpoints", "undo that", "cmd/main.go", "new.go (not created yet)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSnapshotsRestoreCmd(t *testing.T) {
+	home := setupFixtures(t)
+	writeSnapshotFixture(t, home)
+	to := filepath.Join(t.TempDir(), "restored")
+
+	out := captureStdout(t, func() {
+		cmd := &SnapshotsRestoreCmd{ID: "snap1111", Checkpoint: "2", To: to}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var res restoreOutput
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(res.Restored) != 1 || len(res.Skipped) != 1 || res.Skipped[0].Path != "/Users/test/snap/new.go" {
+		t.Errorf("restored %+v, skipped %+v", res.Restored, res.Skipped)
+	}
+	data, err := os.ReadFile(filepath.Join(to, "cmd", "main.go"))
+	if err != nil || string(data) != "package main // before\n" {
+		t.Errorf("restored main.go = %q, %v", data, err)
+	}
+
+	// The target must be empty, so a second restore can't clobber the first.
+	cmd := &SnapshotsRestoreCmd{ID: "snap1111", Checkpoint: "m2", To: to}
+	if err := cmd.Run(&Globals{}); err == nil || !strings.Contains(err.Error(), "not empty") {
+		t.Errorf("err = %v, want not empty", err)
+	}
+	cmd = &SnapshotsRestoreCmd{ID: "snap1111", Checkpoint: "2", To: "/Users/test/snap/out"}
+	if err := cmd.Run(&Globals{}); err == nil || !strings.Contains(err.Error(), "inside the project") {
+		t.Errorf("err = %v, want inside the project", err)
+	}
+}
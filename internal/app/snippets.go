@@ -0,0 +1,263 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andyhtran/cct/internal/index"
+	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/session"
+)
+
+type SnippetsCmd struct {
+	List SnippetsListCmd `cmd:"" default:"withargs" help:"Search code blocks from assistant replies, newest first (default when no subcommand)"`
+	Show SnippetsShowCmd `cmd:"" help:"Print a snippet's code raw, or write it to a file"`
+}
+
+type SnippetsListCmd struct {
+	Query    string `arg:"" optional:"" help:"Only snippets whose code contains every word of this query"`
+	Lang     string `help:"Only snippets fenced with this language (aliases like golang, py, sh match)"`
+	Def      string `help:"Only snippets that define this function, type, or class"`
+	MinLines int    `help:"Only snippets with at least this many lines" default:"1"`
+	Project  string `short:"p" help:"Filter by project name"`
+	Since    string `help:"Only snippets written at or after this time (today, yesterday, 7d, 2026-01-31)"`
+	Until    string `help:"Only snippets written before this time (same forms as --since)"`
+	Limit    int    `short:"n" help:"Max snippets (0=no limit)" default:"25"`
+	Agents   bool   `help:"Include snippets from sub-agent sessions"`
+}
+
+type SnippetsShowCmd struct {
+	Ref    string `arg:"" help:"Snippet ID from cct snippets (<session>:<n>)"`
+	Output string `short:"o" help:"Write the code to this file instead of stdout"`
+	Force  bool   `short:"f" help:"Overwrite an existing file"`
+}
+
+// snippetEntry is a snippet as listed. Preview is the line that matched
+// the query or definition, or the first line.
+type snippetEntry struct {
+	ID        string    `json:"id"`
+	SessionID string    `json:"session_id"`
+	ShortID   string    `json:"short_id"`
+	Project   string    `json:"project"`
+	Lang      string    `json:"lang"`
+	Lines     int       `json:"lines"`
+	Symbols   []string  `json:"symbols,omitempty"`
+	At        time.Time `json:"at,omitzero"`
+	Offset    int64     `json:"offset"`
+	Preview   string    `json:"preview"`
+}
+
+// snippetID is how list and show name a snippet: the session's short ID
+// and the snippet's number within it.
+func snippetID(sessionID string, seq int) string {
+	return fmt.Sprintf("%s:%d", session.ShortID(sessionID), seq)
+}
+
+func (cmd *SnippetsListCmd) Run(globals *Globals) error {
+	w, err := parseTimeWindow(cmd.Since, cmd.Until, time.Now())
+	if err != nil {
+		return err
+	}
+	idx, err := index.Open()
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+	defer func() { _ = idx.Close() }()
+
+	rows, err := idx.Snippets(index.EventFilter{
+		Root:          globals.Root,
+		ProjectFilter: cmd.Project,
+		IncludeAgents: cmd.Agents,
+		Since:         w.Since,
+		Until:         w.Until,
+	}, session.NormalizeLang(cmd.Lang))
+	if err != nil {
+		return fmt.Errorf("snippets: %w", err)
+	}
+	entries := filterSnippets(rows, cmd.Query, cmd.Def, cmd.MinLines)
+	if cmd.Limit > 0 && len(entries) > cmd.Limit {
+		entries = entries[:cmd.Limit]
+	}
+
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+	if len(entries) == 0 {
+		fmt.Println("No matching snippets.")
+		return nil
+	}
+	tbl := output.NewTable(cmd.Query,
+		output.Fixed("ID", 12),
+		output.Fixed("AGE", 6),
+		output.Fixed("LANG", 10),
+		output.Fixed("LINES", 5),
+		output.Flex("CODE", 0, 30),
+	)
+	fmt.Println()
+	tbl.PrintHeader()
+	for _, e := range entries {
+		lang := e.Lang
+		if lang == "" {
+			lang = "-"
+		}
+		tbl.Row(
+			[]string{
+				e.ID,
+				output.FormatAge(e.At),
+				lang,
+				formatInt(e.Lines),
+				output.Truncate(e.Preview, tbl.LastColWidth()),
+			},
+			[]func(string) string{output.Bold, output.Dim, nil, output.Dim, output.Dim},
+		)
+	}
+	fmt.Println()
+	fmt.Printf("  %s\n\n", output.Cyan("cct snippets show <id> · cct snippets show <id> -o <file>"))
+	return nil
+}
+
+// filterSnippets keeps the rows (oldest first) whose code contains every
+// word of query, that define def, and that have at least minLines lines,
+// newest first.
+func filterSnippets(rows []index.SnippetRow, query, def string, minLines int) []*snippetEntry {
+	words := strings.Fields(strings.ToLower(query))
+	var out []*snippetEntry
+	for _, r := range rows {
+		sn := r.Snippet
+		if sn.Lines < minLines {
+			continue
+		}
+		if def != "" && !containsFold(sn.Symbols, def) {
+			continue
+		}
+		lower := strings.ToLower(sn.Code)
+		matched := true
+		for _, w := range words {
+			if !strings.Contains(lower, w) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		out = append(out, &snippetEntry{
+			ID:        snippetID(r.SessionID, sn.Seq),
+			SessionID: r.SessionID,
+			ShortID:   session.ShortID(r.SessionID),
+			Project:   r.Project,
+			Lang:      sn.Lang,
+			Lines:     sn.Lines,
+			Symbols:   sn.Symbols,
+			At:        sn.At,
+			Offset:    sn.Offset,
+			Preview:   snippetPreview(sn.Code, words, def),
+		})
+	}
+	slices.Reverse(out)
+	return out
+}
+
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// snippetPreview picks the line to show for a snippet: the first naming
+// def, else the first containing a query word, else the first non-blank.
+func snippetPreview(code string, words []string, def string) string {
+	lines := strings.Split(code, "\n")
+	pick := func(match func(string) bool) string {
+		for _, line := range lines {
+			if match(line) {
+				return strings.Join(strings.Fields(line), " ")
+			}
+		}
+		return ""
+	}
+	if def != "" {
+		if line := pick(func(l string) bool { return strings.Contains(l, def) }); line != "" {
+			return line
+		}
+	}
+	for _, w := range words {
+		if line := pick(func(l string) bool { return strings.Contains(strings.ToLower(l), w) }); line != "" {
+			return line
+		}
+	}
+	return pick(func(l string) bool { return strings.TrimSpace(l) != "" })
+}
+
+func (cmd *SnippetsShowCmd) Run(globals *Globals) error {
+	prefix, n, ok := strings.Cut(cmd.Ref, ":")
+	seq, err := strconv.Atoi(n)
+	if !ok || err != nil || seq < 1 {
+		return fmt.Errorf("invalid snippet ID %q (want <session>:<n>, as listed by cct snippets)", cmd.Ref)
+	}
+	s, err := findSession(globals, prefix)
+	if err != nil {
+		return err
+	}
+	idx, err := index.Open()
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+	defer func() { _ = idx.Close() }()
+
+	rows, err := idx.SessionSnippets(s.Root, s.ID)
+	if err != nil {
+		return fmt.Errorf("snippets: %w", err)
+	}
+	if seq > len(rows) {
+		return fmt.Errorf("session %s has %d %s", session.ShortID(s.ID), len(rows), plural(len(rows), "snippet", "snippets"))
+	}
+	r := rows[seq-1]
+	code := r.Snippet.Code + "\n"
+
+	if cmd.Output != "" {
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if !cmd.Force {
+			flags |= os.O_EXCL
+		}
+		f, err := os.OpenFile(cmd.Output, flags, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("%s exists (use --force to overwrite)", cmd.Output)
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := f.WriteString(code); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d %s to %s.\n", r.Snippet.Lines, plural(r.Snippet.Lines, "line", "lines"), cmd.Output)
+		return nil
+	}
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			ID        string `json:"id"`
+			SessionID string `json:"session_id"`
+			Project   string `json:"project"`
+			session.Snippet
+		}{snippetID(r.SessionID, r.Snippet.Seq), r.SessionID, r.Project, r.Snippet})
+	}
+	fmt.Print(code)
+	return nil
+}
@@ -0,0 +1,92 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnippetsCmd(t *testing.T) {
+	home := setupFixtures(t)
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-snip")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	reply := `{"type":"assistant","timestamp":"2026-03-01T10:00:05Z","message":{"role":"assistant","content":[{"type":"text","text":"Here:\n` +
+		"```golang\\nfunc Retry(n int) error {\\n\\treturn nil\\n}\\n```\\nRun it with\\n```sh\\ngo test ./...\\n```\\nand again after each change\\n```sh\\ngo test ./...\\n```" + `"}]}}`
+	original := filepath.Join(projDir, "snip3333-0000-0000-0000-000000000000.jsonl")
+	writeLines(t, original, []string{
+		`{"type":"user","cwd":"/Users/test/snip","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"write a retry helper"}}`,
+		reply,
+	})
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(original, old, old); err != nil {
+		t.Fatal(err)
+	}
+	// A resumed session replays the reply before adding its own; the
+	// replayed blocks stay credited to the original.
+	writeLines(t, filepath.Join(projDir, "snip2222-0000-0000-0000-000000000000.jsonl"), []string{
+		reply,
+		`{"type":"assistant","timestamp":"2026-03-02T10:00:00Z","message":{"role":"assistant","content":[{"type":"text","text":"` + "```python\\ndef retry():\\n    pass\\n```" + `"}]}}`,
+	})
+
+	list := func(cmd *SnippetsListCmd) []snippetEntry {
+		out := captureStdout(t, func() {
+			if err := cmd.Run(&Globals{JSON: true}); err != nil {
+				t.Fatal(err)
+			}
+		})
+		var entries []snippetEntry
+		if err := json.Unmarshal([]byte(out), &entries); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		return entries
+	}
+
+	all := list(&SnippetsListCmd{Project: "snip", MinLines: 1, Limit: 25})
+	var ids []string
+	for _, e := range all {
+		ids = append(ids, e.ID)
+	}
+	if strings.Join(ids, " ") != "snip2222:4 snip3333:3 snip3333:2 snip3333:1" {
+		t.Errorf("ids = %v", ids)
+	}
+
+	got := list(&SnippetsListCmd{Query: "RETRY", Lang: "go", MinLines: 1, Limit: 25})
+	if len(got) != 1 || got[0].ID != "snip3333:1" || got[0].Preview != "func Retry(n int) error {" {
+		t.Errorf("--lang go = %+v", got)
+	}
+	got = list(&SnippetsListCmd{Def: "retry", MinLines: 3, Limit: 25})
+	if len(got) != 1 || got[0].Lang != "go" {
+		t.Errorf("--def retry --min-lines 3 = %+v", got)
+	}
+
+	out := captureStdout(t, func() {
+		if err := (&SnippetsShowCmd{Ref: "snip3333:2"}).Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if out != "go test ./...\n" {
+		t.Errorf("show = %q", out)
+	}
+
+	file := filepath.Join(t.TempDir(), "retry.go")
+	if err := (&SnippetsShowCmd{Ref: "snip3333:1", Output: file}).Run(&Globals{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil || string(data) != "func Retry(n int) error {\n\treturn nil\n}\n" {
+		t.Errorf("written file = %q (%v)", data, err)
+	}
+	if err := (&SnippetsShowCmd{Ref: "snip3333:1", Output: file}).Run(&Globals{}); err == nil {
+		t.Error("expected an error overwriting without --force")
+	}
+	if err := (&SnippetsShowCmd{Ref: "snip3333:9"}).Run(&Globals{}); err == nil {
+		t.Error("expected an error for a snippet past the end")
+	}
+}
@@ -12,6 +12,13 @@ import (
 )
 
 type StatsCmd struct {
+	Summary  StatsSummaryCmd  `cmd:"" default:"withargs" help:"Session counts by project and agent type (default when no subcommand)"`
+	Tools    StatsToolsCmd    `cmd:"" help:"Tool call counts, error rates, output sizes, and latency"`
+	Activity StatsActivityCmd `cmd:"" help:"Calendar heatmap and weekday/hour histograms of message activity"`
+	Time     StatsTimeCmd     `cmd:"" help:"Active time per project and per week, from gaps between messages"`
+}
+
+type StatsSummaryCmd struct {
 	Agents bool `help:"Include sub-agent sessions"`
 }
 
@@ -36,8 +43,8 @@ type agentStat struct {
 	Count int    `json:"count"`
 }
 
-func (cmd *StatsCmd) Run(globals *Globals) error {
-	files := session.DiscoverFilesWithBackups("", cmd.Agents)
+func (cmd *StatsSummaryCmd) Run(globals *Globals) error {
+	files := session.DiscoverFilesWithBackups(globals.Root, "", cmd.Agents)
 	if !globals.JSON && len(files) > 50 {
 		fmt.Fprintf(os.Stderr, "Scanning %d sessions...\n", len(files))
 	}
@@ -0,0 +1,456 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/andyhtran/cct/internal/index"
+	"github.com/andyhtran/cct/internal/output"
+)
+
+type StatsActivityCmd struct {
+	Project string `short:"p" help:"Filter by project name"`
+	Since   string `help:"Start of the window (today, yesterday, 7d, 12w, 2026-01-31, or 'all')" default:"26w"`
+	Until   string `help:"End of the window, exclusive (same forms as --since)"`
+	Limit   int    `short:"n" help:"Max projects to list (0=no limit)" default:"10"`
+	Agents  bool   `help:"Include messages from sub-agent sessions"`
+}
+
+type activityDay struct {
+	Date     string `json:"date"`
+	Messages int    `json:"messages"`
+	Sessions int    `json:"sessions"`
+}
+
+type activityBucket struct {
+	Label    string `json:"label"`
+	Messages int    `json:"messages"`
+}
+
+type activityProject struct {
+	Name       string        `json:"name"`
+	Messages   int           `json:"messages"`
+	Sessions   int           `json:"sessions"`
+	ActiveDays int           `json:"active_days"`
+	Days       []activityDay `json:"days"`
+}
+
+// activityData is the stats activity report. Days is a dense series, one
+// entry per local calendar day from Start to End inclusive, so dashboards
+// can plot it without filling gaps; per-project Days lists active days only.
+type activityData struct {
+	Window     string             `json:"window"`
+	Start      string             `json:"start"`
+	End        string             `json:"end"`
+	Messages   int                `json:"total_messages"`
+	Sessions   int                `json:"sessions"`
+	ActiveDays int                `json:"active_days"`
+	Days       []activityDay      `json:"days"`
+	ByWeekday  []activityBucket   `json:"by_weekday"`
+	ByHour     []activityBucket   `json:"by_hour"`
+	Projects   []*activityProject `json:"projects"`
+}
+
+func (cmd *StatsActivityCmd) Run(globals *Globals) error {
+	now := time.Now()
+	window, err := parseTimeWindow(cmd.Since, cmd.Until, now)
+	if err != nil {
+		return err
+	}
+
+	idx, err := index.Open()
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+	defer func() { _ = idx.Close() }()
+
+	times, err := idx.MessageTimes(index.EventFilter{
+		Root:          globals.Root,
+		ProjectFilter: cmd.Project,
+		IncludeAgents: cmd.Agents,
+		Since:         window.Since,
+		Until:         window.Until,
+	})
+	if err != nil {
+		return fmt.Errorf("message times: %w", err)
+	}
+
+	start, end := activitySpan(window, times, now)
+	data := buildActivity(times, start, end)
+	data.Window = describeWindow(cmd.Since, cmd.Until)
+	if cmd.Limit > 0 && len(data.Projects) > cmd.Limit {
+		data.Projects = data.Projects[:cmd.Limit]
+	}
+
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	}
+
+	if data.Messages == 0 {
+		fmt.Println("  No activity found.")
+		return nil
+	}
+	printActivity(data, output.TerminalWidth())
+	return nil
+}
+
+// activitySpan returns the first and last local calendar days to report.
+// Open bounds fall back to the first message and today.
+func activitySpan(w timeWindow, times []index.MessageTime, now time.Time) (start, end time.Time) {
+	end = now
+	if !w.Until.IsZero() {
+		end = w.Until.Add(-time.Nanosecond)
+	}
+	start = w.Since
+	if start.IsZero() {
+		start = end
+		for _, m := range times {
+			if m.At.Before(start) {
+				start = m.At
+			}
+		}
+	}
+	return localDay(start), localDay(end)
+}
+
+func localDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+const dayFormat = "2006-01-02"
+
+// buildActivity buckets message timestamps by local day, weekday, hour,
+// and project. start and end are local midnights; end is inclusive.
+func buildActivity(times []index.MessageTime, start, end time.Time) *activityData {
+	data := &activityData{
+		Start: start.Format(dayFormat),
+		End:   end.Format(dayFormat),
+	}
+
+	type dayStat struct {
+		messages int
+		sessions map[string]bool
+	}
+	days := map[string]*dayStat{}
+	sessions := map[string]bool{}
+	projects := map[string]*activityProject{}
+	projectDays := map[string]map[string]int{}
+	projectSessions := map[string]map[string]bool{}
+	var weekdays [7]int
+	var hours [24]int
+
+	for _, m := range times {
+		at := m.At.Local()
+		day := at.Format(dayFormat)
+		key := m.Root + "/" + m.SessionID
+
+		data.Messages++
+		sessions[key] = true
+		weekdays[at.Weekday()]++
+		hours[at.Hour()]++
+
+		d, ok := days[day]
+		if !ok {
+			d = &dayStat{sessions: map[string]bool{}}
+			days[day] = d
+		}
+		d.messages++
+		d.sessions[key] = true
+
+		name := m.Project
+		if name == "" {
+			name = "(unknown)"
+		}
+		p, ok := projects[name]
+		if !ok {
+			p = &activityProject{Name: name}
+			projects[name] = p
+			projectDays[name] = map[string]int{}
+			projectSessions[name] = map[string]bool{}
+		}
+		p.Messages++
+		projectDays[name][day]++
+		projectSessions[name][key] = true
+	}
+	data.Sessions = len(sessions)
+
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dayFormat)
+		entry := activityDay{Date: date}
+		if d, ok := days[date]; ok {
+			entry.Messages = d.messages
+			entry.Sessions = len(d.sessions)
+			data.ActiveDays++
+		}
+		data.Days = append(data.Days, entry)
+	}
+
+	// Monday-first reads naturally for a work week; time.Weekday is Sunday=0.
+	for i := range 7 {
+		wd := time.Weekday((i + 1) % 7)
+		data.ByWeekday = append(data.ByWeekday, activityBucket{Label: wd.String()[:3], Messages: weekdays[wd]})
+	}
+	for h, n := range hours {
+		data.ByHour = append(data.ByHour, activityBucket{Label: fmt.Sprintf("%02d", h), Messages: n})
+	}
+
+	for name, p := range projects {
+		p.Sessions = len(projectSessions[name])
+		p.ActiveDays = len(projectDays[name])
+		for date, n := range projectDays[name] {
+			p.Days = append(p.Days, activityDay{Date: date, Messages: n})
+		}
+		sort.Slice(p.Days, func(i, j int) bool { return p.Days[i].Date < p.Days[j].Date })
+		data.Projects = append(data.Projects, p)
+	}
+	sort.Slice(data.Projects, func(i, j int) bool {
+		a, b := data.Projects[i], data.Projects[j]
+		if a.Messages != b.Messages {
+			return a.Messages > b.Messages
+		}
+		return a.Name < b.Name
+	})
+	return data
+}
+
+// heatLevels pairs a glyph with a colour per intensity level, GitHub-style.
+// The glyphs differ too, so the heatmap still reads without colour.
+var heatLevels = []struct {
+	glyph string
+	style lipgloss.Style
+}{
+	{"·", lipgloss.NewStyle().Foreground(lipgloss.Color("8"))},
+	{"░", lipgloss.NewStyle().Foreground(lipgloss.Color("22"))},
+	{"▒", lipgloss.NewStyle().Foreground(lipgloss.Color("28"))},
+	{"▓", lipgloss.NewStyle().Foreground(lipgloss.Color("34"))},
+	{"█", lipgloss.NewStyle().Foreground(lipgloss.Color("40"))},
+}
+
+var barStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("34"))
+
+// heatLevel maps n to 0..4 relative to the busiest day.
+func heatLevel(n, peak int) int {
+	if n == 0 || peak == 0 {
+		return 0
+	}
+	level := (4*n + peak - 1) / peak
+	return min(max(level, 1), 4)
+}
+
+func printActivity(data *activityData, width int) {
+	fmt.Println()
+	fmt.Printf("  %s  %s  %s\n", output.Pad("Messages:", 12, output.Dim), formatInt(data.Messages),
+		output.Dim(fmt.Sprintf("(%d sessions, %s)", data.Sessions, data.Window)))
+	fmt.Printf("  %s  %s\n", output.Pad("Active days:", 12, output.Dim),
+		fmt.Sprintf("%d of %d", data.ActiveDays, len(data.Days)))
+
+	fmt.Println()
+	for _, line := range renderHeatmap(data.Days, width) {
+		fmt.Println(line)
+	}
+
+	fmt.Println()
+	fmt.Println("  " + output.Bold("By Weekday"))
+	for _, line := range renderBars(data.ByWeekday, 40) {
+		fmt.Println(line)
+	}
+
+	fmt.Println()
+	fmt.Println("  " + output.Bold("By Hour"))
+	for _, line := range renderColumns(data.ByHour, 6) {
+		fmt.Println(line)
+	}
+
+	if len(data.Projects) > 0 {
+		fmt.Println()
+		fmt.Println("  " + output.Bold("Projects"))
+		weeks := weeklyTotals(data.Days, data.Projects)
+		sparkWidth := max(width-62, 8)
+		for i, p := range data.Projects {
+			spark := weeks[i]
+			if len(spark) > sparkWidth {
+				spark = spark[len(spark)-sparkWidth:]
+			}
+			fmt.Printf("    %s  %s  %s\n",
+				output.Pad(output.Truncate(p.Name, 24), 24, output.Bold),
+				output.Dim(fmt.Sprintf("%-30s", fmt.Sprintf("%s msgs, %d sessions, %d days",
+					formatInt(p.Messages), p.Sessions, p.ActiveDays))),
+				barStyle.Render(sparkline(spark)))
+		}
+	}
+	fmt.Println()
+}
+
+// renderHeatmap lays days out as a calendar: one column per week (Sunday
+// first), one row per weekday, month names above. Older weeks are dropped
+// when the calendar is wider than the terminal.
+func renderHeatmap(days []activityDay, width int) []string {
+	if len(days) == 0 {
+		return nil
+	}
+	first, _ := time.ParseInLocation(dayFormat, days[0].Date, time.Local)
+	lead := int(first.Weekday())
+	weeks := (lead + len(days) + 6) / 7
+
+	const labelWidth = 6 // "  Mon "
+	maxWeeks := max((width-labelWidth)/2, 4)
+	skip := 0
+	if weeks > maxWeeks {
+		skip = weeks - maxWeeks
+		weeks = maxWeeks
+	}
+
+	peak := 0
+	for _, d := range days {
+		peak = max(peak, d.Messages)
+	}
+
+	grid := make([][]string, 7)
+	for row := range grid {
+		grid[row] = make([]string, weeks)
+		for col := range grid[row] {
+			grid[row][col] = " "
+		}
+	}
+	months := make([]byte, weeks*2)
+	for i := range months {
+		months[i] = ' '
+	}
+	lastMonth, labelEnd := time.Month(0), 0
+	for i, d := range days {
+		col := (lead+i)/7 - skip
+		if col < 0 {
+			continue
+		}
+		row := (lead + i) % 7
+		level := heatLevels[heatLevel(d.Messages, peak)]
+		grid[row][col] = level.style.Render(level.glyph)
+
+		// Label each month at the first column it appears in, unless the
+		// previous label would be overwritten.
+		if date := first.AddDate(0, 0, i); date.Month() != lastMonth {
+			lastMonth = date.Month()
+			name := date.Format("Jan")
+			if col*2 >= labelEnd && col*2+len(name) <= len(months) {
+				copy(months[col*2:], name)
+				labelEnd = col*2 + len(name) + 1
+			}
+		}
+	}
+
+	lines := []string{strings.Repeat(" ", labelWidth) + output.Dim(strings.TrimRight(string(months), " "))}
+	labels := []string{"", "Mon", "", "Wed", "", "Fri", ""}
+	for row, cells := range grid {
+		lines = append(lines, "  "+output.Dim(fmt.Sprintf("%-3s", labels[row]))+" "+strings.Join(cells, " "))
+	}
+
+	legend := "  " + strings.Repeat(" ", labelWidth-2) + output.Dim("less ")
+	for _, l := range heatLevels {
+		legend += l.style.Render(l.glyph) + " "
+	}
+	lines = append(lines, "", legend+output.Dim("more"))
+	if skip > 0 {
+		lines = append(lines, output.Dim(fmt.Sprintf("  (showing the last %d weeks; widen the terminal or narrow --since for more)", weeks)))
+	}
+	return lines
+}
+
+// renderBars draws one horizontal bar per bucket, scaled to barWidth.
+func renderBars(buckets []activityBucket, barWidth int) []string {
+	peak := 0
+	for _, b := range buckets {
+		peak = max(peak, b.Messages)
+	}
+	var lines []string
+	for _, b := range buckets {
+		n := 0
+		if peak > 0 {
+			n = (b.Messages*barWidth + peak - 1) / peak
+		}
+		lines = append(lines, fmt.Sprintf("    %s  %s %s",
+			output.Dim(fmt.Sprintf("%-3s", b.Label)),
+			barStyle.Render(strings.Repeat("█", n)),
+			output.Dim(formatInt(b.Messages))))
+	}
+	return lines
+}
+
+var eighths = []string{" ", "▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+
+// renderColumns draws a vertical bar chart, height rows tall, with
+// eighth-block tops and an axis labelled every six buckets.
+func renderColumns(buckets []activityBucket, height int) []string {
+	peak := 0
+	for _, b := range buckets {
+		peak = max(peak, b.Messages)
+	}
+	var lines []string
+	for row := height; row >= 1; row-- {
+		var sb strings.Builder
+		for _, b := range buckets {
+			fill := 0
+			if peak > 0 {
+				fill = b.Messages * height * 8 / peak
+			}
+			cell := fill - (row-1)*8
+			sb.WriteString(eighths[min(max(cell, 0), 8)])
+			sb.WriteString(" ")
+		}
+		lines = append(lines, "    "+barStyle.Render(strings.TrimRight(sb.String(), " ")))
+	}
+	axis := make([]byte, len(buckets)*2)
+	for i := range axis {
+		axis[i] = ' '
+	}
+	for i := 0; i < len(buckets); i += 6 {
+		copy(axis[i*2:], buckets[i].Label)
+	}
+	lines = append(lines, "    "+output.Dim(strings.TrimRight(string(axis), " ")))
+	return lines
+}
+
+// weeklyTotals sums each project's messages into 7-day buckets aligned to
+// the end of days, oldest first.
+func weeklyTotals(days []activityDay, projects []*activityProject) [][]int {
+	weeks := (len(days) + 6) / 7
+	offset := weeks*7 - len(days) // pad the oldest week so the last one ends today
+	index := make(map[string]int, len(days))
+	for i, d := range days {
+		index[d.Date] = (i + offset) / 7
+	}
+	out := make([][]int, len(projects))
+	for i, p := range projects {
+		out[i] = make([]int, weeks)
+		for _, d := range p.Days {
+			if w, ok := index[d.Date]; ok {
+				out[i][w] += d.Messages
+			}
+		}
+	}
+	return out
+}
+
+func sparkline(vals []int) string {
+	peak := 0
+	for _, v := range vals {
+		peak = max(peak, v)
+	}
+	var sb strings.Builder
+	for _, v := range vals {
+		if v == 0 || peak == 0 {
+			sb.WriteString(" ")
+			continue
+		}
+		sb.WriteString(eighths[max((v*8+peak-1)/peak, 1)])
+	}
+	return sb.String()
+}
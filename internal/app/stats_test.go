@@ -0,0 +1,288 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStatsToolsCmd_JSON(t *testing.T) {
+	home := setupFixtures(t)
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-myproject")
+	writeLines(t, filepath.Join(projDir, "tool1111-2222-3333-4444-555555555555.jsonl"), []string{
+		`{"type":"user","message":{"role":"user","content":"check things"},"cwd":"/Users/test/myproject","timestamp":"2026-02-02T08:00:00Z"}`,
+		`{"type":"assistant","timestamp":"2026-02-02T08:00:01Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"a","name":"Bash","input":{"command":"ls"}},{"type":"tool_use","id":"b","name":"mcp__linear__list_issues","input":{}}]}}`,
+		`{"type":"user","timestamp":"2026-02-02T08:00:03Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"a","content":"main.go"},{"type":"tool_result","tool_use_id":"b","is_error":true,"content":"unauthorized"}]}}`,
+		`{"type":"assistant","timestamp":"2026-02-02T08:00:04Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"c","name":"Bash","input":{"command":"pwd"}}]}}`,
+		`{"type":"user","timestamp":"2026-02-02T08:00:05Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"c","content":"/Users/test/myproject"}]}}`,
+	})
+
+	run := func(cmd *StatsToolsCmd) toolStatsData {
+		t.Helper()
+		out := captureStdout(t, func() {
+			if err := cmd.Run(&Globals{JSON: true}); err != nil {
+				t.Fatal(err)
+			}
+		})
+		var data toolStatsData
+		if err := json.Unmarshal([]byte(out), &data); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		return data
+	}
+
+	data := run(&StatsToolsCmd{By: "tool"})
+	if data.TotalCalls != 3 || data.Errors != 1 || data.Sessions != 1 {
+		t.Fatalf("totals = %d calls / %d errors / %d sessions", data.TotalCalls, data.Errors, data.Sessions)
+	}
+	if len(data.Rows) != 2 || data.Rows[0].Name != "Bash" || data.Rows[0].Calls != 2 {
+		t.Fatalf("rows = %+v", data.Rows)
+	}
+	if data.Rows[0].MedianOutputBytes != len("main.go") || data.Rows[0].MedianLatencyMS != 1000 {
+		t.Errorf("Bash median out/latency = %d/%d", data.Rows[0].MedianOutputBytes, data.Rows[0].MedianLatencyMS)
+	}
+	if len(data.MCPServers) != 1 || data.MCPServers[0].Name != "linear" || data.MCPServers[0].ErrorRate != 1 {
+		t.Errorf("mcp servers = %+v", data.MCPServers)
+	}
+
+	if data := run(&StatsToolsCmd{By: "server"}); len(data.Rows) != 1 || data.Rows[0].Name != "linear" {
+		t.Errorf("--by server rows = %+v", data.Rows)
+	}
+
+	if data := run(&StatsToolsCmd{By: "tool", Since: time.Now().Format("2006-01-02")}); data.TotalCalls != 0 {
+		t.Errorf("--since today: %d calls, want 0", data.TotalCalls)
+	}
+}
+
+func TestStatsToolsCmd_ResumedFileCountsOnce(t *testing.T) {
+	home := setupFixtures(t)
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-myproject")
+	calls := []string{
+		`{"type":"user","uuid":"t1","message":{"role":"user","content":"check things"},"cwd":"/Users/test/myproject","timestamp":"2026-02-02T08:00:00Z"}`,
+		`{"type":"assistant","uuid":"t2","timestamp":"2026-02-02T08:00:01Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"a","name":"Bash","input":{"command":"ls"}}]}}`,
+		`{"type":"user","uuid":"t3","timestamp":"2026-02-02T08:00:03Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"a","content":"main.go"}]}}`,
+	}
+	original := filepath.Join(projDir, "orig1111-2222-3333-4444-555555555555.jsonl")
+	writeLines(t, original, calls)
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(original, old, old); err != nil {
+		t.Fatal(err)
+	}
+	// The resumed file replays the call before making one of its own.
+	writeLines(t, filepath.Join(projDir, "resu1111-2222-3333-4444-555555555555.jsonl"), append(calls,
+		`{"type":"assistant","uuid":"t4","timestamp":"2026-02-03T08:00:01Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"b","name":"Bash","input":{"command":"pwd"}}]}}`,
+	))
+
+	out := captureStdout(t, func() {
+		if err := (&StatsToolsCmd{By: "tool"}).Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var data toolStatsData
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if data.TotalCalls != 2 || data.Sessions != 2 {
+		t.Errorf("totals = %d calls / %d sessions, want 2 / 2", data.TotalCalls, data.Sessions)
+	}
+}
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.Local)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"", time.Time{}},
+		{"today", time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)},
+		{"yesterday", time.Date(2026, 3, 9, 0, 0, 0, 0, time.Local)},
+		{"7d", now.Add(-7 * 24 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"2026-01-31", time.Date(2026, 1, 31, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeBound(tt.in, now)
+		if err != nil {
+			t.Errorf("parseTimeBound(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseTimeBound(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"7x", "soon", "d"} {
+		if _, err := parseTimeBound(bad, now); err == nil {
+			t.Errorf("parseTimeBound(%q): want error", bad)
+		}
+	}
+	if _, err := parseTimeWindow("today", "yesterday", now); err == nil {
+		t.Error("since after until: want error")
+	}
+}
+
+func TestStatsActivityCmd_JSON(t *testing.T) {
+	home := setupFixtures(t)
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-heatmap")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	day1 := time.Date(2026, 3, 2, 9, 15, 0, 0, time.Local) // Monday
+	day3 := day1.AddDate(0, 0, 2).Add(5 * time.Hour)       // Wednesday 14:15
+	ts := func(t time.Time) string { return t.UTC().Format(time.RFC3339) }
+	writeLines(t, filepath.Join(projDir, "heat1111-2222-3333-4444-555555555555.jsonl"), []string{
+		`{"type":"user","message":{"role":"user","content":"start"},"cwd":"/Users/test/heatmap","timestamp":"` + ts(day1) + `"}`,
+		`{"type":"assistant","message":{"role":"assistant","content":"ok"},"timestamp":"` + ts(day1.Add(time.Minute)) + `"}`,
+		`{"type":"user","message":{"role":"user","content":"again"},"cwd":"/Users/test/heatmap","timestamp":"` + ts(day3) + `"}`,
+	})
+
+	out := captureStdout(t, func() {
+		cmd := &StatsActivityCmd{Project: "heatmap", Since: "all"}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var data activityData
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+
+	if data.Messages != 3 || data.Sessions != 1 || data.ActiveDays != 2 {
+		t.Fatalf("totals = %d msgs / %d sessions / %d days", data.Messages, data.Sessions, data.ActiveDays)
+	}
+	if data.Start != "2026-03-02" || len(data.Days) < 3 {
+		t.Fatalf("span = %s, %d days", data.Start, len(data.Days))
+	}
+	if d := data.Days[1]; d.Date != "2026-03-03" || d.Messages != 0 {
+		t.Errorf("gap day = %+v, want a zero entry", d)
+	}
+	if d := data.Days[2]; d.Messages != 1 || d.Sessions != 1 {
+		t.Errorf("day 3 = %+v", d)
+	}
+	if data.ByWeekday[0].Label != "Mon" || data.ByWeekday[0].Messages != 2 || data.ByWeekday[2].Messages != 1 {
+		t.Errorf("by_weekday = %+v", data.ByWeekday)
+	}
+	if data.ByHour[9].Messages != 2 || data.ByHour[14].Messages != 1 {
+		t.Errorf("by_hour = %+v", data.ByHour)
+	}
+	if len(data.Projects) != 1 || data.Projects[0].Name != "heatmap" || data.Projects[0].ActiveDays != 2 {
+		t.Errorf("projects = %+v", data.Projects)
+	}
+}
+
+func TestStatsActivityCmd_ResumedFileCountsOnce(t *testing.T) {
+	home := setupFixtures(t)
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-resumed")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	first := []string{
+		`{"type":"user","uuid":"m1","message":{"role":"user","content":"start"},"cwd":"/Users/test/resumed","timestamp":"2026-03-02T09:00:00Z"}`,
+		`{"type":"assistant","uuid":"m2","message":{"role":"assistant","content":"ok"},"timestamp":"2026-03-02T09:01:00Z"}`,
+	}
+	original := filepath.Join(projDir, "orig2222-2222-3333-4444-555555555555.jsonl")
+	writeLines(t, original, first)
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(original, old, old); err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, filepath.Join(projDir, "resu2222-2222-3333-4444-555555555555.jsonl"), append(first,
+		`{"type":"user","uuid":"m3","message":{"role":"user","content":"carry on"},"cwd":"/Users/test/resumed","timestamp":"2026-03-04T09:00:00Z"}`,
+	))
+
+	out := captureStdout(t, func() {
+		if err := (&StatsActivityCmd{Project: "resumed", Since: "all"}).Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var data activityData
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if data.Messages != 3 || data.Days[0].Messages != 2 || data.Days[0].Sessions != 1 {
+		t.Errorf("messages = %d, first day = %+v; want 3 and the replay not counted", data.Messages, data.Days[0])
+	}
+}
+
+func TestHeatLevel(t *testing.T) {
+	tests := []struct{ n, peak, want int }{
+		{0, 10, 0},
+		{1, 10, 1},
+		{3, 10, 2},
+		{6, 10, 3},
+		{10, 10, 4},
+		{5, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := heatLevel(tt.n, tt.peak); got != tt.want {
+			t.Errorf("heatLevel(%d, %d) = %d, want %d", tt.n, tt.peak, got, tt.want)
+		}
+	}
+}
+
+func TestStatsTimeCmd_JSON(t *testing.T) {
+	home := setupFixtures(t)
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-billing")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2026, 3, 4, 9, 0, 0, 0, time.Local) // Wednesday
+	line := func(typ string, at time.Time) string {
+		return `{"type":"` + typ + `","message":{"role":"` + typ + `","content":"x"},"cwd":"/Users/test/billing","timestamp":"` +
+			at.UTC().Format(time.RFC3339) + `"}`
+	}
+	// Two segments (10m + 5m) split by an overnight gap.
+	writeLines(t, filepath.Join(projDir, "bill1111-2222-3333-4444-555555555555.jsonl"), []string{
+		line("user", base),
+		line("assistant", base.Add(10*time.Minute)),
+		line("user", base.Add(20*time.Hour)),
+		line("assistant", base.Add(20*time.Hour+5*time.Minute)),
+	})
+	// A parallel session overlapping the first segment adds only 2m.
+	writeLines(t, filepath.Join(projDir, "bill2222-2222-3333-4444-555555555555.jsonl"), []string{
+		line("user", base.Add(5*time.Minute)),
+		line("assistant", base.Add(12*time.Minute)),
+	})
+
+	out := captureStdout(t, func() {
+		cmd := &StatsTimeCmd{Project: "billing", Since: "all", Idle: 15 * time.Minute}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var data timeStatsData
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+
+	if data.Sessions != 2 || data.Segments != 3 {
+		t.Errorf("sessions/segments = %d/%d, want 2/3", data.Sessions, data.Segments)
+	}
+	if want := int64((17 * time.Minute).Seconds()); data.ActiveSeconds != want {
+		t.Errorf("active = %ds, want %ds", data.ActiveSeconds, want)
+	}
+	if len(data.Projects) != 1 || data.Projects[0].Name != "billing" || data.Projects[0].ActiveSeconds != data.ActiveSeconds {
+		t.Errorf("projects = %+v", data.Projects)
+	}
+	if len(data.Weeks) != 1 || data.Weeks[0].WeekStart != "2026-03-02" || data.Weeks[0].ActiveSeconds != data.ActiveSeconds {
+		t.Errorf("weeks = %+v", data.Weeks)
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	tests := map[time.Time]string{
+		time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local):   "2026-03-02", // Monday
+		time.Date(2026, 3, 8, 23, 0, 0, 0, time.Local):  "2026-03-02", // Sunday
+		time.Date(2026, 3, 9, 8, 30, 0, 0, time.Local):  "2026-03-09",
+		time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local):  "2025-12-29",
+		time.Date(2026, 2, 28, 12, 0, 0, 0, time.Local): "2026-02-23",
+	}
+	for in, want := range tests {
+		if got := weekStart(in); got != want {
+			t.Errorf("weekStart(%v) = %s, want %s", in, got, want)
+		}
+	}
+}
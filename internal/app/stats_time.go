@@ -0,0 +1,229 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andyhtran/cct/internal/index"
+	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/session"
+)
+
+type StatsTimeCmd struct {
+	Project string        `short:"p" help:"Filter by project name"`
+	Since   string        `help:"Start of the window (today, yesterday, 7d, 12w, 2026-01-31, or 'all')" default:"8w"`
+	Until   string        `help:"End of the window, exclusive (same forms as --since)"`
+	Idle    time.Duration `help:"Gap between messages that ends a work segment" default:"15m"`
+	Agents  bool          `help:"Include sub-agent sessions (their time usually overlaps the parent's)"`
+}
+
+type projectTime struct {
+	Name          string `json:"name"`
+	ActiveSeconds int64  `json:"active_seconds"`
+	Sessions      int    `json:"sessions,omitempty"`
+	Segments      int    `json:"segments,omitempty"`
+}
+
+type weekTime struct {
+	WeekStart     string         `json:"week_start"`
+	ActiveSeconds int64          `json:"active_seconds"`
+	Projects      []*projectTime `json:"projects"`
+}
+
+// timeStatsData is the stats time report. Time in parallel sessions of
+// one project is counted once; parallel work on different projects counts
+// toward each. Segments are attributed to the week (Monday-start, local)
+// they begin in.
+type timeStatsData struct {
+	Window        string         `json:"window"`
+	IdleSeconds   int64          `json:"idle_threshold_seconds"`
+	ActiveSeconds int64          `json:"active_seconds"`
+	Sessions      int            `json:"sessions"`
+	Segments      int            `json:"segments"`
+	Projects      []*projectTime `json:"projects"`
+	Weeks         []*weekTime    `json:"weeks"`
+}
+
+func (cmd *StatsTimeCmd) Run(globals *Globals) error {
+	window, err := parseTimeWindow(cmd.Since, cmd.Until, time.Now())
+	if err != nil {
+		return err
+	}
+	if cmd.Idle <= 0 {
+		return fmt.Errorf("--idle must be positive")
+	}
+
+	idx, err := index.Open()
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+	defer func() { _ = idx.Close() }()
+
+	times, err := idx.MessageTimes(index.EventFilter{
+		Root:          globals.Root,
+		ProjectFilter: cmd.Project,
+		IncludeAgents: cmd.Agents,
+		Since:         window.Since,
+		Until:         window.Until,
+	})
+	if err != nil {
+		return fmt.Errorf("message times: %w", err)
+	}
+
+	data := aggregateTime(times, cmd.Idle)
+	data.Window = describeWindow(cmd.Since, cmd.Until)
+
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	}
+
+	if data.Segments == 0 {
+		fmt.Println("  No activity found.")
+		return nil
+	}
+	printTimeStats(data)
+	return nil
+}
+
+// aggregateTime builds work segments per session from times (grouped by
+// session, as MessageTimes returns them), then merges them per project.
+func aggregateTime(times []index.MessageTime, idle time.Duration) *timeStatsData {
+	data := &timeStatsData{IdleSeconds: int64(idle.Seconds())}
+
+	type projectAcc struct {
+		segs     []session.Segment
+		sessions int
+	}
+	projects := map[string]*projectAcc{}
+	flush := func(project string, ts []time.Time) {
+		if len(ts) == 0 {
+			return
+		}
+		if project == "" {
+			project = "(unknown)"
+		}
+		p, ok := projects[project]
+		if !ok {
+			p = &projectAcc{}
+			projects[project] = p
+		}
+		segs := session.Segments(ts, idle)
+		p.segs = append(p.segs, segs...)
+		p.sessions++
+		data.Sessions++
+		data.Segments += len(segs)
+	}
+
+	var current, project string
+	var ts []time.Time
+	for _, m := range times {
+		key := m.Root + "/" + m.SessionID
+		if key != current {
+			flush(project, ts)
+			current, project, ts = key, m.Project, nil
+		}
+		ts = append(ts, m.At)
+	}
+	flush(project, ts)
+
+	weeks := map[string]*weekTime{}
+	var all []session.Segment
+	for name, p := range projects {
+		merged := session.MergeSegments(p.segs)
+		all = append(all, merged...)
+		data.Projects = append(data.Projects, &projectTime{
+			Name:          name,
+			ActiveSeconds: int64(session.ActiveTime(merged).Seconds()),
+			Sessions:      p.sessions,
+			Segments:      len(p.segs),
+		})
+
+		perWeek := map[string]time.Duration{}
+		for _, s := range merged {
+			perWeek[weekStart(s.Start)] += s.Duration()
+		}
+		for start, d := range perWeek {
+			w, ok := weeks[start]
+			if !ok {
+				w = &weekTime{WeekStart: start}
+				weeks[start] = w
+			}
+			w.ActiveSeconds += int64(d.Seconds())
+			w.Projects = append(w.Projects, &projectTime{Name: name, ActiveSeconds: int64(d.Seconds())})
+		}
+	}
+	data.ActiveSeconds = int64(session.ActiveTime(session.MergeSegments(all)).Seconds())
+
+	sortProjectTimes(data.Projects)
+	for _, w := range weeks {
+		sortProjectTimes(w.Projects)
+		data.Weeks = append(data.Weeks, w)
+	}
+	sort.Slice(data.Weeks, func(i, j int) bool { return data.Weeks[i].WeekStart > data.Weeks[j].WeekStart })
+	return data
+}
+
+func sortProjectTimes(ps []*projectTime) {
+	sort.Slice(ps, func(i, j int) bool {
+		if ps[i].ActiveSeconds != ps[j].ActiveSeconds {
+			return ps[i].ActiveSeconds > ps[j].ActiveSeconds
+		}
+		return ps[i].Name < ps[j].Name
+	})
+}
+
+// weekStart returns the local Monday of t's week as YYYY-MM-DD.
+func weekStart(t time.Time) string {
+	day := localDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset).Format(dayFormat)
+}
+
+func seconds(n int64) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
+func printTimeStats(data *timeStatsData) {
+	fmt.Println()
+	fmt.Printf("  %s  %s  %s\n", output.Pad("Active:", 12, output.Dim), output.FormatDuration(seconds(data.ActiveSeconds)),
+		output.Dim(fmt.Sprintf("(%d sessions, %d segments, idle > %s, %s)",
+			data.Sessions, data.Segments, output.FormatDuration(seconds(data.IdleSeconds)), data.Window)))
+
+	fmt.Println()
+	tbl := output.NewTable("",
+		output.Flex("PROJECT", 40, 15),
+		output.Fixed("ACTIVE", 8),
+		output.Fixed("SESSIONS", 8),
+		output.Flex("SEGMENTS", 0, 8),
+	)
+	tbl.PrintHeader()
+	for _, p := range data.Projects {
+		tbl.Row([]string{
+			output.Truncate(p.Name, tbl.ColWidth(0)),
+			output.FormatDuration(seconds(p.ActiveSeconds)),
+			formatInt(p.Sessions),
+			formatInt(p.Segments),
+		}, []func(string) string{output.Bold, nil, output.Dim, output.Dim})
+	}
+
+	fmt.Println()
+	fmt.Println("  " + output.Bold("By Week"))
+	for _, w := range data.Weeks {
+		start, _ := time.ParseInLocation(dayFormat, w.WeekStart, time.Local)
+		var parts []string
+		for _, p := range w.Projects {
+			parts = append(parts, fmt.Sprintf("%s %s", p.Name, output.FormatDuration(seconds(p.ActiveSeconds))))
+		}
+		fmt.Printf("    %s  %s  %s\n",
+			output.Pad(start.Format("Jan 2")+" – "+start.AddDate(0, 0, 6).Format("Jan 2"), 16, output.Bold),
+			fmt.Sprintf("%-8s", output.FormatDuration(seconds(w.ActiveSeconds))),
+			output.Dim(output.Truncate(strings.Join(parts, ", "), 60)))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,322 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/andyhtran/cct/internal/index"
+	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/session"
+)
+
+type StatsToolsCmd struct {
+	Project string `short:"p" help:"Filter by project name"`
+	Since   string `help:"Only count calls at or after this time (today, yesterday, 7d, 12h, 2026-01-31)"`
+	Until   string `help:"Only count calls before this time (same forms as --since)"`
+	By      string `help:"Group rows by tool (default), project (project × tool), or server (MCP server)" default:"tool" enum:"tool,project,server"`
+	Limit   int    `short:"n" help:"Max rows (0=no limit)" default:"20"`
+	Agents  bool   `help:"Include calls made inside sub-agent sessions"`
+}
+
+// minSlowSamples keeps one-off slow calls out of the "slowest tools" list;
+// a median over fewer timed calls says more about luck than the tool.
+const (
+	minSlowSamples = 3
+	maxSlowest     = 5
+)
+
+type toolStat struct {
+	Name              string    `json:"name"`
+	Project           string    `json:"project,omitempty"`
+	Calls             int       `json:"calls"`
+	Errors            int       `json:"errors"`
+	ErrorRate         float64   `json:"error_rate"`
+	Sessions          int       `json:"sessions"`
+	MedianOutputBytes int       `json:"median_output_bytes"`
+	MedianLatencyMS   int64     `json:"median_latency_ms"`
+	MaxLatencyMS      int64     `json:"max_latency_ms"`
+	LastUsed          time.Time `json:"last_used"`
+
+	sessions  map[string]bool
+	outputs   []int
+	latencies []time.Duration
+}
+
+type toolStatsData struct {
+	Window     string      `json:"window"`
+	TotalCalls int         `json:"total_calls"`
+	Errors     int         `json:"errors"`
+	Sessions   int         `json:"sessions"`
+	Rows       []*toolStat `json:"rows"`
+	Slowest    []*toolStat `json:"slowest"`
+	MCPServers []*toolStat `json:"mcp_servers,omitempty"`
+}
+
+func (cmd *StatsToolsCmd) Run(globals *Globals) error {
+	window, err := parseTimeWindow(cmd.Since, cmd.Until, time.Now())
+	if err != nil {
+		return err
+	}
+
+	idx, err := index.Open()
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+	defer func() { _ = idx.Close() }()
+
+	calls, err := idx.ToolCalls(index.EventFilter{
+		Root:          globals.Root,
+		ProjectFilter: cmd.Project,
+		IncludeAgents: cmd.Agents,
+		Since:         window.Since,
+		Until:         window.Until,
+	})
+	if err != nil {
+		return fmt.Errorf("tool calls: %w", err)
+	}
+
+	data := aggregateToolStats(calls, cmd.By)
+	data.Window = describeWindow(cmd.Since, cmd.Until)
+	if cmd.Limit > 0 && len(data.Rows) > cmd.Limit {
+		data.Rows = data.Rows[:cmd.Limit]
+	}
+
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	}
+
+	if data.TotalCalls == 0 {
+		fmt.Println("  No tool calls found.")
+		return nil
+	}
+	printToolStats(data, cmd.By)
+	return nil
+}
+
+// aggregateToolStats groups calls into rows keyed by by ("tool", "project",
+// or "server"), plus the slowest-tools and MCP-server summaries that are
+// always keyed by tool and server respectively.
+func aggregateToolStats(calls []index.ToolCallRow, by string) *toolStatsData {
+	data := &toolStatsData{}
+	rows := map[string]*toolStat{}
+	tools := map[string]*toolStat{}
+	servers := map[string]*toolStat{}
+	sessions := map[string]bool{}
+
+	for i := range calls {
+		c := &calls[i]
+		data.TotalCalls++
+		if c.IsError {
+			data.Errors++
+		}
+		sessions[c.SessionID] = true
+
+		server := session.MCPServer(c.Name)
+		switch by {
+		case "project":
+			addToolCall(rows, c.Project+"\x00"+c.Name, c.Name, c.Project, c)
+		case "server":
+			if server != "" {
+				addToolCall(rows, server, server, "", c)
+			}
+		default:
+			addToolCall(rows, c.Name, c.Name, "", c)
+		}
+		addToolCall(tools, c.Name, c.Name, "", c)
+		if server != "" {
+			addToolCall(servers, server, server, "", c)
+		}
+	}
+	data.Sessions = len(sessions)
+
+	data.Rows = finishToolStats(rows)
+	sort.SliceStable(data.Rows, func(i, j int) bool {
+		a, b := data.Rows[i], data.Rows[j]
+		if by == "project" && a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		return a.Calls > b.Calls
+	})
+
+	for _, t := range finishToolStats(tools) {
+		if len(t.latencies) >= minSlowSamples {
+			data.Slowest = append(data.Slowest, t)
+		}
+	}
+	sort.SliceStable(data.Slowest, func(i, j int) bool {
+		return data.Slowest[i].MedianLatencyMS > data.Slowest[j].MedianLatencyMS
+	})
+	if len(data.Slowest) > maxSlowest {
+		data.Slowest = data.Slowest[:maxSlowest]
+	}
+
+	data.MCPServers = finishToolStats(servers)
+	sort.SliceStable(data.MCPServers, func(i, j int) bool {
+		return data.MCPServers[i].Calls > data.MCPServers[j].Calls
+	})
+	return data
+}
+
+func addToolCall(m map[string]*toolStat, key, name, project string, c *index.ToolCallRow) {
+	t, ok := m[key]
+	if !ok {
+		t = &toolStat{Name: name, Project: project, sessions: map[string]bool{}}
+		m[key] = t
+	}
+	t.Calls++
+	if c.IsError {
+		t.Errors++
+	}
+	t.sessions[c.SessionID] = true
+	if c.HasResult {
+		t.outputs = append(t.outputs, c.OutputBytes)
+	}
+	if d := c.Latency(); d > 0 {
+		t.latencies = append(t.latencies, d)
+	}
+	if c.CalledAt.After(t.LastUsed) {
+		t.LastUsed = c.CalledAt
+	}
+}
+
+// finishToolStats fills the derived fields and returns the stats sorted by
+// name, so callers' stable sorts give deterministic output.
+func finishToolStats(m map[string]*toolStat) []*toolStat {
+	out := make([]*toolStat, 0, len(m))
+	for _, t := range m {
+		t.Sessions = len(t.sessions)
+		if t.Calls > 0 {
+			t.ErrorRate = float64(t.Errors) / float64(t.Calls)
+		}
+		t.MedianOutputBytes = median(t.outputs)
+		if len(t.latencies) > 0 {
+			t.MedianLatencyMS = median(t.latencies).Milliseconds()
+			t.MaxLatencyMS = slices.Max(t.latencies).Milliseconds()
+		}
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Project != out[j].Project {
+			return out[i].Project < out[j].Project
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// median returns the lower median of vals (0 when empty) without
+// reordering the caller's slice.
+func median[T int | time.Duration](vals []T) T {
+	if len(vals) == 0 {
+		var zero T
+		return zero
+	}
+	sorted := slices.Clone(vals)
+	slices.Sort(sorted)
+	return sorted[(len(sorted)-1)/2]
+}
+
+// formatLatency is FormatDuration with sub-minute precision, since most
+// tool calls finish in seconds.
+func formatLatency(ms int64) string {
+	d := time.Duration(ms) * time.Millisecond
+	switch {
+	case d == 0:
+		return "-"
+	case d < time.Second:
+		return fmt.Sprintf("%dms", ms)
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	default:
+		return output.FormatDuration(d)
+	}
+}
+
+func formatErrorRate(r float64) string {
+	if r == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", r*100)
+}
+
+func printToolStats(data *toolStatsData, by string) {
+	fmt.Println()
+	fmt.Printf("  %s  %s  %s\n", output.Pad("Tool calls:", 12, output.Dim), formatInt(data.TotalCalls),
+		output.Dim(fmt.Sprintf("(%d sessions, %s)", data.Sessions, data.Window)))
+	fmt.Printf("  %s  %s  %s\n", output.Pad("Errors:", 12, output.Dim), formatInt(data.Errors),
+		output.Dim(formatErrorRate(float64(data.Errors)/float64(data.TotalCalls))))
+	fmt.Println()
+
+	cols := []output.ColDef{output.Flex("TOOL", 50, 20)}
+	if by == "project" {
+		cols = []output.ColDef{output.Flex("PROJECT", 30, 15), output.Flex("TOOL", 40, 20)}
+	} else if by == "server" {
+		cols = []output.ColDef{output.Flex("MCP SERVER", 50, 20)}
+	}
+	cols = append(cols,
+		output.Fixed("CALLS", 8),
+		output.Fixed("ERR%", 6),
+		output.Fixed("SESSIONS", 8),
+		output.Fixed("MED OUT", 9),
+		output.Fixed("MED TIME", 8),
+		output.Flex("LAST", 0, 6),
+	)
+	tbl := output.NewTable("", cols...)
+	tbl.PrintHeader()
+
+	lastProject := ""
+	for _, t := range data.Rows {
+		var lead []string
+		var colors []func(string) string
+		if by == "project" {
+			project := ""
+			if t.Project != lastProject {
+				project = t.Project
+				lastProject = t.Project
+			}
+			lead = []string{output.Truncate(project, tbl.ColWidth(0)), output.Truncate(t.Name, tbl.ColWidth(1))}
+			colors = []func(string) string{output.Bold, nil}
+		} else {
+			lead = []string{output.Truncate(t.Name, tbl.ColWidth(0))}
+			colors = []func(string) string{output.Bold}
+		}
+		values := append(lead,
+			formatInt(t.Calls),
+			formatErrorRate(t.ErrorRate),
+			formatInt(t.Sessions),
+			output.FormatBytes(int64(t.MedianOutputBytes)),
+			formatLatency(t.MedianLatencyMS),
+			output.FormatAge(t.LastUsed),
+		)
+		tbl.Row(values, append(colors, nil, nil, output.Dim, output.Dim, output.Dim, output.Dim))
+	}
+
+	if len(data.Slowest) > 0 {
+		fmt.Println()
+		fmt.Println("  " + output.Bold("Slowest Tools (median tool_use → tool_result)"))
+		for _, t := range data.Slowest {
+			fmt.Printf("    %s  %s  %s\n",
+				output.Pad(output.Truncate(t.Name, 40), 40, output.Bold),
+				fmt.Sprintf("%-8s", formatLatency(t.MedianLatencyMS)),
+				output.Dim(fmt.Sprintf("max %s, %d calls", formatLatency(t.MaxLatencyMS), t.Calls)))
+		}
+	}
+
+	if by != "server" && len(data.MCPServers) > 0 {
+		fmt.Println()
+		fmt.Println("  " + output.Bold("MCP Servers"))
+		for _, t := range data.MCPServers {
+			fmt.Printf("    %s  %s\n",
+				output.Pad(output.Truncate(t.Name, 40), 40, output.Bold),
+				output.Dim(fmt.Sprintf("%s calls, %d sessions, %s errors, last used %s ago",
+					formatInt(t.Calls), t.Sessions, formatInt(t.Errors), output.FormatAge(t.LastUsed))))
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeWindow is a half-open [Since, Until) range; a zero bound is open.
+type timeWindow struct {
+	Since time.Time
+	Until time.Time
+}
+
+func (w timeWindow) Contains(t time.Time) bool {
+	if !w.Since.IsZero() && t.Before(w.Since) {
+		return false
+	}
+	if !w.Until.IsZero() && !t.Before(w.Until) {
+		return false
+	}
+	return true
+}
+
+// describeWindow renders --since/--until for headers: "last 7d",
+// "since 2026-01-31", "all time".
+func describeWindow(since, until string) string {
+	if strings.EqualFold(since, "all") {
+		since = ""
+	}
+	if strings.EqualFold(until, "all") {
+		until = ""
+	}
+	switch {
+	case since != "" && until != "":
+		return since + " to " + until
+	case since != "":
+		if _, err := parseRelative(since); err == nil {
+			return "last " + since
+		}
+		return "since " + since
+	case until != "":
+		return "until " + until
+	default:
+		return "all time"
+	}
+}
+
+// parseTimeWindow parses --since/--until values relative to now. See
+// parseTimeBound for the accepted forms.
+func parseTimeWindow(since, until string, now time.Time) (timeWindow, error) {
+	var w timeWindow
+	var err error
+	if w.Since, err = parseTimeBound(since, now); err != nil {
+		return w, fmt.Errorf("--since: %w", err)
+	}
+	if w.Until, err = parseTimeBound(until, now); err != nil {
+		return w, fmt.Errorf("--until: %w", err)
+	}
+	if !w.Since.IsZero() && !w.Until.IsZero() && !w.Since.Before(w.Until) {
+		return w, fmt.Errorf("--since must be before --until")
+	}
+	return w, nil
+}
+
+// parseTimeBound accepts "" or "all" (open), "today", "yesterday", a relative age
+// ("90m", "12h", "7d", "2w"), a local date ("2026-01-31"), or RFC3339.
+// Dates and day names resolve to local midnight.
+func parseTimeBound(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(s) {
+	case "", "all":
+		return time.Time{}, nil
+	case "today":
+		return midnight, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	}
+	if d, err := parseRelative(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (want today, yesterday, 7d, 12h, 2026-01-31, or RFC3339)", s)
+}
+
+// parseClockTime parses a time within a session: a clock time ("14:00",
+// "14:00:30") on the local day of day, a local date and time
+// ("2026-01-31 14:00"), or anything parseTimeBound accepts.
+func parseClockTime(s string, day, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	day = day.In(now.Location())
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), 0, now.Location()), nil
+		}
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := parseTimeBound(s, now); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (want 14:00, 2026-01-31 14:00, 30m, or RFC3339)", s)
+}
+
+// parseRelative parses "<n><unit>" with unit m, h, d, or w.
+func parseRelative(s string) (time.Duration, error) {
+	if len(s) < 2 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	unit := map[byte]time.Duration{
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}[s[len(s)-1]]
+	if unit == 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return time.Duration(n) * unit, nil
+}
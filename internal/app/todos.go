@@ -0,0 +1,184 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/andyhtran/cct/internal/index"
+	"github.com/andyhtran/cct/internal/output"
+	"github.com/andyhtran/cct/internal/session"
+)
+
+type TodosCmd struct {
+	ID      string `arg:"" optional:"" help:"Session ID or prefix (omit with --open to search every session)"`
+	Open    bool   `help:"Only items left pending or in progress when the session ended"`
+	History bool   `help:"Show every TodoWrite update, not just the final list"`
+	Project string `short:"p" help:"Filter by project name (with --open and no ID)"`
+	Since   string `help:"Only sessions whose list last changed at or after this time (today, yesterday, 7d, 2026-01-31)"`
+	Until   string `help:"Only sessions whose list last changed before this time (same forms as --since)"`
+	Limit   int    `short:"n" help:"Max sessions listed with --open (0=no limit)" default:"20"`
+	Agents  bool   `help:"Include sub-agent sessions"`
+}
+
+type todosOutput struct {
+	Session *session.Session     `json:"session"`
+	Todos   []*session.Todo      `json:"todos"`
+	States  []*session.TodoState `json:"states,omitempty"`
+}
+
+type openTodosGroup struct {
+	Session   *session.Session `json:"session"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	Offset    int64            `json:"offset"`
+	Todos     []session.Todo   `json:"todos"`
+}
+
+func (cmd *TodosCmd) Run(globals *Globals) error {
+	if cmd.ID == "" {
+		if !cmd.Open {
+			return errors.New("give a session ID, or --open to list unfinished todos across sessions")
+		}
+		return cmd.runOpen(globals)
+	}
+
+	match, err := findSession(globals, cmd.ID)
+	if err != nil {
+		return err
+	}
+	states, err := session.ReadTodoStates(match.FilePath)
+	if err != nil {
+		return fmt.Errorf("read session: %w", err)
+	}
+	res := todosOutput{Session: match, Todos: []*session.Todo{}}
+	if n := len(states); n > 0 {
+		res.Todos = states[n-1].Todos
+	}
+	if cmd.Open {
+		res.Todos = openOnly(res.Todos)
+	}
+	if cmd.History {
+		res.States = states
+	}
+
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+
+	if len(states) == 0 {
+		fmt.Println("No TodoWrite calls in this session.")
+		return nil
+	}
+	fmt.Println()
+	fmt.Printf("  %s %s\n", output.Bold(sessionTitle(match)), output.Dim(fmt.Sprintf("(%d %s)", len(states), plural(len(states), "update", "updates"))))
+	if cmd.History {
+		for i, st := range states {
+			fmt.Printf("\n  %s %s\n", output.Dim(fmt.Sprintf("#%d", i+1)), output.Dim(formatTodoTime(st.At)))
+			printTodos(st.Todos, "    ")
+		}
+	} else {
+		fmt.Println()
+		if len(res.Todos) == 0 {
+			fmt.Println("    Every item was completed.")
+		}
+		printTodos(res.Todos, "    ")
+	}
+	fmt.Println()
+	return nil
+}
+
+func (cmd *TodosCmd) runOpen(globals *Globals) error {
+	w, err := parseTimeWindow(cmd.Since, cmd.Until, time.Now())
+	if err != nil {
+		return err
+	}
+	idx, err := index.Open()
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+	defer func() { _ = idx.Close() }()
+
+	rows, err := idx.OpenTodos(index.EventFilter{
+		Root:          globals.Root,
+		ProjectFilter: cmd.Project,
+		IncludeAgents: cmd.Agents,
+		Since:         w.Since,
+		Until:         w.Until,
+	})
+	if err != nil {
+		return err
+	}
+
+	groups := []*openTodosGroup{}
+	byKey := map[string]*openTodosGroup{}
+	for _, r := range rows {
+		key := r.Session.Root + "/" + r.Session.ID
+		g, ok := byKey[key]
+		if !ok {
+			if cmd.Limit > 0 && len(groups) >= cmd.Limit {
+				continue
+			}
+			g = &openTodosGroup{Session: r.Session, UpdatedAt: r.UpdatedAt, Offset: r.Offset}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.Todos = append(g.Todos, r.Todo)
+	}
+
+	if globals.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(groups)
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("No unfinished todos.")
+		return nil
+	}
+	for _, g := range groups {
+		fmt.Println()
+		fmt.Printf("  %s  %s %s\n", output.Bold(g.Session.ShortID), sessionTitle(g.Session),
+			output.Dim(fmt.Sprintf("(%s, %s)", projectLabel(g.Session), output.FormatAge(g.UpdatedAt))))
+		todos := make([]*session.Todo, len(g.Todos))
+		for i := range g.Todos {
+			todos[i] = &g.Todos[i]
+		}
+		printTodos(todos, "    ")
+	}
+	fmt.Println()
+	return nil
+}
+
+func openOnly(todos []*session.Todo) []*session.Todo {
+	out := []*session.Todo{}
+	for _, t := range todos {
+		if t.Open() {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func printTodos(todos []*session.Todo, indent string) {
+	for _, t := range todos {
+		switch t.Status {
+		case session.TodoCompleted:
+			fmt.Printf("%s[x] %s %s\n", indent, t.Content, output.Dim("done "+formatTodoTime(t.CompletedAt)))
+		case session.TodoInProgress:
+			fmt.Printf("%s[~] %s %s\n", indent, output.Bold(t.Content), output.Dim("in progress since "+formatTodoTime(t.StartedAt)))
+		default:
+			fmt.Printf("%s[ ] %s\n", indent, t.Content)
+		}
+	}
+}
+
+func formatTodoTime(t time.Time) string {
+	if t.IsZero() {
+		return "at an unknown time"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
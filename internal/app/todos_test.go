@@ -0,0 +1,123 @@
+//go:build darwin || linux
+
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func todoWrite(id, at, items string) string {
+	return `{"type":"assistant","timestamp":"` + at + `","message":{"role":"assistant","content":[{"type":"tool_use","id":"` + id + `","name":"TodoWrite","input":{"todos":[` + items + `]}}]}}`
+}
+
+func writeTodoFixtures(t *testing.T, home string) {
+	t.Helper()
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-todo")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, filepath.Join(projDir, "todo1111-0000-0000-0000-000000000000.jsonl"), []string{
+		`{"type":"user","cwd":"/Users/test/todo","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"ship the parser"}}`,
+		todoWrite("t1", "2026-03-01T10:00:01Z", `{"content":"write parser","status":"in_progress","activeForm":"Writing parser"},{"content":"add tests","status":"pending","activeForm":"Adding tests"}`),
+		todoWrite("t2", "2026-03-01T10:20:00Z", `{"content":"write parser","status":"completed","activeForm":"Writing parser"},{"content":"add tests","status":"in_progress","activeForm":"Adding tests"}`),
+	})
+	writeLines(t, filepath.Join(projDir, "todo2222-0000-0000-0000-000000000000.jsonl"), []string{
+		`{"type":"user","cwd":"/Users/test/todo","timestamp":"2026-03-02T10:00:00Z","message":{"role":"user","content":"tidy up"}}`,
+		todoWrite("t3", "2026-03-02T10:00:01Z", `{"content":"tidy","status":"completed","activeForm":"Tidying"}`),
+	})
+}
+
+func TestTodosCmd(t *testing.T) {
+	home := setupFixtures(t)
+	writeTodoFixtures(t, home)
+
+	out := captureStdout(t, func() {
+		cmd := &TodosCmd{ID: "todo1111"}
+		if err := cmd.Run(&Globals{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "[x] write parser") || !strings.Contains(out, "[~] add tests") || !strings.Contains(out, "2 updates") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		cmd := &TodosCmd{ID: "todo1111", History: true}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var res todosOutput
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(res.States) != 2 || len(res.Todos) != 2 || res.Todos[0].CompletedAt.IsZero() {
+		t.Errorf("todos = %+v, states = %d", res.Todos, len(res.States))
+	}
+}
+
+func TestTodosCmd_OpenAcrossSessions(t *testing.T) {
+	home := setupFixtures(t)
+	writeTodoFixtures(t, home)
+
+	out := captureStdout(t, func() {
+		cmd := &TodosCmd{Open: true, Limit: 20}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var groups []openTodosGroup
+	if err := json.Unmarshal([]byte(out), &groups); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(groups) != 1 || groups[0].Session.ShortID != "todo1111" {
+		t.Fatalf("groups = %+v, want only todo1111", groups)
+	}
+	if len(groups[0].Todos) != 1 || groups[0].Todos[0].Content != "add tests" || groups[0].Todos[0].Status != "in_progress" {
+		t.Errorf("todos = %+v", groups[0].Todos)
+	}
+
+	if err := (&TodosCmd{}).Run(&Globals{}); err == nil {
+		t.Error("expected an error without an ID or --open")
+	}
+}
+
+func TestTodosCmd_OpenResumedThenCompleted(t *testing.T) {
+	home := setupFixtures(t)
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-todo")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	lines := []string{
+		`{"type":"user","uuid":"aaaa0000-0000-0000-0000-000000000001","cwd":"/Users/test/todo","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"ship the parser"}}`,
+		`{"type":"assistant","uuid":"aaaa0000-0000-0000-0000-000000000002","parentUuid":"aaaa0000-0000-0000-0000-000000000001","timestamp":"2026-03-01T10:00:01Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"TodoWrite","input":{"todos":[{"content":"write parser","status":"pending","activeForm":"Writing parser"}]}}]}}`,
+	}
+	original := filepath.Join(projDir, "todo3333-0000-0000-0000-000000000000.jsonl")
+	writeLines(t, original, lines)
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(original, old, old); err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, filepath.Join(projDir, "todo4444-0000-0000-0000-000000000000.jsonl"), append(lines,
+		`{"type":"assistant","uuid":"aaaa0000-0000-0000-0000-000000000003","parentUuid":"aaaa0000-0000-0000-0000-000000000002","timestamp":"2026-03-02T10:00:00Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"TodoWrite","input":{"todos":[{"content":"write parser","status":"completed","activeForm":"Writing parser"}]}}]}}`,
+	))
+
+	out := captureStdout(t, func() {
+		cmd := &TodosCmd{Open: true, Limit: 20}
+		if err := cmd.Run(&Globals{JSON: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var groups []openTodosGroup
+	if err := json.Unmarshal([]byte(out), &groups); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(groups) != 0 {
+		t.Errorf("groups = %+v, want none: the resumed session completed every item", groups)
+	}
+}
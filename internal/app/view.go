@@ -1,19 +1,32 @@
 package app
 
 import (
+	"fmt"
+
 	"github.com/andyhtran/cct/internal/session"
 	"github.com/andyhtran/cct/internal/tui"
 )
 
 type ViewCmd struct {
-	ID string `arg:"" help:"Session ID or prefix"`
+	ID       string `arg:"" help:"Session ID or prefix, or a match ref from cct search (<session>#<message>) to open scrolled to it"`
+	Agents   bool   `help:"Start with subagent transcripts expanded at their spawn point (toggle with 'a')"`
+	Thinking bool   `help:"Start with the model's thinking blocks shown (toggle with 't')"`
 }
 
 func (cmd *ViewCmd) Run(globals *Globals) error {
-	s, err := session.FindByPrefixFull(cmd.ID)
+	s, anchor, err := findSessionRef(globals, cmd.ID)
 	if err != nil {
 		return err
 	}
 
-	return tui.Run(s)
+	spawns, err := session.LinkSpawns(s)
+	if err != nil {
+		return fmt.Errorf("link subagents: %w", err)
+	}
+	return tui.Run(s, tui.Options{
+		Agents:       session.SpawnsByToolUse(spawns),
+		ExpandAgents: cmd.Agents,
+		Thinking:     cmd.Thinking,
+		Anchor:       anchor,
+	})
 }
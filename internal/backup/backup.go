@@ -44,6 +44,11 @@ type Options struct {
 
 	// Progress receives human-readable status lines. Nil silences output.
 	Progress io.Writer
+
+	// Root names the Claude data root being swept (see paths.Roots). SweepAt
+	// keys manifest entries by it and only judges orphans among its own
+	// entries; Sweep restricts itself to this root when non-empty.
+	Root string
 }
 
 type SweepResult struct {
@@ -57,6 +62,17 @@ type SweepResult struct {
 	Errors        []error `json:"errors,omitempty"`
 }
 
+func (r *SweepResult) add(o *SweepResult) {
+	r.Linked += o.Linked
+	r.Copied += o.Copied
+	r.Relinked += o.Relinked
+	r.Unchanged += o.Unchanged
+	r.SkippedActive += o.SkippedActive
+	r.SkippedError += o.SkippedError
+	r.Orphaned += o.Orphaned
+	r.Errors = append(r.Errors, o.Errors...)
+}
+
 func (r *SweepResult) Summary() string {
 	var parts []string
 	if r.Linked > 0 {
@@ -107,11 +123,27 @@ func DiscoverBackupSources() []string {
 	return out
 }
 
-// Sweep walks ~/.claude/projects/ and hardlinks any JSONL files that aren't
-// already backed up with the current inode+size. It is idempotent — re-running
+// Sweep walks the projects/ tree of every configured root (or only
+// opts.Root when set) and hardlinks any JSONL files that aren't already
+// backed up with the current inode+size. It is idempotent — re-running
 // with no source changes is ~free (stat calls only).
 func Sweep(opts Options) (*SweepResult, error) {
-	return SweepAt(paths.ProjectsDir(), paths.BackupProjectsDir(), paths.BackupManifestPath(), opts)
+	total := &SweepResult{}
+	for _, r := range paths.Roots() {
+		if opts.Root != "" && r.Name != opts.Root {
+			continue
+		}
+		o := opts
+		o.Root = r.Name
+		result, err := SweepAt(r.ProjectsDir(), r.BackupProjectsDir(), paths.BackupManifestPath(), o)
+		if result != nil {
+			total.add(result)
+		}
+		if err != nil {
+			return total, fmt.Errorf("root %s: %w", r.Name, err)
+		}
+	}
+	return total, nil
 }
 
 // SweepAt lets tests inject alternate directories without stomping on HOME.
@@ -139,12 +171,12 @@ func SweepAt(sourceRoot, backupRoot, manifestPath string, opts Options) (*SweepR
 	files := discoverSources(sourceRoot, opts.IncludeAgents)
 	result := &SweepResult{}
 
-	seenIDs := make(map[string]bool, len(files))
+	seenKeys := make(map[string]bool, len(files))
 	for _, source := range files {
-		id := session.ExtractIDFromFilename(source)
-		seenIDs[id] = true
+		key := EntryKey(opts.Root, session.ExtractIDFromFilename(source))
+		seenKeys[key] = true
 
-		outcome, err := processOne(source, id, backupRoot, manifest, opts)
+		outcome, err := processOne(source, key, sourceRoot, backupRoot, manifest, opts)
 		if err != nil {
 			result.SkippedError++
 			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", source, err))
@@ -157,10 +189,16 @@ func SweepAt(sourceRoot, backupRoot, manifestPath string, opts Options) (*SweepR
 	// tree. We keep the entry and the backup file — that's the whole point
 	// of the backup. We only update SourceDeletedAt on the first sweep that
 	// notices the gap. Scope the check to the same filter discovery used:
-	// agent entries aren't orphaned just because this sweep excluded them.
+	// agent entries aren't orphaned just because this sweep excluded them,
+	// and another root's entries aren't orphaned because this sweep only
+	// walked one root.
 	now := time.Now()
+	tag := rootTag(opts.Root)
 	for id := range manifest.Entries {
-		if seenIDs[id] {
+		if seenKeys[id] {
+			continue
+		}
+		if manifest.Entries[id].Root != tag {
 			continue
 		}
 		if !opts.IncludeAgents && manifest.Entries[id].IsSubagent {
@@ -207,7 +245,7 @@ func applyOutcome(r *SweepResult, o outcome) {
 	}
 }
 
-func processOne(source, id, backupRoot string, manifest *Manifest, opts Options) (outcome, error) {
+func processOne(source, key, sourceRoot, backupRoot string, manifest *Manifest, opts Options) (outcome, error) {
 	info, err := os.Stat(source)
 	if err != nil {
 		return 0, fmt.Errorf("stat: %w", err)
@@ -220,10 +258,10 @@ func processOne(source, id, backupRoot string, manifest *Manifest, opts Options)
 	ino := inodeOf(info)
 	size := info.Size()
 	now := time.Now()
-	backupPath := backupPathFor(source, backupRoot)
-	isSubagent := session.IsAgentSession(id)
+	backupPath := backupPathFor(source, sourceRoot, backupRoot)
+	isSubagent := session.IsAgentSession(session.ExtractIDFromFilename(source))
 
-	existing, present := manifest.Entries[id]
+	existing, present := manifest.Entries[key]
 
 	if present && existing.Inode == ino && existing.Size == size && existing.CopyMode == CopyModeHardlink {
 		if _, err := os.Stat(existing.BackupPath); err == nil {
@@ -233,7 +271,7 @@ func processOne(source, id, backupRoot string, manifest *Manifest, opts Options)
 			// from a prior orphan-detecting sweep (e.g. a --no-agents sweep
 			// that excluded this entry, or a brief disappearance).
 			existing.SourceDeletedAt = time.Time{}
-			manifest.Entries[id] = existing
+			manifest.Entries[key] = existing
 			return outcomeUnchanged, nil
 		}
 	}
@@ -255,6 +293,7 @@ func processOne(source, id, backupRoot string, manifest *Manifest, opts Options)
 		Size:             size,
 		CopyMode:         mode,
 		IsSubagent:       isSubagent,
+		Root:             rootTag(opts.Root),
 		LastVerifiedAt:   now,
 		SourceLastSeenAt: now,
 	}
@@ -264,7 +303,7 @@ func processOne(source, id, backupRoot string, manifest *Manifest, opts Options)
 	if entry.FirstBackedUpAt.IsZero() {
 		entry.FirstBackedUpAt = now
 	}
-	manifest.Entries[id] = entry
+	manifest.Entries[key] = entry
 
 	switch {
 	case !present && mode == CopyModeHardlink:
@@ -339,10 +378,9 @@ func atomicCopy(source, dest string) error {
 
 // backupPathFor mirrors the source layout under backupRoot so restore is a
 // straightforward reverse operation. Accepts any path that's a descendant of
-// ~/.claude/projects (the normal case) and any path otherwise (for tests).
-func backupPathFor(source, backupRoot string) string {
-	projectsDir := paths.ProjectsDir()
-	if rel, err := filepath.Rel(projectsDir, source); err == nil && !strings.HasPrefix(rel, "..") {
+// sourceRoot (the normal case) and any path otherwise (for tests).
+func backupPathFor(source, sourceRoot, backupRoot string) string {
+	if rel, err := filepath.Rel(sourceRoot, source); err == nil && !strings.HasPrefix(rel, "..") {
 		return filepath.Join(backupRoot, rel)
 	}
 	return filepath.Join(backupRoot, filepath.Base(source))
@@ -358,12 +396,14 @@ func inodeOf(info os.FileInfo) uint64 {
 	return 0
 }
 
-// discoverSources reuses session.DiscoverFiles when operating on the real
-// ProjectsDir, otherwise walks a custom root (tests only). Subagent layout
-// mirrors session.discoverNestedSubagents.
+// discoverSources reuses session.DiscoverFiles when operating on a
+// configured root's projects dir, otherwise walks a custom root (tests
+// only). Subagent layout mirrors session.discoverNestedSubagents.
 func discoverSources(sourceRoot string, includeAgents bool) []string {
-	if sourceRoot == paths.ProjectsDir() {
-		return session.DiscoverFiles("", includeAgents)
+	for _, r := range paths.Roots() {
+		if sourceRoot == r.ProjectsDir() {
+			return session.DiscoverFiles(r.Name, "", includeAgents)
+		}
 	}
 	return walkCustomRoot(sourceRoot, includeAgents)
 }
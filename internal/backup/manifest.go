@@ -7,7 +7,10 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/andyhtran/cct/internal/paths"
 )
 
 // manifestVersion is bumped only when the on-disk format changes in a
@@ -36,6 +39,7 @@ type Entry struct {
 	Size             int64     `json:"size"`
 	CopyMode         CopyMode  `json:"copy_mode"`
 	IsSubagent       bool      `json:"is_subagent"`
+	Root             string    `json:"root,omitempty"` // empty for the default root
 	FirstBackedUpAt  time.Time `json:"first_backed_up_at"`
 	LastVerifiedAt   time.Time `json:"last_verified_at"`
 	SourceLastSeenAt time.Time `json:"source_last_seen_at"`
@@ -44,11 +48,65 @@ type Entry struct {
 
 type Manifest struct {
 	Version int              `json:"version"`
-	Entries map[string]Entry `json:"entries"` // keyed by session ID (UUID)
+	Entries map[string]Entry `json:"entries"` // keyed by EntryKey(root, session ID)
 
 	path string `json:"-"`
 }
 
+// EntryKey is the manifest key for a session. Sessions from ~/.claude keep
+// the bare session ID, so manifests written before multiple roots existed stay
+// valid; other roots prefix it with "<root>/".
+func EntryKey(root, id string) string {
+	if tag := rootTag(root); tag != "" {
+		return tag + "/" + id
+	}
+	return id
+}
+
+// SplitEntryKey reverses EntryKey, returning the root ("" for the default
+// root) and the bare session ID.
+func SplitEntryKey(key string) (root, id string) {
+	if r, i, ok := strings.Cut(key, "/"); ok {
+		return r, i
+	}
+	return "", key
+}
+
+// rootTag maps the root that owns the legacy backup location (~/.claude,
+// whatever it is named) to "" so entries written before roots existed and
+// entries written after compare equal.
+func rootTag(root string) string {
+	if root == "" {
+		return ""
+	}
+	if r, ok := paths.LookupRoot(root); ok && r.BackupProjectsDir() == paths.BackupProjectsDir() {
+		return ""
+	}
+	return root
+}
+
+// Lookup finds the entry for a session ID. A root-qualified key
+// ("work/<id>") or a default-root ID matches directly; otherwise a bare ID
+// matches an entry in any root when exactly one root has it.
+func (m *Manifest) Lookup(id string) (Entry, string, bool) {
+	if e, ok := m.Entries[id]; ok {
+		return e, id, true
+	}
+	var found string
+	for key := range m.Entries {
+		if _, bare := SplitEntryKey(key); bare == id {
+			if found != "" {
+				return Entry{}, "", false
+			}
+			found = key
+		}
+	}
+	if found == "" {
+		return Entry{}, "", false
+	}
+	return m.Entries[found], found, true
+}
+
 func newManifest(path string) *Manifest {
 	return &Manifest{
 		Version: manifestVersion,
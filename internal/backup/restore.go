@@ -57,8 +57,9 @@ func RestoreAt(manifestPath string, opts RestoreOptions) (*RestoreResult, error)
 	}
 
 	result := &RestoreResult{}
+	restored := make([]string, 0, len(opts.SessionIDs))
 	for _, id := range opts.SessionIDs {
-		entry, ok := manifest.Entries[id]
+		entry, key, ok := manifest.Lookup(id)
 		if !ok {
 			result.Skipped++
 			if opts.Progress != nil {
@@ -99,6 +100,7 @@ func RestoreAt(manifestPath string, opts RestoreOptions) (*RestoreResult, error)
 			continue
 		}
 		result.Restored++
+		restored = append(restored, key)
 		if opts.Progress != nil {
 			_, _ = fmt.Fprintf(opts.Progress, "restored %s\n", entry.SourcePath)
 		}
@@ -106,15 +108,12 @@ func RestoreAt(manifestPath string, opts RestoreOptions) (*RestoreResult, error)
 
 	if !opts.DryRun && result.Restored > 0 {
 		now := time.Now()
-		for _, id := range opts.SessionIDs {
-			entry, ok := manifest.Entries[id]
-			if !ok {
-				continue
-			}
+		for _, key := range restored {
+			entry := manifest.Entries[key]
 			entry.LastVerifiedAt = now
 			entry.SourceLastSeenAt = now
 			entry.SourceDeletedAt = time.Time{}
-			manifest.Entries[id] = entry
+			manifest.Entries[key] = entry
 		}
 		if err := manifest.Save(); err != nil {
 			return result, fmt.Errorf("save manifest: %w", err)
@@ -50,22 +50,43 @@ type Status struct {
 	TotalBackupSize int64           `json:"total_backup_size,omitempty"`
 }
 
-// BuildStatus classifies every session ID in the manifest and live tree.
-// Production callers use this wrapper; tests inject paths via BuildStatusAt.
-func BuildStatus() (*Status, error) {
-	return BuildStatusAt(paths.BackupManifestPath(), paths.ProjectsDir(), paths.BackupDir())
+// BuildStatus classifies every session in the manifest and the live tree of
+// every configured root. A non-empty root restricts the report to that
+// root. Production callers use this wrapper; tests inject paths via
+// BuildStatusAt.
+func BuildStatus(root string) (*Status, error) {
+	live := make(map[string]string)
+	for _, r := range paths.Roots() {
+		if root != "" && r.Name != root {
+			continue
+		}
+		for _, path := range discoverSources(r.ProjectsDir(), true) {
+			live[EntryKey(r.Name, session.ExtractIDFromFilename(path))] = path
+		}
+	}
+	keep := func(Entry) bool { return true }
+	if root != "" {
+		tag := rootTag(root)
+		keep = func(e Entry) bool { return e.Root == tag }
+	}
+	return buildStatus(paths.BackupManifestPath(), paths.BackupDir(), live, keep)
 }
 
 func BuildStatusAt(manifestPath, sourceRoot, backupDir string) (*Status, error) {
-	manifest, err := LoadManifest(manifestPath)
-	if err != nil {
-		return nil, err
-	}
-
 	liveByID := make(map[string]string)
 	for _, path := range discoverSources(sourceRoot, true) {
 		liveByID[session.ExtractIDFromFilename(path)] = path
 	}
+	return buildStatus(manifestPath, backupDir, liveByID, func(Entry) bool { return true })
+}
+
+// buildStatus joins manifest entries (filtered by keep) against live files
+// keyed the same way as the manifest (see EntryKey).
+func buildStatus(manifestPath, backupDir string, liveByID map[string]string, keep func(Entry) bool) (*Status, error) {
+	manifest, err := LoadManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
 
 	status := &Status{
 		ManifestPath: manifestPath,
@@ -77,6 +98,9 @@ func BuildStatusAt(manifestPath, sourceRoot, backupDir string) (*Status, error)
 	seen := make(map[string]bool, len(manifest.Entries))
 	for id := range manifest.Entries {
 		entry := manifest.Entries[id]
+		if !keep(entry) {
+			continue
+		}
 		ss := classifyManifestEntry(id, entry, liveByID)
 		status.Counts[string(ss.Status)]++
 		status.Sessions = append(status.Sessions, ss)
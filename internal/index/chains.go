@@ -0,0 +1,166 @@
+package index
+
+import (
+	"sort"
+
+	"github.com/andyhtran/cct/internal/session"
+)
+
+// Chains returns every conversation that `claude --resume` or `--continue`
+// split across more than one session file, each oldest first. Two sessions
+// chain when they hold the same message uuid (a resume replays the earlier
+// messages) or one points at a message the other holds (parentUuid,
+// logicalParentUuid, or a summary record's leafUuid). Sub-agents are never
+// chained.
+func (idx *Index) Chains(root string) ([][]*session.Session, error) {
+	idx.syncForRead()
+
+	rows, err := idx.db.Query(`
+		SELECT l.root, l.uuid, l.session_id
+		FROM lineage l
+		JOIN (
+			SELECT root, uuid FROM lineage
+			WHERE (? = '' OR root = ?)
+			GROUP BY root, uuid
+			HAVING COUNT(DISTINCT session_id) > 1 AND SUM(kind = 'message') > 0
+		) shared ON shared.root = l.root AND shared.uuid = l.uuid
+		ORDER BY l.root, l.uuid
+	`, root, root)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	parent := map[string]string{}
+	var find func(string) string
+	find = func(k string) string {
+		p, ok := parent[k]
+		if !ok || p == k {
+			parent[k] = k
+			return k
+		}
+		p = find(p)
+		parent[k] = p
+		return p
+	}
+
+	var group, first string
+	for rows.Next() {
+		var r, uuid, id string
+		if err := rows.Scan(&r, &uuid, &id); err != nil {
+			return nil, err
+		}
+		key := r + "/" + id
+		if g := r + "/" + uuid; g != group {
+			group, first = g, key
+			find(key)
+			continue
+		}
+		parent[find(key)] = find(first)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(parent) == 0 {
+		return nil, nil
+	}
+
+	sessions, err := idx.querySessions(`
+		SELECT `+sessionColumns+`
+		FROM sessions s
+		WHERE (? = '' OR s.root = ?) AND s.is_agent = 0
+	`, root, root)
+	if err != nil {
+		return nil, err
+	}
+	members := map[string][]*session.Session{}
+	for _, s := range sessions {
+		key := s.Root + "/" + s.ID
+		if _, ok := parent[key]; ok {
+			r := find(key)
+			members[r] = append(members[r], s)
+		}
+	}
+
+	var chains [][]*session.Session
+	for _, chain := range members {
+		if len(chain) > 1 {
+			sortChain(chain)
+			chains = append(chains, chain)
+		}
+	}
+	sort.Slice(chains, func(i, j int) bool {
+		return chains[i][0].End().Before(chains[j][0].End())
+	})
+	return chains, nil
+}
+
+// ChainOf returns the chain holding the session root/id, oldest first, or
+// nil when the session stands alone.
+func (idx *Index) ChainOf(root, id string) ([]*session.Session, error) {
+	idx.syncForRead()
+
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		rows, err := idx.db.Query(`
+			SELECT DISTINCT o.session_id
+			FROM lineage l
+			JOIN lineage o ON o.root = l.root AND o.uuid = l.uuid AND o.session_id != l.session_id
+			WHERE l.root = ? AND l.session_id = ?
+			  AND (l.kind = 'message' OR o.kind = 'message')
+		`, root, current)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var other string
+			if err := rows.Scan(&other); err != nil {
+				_ = rows.Close()
+				return nil, err
+			}
+			if !seen[other] {
+				seen[other] = true
+				queue = append(queue, other)
+			}
+		}
+		_ = rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if len(seen) < 2 {
+		return nil, nil
+	}
+
+	var chain []*session.Session
+	for other := range seen {
+		s, err := idx.Session(root, other)
+		if err != nil {
+			continue
+		}
+		if !s.IsAgent {
+			chain = append(chain, s)
+		}
+	}
+	if len(chain) < 2 {
+		return nil, nil
+	}
+	sortChain(chain)
+	return chain, nil
+}
+
+// sortChain orders a chain's sessions by when each ended. Start times
+// don't work: a resumed file replays the earlier messages with their
+// original timestamps.
+func sortChain(chain []*session.Session) {
+	sort.SliceStable(chain, func(i, j int) bool {
+		a, b := chain[i].End(), chain[j].End()
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return chain[i].ID < chain[j].ID
+	})
+}
@@ -0,0 +1,157 @@
+package index
+
+import (
+	"strings"
+	"time"
+
+	"github.com/andyhtran/cct/internal/session"
+)
+
+// CommitRow is an indexed git commit joined to the session that made it.
+// Offset is the byte offset of the assistant record that ran the commit.
+type CommitRow struct {
+	Session *session.Session
+	SHA     string
+	Branch  string
+	Subject string
+	At      time.Time
+	Offset  int64
+}
+
+// FileEditRow is an indexed Edit/MultiEdit/Write/NotebookEdit call.
+type FileEditRow struct {
+	Session *session.Session
+	Tool    string
+	Path    string
+	At      time.Time
+	Offset  int64
+}
+
+// CommitsBySHA returns commits whose recorded SHA and sha share a prefix,
+// so a short SHA from git's summary line matches a full one and vice versa.
+func (idx *Index) CommitsBySHA(root, sha string) ([]CommitRow, error) {
+	idx.syncForRead()
+
+	sha = strings.ToLower(sha)
+	return idx.queryCommits(`
+		WHERE c.sha != ''
+		  AND (c.sha LIKE ? || '%' ESCAPE '\' OR ? LIKE c.sha || '%')
+		  AND (? = '' OR s.root = ?)
+	`, likeEscaper.Replace(sha), sha, root, root)
+}
+
+// CommitsBySubject returns commits recorded without a SHA (git commit -q)
+// whose subject matches, committed within [since, until).
+func (idx *Index) CommitsBySubject(root, subject string, since, until time.Time) ([]CommitRow, error) {
+	idx.syncForRead()
+
+	return idx.queryCommits(`
+		WHERE c.sha = '' AND c.subject = ?
+		  AND julianday(c.committed_at) >= julianday(?)
+		  AND julianday(c.committed_at) < julianday(?)
+		  AND (? = '' OR s.root = ?)
+	`, subject, formatMessageTime(since), formatMessageTime(until), root, root)
+}
+
+// queryCommits returns the commits matching where, newest first. A resumed
+// session file repeats the earlier file's commit calls under the same
+// tool_use_id; only the copy in the file modified first is returned. The
+// copies match where alike, so filtering before numbering them is safe.
+func (idx *Index) queryCommits(where string, args ...any) ([]CommitRow, error) {
+	rows, err := idx.db.Query(`
+		SELECT `+sessionColumns+`, c.sha, c.branch, c.subject, c.committed_at, c.byte_offset
+		FROM (
+			SELECT c.*, ROW_NUMBER() OVER (
+				PARTITION BY c.root, c.tool_use_id ORDER BY julianday(s.modified_at), s.id
+			) AS copy
+			FROM commits c
+			JOIN sessions s ON c.root = s.root AND c.session_id = s.id
+			`+where+`
+		) c
+		JOIN sessions s ON c.root = s.root AND c.session_id = s.id
+		WHERE c.copy = 1 OR c.tool_use_id = ''
+		ORDER BY c.committed_at DESC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []CommitRow
+	for rows.Next() {
+		var r CommitRow
+		var at string
+		r.Session, err = scanSession(rows, &r.SHA, &r.Branch, &r.Subject, &at, &r.Offset)
+		if err != nil {
+			return nil, err
+		}
+		r.At, _ = time.Parse(time.RFC3339Nano, at)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// likeEscaper escapes the LIKE wildcards in a pattern used with ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// FileEditFilter selects FileEdits. Path is matched exactly; when Suffix is
+// set, any path ending in "/"+Suffix also matches, which catches edits made
+// in another checkout or worktree of the same repository.
+type FileEditFilter struct {
+	Root   string
+	Path   string
+	Suffix string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+// FileEdits returns matching file edits, newest first. Like commits, an
+// edit a resumed session file repeats is returned once, from the file
+// modified first.
+func (idx *Index) FileEdits(f FileEditFilter) ([]FileEditRow, error) {
+	idx.syncForRead()
+
+	suffix := ""
+	if f.Suffix != "" {
+		suffix = "%/" + likeEscaper.Replace(f.Suffix)
+	}
+	since, until := formatMessageTime(f.Since), formatMessageTime(f.Until)
+	query := `
+		SELECT ` + sessionColumns + `, e.tool, e.path, e.edited_at, e.byte_offset
+		FROM (
+			SELECT e.*, ROW_NUMBER() OVER (
+				PARTITION BY e.root, e.tool_use_id ORDER BY julianday(s.modified_at), s.id
+			) AS copy
+			FROM file_edits e
+			JOIN sessions s ON e.root = s.root AND e.session_id = s.id
+			WHERE (e.path = ? OR (? != '' AND e.path LIKE ? ESCAPE '\'))
+			  AND (? = '' OR s.root = ?)
+			  AND (? = '' OR julianday(e.edited_at) >= julianday(?))
+			  AND (? = '' OR julianday(e.edited_at) < julianday(?))
+		) e
+		JOIN sessions s ON e.root = s.root AND e.session_id = s.id
+		WHERE e.copy = 1 OR e.tool_use_id = ''
+		ORDER BY e.edited_at DESC` + limitClause(f.Limit)
+	args := []any{f.Path, suffix, suffix, f.Root, f.Root, since, since, until, until}
+	args = appendLimit(args, f.Limit)
+
+	rows, err := idx.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []FileEditRow
+	for rows.Next() {
+		var r FileEditRow
+		var at string
+		r.Session, err = scanSession(rows, &r.Tool, &r.Path, &at, &r.Offset)
+		if err != nil {
+			return nil, err
+		}
+		r.At, _ = time.Parse(time.RFC3339Nano, at)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
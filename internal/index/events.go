@@ -0,0 +1,84 @@
+package index
+
+import (
+	"strings"
+	"time"
+)
+
+// EventFilter narrows the per-message queries (ToolCalls, MessageTimes).
+// Zero Since/Until leave that side of the window open; the window applies
+// to each event's own timestamp, not the session's.
+type EventFilter struct {
+	Root          string
+	ProjectFilter string
+	IncludeAgents bool
+	Since         time.Time
+	Until         time.Time
+}
+
+// where returns the WHERE clause for f against sessions s and the event
+// timestamp column col, plus its arguments.
+func (f EventFilter) where(col string) (string, []any) {
+	projectFilter := strings.ToLower(f.ProjectFilter)
+	since, until := formatMessageTime(f.Since), formatMessageTime(f.Until)
+	clause := `
+		WHERE (? = 1 OR s.is_agent = 0)
+		  AND (? = '' OR LOWER(s.project_dir) LIKE '%' || ? || '%')
+		  AND (? = '' OR s.root = ?)
+		  AND (? = '' OR julianday(` + col + `) >= julianday(?))
+		  AND (? = '' OR julianday(` + col + `) < julianday(?))`
+	args := []any{
+		boolToInt(f.IncludeAgents), projectFilter, projectFilter, f.Root, f.Root,
+		since, since, until, until,
+	}
+	return clause, args
+}
+
+// MessageTime is the timestamp of one indexed user or assistant record.
+type MessageTime struct {
+	Root      string
+	SessionID string
+	Project   string
+	Role      string
+	At        time.Time
+}
+
+// MessageTimes returns the timestamp of every indexed message matching f,
+// grouped by session and oldest first within each session. A resumed
+// session file repeats the earlier file's messages under the same uuids;
+// only the copy in the file modified first is returned.
+func (idx *Index) MessageTimes(f EventFilter) ([]MessageTime, error) {
+	idx.syncForRead()
+
+	where, args := f.where("m.at")
+	rows, err := idx.db.Query(`
+		SELECT s.root, s.id, s.project_name, m.role, m.at
+		FROM (
+			SELECT m.*, ROW_NUMBER() OVER (
+				PARTITION BY m.root, m.uuid ORDER BY julianday(s.modified_at), s.id
+			) AS copy
+			FROM message_times m
+			JOIN sessions s ON m.root = s.root AND m.session_id = s.id
+		) m
+		JOIN sessions s ON m.root = s.root AND m.session_id = s.id
+		`+where+`
+		  AND (m.copy = 1 OR m.uuid = '')
+		ORDER BY s.root, s.id, m.at
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []MessageTime
+	for rows.Next() {
+		var m MessageTime
+		var at string
+		if err := rows.Scan(&m.Root, &m.SessionID, &m.Project, &m.Role, &at); err != nil {
+			return nil, err
+		}
+		m.At, _ = time.Parse(time.RFC3339Nano, at)
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
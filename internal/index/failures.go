@@ -0,0 +1,55 @@
+package index
+
+import (
+	"time"
+
+	"github.com/andyhtran/cct/internal/session"
+)
+
+// FailureRow is an indexed failure joined to the session it happened in.
+type FailureRow struct {
+	Session *session.Session
+	Failure session.Failure
+}
+
+// Failures returns every indexed failure matching f, oldest first. A
+// resumed session file replays the earlier file's failures with their
+// original timestamps; only the copy in the file modified first is
+// returned.
+func (idx *Index) Failures(f EventFilter) ([]FailureRow, error) {
+	idx.syncForRead()
+
+	where, args := f.where("x.at")
+	rows, err := idx.db.Query(`
+		SELECT `+sessionColumns+`, x.kind, x.tool, x.input, x.signature, x.text, x.at, x.byte_offset
+		FROM (
+			SELECT x.*, ROW_NUMBER() OVER (
+				PARTITION BY x.root, x.at, x.kind, x.text ORDER BY julianday(s.modified_at), s.id
+			) AS copy
+			FROM failures x
+			JOIN sessions s ON x.root = s.root AND x.session_id = s.id
+		) x
+		JOIN sessions s ON x.root = s.root AND x.session_id = s.id
+		`+where+`
+		  AND (x.copy = 1 OR COALESCE(x.at, '') = '')
+		ORDER BY x.at, s.root, s.id, x.byte_offset
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []FailureRow
+	for rows.Next() {
+		var r FailureRow
+		var at string
+		fl := &r.Failure
+		r.Session, err = scanSession(rows, &fl.Kind, &fl.Tool, &fl.Input, &fl.Signature, &fl.Text, &at, &fl.Offset)
+		if err != nil {
+			return nil, err
+		}
+		fl.At, _ = time.Parse(time.RFC3339Nano, at)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,181 @@
+package index
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andyhtran/cct/internal/paths"
+	"github.com/andyhtran/cct/internal/session"
+)
+
+// HistoryFilter selects prompt history rows. An empty Query lists the most
+// recent prompts.
+type HistoryFilter struct {
+	Root          string
+	Query         string
+	ProjectFilter string
+	Since         time.Time
+	Until         time.Time
+	Limit         int
+}
+
+// HistoryRow is one indexed prompt history entry. Session is the session
+// the prompt was typed into when it is still indexed (live or in the
+// backup mirror), nil otherwise. Link says how it was found: "session_id"
+// from the entry itself, "time" from a session in the same project that
+// was active when the prompt was sent, or "" when none matched.
+type HistoryRow struct {
+	Root      string
+	Project   string
+	SessionID string
+	At        time.Time
+	Display   string
+	Session   *session.Session
+	Link      string
+}
+
+// historyLinkSlack widens a session's first-to-last message span when
+// matching prompts without a session ID: the prompt is logged as it is
+// sent, a moment before the message record.
+const historyLinkSlack = 2 * time.Minute
+
+// syncHistory indexes each root's history.jsonl when its size or mtime
+// changed since the last sync. Entries already indexed are skipped, so a
+// rewritten or trimmed file adds only what is new. A root whose history
+// can't be indexed is skipped with a warning; it never blocks the session
+// sync.
+func (idx *Index) syncHistory() {
+	for _, r := range paths.Roots() {
+		if err := idx.syncHistoryRoot(r); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: index prompt history for %s: %v\n", r.Name, err)
+		}
+	}
+}
+
+func (idx *Index) syncHistoryRoot(r paths.Root) error {
+	path := r.HistoryPath()
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	key := "history:" + r.Name
+	stamp := fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano())
+	var prev string
+	_ = idx.db.QueryRow("SELECT value FROM index_meta WHERE key = ?", key).Scan(&prev)
+	if prev == stamp {
+		return nil
+	}
+
+	entries, err := session.ReadHistory(path)
+	if err != nil {
+		return err
+	}
+	tx, err := idx.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, e := range entries {
+		res, err := tx.Exec(`
+			INSERT OR IGNORE INTO prompt_history (root, project, session_id, at, display)
+			VALUES (?, ?, ?, ?, ?)
+		`, r.Name, e.Project, e.SessionID, formatMessageTime(e.At), e.Display)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue
+		}
+		rowID, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO prompt_history_fts (rowid, text) VALUES (?, ?)", rowID, e.Text()); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("INSERT OR REPLACE INTO index_meta (key, value) VALUES (?, ?)", key, stamp); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SearchHistory returns matching prompt history entries, newest first,
+// each linked to its session where one can be found.
+func (idx *Index) SearchHistory(f HistoryFilter) ([]HistoryRow, error) {
+	idx.syncForRead()
+
+	projectFilter := strings.ToLower(f.ProjectFilter)
+	since, until := formatMessageTime(f.Since), formatMessageTime(f.Until)
+	query := `
+		SELECT h.root, h.project, h.session_id, h.at, h.display
+		FROM prompt_history h
+		WHERE (? = '' OR h.root = ?)
+		  AND (? = '' OR LOWER(h.project) LIKE '%' || ? || '%')
+		  AND (? = '' OR julianday(h.at) >= julianday(?))
+		  AND (? = '' OR julianday(h.at) < julianday(?))`
+	args := []any{f.Root, f.Root, projectFilter, projectFilter, since, since, until, until}
+	if match := buildFTSQuery(f.Query); match != "" {
+		query += `
+		  AND h.rowid IN (SELECT rowid FROM prompt_history_fts WHERE prompt_history_fts MATCH ?)`
+		args = append(args, match)
+	}
+	query += `
+		ORDER BY h.at DESC` + limitClause(f.Limit)
+	args = appendLimit(args, f.Limit)
+
+	rows, err := idx.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var out []HistoryRow
+	for rows.Next() {
+		var r HistoryRow
+		var at string
+		if err := rows.Scan(&r.Root, &r.Project, &r.SessionID, &at, &r.Display); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		r.At, _ = time.Parse(time.RFC3339Nano, at)
+		out = append(out, r)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range out {
+		idx.linkHistory(&out[i])
+	}
+	return out, nil
+}
+
+// linkHistory finds the session a prompt belongs to: by its recorded
+// session ID, else the shortest session in the same project whose messages
+// span the prompt's time.
+func (idx *Index) linkHistory(r *HistoryRow) {
+	if r.SessionID != "" {
+		if s, err := idx.Session(r.Root, r.SessionID); err == nil {
+			r.Session, r.Link = s, "session_id"
+		}
+		return
+	}
+	if r.At.IsZero() || r.Project == "" {
+		return
+	}
+	sessions, err := idx.querySessions(`
+		SELECT `+sessionColumns+`
+		FROM sessions s
+		WHERE s.root = ? AND s.project_path = ? AND s.is_agent = 0
+		  AND s.first_message_at != '' AND s.last_message_at != ''
+		  AND julianday(s.first_message_at) <= julianday(?)
+		  AND julianday(s.last_message_at) >= julianday(?)
+		ORDER BY julianday(s.last_message_at) - julianday(s.first_message_at)
+		LIMIT 1
+	`, r.Root, r.Project, formatMessageTime(r.At.Add(historyLinkSlack)), formatMessageTime(r.At.Add(-historyLinkSlack)))
+	if err == nil && len(sessions) > 0 {
+		r.Session, r.Link = sessions[0], "time"
+	}
+}
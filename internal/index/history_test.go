@@ -0,0 +1,106 @@
+//go:build darwin || linux
+
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeHistory(t *testing.T, lines ...string) {
+	t.Helper()
+	path := filepath.Join(os.Getenv("HOME"), ".claude", "history.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSearchHistory(t *testing.T) {
+	idx := setupTestIndex(t)
+	// 1769932800000 is 2026-02-01T08:00:00Z.
+	writeHistory(t,
+		`{"display":"fix the pre-commit hook","pastedContents":{},"timestamp":1769932800000,"project":"/Users/test/myproject","sessionId":"aaaa1111-2222-3333-4444-555555555555"}`,
+		`{"display":"now add tests for the parser","pastedContents":{},"timestamp":1769932860000,"project":"/Users/test/myproject"}`,
+		`{"display":"rename the widget [Pasted text #1 +3 lines]","pastedContents":{"1":{"id":1,"type":"text","content":"type Gizmo struct{}"}},"timestamp":1769936400000,"project":"/Users/test/other","sessionId":"dead0000-0000-0000-0000-000000000000"}`,
+	)
+	if err := idx.ForceSync(true); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := idx.SearchHistory(HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0].Display != "rename the widget [Pasted text #1 +3 lines]" {
+		t.Fatalf("rows = %+v, want 3 newest first", rows)
+	}
+	if rows[0].Session != nil || rows[0].Link != "" {
+		t.Errorf("deleted session linked: %+v", rows[0])
+	}
+	if rows[1].Link != "time" || rows[1].Session == nil || rows[1].Session.ShortID != "aaaa1111" {
+		t.Errorf("prompt without session ID = %+v, want linked by time", rows[1])
+	}
+	if rows[2].Link != "session_id" || rows[2].Session == nil {
+		t.Errorf("prompt with session ID = %+v", rows[2])
+	}
+
+	// Pasted text is searchable; the project filter applies.
+	if rows, _ := idx.SearchHistory(HistoryFilter{Query: "gizmo"}); len(rows) != 1 {
+		t.Errorf("pasted text search got %d rows, want 1", len(rows))
+	}
+	if rows, _ := idx.SearchHistory(HistoryFilter{Query: "parser", ProjectFilter: "other"}); len(rows) != 0 {
+		t.Errorf("project filter got %d rows, want 0", len(rows))
+	}
+
+	// Prompts stay indexed after the file loses them.
+	writeHistory(t, `{"display":"a later prompt","timestamp":1770000000000,"project":"/Users/test/myproject"}`)
+	if err := idx.ForceSync(true); err != nil {
+		t.Fatal(err)
+	}
+	rows, err = idx.SearchHistory(HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 {
+		t.Errorf("got %d rows after the file was trimmed, want 4", len(rows))
+	}
+
+	// They survive a schema version bump and a rebuild too.
+	if _, err := idx.db.Exec("PRAGMA user_version = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.ensureSchema(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := idx.RebuildWithProgress(true, nil); err != nil {
+		t.Fatal(err)
+	}
+	if rows, _ := idx.SearchHistory(HistoryFilter{Query: "parser"}); len(rows) != 1 {
+		t.Errorf("got %d rows for a trimmed prompt after a schema bump and rebuild, want 1", len(rows))
+	}
+	if rows, _ := idx.SearchHistory(HistoryFilter{}); len(rows) != 4 {
+		t.Errorf("got %d rows after a schema bump and rebuild, want 4", len(rows))
+	}
+}
+
+func TestSyncHistory_UnreadableFileSkipped(t *testing.T) {
+	idx := setupTestIndex(t)
+	home := os.Getenv("HOME")
+	if err := os.Mkdir(filepath.Join(home, ".claude", "history.jsonl"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(home, ".claude", "projects", "-Users-test-myproject", "bbbb2222-0000-0000-0000-000000000000.jsonl")
+	line := `{"type":"user","message":{"role":"user","content":"a new session"},"cwd":"/Users/test/myproject","timestamp":"2026-02-02T08:00:00Z"}` + "\n"
+	if err := os.WriteFile(path, []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := idx.ForceSync(true); err != nil {
+		t.Fatalf("sync failed on an unreadable history file: %v", err)
+	}
+	if _, err := idx.FindByPrefix("", "bbbb2222"); err != nil {
+		t.Errorf("new session not indexed: %v", err)
+	}
+}
@@ -74,6 +74,7 @@ type IndexStatus struct {
 	TotalMessages  int       `json:"total_messages"`
 	LastSyncTime   time.Time `json:"last_sync_time"`
 	IndexSizeBytes int64     `json:"index_size_bytes"`
+	Thinking       bool      `json:"thinking"`
 }
 
 func (idx *Index) Status() (*IndexStatus, error) {
@@ -104,5 +105,6 @@ func (idx *Index) Status() (*IndexStatus, error) {
 		TotalMessages:  messages,
 		LastSyncTime:   lastSync,
 		IndexSizeBytes: size,
+		Thinking:       ThinkingEnabled(),
 	}, nil
 }
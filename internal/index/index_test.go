@@ -430,6 +430,61 @@ func TestSearch_ProjectFilter_NoMatch(t *testing.T) {
 	}
 }
 
+func TestSearch_MultipleRoots(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	workDir := filepath.Join(home, ".claude-work")
+	t.Setenv("CCT_ROOTS", "work="+workDir)
+
+	// The same session ID in both roots must index as two sessions.
+	const id = "dupe1111-2222-3333-4444-555555555555"
+	for _, dir := range []string{filepath.Join(home, ".claude"), workDir} {
+		projDir := filepath.Join(dir, "projects", "-Users-test-proj")
+		if err := os.MkdirAll(projDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeTestSession(t, projDir, id, []string{
+			`{"type":"user","message":{"role":"user","content":"rotate the signing keys"},"cwd":"/Users/test/proj","sessionId":"` + id + `","timestamp":"2026-02-01T08:00:00Z"}`,
+		})
+	}
+
+	idx, err := Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = idx.Close() })
+	if err := idx.ForceSync(true); err != nil {
+		t.Fatal(err)
+	}
+
+	results, total, err := idx.Search(SearchOptions{Query: "signing", IncludeAgents: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || total != 2 {
+		t.Fatalf("got %d results (total %d), want 2", len(results), total)
+	}
+	roots := map[string]bool{}
+	for _, r := range results {
+		roots[r.Root] = true
+		if len(r.Matches) != 1 {
+			t.Errorf("root %s: got %d matches, want 1", r.Root, len(r.Matches))
+		}
+	}
+	if !roots["default"] || !roots["work"] {
+		t.Errorf("roots = %v, want default and work", roots)
+	}
+
+	results, _, err = idx.Search(SearchOptions{Query: "signing", Root: "work", IncludeAgents: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Root != "work" {
+		t.Fatalf("Root filter: got %d results, want 1 from work", len(results))
+	}
+}
+
 func TestSearch_CompoundTermFiltering(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
@@ -0,0 +1,59 @@
+package index
+
+import (
+	"strings"
+	"time"
+
+	"github.com/andyhtran/cct/internal/session"
+)
+
+// LinkRow is an indexed link joined to the session it appeared in.
+type LinkRow struct {
+	SessionID string
+	Project   string
+	Link      session.Link
+}
+
+// Links returns every indexed link matching f whose URL, title, or
+// context contains query (all of them when query is empty), oldest first.
+// A resumed session file replays the earlier file's links with their
+// original timestamps; only the copy in the file modified first is
+// returned.
+func (idx *Index) Links(f EventFilter, query string) ([]LinkRow, error) {
+	idx.syncForRead()
+
+	where, args := f.where("l.at")
+	query = strings.ToLower(query)
+	rows, err := idx.db.Query(`
+		SELECT s.id, s.project_name, l.url, l.domain, l.title, l.source, l.context, l.at, l.byte_offset
+		FROM (
+			SELECT l.*, ROW_NUMBER() OVER (
+				PARTITION BY l.root, l.at, l.source, l.url ORDER BY julianday(s.modified_at), s.id
+			) AS copy
+			FROM links l
+			JOIN sessions s ON l.root = s.root AND l.session_id = s.id
+		) l
+		JOIN sessions s ON l.root = s.root AND l.session_id = s.id
+		`+where+`
+		  AND (l.copy = 1 OR COALESCE(l.at, '') = '')
+		  AND (? = '' OR LOWER(l.url) LIKE '%' || ? || '%' OR LOWER(l.title) LIKE '%' || ? || '%' OR LOWER(l.context) LIKE '%' || ? || '%')
+		ORDER BY l.at, s.root, s.id, l.byte_offset
+	`, append(args, query, query, query, query)...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []LinkRow
+	for rows.Next() {
+		var r LinkRow
+		var at string
+		l := &r.Link
+		if err := rows.Scan(&r.SessionID, &r.Project, &l.URL, &l.Domain, &l.Title, &l.Source, &l.Context, &at, &l.Offset); err != nil {
+			return nil, err
+		}
+		l.At, _ = time.Parse(time.RFC3339Nano, at)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
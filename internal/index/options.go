@@ -0,0 +1,197 @@
+package index
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/andyhtran/cct/internal/paths"
+)
+
+// indexOptions are the settings that change what gets indexed.
+type indexOptions struct {
+	// thinking indexes the text of thinking blocks under role "thinking".
+	thinking bool
+	// toolOutput caps how much of each tool result is indexed.
+	toolOutput ToolOutputPolicy
+}
+
+// optionsMetaKey records in index_meta the options the indexed content was
+// built with, so changing a setting re-indexes every session.
+const optionsMetaKey = "options"
+
+func currentOptions() indexOptions {
+	policy, err := LoadToolOutputPolicy()
+	if err != nil {
+		policy = DefaultToolOutputPolicy()
+	}
+	return indexOptions{thinking: ThinkingEnabled(), toolOutput: policy}
+}
+
+func (o indexOptions) stamp() string {
+	thinking := "off"
+	if o.thinking {
+		thinking = "on"
+	}
+	return "thinking=" + thinking + " tool-output=" + o.toolOutput.String()
+}
+
+// ThinkingEnabled reports whether thinking blocks are indexed.
+func ThinkingEnabled() bool {
+	_, err := os.Stat(paths.IndexThinkingPath())
+	return err == nil
+}
+
+// SetThinkingEnabled toggles thinking indexing. The next sync re-indexes
+// every session to match.
+func SetThinkingEnabled(enabled bool) error {
+	p := paths.IndexThinkingPath()
+	if !enabled {
+		err := os.Remove(p)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, []byte{}, 0o644)
+}
+
+// ToolOutputPolicy maps a tool name to the most bytes of its output the
+// index keeps per call: NoToolOutput skips it, FullToolOutput keeps it
+// all. DefaultTool covers tools not listed. Output past the cap can't be
+// searched, but export still shows it in full.
+type ToolOutputPolicy map[string]int
+
+const (
+	DefaultTool    = "*"
+	NoToolOutput   = 0
+	FullToolOutput = -1
+)
+
+// DefaultToolOutputPolicy keeps a few KB of most output: enough for error
+// messages and summaries, not whole logs. File reads and searches echo
+// content indexed elsewhere, so they get less; subagent results are the
+// agent's final answer, so they get more.
+func DefaultToolOutputPolicy() ToolOutputPolicy {
+	return ToolOutputPolicy{
+		DefaultTool: 4 << 10,
+		"Read":      1 << 10,
+		"Grep":      1 << 10,
+		"Glob":      1 << 10,
+		"Task":      16 << 10,
+		"Agent":     16 << 10,
+	}
+}
+
+// Limit is the cap for tool.
+func (p ToolOutputPolicy) Limit(tool string) int {
+	if n, ok := p[tool]; ok {
+		return n
+	}
+	if n, ok := p[DefaultTool]; ok {
+		return n
+	}
+	return FullToolOutput
+}
+
+// Tools lists the policy's entries, the default first.
+func (p ToolOutputPolicy) Tools() []string {
+	tools := make([]string, 0, len(p))
+	for t := range p {
+		if t != DefaultTool {
+			tools = append(tools, t)
+		}
+	}
+	sort.Strings(tools)
+	if _, ok := p[DefaultTool]; ok {
+		tools = append([]string{DefaultTool}, tools...)
+	}
+	return tools
+}
+
+func (p ToolOutputPolicy) String() string {
+	parts := make([]string, 0, len(p))
+	for _, t := range p.Tools() {
+		parts = append(parts, fmt.Sprintf("%s:%d", t, p[t]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// LoadToolOutputPolicy returns the default policy with the overrides set by
+// `cct index tool-output` applied.
+func LoadToolOutputPolicy() (ToolOutputPolicy, error) {
+	policy := DefaultToolOutputPolicy()
+	overrides, err := loadToolOutputOverrides()
+	for t, n := range overrides {
+		policy[t] = n
+	}
+	return policy, err
+}
+
+func loadToolOutputOverrides() (map[string]int, error) {
+	data, err := os.ReadFile(paths.IndexToolOutputPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var overrides map[string]int
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", paths.IndexToolOutputPath(), err)
+	}
+	return overrides, nil
+}
+
+// SetToolOutputLimit overrides the cap for tool (DefaultTool for the
+// fallback). reset drops the override, restoring the built-in cap. The
+// next sync re-indexes every session to match.
+func SetToolOutputLimit(tool string, limit int, reset bool) error {
+	overrides, err := loadToolOutputOverrides()
+	if err != nil {
+		return err
+	}
+	if overrides == nil {
+		overrides = map[string]int{}
+	}
+	if reset {
+		delete(overrides, tool)
+	} else {
+		overrides[tool] = limit
+	}
+
+	p := paths.IndexToolOutputPath()
+	if len(overrides) == 0 {
+		err := os.Remove(p)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	data, err := json.MarshalIndent(overrides, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, append(data, '\n'), 0o644)
+}
+
+// builtWith reports whether the indexed content was built with opts.
+func (idx *Index) builtWith(opts indexOptions) bool {
+	var value string
+	_ = idx.db.QueryRow("SELECT value FROM index_meta WHERE key = ?", optionsMetaKey).Scan(&value)
+	return value == opts.stamp()
+}
+
+func (idx *Index) recordOptions(opts indexOptions) {
+	_, _ = idx.db.Exec("INSERT OR REPLACE INTO index_meta (key, value) VALUES (?, ?)", optionsMetaKey, opts.stamp())
+}
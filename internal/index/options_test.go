@@ -0,0 +1,132 @@
+//go:build darwin || linux
+
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSearch_Thinking(t *testing.T) {
+	idx := setupTestIndex(t)
+	projDir := filepath.Join(os.Getenv("HOME"), ".claude", "projects", "-Users-test-myproject")
+	writeTestSession(t, projDir, "bbbb1111-2222-3333-4444-555555555555", []string{
+		`{"type":"user","message":{"role":"user","content":"pick a cache"},"cwd":"/Users/test/myproject","timestamp":"2026-02-02T08:00:00Z"}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"thinking","thinking":"An LRU beats a TTL cache here because entries never expire.","signature":"x"}]},"timestamp":"2026-02-02T08:00:05Z"}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"redacted_thinking","data":"secretpayload"}]},"timestamp":"2026-02-02T08:00:06Z"}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Using an LRU."}]},"timestamp":"2026-02-02T08:00:07Z"}`,
+	})
+
+	search := func(query string) []SearchResult {
+		t.Helper()
+		results, _, err := idx.Search(SearchOptions{Query: query, IncludeAgents: true, MaxResults: 10, MaxMatches: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return results
+	}
+
+	if err := idx.ForceSync(true); err != nil {
+		t.Fatal(err)
+	}
+	if got := search("expire"); len(got) != 0 {
+		t.Fatalf("thinking should not be indexed by default, got %d results", len(got))
+	}
+
+	if err := SetThinkingEnabled(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.ForceSync(true); err != nil {
+		t.Fatal(err)
+	}
+	got := search("expire")
+	if len(got) != 1 || len(got[0].Matches) != 1 {
+		t.Fatalf("got %+v, want one thinking match", got)
+	}
+	if m := got[0].Matches[0]; m.Role != "thinking" || m.Snippet == "" {
+		t.Errorf("match = %+v, want role thinking with a snippet", m)
+	}
+	if got := search("secretpayload"); len(got) != 0 {
+		t.Error("redacted thinking must stay out of the index")
+	}
+
+	if err := SetThinkingEnabled(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.ForceSync(true); err != nil {
+		t.Fatal(err)
+	}
+	if got := search("expire"); len(got) != 0 {
+		t.Error("turning thinking off should re-index without it")
+	}
+}
+
+func TestSearch_ToolOutput(t *testing.T) {
+	idx := setupTestIndex(t)
+	projDir := filepath.Join(os.Getenv("HOME"), ".claude", "projects", "-Users-test-myproject")
+	flood := strings.Repeat("ok line ", 1024) + "zebrafinch"
+	writeTestSession(t, projDir, "cccc1111-2222-3333-4444-555555555555", []string{
+		`{"type":"user","message":{"role":"user","content":"run the flaky suite"},"cwd":"/Users/test/myproject","timestamp":"2026-02-03T08:00:00Z"}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"make test"}}]},"timestamp":"2026-02-03T08:00:01Z"}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"panic: flaky timeout in worker\n` + flood + `"}]},"timestamp":"2026-02-03T08:00:09Z"}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"The worker panicked."}]},"timestamp":"2026-02-03T08:00:10Z"}`,
+	})
+	if err := idx.ForceSync(true); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(query string, roles ...string) []SearchResult {
+		t.Helper()
+		results, _, err := idx.Search(SearchOptions{Query: query, IncludeAgents: true, MaxResults: 10, MaxMatches: 5, Roles: roles})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return results
+	}
+
+	got := search("timeout")
+	if len(got) != 1 || len(got[0].Matches) != 1 {
+		t.Fatalf("got %+v, want one tool output match", got)
+	}
+	if m := got[0].Matches[0]; m.Role != "tool_result" || m.Source != "Bash" {
+		t.Errorf("match = %+v, want role tool_result from Bash", m)
+	}
+	if got := search("timeout", "user"); len(got) != 0 {
+		t.Error("a prompt-only search should skip tool output")
+	}
+	if got := search("flaky", "user"); len(got) != 1 || got[0].Matches[0].Role != "user" {
+		t.Errorf("prompt-only search = %+v, want the typed prompt", got)
+	}
+	// Two words, so the substring fallback for FTS misses stays out of it.
+	if got := search("worker zebrafinch"); len(got) != 0 {
+		t.Error("output past the default cap should not be indexed")
+	}
+
+	if err := SetToolOutputLimit("Bash", FullToolOutput, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.ForceSync(true); err != nil {
+		t.Fatal(err)
+	}
+	if got := search("worker zebrafinch"); len(got) != 1 {
+		t.Error("raising the Bash cap should re-index its full output")
+	}
+}
+
+func TestCapToolOutput(t *testing.T) {
+	used := map[string]int{}
+	if got := capToolOutput("héllo", 2, "a", used); got != "h" {
+		t.Errorf("cap should cut on a rune boundary, got %q", got)
+	}
+	if got := capToolOutput("more", 2, "a", used); got != "m" {
+		t.Errorf("second block should get the remaining byte, got %q", got)
+	}
+	if got := capToolOutput("more", 2, "a", used); got != "" {
+		t.Errorf("exhausted budget should index nothing, got %q", got)
+	}
+	if got := capToolOutput("all of it", FullToolOutput, "b", used); got != "all of it" {
+		t.Errorf("full should keep everything, got %q", got)
+	}
+}
@@ -0,0 +1,45 @@
+package index
+
+import "time"
+
+// PromptRow is one prompt a person typed into a session.
+type PromptRow struct {
+	Root      string
+	SessionID string
+	Project   string
+	Text      string
+	At        time.Time
+	Offset    int64
+}
+
+// HumanPrompts returns every indexed human prompt matching f, oldest
+// first. Copies a resumed session file repeats come after the original,
+// from the file modified first.
+func (idx *Index) HumanPrompts(f EventFilter) ([]PromptRow, error) {
+	idx.syncForRead()
+
+	where, args := f.where("p.at")
+	rows, err := idx.db.Query(`
+		SELECT s.root, s.id, s.project_name, p.text, p.at, p.byte_offset
+		FROM human_prompts p
+		JOIN sessions s ON p.root = s.root AND p.session_id = s.id
+		`+where+`
+		ORDER BY p.at, julianday(s.modified_at), s.id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []PromptRow
+	for rows.Next() {
+		var r PromptRow
+		var at string
+		if err := rows.Scan(&r.Root, &r.SessionID, &r.Project, &r.Text, &at, &r.Offset); err != nil {
+			return nil, err
+		}
+		r.At, _ = time.Parse(time.RFC3339Nano, at)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
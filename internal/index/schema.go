@@ -6,16 +6,19 @@ import (
 )
 
 // schemaVersion is bumped whenever the table layout or the JSONL parsing
-// logic changes in a way that invalidates existing rows. The index is a
-// pure derived cache of ~/.claude/projects/**/*.jsonl, so any version
-// mismatch is resolved by dropping all tables and letting the next Sync()
-// repopulate from disk. Adding a new field becomes: edit schemaSQL, bump
-// this constant.
-const schemaVersion = 9
+// logic changes in a way that invalidates existing rows. Almost all of the
+// index is derived from each root's session files and history.jsonl, so a
+// version mismatch is resolved by dropping the derived tables and letting
+// the next Sync() repopulate from disk. Adding a new field becomes: edit
+// schemaSQL, bump this constant. prompt_history is the exception: it keeps
+// prompts Claude Code has since dropped from history.jsonl, so it survives
+// the bump and any change to its layout needs a migration in ensureSchema.
+const schemaVersion = 24
 
 const schemaSQL = `
 CREATE TABLE IF NOT EXISTS sessions (
-	id TEXT PRIMARY KEY,
+	root TEXT NOT NULL,
+	id TEXT NOT NULL,
 	file_path TEXT NOT NULL UNIQUE,
 	project_dir TEXT NOT NULL,
 	project_name TEXT NOT NULL,
@@ -29,7 +32,16 @@ CREATE TABLE IF NOT EXISTS sessions (
 	message_count INTEGER NOT NULL DEFAULT 0,
 	custom_title TEXT,
 	agent_type TEXT,
-	agent_description TEXT
+	agent_description TEXT,
+	model TEXT,
+	context_tokens INTEGER NOT NULL DEFAULT 0,
+	peak_context_tokens INTEGER NOT NULL DEFAULT 0,
+	total_output_tokens INTEGER NOT NULL DEFAULT 0,
+	first_message_at TEXT,
+	last_message_at TEXT,
+	version TEXT,
+	parent_id TEXT,
+	PRIMARY KEY (root, id)
 );
 
 CREATE INDEX IF NOT EXISTS idx_sessions_project ON sessions(project_dir);
@@ -37,6 +49,7 @@ CREATE INDEX IF NOT EXISTS idx_sessions_modified ON sessions(modified_at DESC);
 
 CREATE TABLE IF NOT EXISTS content_map (
 	rowid INTEGER PRIMARY KEY,
+	root TEXT NOT NULL,
 	session_id TEXT NOT NULL,
 	role TEXT NOT NULL,
 	source TEXT,
@@ -51,7 +64,183 @@ CREATE VIRTUAL TABLE IF NOT EXISTS content_fts USING fts5(
 	tokenize='porter unicode61'
 );
 
-CREATE INDEX IF NOT EXISTS idx_content_map_session ON content_map(session_id);
+CREATE INDEX IF NOT EXISTS idx_content_map_session ON content_map(root, session_id);
+
+CREATE TABLE IF NOT EXISTS tool_calls (
+	root TEXT NOT NULL,
+	session_id TEXT NOT NULL,
+	tool_use_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	called_at TEXT,
+	result_at TEXT,
+	is_error INTEGER NOT NULL DEFAULT 0,
+	output_bytes INTEGER, -- NULL when no tool_result was recorded
+	target TEXT NOT NULL DEFAULT '', -- the command, path, URL, or pattern the call acted on
+	denied INTEGER NOT NULL DEFAULT 0 -- refused permission rather than run
+);
+
+CREATE INDEX IF NOT EXISTS idx_tool_calls_session ON tool_calls(root, session_id);
+
+CREATE TABLE IF NOT EXISTS message_times (
+	root TEXT NOT NULL,
+	session_id TEXT NOT NULL,
+	role TEXT NOT NULL,
+	at TEXT NOT NULL,
+	uuid TEXT NOT NULL DEFAULT '' -- shared by the copies a resumed file repeats
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_times_session ON message_times(root, session_id);
+CREATE INDEX IF NOT EXISTS idx_message_times_at ON message_times(at);
+
+-- commits made through Bash "git commit" calls. sha and branch are empty
+-- when git printed no summary line (commit -q); subject then comes from -m.
+CREATE TABLE IF NOT EXISTS commits (
+	root TEXT NOT NULL,
+	session_id TEXT NOT NULL,
+	tool_use_id TEXT NOT NULL,
+	sha TEXT NOT NULL,
+	branch TEXT NOT NULL,
+	subject TEXT NOT NULL,
+	committed_at TEXT,
+	byte_offset INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_commits_session ON commits(root, session_id);
+CREATE INDEX IF NOT EXISTS idx_commits_sha ON commits(sha);
+
+CREATE TABLE IF NOT EXISTS file_edits (
+	root TEXT NOT NULL,
+	session_id TEXT NOT NULL,
+	tool_use_id TEXT NOT NULL,
+	tool TEXT NOT NULL,
+	path TEXT NOT NULL,
+	edited_at TEXT,
+	byte_offset INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_edits_session ON file_edits(root, session_id);
+CREATE INDEX IF NOT EXISTS idx_file_edits_path ON file_edits(path);
+
+-- lineage ties the files of one conversation together: kind 'message' rows
+-- are the uuids a session holds, kind 'ref' rows the uuids it points at in
+-- other files (parentUuid, logicalParentUuid, summary leafUuid).
+CREATE TABLE IF NOT EXISTS lineage (
+	root TEXT NOT NULL,
+	session_id TEXT NOT NULL,
+	uuid TEXT NOT NULL,
+	kind TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_lineage_session ON lineage(root, session_id);
+CREATE INDEX IF NOT EXISTS idx_lineage_uuid ON lineage(root, uuid);
+
+-- todos is the list the last TodoWrite call of a session left, one row per
+-- item in list order. byte_offset points at that call.
+CREATE TABLE IF NOT EXISTS todos (
+	root TEXT NOT NULL,
+	session_id TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	content TEXT NOT NULL,
+	active_form TEXT NOT NULL,
+	status TEXT NOT NULL,
+	added_at TEXT,
+	started_at TEXT,
+	completed_at TEXT,
+	updated_at TEXT,
+	byte_offset INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_todos_session ON todos(root, session_id);
+CREATE INDEX IF NOT EXISTS idx_todos_status ON todos(status);
+
+-- human_prompts holds the text of every prompt a person typed into a
+-- session (no tool results, compaction summaries, or command output).
+CREATE TABLE IF NOT EXISTS human_prompts (
+	root TEXT NOT NULL,
+	session_id TEXT NOT NULL,
+	at TEXT,
+	text TEXT NOT NULL,
+	byte_offset INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_human_prompts_session ON human_prompts(root, session_id);
+
+-- prompt_history mirrors each root's history.jsonl. Rows are only added:
+-- they outlive their session files, the entries Claude Code trims from
+-- history.jsonl, schema version bumps, and rebuilds, so old prompts stay
+-- searchable. session_id is empty for entries written before Claude Code
+-- recorded it.
+CREATE TABLE IF NOT EXISTS prompt_history (
+	rowid INTEGER PRIMARY KEY,
+	root TEXT NOT NULL,
+	project TEXT NOT NULL,
+	session_id TEXT NOT NULL,
+	at TEXT NOT NULL,
+	display TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prompt_history_entry ON prompt_history(root, at, project, display);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS prompt_history_fts USING fts5(
+	text,
+	content='',
+	contentless_delete=1,
+	tokenize='porter unicode61'
+);
+
+-- failures are tool errors, API errors, interrupts, and permission
+-- denials (kind), with the tool and its command or path when a call
+-- failed. signature is the error text normalized for grouping.
+CREATE TABLE IF NOT EXISTS failures (
+	root TEXT NOT NULL,
+	session_id TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	tool TEXT NOT NULL,
+	input TEXT NOT NULL,
+	signature TEXT NOT NULL,
+	text TEXT NOT NULL,
+	at TEXT,
+	byte_offset INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_failures_session ON failures(root, session_id);
+CREATE INDEX IF NOT EXISTS idx_failures_at ON failures(at);
+
+-- links are URLs from typed prompts, assistant replies, WebFetch inputs,
+-- and WebSearch results. source is "prompt", "reply", or the tool name;
+-- context the search query, fetch prompt, or line the URL was on.
+CREATE TABLE IF NOT EXISTS links (
+	root TEXT NOT NULL,
+	session_id TEXT NOT NULL,
+	url TEXT NOT NULL,
+	domain TEXT NOT NULL,
+	title TEXT NOT NULL,
+	source TEXT NOT NULL,
+	context TEXT NOT NULL,
+	at TEXT,
+	byte_offset INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_links_session ON links(root, session_id);
+CREATE INDEX IF NOT EXISTS idx_links_domain ON links(domain);
+
+-- snippets are fenced code blocks from assistant replies. seq numbers a
+-- session's snippets from 1; symbols holds the names the code defines,
+-- space-separated.
+CREATE TABLE IF NOT EXISTS snippets (
+	root TEXT NOT NULL,
+	session_id TEXT NOT NULL,
+	seq INTEGER NOT NULL,
+	lang TEXT NOT NULL,
+	code TEXT NOT NULL,
+	lines INTEGER NOT NULL,
+	symbols TEXT NOT NULL,
+	at TEXT,
+	byte_offset INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_snippets_session ON snippets(root, session_id, seq);
+CREATE INDEX IF NOT EXISTS idx_snippets_lang ON snippets(lang);
 
 CREATE TABLE IF NOT EXISTS index_meta (
 	key TEXT PRIMARY KEY,
@@ -59,16 +248,43 @@ CREATE TABLE IF NOT EXISTS index_meta (
 );
 `
 
-// derivedTables lists every table we own. All of them are reconstructable
-// from JSONL on disk, so ensureSchema drops them wholesale on any version
-// mismatch. content_raw is a legacy pre-v5 table kept in the drop list so
-// old DBs upgrading to the rebuild model don't leave orphaned tables behind.
+// derivedTables lists every table we own but prompt_history and its FTS
+// table. All of them are reconstructable from JSONL on disk, so
+// ensureSchema drops them wholesale on any version mismatch. content_raw
+// is a legacy pre-v5 table kept in the drop list so old DBs upgrading to
+// the rebuild model don't leave orphaned tables behind.
 var derivedTables = []string{
 	"sessions",
 	"content_map",
 	"content_fts",
 	"content_raw",
 	"index_meta",
+	"tool_calls",
+	"message_times",
+	"commits",
+	"file_edits",
+	"lineage",
+	"todos",
+	"human_prompts",
+	"failures",
+	"links",
+	"snippets",
+}
+
+// sessionTables hold per-session rows keyed by (root, session_id) beyond
+// content_map. deleteSessionData clears them when a session is re-indexed
+// and RebuildWithProgress empties them.
+var sessionTables = []string{
+	"tool_calls",
+	"message_times",
+	"commits",
+	"file_edits",
+	"lineage",
+	"todos",
+	"human_prompts",
+	"failures",
+	"links",
+	"snippets",
 }
 
 func (idx *Index) ensureSchema() error {
@@ -104,9 +320,9 @@ func (idx *Index) ensureSchema() error {
 	return nil
 }
 
-func (idx *Index) deleteSessionData(tx *sql.Tx, sessionID string) error {
+func (idx *Index) deleteSessionData(tx *sql.Tx, root, sessionID string) error {
 	// For contentless_delete=1 tables, use standard DELETE syntax
-	rows, err := tx.Query("SELECT rowid FROM content_map WHERE session_id = ?", sessionID)
+	rows, err := tx.Query("SELECT rowid FROM content_map WHERE root = ? AND session_id = ?", root, sessionID)
 	if err != nil {
 		return err
 	}
@@ -127,10 +343,15 @@ func (idx *Index) deleteSessionData(tx *sql.Tx, sessionID string) error {
 		}
 	}
 
-	if _, err := tx.Exec("DELETE FROM content_map WHERE session_id = ?", sessionID); err != nil {
+	if _, err := tx.Exec("DELETE FROM content_map WHERE root = ? AND session_id = ?", root, sessionID); err != nil {
 		return err
 	}
-	if _, err := tx.Exec("DELETE FROM sessions WHERE id = ?", sessionID); err != nil {
+	for _, table := range sessionTables {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE root = ? AND session_id = ?", root, sessionID); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM sessions WHERE root = ? AND id = ?", root, sessionID); err != nil {
 		return err
 	}
 	return nil
@@ -1,19 +1,18 @@
 package index
 
 import (
-	"database/sql"
 	"encoding/json"
-	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
-	"time"
 
 	"github.com/andyhtran/cct/internal/output"
 	"github.com/andyhtran/cct/internal/session"
 )
 
 type snippetLocation struct {
+	root       string
 	sessionID  string
 	filePath   string
 	role       string
@@ -28,11 +27,15 @@ type snippetLocation struct {
 type SearchOptions struct {
 	Query         string
 	ProjectFilter string
+	Root          string // restrict to one configured root; "" searches all
 	IncludeAgents bool
 	MaxResults    int
 	MaxMatches    int
 	SnippetWidth  int
-	SortBy        string // "recency" (default) or "relevance"
+	SortBy        string // "recency" (default), "relevance", or a sortOrders key
+	// Roles limits matches to content of these roles (user, assistant,
+	// thinking, tool_result, description); empty matches every role.
+	Roles []string
 }
 
 type SearchResult struct {
@@ -48,9 +51,7 @@ type sessionInfo struct {
 
 func (idx *Index) Search(opts SearchOptions) ([]SearchResult, int, error) {
 	// Sync failure is non-fatal: search stale data rather than failing entirely.
-	if err := idx.Sync(opts.IncludeAgents); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: index sync failed: %v\n", err)
-	}
+	idx.syncForRead()
 
 	results, total, err := idx.ftsSearch(opts)
 	if err != nil {
@@ -97,7 +98,7 @@ func compoundTerms(query string) []string {
 }
 
 func (idx *Index) substringSearch(opts SearchOptions) []SearchResult {
-	toSearch := session.DiscoverFilesWithBackups(opts.ProjectFilter, opts.IncludeAgents)
+	toSearch := session.DiscoverFilesWithBackups(opts.Root, opts.ProjectFilter, opts.IncludeAgents)
 	if len(toSearch) == 0 {
 		return nil
 	}
@@ -120,6 +121,11 @@ func (idx *Index) substringSearch(opts SearchOptions) []SearchResult {
 
 	results := make([]SearchResult, 0, len(streamResults))
 	for _, sr := range streamResults {
+		if sr != nil && len(opts.Roles) > 0 {
+			sr.Matches = slices.DeleteFunc(sr.Matches, func(m session.Match) bool {
+				return !slices.Contains(opts.Roles, m.Role)
+			})
+		}
 		if sr == nil || len(sr.Matches) == 0 {
 			continue
 		}
@@ -146,6 +152,7 @@ func (idx *Index) ftsSearch(opts SearchOptions) ([]SearchResult, int, error) {
 	compounds := compoundTerms(opts.Query)
 	projectFilter := strings.ToLower(opts.ProjectFilter)
 	multiTerm := len(tokens) > 1
+	roleSQL, roleArgs := roleFilter(opts.Roles)
 
 	// ftsLimit controls the SQL LIMIT clause. 0 means no limit.
 	// For compound queries, we need all FTS candidates since post-filtering
@@ -155,55 +162,55 @@ func (idx *Index) ftsSearch(opts SearchOptions) ([]SearchResult, int, error) {
 		ftsLimit = 0
 	}
 
-	orderBy := "s.modified_at DESC"
+	order := orderBy(opts.SortBy)
 	if opts.SortBy == "relevance" {
-		orderBy = "m.match_count DESC, s.modified_at DESC"
+		order = "m.match_count DESC, s.modified_at DESC"
 	}
 
 	var totalMatched int
-	var sessionIDs []string
-	var sessions map[string]sessionInfo
+	var sessionIDs []sessionKey
+	var sessions map[sessionKey]sessionInfo
 
 	if multiTerm {
-		intersectSQL, intersectArgs := buildIntersectSQL(tokens)
+		intersectSQL, intersectArgs := buildIntersectSQL(tokens, opts.Roles)
 		orQuery := buildOrQuery(tokens)
 
 		countQuery := `
 			WITH session_pool AS (` + intersectSQL + `)
 			SELECT COUNT(*) FROM session_pool sp
-			JOIN sessions s ON sp.session_id = s.id
+			JOIN sessions s ON sp.root = s.root AND sp.session_id = s.id
 			WHERE (? = 1 OR s.is_agent = 0)
 			  AND (? = '' OR LOWER(s.project_dir) LIKE '%' || ? || '%')
+			  AND (? = '' OR s.root = ?)
 		`
-		countArgs := make([]any, 0, len(intersectArgs)+3)
+		countArgs := make([]any, 0, len(intersectArgs)+5)
 		countArgs = append(countArgs, intersectArgs...)
-		countArgs = append(countArgs, boolToInt(opts.IncludeAgents), projectFilter, projectFilter)
+		countArgs = append(countArgs, boolToInt(opts.IncludeAgents), projectFilter, projectFilter, opts.Root, opts.Root)
 		_ = idx.db.QueryRow(countQuery, countArgs...).Scan(&totalMatched)
 
 		mainQuery := `
 			WITH session_pool AS (` + intersectSQL + `),
 			matches AS (
-				SELECT sp.session_id, COUNT(*) as match_count
+				SELECT sp.root, sp.session_id, COUNT(*) as match_count
 				FROM session_pool sp
-				JOIN content_map m ON sp.session_id = m.session_id
-				WHERE m.rowid IN (SELECT rowid FROM content_fts WHERE content_fts MATCH ?)
-				GROUP BY sp.session_id
+				JOIN content_map m ON sp.root = m.root AND sp.session_id = m.session_id
+				WHERE m.rowid IN (SELECT rowid FROM content_fts WHERE content_fts MATCH ?)` + roleSQL + `
+				GROUP BY sp.root, sp.session_id
 			)
-			SELECT
-				s.id, s.file_path, s.project_name, s.project_path,
-				s.is_agent, s.modified_at,
-				s.first_prompt, s.created_at, s.git_branch, s.message_count,
-				s.custom_title, s.agent_type, s.agent_description,
+			SELECT ` + sessionColumns + `,
 				m.match_count
 			FROM sessions s
-			JOIN matches m ON s.id = m.session_id
+			JOIN matches m ON s.root = m.root AND s.id = m.session_id
 			WHERE (? = 1 OR s.is_agent = 0)
 			  AND (? = '' OR LOWER(s.project_dir) LIKE '%' || ? || '%')
-			ORDER BY ` + orderBy + limitClause(ftsLimit) + `
+			  AND (? = '' OR s.root = ?)
+			ORDER BY ` + order + limitClause(ftsLimit) + `
 		`
-		mainArgs := make([]any, 0, len(intersectArgs)+5)
+		mainArgs := make([]any, 0, len(intersectArgs)+len(roleArgs)+7)
 		mainArgs = append(mainArgs, intersectArgs...)
-		mainArgs = append(mainArgs, orQuery, boolToInt(opts.IncludeAgents), projectFilter, projectFilter)
+		mainArgs = append(mainArgs, orQuery)
+		mainArgs = append(mainArgs, roleArgs...)
+		mainArgs = append(mainArgs, boolToInt(opts.IncludeAgents), projectFilter, projectFilter, opts.Root, opts.Root)
 		mainArgs = appendLimit(mainArgs, ftsLimit)
 
 		var err error
@@ -215,38 +222,39 @@ func (idx *Index) ftsSearch(opts SearchOptions) ([]SearchResult, int, error) {
 		ftsQuery := tokens[0] + "*"
 
 		countQuery := `
-			SELECT COUNT(DISTINCT m.session_id)
+			SELECT COUNT(DISTINCT s.rowid)
 			FROM content_fts f
 			JOIN content_map m ON f.rowid = m.rowid
-			JOIN sessions s ON m.session_id = s.id
-			WHERE content_fts MATCH ?
+			JOIN sessions s ON m.root = s.root AND m.session_id = s.id
+			WHERE content_fts MATCH ?` + roleSQL + `
 			  AND (? = 1 OR s.is_agent = 0)
 			  AND (? = '' OR LOWER(s.project_dir) LIKE '%' || ? || '%')
+			  AND (? = '' OR s.root = ?)
 		`
-		_ = idx.db.QueryRow(countQuery, ftsQuery, boolToInt(opts.IncludeAgents), projectFilter, projectFilter).Scan(&totalMatched)
+		countArgs := append([]any{ftsQuery}, roleArgs...)
+		countArgs = append(countArgs, boolToInt(opts.IncludeAgents), projectFilter, projectFilter, opts.Root, opts.Root)
+		_ = idx.db.QueryRow(countQuery, countArgs...).Scan(&totalMatched)
 
 		mainQuery := `
 			WITH matches AS (
-				SELECT m.session_id, COUNT(*) as match_count
+				SELECT m.root, m.session_id, COUNT(*) as match_count
 				FROM content_fts f
 				JOIN content_map m ON f.rowid = m.rowid
-				WHERE content_fts MATCH ?
-				GROUP BY m.session_id
+				WHERE content_fts MATCH ?` + roleSQL + `
+				GROUP BY m.root, m.session_id
 			)
-			SELECT
-				s.id, s.file_path, s.project_name, s.project_path,
-				s.is_agent, s.modified_at,
-				s.first_prompt, s.created_at, s.git_branch, s.message_count,
-				s.custom_title, s.agent_type, s.agent_description,
+			SELECT ` + sessionColumns + `,
 				m.match_count
 			FROM sessions s
-			JOIN matches m ON s.id = m.session_id
+			JOIN matches m ON s.root = m.root AND s.id = m.session_id
 			WHERE (? = 1 OR s.is_agent = 0)
 			  AND (? = '' OR LOWER(s.project_dir) LIKE '%' || ? || '%')
-			ORDER BY ` + orderBy + limitClause(ftsLimit) + `
+			  AND (? = '' OR s.root = ?)
+			ORDER BY ` + order + limitClause(ftsLimit) + `
 		`
 
-		mainArgs := []any{ftsQuery, boolToInt(opts.IncludeAgents), projectFilter, projectFilter}
+		mainArgs := append([]any{ftsQuery}, roleArgs...)
+		mainArgs = append(mainArgs, boolToInt(opts.IncludeAgents), projectFilter, projectFilter, opts.Root, opts.Root)
 		mainArgs = appendLimit(mainArgs, ftsLimit)
 
 		var err error
@@ -274,7 +282,7 @@ func (idx *Index) ftsSearch(opts SearchOptions) ([]SearchResult, int, error) {
 		snippetQuery = buildOrQuery(tokens)
 	}
 
-	snippetMap := idx.batchGetSnippets(sessionIDs, snippetQuery, maxMatches, snippetWidth, opts.Query)
+	snippetMap := idx.batchGetSnippets(sessionIDs, snippetQuery, opts.Roles, maxMatches, snippetWidth, opts.Query)
 
 	results := make([]SearchResult, 0, len(sessionIDs))
 	for _, id := range sessionIDs {
@@ -301,52 +309,31 @@ func (idx *Index) ftsSearch(opts SearchOptions) ([]SearchResult, int, error) {
 	return results, totalMatched, nil
 }
 
-func (idx *Index) scanSessionRows(query string, args []any) ([]string, map[string]sessionInfo, error) {
+// sessionKey identifies a sessions row. IDs are only unique within a root.
+type sessionKey struct {
+	root, id string
+}
+
+func (idx *Index) scanSessionRows(query string, args []any) ([]sessionKey, map[sessionKey]sessionInfo, error) {
 	rows, err := idx.db.Query(query, args...)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	var sessionIDs []string
-	sessions := make(map[string]sessionInfo)
+	var sessionIDs []sessionKey
+	sessions := make(map[sessionKey]sessionInfo)
 
 	for rows.Next() {
-		var id, filePath, projectName, projectPath, modifiedStr string
-		var firstPrompt, createdAtStr, gitBranch, customTitle, agentType, agentDescription sql.NullString
-		var isAgent, messageCount, matchCount int
-
-		if err := rows.Scan(&id, &filePath, &projectName, &projectPath, &isAgent, &modifiedStr,
-			&firstPrompt, &createdAtStr, &gitBranch, &messageCount, &customTitle,
-			&agentType, &agentDescription, &matchCount); err != nil {
+		var matchCount int
+		sess, err := scanSession(rows, &matchCount)
+		if err != nil {
 			_ = rows.Close()
 			return nil, nil, err
 		}
 
-		modified, _ := time.Parse(time.RFC3339, modifiedStr)
-		var created time.Time
-		if createdAtStr.Valid {
-			created, _ = time.Parse(time.RFC3339, createdAtStr.String)
-		}
-
-		sess := &session.Session{
-			ID:               id,
-			ShortID:          session.ShortID(id),
-			IsAgent:          isAgent == 1,
-			ProjectPath:      projectPath,
-			ProjectName:      projectName,
-			FilePath:         filePath,
-			Modified:         modified,
-			FirstPrompt:      firstPrompt.String,
-			CustomTitle:      customTitle.String,
-			Created:          created,
-			GitBranch:        gitBranch.String,
-			MessageCount:     messageCount,
-			AgentType:        agentType.String,
-			AgentDescription: agentDescription.String,
-		}
-
-		sessionIDs = append(sessionIDs, id)
-		sessions[id] = sessionInfo{
+		key := sessionKey{sess.Root, sess.ID}
+		sessionIDs = append(sessionIDs, key)
+		sessions[key] = sessionInfo{
 			sess:  sess,
 			score: float64(matchCount),
 		}
@@ -361,27 +348,29 @@ func (idx *Index) scanSessionRows(query string, args []any) ([]string, map[strin
 	return sessionIDs, sessions, nil
 }
 
-func (idx *Index) batchGetSnippets(sessionIDs []string, ftsQuery string, maxPerSession, width int, originalQuery string) map[string][]session.Match {
+func (idx *Index) batchGetSnippets(sessionIDs []sessionKey, ftsQuery string, roles []string, maxPerSession, width int, originalQuery string) map[sessionKey][]session.Match {
 	if len(sessionIDs) == 0 {
 		return nil
 	}
 
 	placeholders := make([]string, len(sessionIDs))
-	args := make([]any, len(sessionIDs)+1)
-	for i, id := range sessionIDs {
-		placeholders[i] = "?"
-		args[i] = id
+	args := make([]any, 0, 2*len(sessionIDs)+1)
+	for i, key := range sessionIDs {
+		placeholders[i] = "(?, ?)"
+		args = append(args, key.root, key.id)
 	}
-	args[len(sessionIDs)] = ftsQuery
+	args = append(args, ftsQuery)
+	roleSQL, roleArgs := roleFilter(roles)
+	args = append(args, roleArgs...)
 
 	query := `
-		SELECT m.session_id, s.file_path, m.role, m.source, m.byte_offset, m.byte_length,
+		SELECT m.root, m.session_id, s.file_path, m.role, m.source, m.byte_offset, m.byte_length,
 		       COALESCE(s.agent_description, '')
 		FROM content_map m
-		JOIN sessions s ON m.session_id = s.id
-		WHERE m.session_id IN (` + strings.Join(placeholders, ",") + `)
-		  AND m.rowid IN (SELECT rowid FROM content_fts WHERE content_fts MATCH ?)
-		ORDER BY m.session_id, m.rowid
+		JOIN sessions s ON m.root = s.root AND m.session_id = s.id
+		WHERE (m.root, m.session_id) IN (VALUES ` + strings.Join(placeholders, ",") + `)
+		  AND m.rowid IN (SELECT rowid FROM content_fts WHERE content_fts MATCH ?)` + roleSQL + `
+		ORDER BY m.root, m.session_id, m.rowid
 	`
 
 	rows, err := idx.db.Query(query, args...)
@@ -394,7 +383,7 @@ func (idx *Index) batchGetSnippets(sessionIDs []string, ftsQuery string, maxPerS
 	for rows.Next() {
 		var loc snippetLocation
 		var agentDesc string
-		if err := rows.Scan(&loc.sessionID, &loc.filePath, &loc.role, &loc.source, &loc.byteOffset, &loc.byteLength, &agentDesc); err != nil {
+		if err := rows.Scan(&loc.root, &loc.sessionID, &loc.filePath, &loc.role, &loc.source, &loc.byteOffset, &loc.byteLength, &agentDesc); err != nil {
 			continue
 		}
 		if loc.role == "description" {
@@ -425,10 +414,11 @@ func (idx *Index) batchGetSnippets(sessionIDs []string, ftsQuery string, maxPerS
 		byFile[loc.filePath] = append(byFile[loc.filePath], loc)
 	}
 
-	result := make(map[string][]session.Match)
+	result := make(map[sessionKey][]session.Match)
 
-	addMatch := func(loc snippetLocation, text string) {
-		if len(result[loc.sessionID]) >= maxPerSession {
+	addMatch := func(loc snippetLocation, text, uuid string) {
+		key := sessionKey{loc.root, loc.sessionID}
+		if len(result[key]) >= maxPerSession {
 			return
 		}
 		if len(compounds) > 0 {
@@ -445,15 +435,21 @@ func (idx *Index) batchGetSnippets(sessionIDs []string, ftsQuery string, maxPerS
 			}
 		}
 		snippet := output.ExtractSnippet(text, firstTerm, width)
-		result[loc.sessionID] = append(result[loc.sessionID], session.Match{
+		m := session.Match{
 			Role:    loc.role,
 			Source:  loc.source,
 			Snippet: snippet,
-		})
+		}
+		if loc.inlineText == "" {
+			m.Ref = session.MessageRef(session.ShortID(loc.sessionID), uuid, loc.byteOffset)
+			m.UUID = uuid
+			m.Offset = loc.byteOffset
+		}
+		result[key] = append(result[key], m)
 	}
 
 	for _, loc := range inline {
-		addMatch(loc, loc.inlineText)
+		addMatch(loc, loc.inlineText, "")
 	}
 
 	for filePath, fileLocs := range byFile {
@@ -462,14 +458,14 @@ func (idx *Index) batchGetSnippets(sessionIDs []string, ftsQuery string, maxPerS
 			continue
 		}
 		for _, loc := range fileLocs {
-			if len(result[loc.sessionID]) >= maxPerSession {
+			if len(result[sessionKey{loc.root, loc.sessionID}]) >= maxPerSession {
 				continue
 			}
-			text, err := readTextAt(f, loc.byteOffset, loc.byteLength)
+			text, uuid, err := readTextAt(f, loc.role, loc.byteOffset, loc.byteLength)
 			if err != nil {
 				continue
 			}
-			addMatch(loc, text)
+			addMatch(loc, text, uuid)
 		}
 		_ = f.Close()
 	}
@@ -477,20 +473,38 @@ func (idx *Index) batchGetSnippets(sessionIDs []string, ftsQuery string, maxPerS
 	return result
 }
 
-func readTextAt(f *os.File, offset int64, length int) (string, error) {
+// readTextAt returns the text of the record at offset that a row of role
+// indexed, and the record's uuid.
+func readTextAt(f *os.File, role string, offset int64, length int) (text, uuid string, err error) {
 	if _, err := f.Seek(offset, io.SeekStart); err != nil {
-		return "", err
+		return "", "", err
 	}
 	buf := make([]byte, length)
 	n, err := io.ReadFull(f, buf)
 	if err != nil && n == 0 {
-		return "", err
+		return "", "", err
 	}
 	var obj map[string]any
 	if err := json.Unmarshal(buf[:n], &obj); err != nil {
-		return "", err
+		return "", "", err
+	}
+	uuid, _ = obj["uuid"].(string)
+	if role == "thinking" {
+		return strings.Join(session.ThinkingBlocks(obj), " "), uuid, nil
+	}
+	// A line can hold both typed text and tool output; take the part the
+	// row indexed.
+	var parts []string
+	lineType, _ := obj["type"].(string)
+	for _, b := range session.ExtractPromptBlocks(obj) {
+		if b.Role(lineType) == role && b.Text != "" {
+			parts = append(parts, b.Text)
+		}
 	}
-	return session.ExtractPromptText(obj), nil
+	if len(parts) == 0 {
+		return session.ExtractPromptText(obj), uuid, nil
+	}
+	return strings.Join(parts, " "), uuid, nil
 }
 
 // ftsTokens returns the individual sanitized FTS tokens for a query.
@@ -534,23 +548,38 @@ func buildOrQuery(tokens []string) string {
 
 // buildIntersectSQL builds a per-term INTERSECT query that finds sessions
 // containing ALL terms, even if the terms appear in different messages.
-func buildIntersectSQL(tokens []string) (string, []any) {
+func buildIntersectSQL(tokens, roles []string) (string, []any) {
+	roleSQL, roleArgs := roleFilter(roles)
 	parts := make([]string, 0, len(tokens))
-	args := make([]any, 0, len(tokens))
+	args := make([]any, 0, len(tokens)*(1+len(roleArgs)))
 	for i, t := range tokens {
 		if i == len(tokens)-1 {
 			t += "*"
 		}
 		parts = append(parts, `
-			SELECT DISTINCT m.session_id
+			SELECT DISTINCT m.root, m.session_id
 			FROM content_fts f
 			JOIN content_map m ON f.rowid = m.rowid
-			WHERE content_fts MATCH ?`)
+			WHERE content_fts MATCH ?`+roleSQL)
 		args = append(args, t)
+		args = append(args, roleArgs...)
 	}
 	return strings.Join(parts, "\nINTERSECT"), args
 }
 
+// roleFilter restricts content_map rows (aliased m) to roles; none means
+// every role.
+func roleFilter(roles []string) (string, []any) {
+	if len(roles) == 0 {
+		return "", nil
+	}
+	args := make([]any, len(roles))
+	for i, r := range roles {
+		args[i] = r
+	}
+	return " AND m.role IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(roles)), ", ") + ")", args
+}
+
 func limitClause(limit int) string {
 	if limit <= 0 {
 		return ""
@@ -0,0 +1,216 @@
+package index
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andyhtran/cct/internal/session"
+)
+
+// sessionColumns is the SELECT list scanSession expects, in order. Queries
+// alias the sessions table as s.
+const sessionColumns = `
+	s.root, s.id, s.file_path, s.project_name, s.project_path,
+	s.is_agent, s.modified_at,
+	s.first_prompt, s.created_at, s.git_branch, s.message_count,
+	s.custom_title, s.agent_type, s.agent_description,
+	s.file_size, s.model, s.context_tokens, s.peak_context_tokens, s.total_output_tokens,
+	s.first_message_at, s.last_message_at, s.version, s.parent_id`
+
+// scanSession reads one sessionColumns row. extra receives any columns the
+// query selects after sessionColumns.
+func scanSession(rows *sql.Rows, extra ...any) (*session.Session, error) {
+	var root, id, filePath, projectName, projectPath, modifiedStr string
+	var firstPrompt, createdAtStr, gitBranch, customTitle, agentType, agentDescription sql.NullString
+	var model, firstMessageStr, lastMessageStr, version, parentID sql.NullString
+	var isAgent, messageCount, contextTokens, peakContextTokens, totalOutputTokens int
+	var fileSize int64
+
+	dest := []any{
+		&root, &id, &filePath, &projectName, &projectPath, &isAgent, &modifiedStr,
+		&firstPrompt, &createdAtStr, &gitBranch, &messageCount, &customTitle,
+		&agentType, &agentDescription,
+		&fileSize, &model, &contextTokens, &peakContextTokens, &totalOutputTokens,
+		&firstMessageStr, &lastMessageStr, &version, &parentID,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	modified, _ := time.Parse(time.RFC3339, modifiedStr)
+	var created time.Time
+	if createdAtStr.Valid {
+		created, _ = time.Parse(time.RFC3339, createdAtStr.String)
+	}
+
+	firstMessage, _ := time.Parse(time.RFC3339Nano, firstMessageStr.String)
+	lastMessage, _ := time.Parse(time.RFC3339Nano, lastMessageStr.String)
+
+	return &session.Session{
+		ID:               id,
+		ShortID:          session.ShortID(id),
+		Root:             root,
+		IsAgent:          isAgent == 1,
+		ProjectPath:      projectPath,
+		ProjectName:      projectName,
+		FilePath:         filePath,
+		Modified:         modified,
+		FirstPrompt:      firstPrompt.String,
+		CustomTitle:      customTitle.String,
+		Created:          created,
+		GitBranch:        gitBranch.String,
+		MessageCount:     messageCount,
+		AgentType:        agentType.String,
+		AgentDescription: agentDescription.String,
+
+		FileSize:          fileSize,
+		Model:             model.String,
+		ContextTokens:     contextTokens,
+		PeakContextTokens: peakContextTokens,
+		TotalOutputTokens: totalOutputTokens,
+		FirstMessageAt:    firstMessage,
+		LastMessageAt:     lastMessage,
+		Version:           version.String,
+		ParentID:          parentID.String,
+	}, nil
+}
+
+type ListOptions struct {
+	Root          string
+	ProjectFilter string
+	IncludeAgents bool
+	Limit         int    // 0 = no limit
+	SortBy        string // "recency" (default) or a sortOrders key
+
+	// ActiveSince and ActiveUntil keep sessions with messages overlapping
+	// [ActiveSince, ActiveUntil); zero leaves that side open.
+	ActiveSince time.Time
+	ActiveUntil time.Time
+}
+
+// sortOrders maps the metadata sort keys shared by list and search to their
+// ORDER BY terms, largest first. Ties fall back to recency. "tokens" ranks
+// by peak context — how much of the window the session ever used — then by
+// output tokens.
+var sortOrders = map[string]string{
+	"tokens":   "s.peak_context_tokens DESC, s.total_output_tokens DESC",
+	"messages": "s.message_count DESC",
+	"duration": "julianday(s.last_message_at) - julianday(s.first_message_at) DESC",
+	"size":     "s.file_size DESC",
+}
+
+// orderBy returns the ORDER BY terms for sortBy, defaulting to recency.
+func orderBy(sortBy string) string {
+	if o, ok := sortOrders[sortBy]; ok {
+		return o + ", s.modified_at DESC"
+	}
+	return "s.modified_at DESC"
+}
+
+// ListSessions returns indexed sessions ordered by opts.SortBy. Like
+// Search it syncs first, so results are at most syncCacheDuration stale.
+func (idx *Index) ListSessions(opts ListOptions) ([]*session.Session, error) {
+	idx.syncForRead()
+
+	projectFilter := strings.ToLower(opts.ProjectFilter)
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions s
+		WHERE (? = 1 OR s.is_agent = 0)
+		  AND (? = '' OR LOWER(s.project_dir) LIKE '%' || ? || '%')
+		  AND (? = '' OR s.root = ?)
+		  AND (? = '' OR julianday(s.last_message_at) >= julianday(?))
+		  AND (? = '' OR julianday(s.first_message_at) < julianday(?))
+		ORDER BY ` + orderBy(opts.SortBy) + limitClause(opts.Limit)
+	since, until := formatMessageTime(opts.ActiveSince), formatMessageTime(opts.ActiveUntil)
+	args := []any{
+		boolToInt(opts.IncludeAgents), projectFilter, projectFilter, opts.Root, opts.Root,
+		since, since, until, until,
+	}
+	args = appendLimit(args, opts.Limit)
+
+	return idx.querySessions(query, args...)
+}
+
+// FindByPrefix resolves a session ID, short ID, custom title, or UUID prefix
+// against the index with the same precedence as session.FindByPrefix. A miss
+// against an index last synced within the freshness window forces one sync
+// before giving up, so a session started since is still found; a miss right
+// after a scan is final.
+func (idx *Index) FindByPrefix(root, prefix string) (*session.Session, error) {
+	scanned := idx.syncForRead()
+
+	s, err := idx.resolve(root, prefix)
+	if !errors.Is(err, session.ErrNotFound) || scanned {
+		return s, err
+	}
+	if err := idx.ForceSync(true); err != nil {
+		return nil, err
+	}
+	return idx.resolve(root, prefix)
+}
+
+func (idx *Index) resolve(root, prefix string) (*session.Session, error) {
+	pattern := likeEscaper.Replace(prefix) + "%"
+	candidates, err := idx.querySessions(`
+		SELECT `+sessionColumns+`
+		FROM sessions s
+		WHERE (? = '' OR s.root = ?)
+		  AND (s.id LIKE ? ESCAPE '\' OR LOWER(s.custom_title) = LOWER(?))
+	`, root, root, pattern, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return session.Resolve(candidates, prefix)
+}
+
+// Session returns the indexed session with exactly this root and ID.
+func (idx *Index) Session(root, id string) (*session.Session, error) {
+	sessions, err := idx.querySessions(`
+		SELECT `+sessionColumns+`
+		FROM sessions s
+		WHERE s.root = ? AND s.id = ?
+	`, root, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, session.ErrNotFound
+	}
+	return sessions[0], nil
+}
+
+func (idx *Index) querySessions(query string, args ...any) ([]*session.Session, error) {
+	rows, err := idx.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []*session.Session
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// syncForRead brings the index up to date before a read. Agents are always
+// indexed so one sync serves every caller; failure is non-fatal and the
+// read proceeds against stale data. It reports whether the session files
+// were scanned just now.
+func (idx *Index) syncForRead() bool {
+	res, err := idx.syncInternal(true, false, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: index sync failed: %v\n", err)
+		return false
+	}
+	return !res.skipped
+}
@@ -0,0 +1,162 @@
+//go:build darwin || linux
+
+package index
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andyhtran/cct/internal/session"
+)
+
+func TestListSessions(t *testing.T) {
+	idx := setupTestIndex(t)
+	projDir := filepath.Join(os.Getenv("HOME"), ".claude", "projects", "-Users-test-other")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestSession(t, projDir, "bbbb1111-2222-3333-4444-555555555555", []string{
+		`{"type":"user","message":{"role":"user","content":"other project"},"cwd":"/Users/test/other","sessionId":"bbbb1111-2222-3333-4444-555555555555","timestamp":"2026-02-02T08:00:00Z"}`,
+	})
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(projDir, "bbbb1111-2222-3333-4444-555555555555.jsonl"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.ForceSync(true); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := idx.ListSessions(ListOptions{IncludeAgents: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(all))
+	}
+	if all[0].ID != "bbbb1111-2222-3333-4444-555555555555" {
+		t.Errorf("first = %s, want the most recently modified session", all[0].ShortID)
+	}
+
+	filtered, err := idx.ListSessions(ListOptions{ProjectFilter: "MyProject"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 1 || filtered[0].CustomTitle != "fix-precommit" {
+		t.Fatalf("project filter: got %d sessions, want the myproject one", len(filtered))
+	}
+	if filtered[0].MessageCount != 4 || filtered[0].FirstPrompt == "" {
+		t.Errorf("metadata not populated: %+v", filtered[0])
+	}
+
+	limited, err := idx.ListSessions(ListOptions{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limited) != 1 {
+		t.Errorf("limit: got %d sessions, want 1", len(limited))
+	}
+}
+
+func TestIndexFindByPrefix(t *testing.T) {
+	idx := setupTestIndex(t)
+
+	tests := []struct {
+		name, prefix string
+	}{
+		{"full id", "aaaa1111-2222-3333-4444-555555555555"},
+		{"short id", "aaaa1111"},
+		{"prefix", "aaaa"},
+		{"title", "FIX-PRECOMMIT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := idx.FindByPrefix("", tt.prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.ID != "aaaa1111-2222-3333-4444-555555555555" {
+				t.Errorf("ID = %s", s.ID)
+			}
+		})
+	}
+
+	t.Run("like wildcards are literal", func(t *testing.T) {
+		if _, err := idx.FindByPrefix("", "aaaa_"); !errors.Is(err, session.ErrNotFound) {
+			t.Errorf("err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("new session within freshness window", func(t *testing.T) {
+		projDir := filepath.Join(os.Getenv("HOME"), ".claude", "projects", "-Users-test-myproject")
+		writeTestSession(t, projDir, "cccc1111-2222-3333-4444-555555555555", []string{
+			`{"type":"user","message":{"role":"user","content":"just started"},"cwd":"/Users/test/myproject","timestamp":"2026-02-03T08:00:00Z"}`,
+		})
+		s, err := idx.FindByPrefix("", "cccc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.FirstPrompt != "just started" {
+			t.Errorf("FirstPrompt = %q", s.FirstPrompt)
+		}
+	})
+}
+
+func TestListSessions_Metadata(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	projDir := filepath.Join(home, ".claude", "projects", "-Users-test-meta")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestSession(t, projDir, "long1111-2222-3333-4444-555555555555", []string{
+		`{"type":"user","version":"2.1.100","message":{"role":"user","content":"long task"},"cwd":"/Users/test/meta","timestamp":"2026-02-01T08:00:00Z"}`,
+		`{"type":"assistant","version":"2.1.101","message":{"role":"assistant","model":"claude-opus-4-5","content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":10,"cache_read_input_tokens":5000,"output_tokens":300}},"timestamp":"2026-02-01T10:30:00Z"}`,
+	})
+	writeTestSession(t, projDir, "big11111-2222-3333-4444-555555555555", []string{
+		`{"type":"user","version":"2.1.90","message":{"role":"user","content":"big task"},"cwd":"/Users/test/meta","timestamp":"2026-02-02T08:00:00Z"}`,
+		`{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":90000,"output_tokens":10}},"timestamp":"2026-02-02T08:01:00Z"}`,
+		`{"type":"user","message":{"role":"user","content":"more"},"timestamp":"2026-02-02T08:02:00Z"}`,
+	})
+
+	idx, err := Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = idx.Close() })
+
+	sessions, err := idx.ListSessions(ListOptions{SortBy: "duration"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(sessions))
+	}
+	long := sessions[0]
+	if long.ShortID != "long1111" {
+		t.Fatalf("duration sort: first = %s, want long1111", long.ShortID)
+	}
+	if long.Model != "claude-opus-4-5" || long.ContextTokens != 5010 || long.TotalOutputTokens != 300 {
+		t.Errorf("usage = %s/%d/%d", long.Model, long.ContextTokens, long.TotalOutputTokens)
+	}
+	if long.Version != "2.1.101" {
+		t.Errorf("Version = %q, want the latest message's", long.Version)
+	}
+	if long.Duration() != 150*time.Minute {
+		t.Errorf("Duration = %v, want 2h30m", long.Duration())
+	}
+
+	for sortBy, want := range map[string]string{"tokens": "big11111", "messages": "big11111"} {
+		sessions, err := idx.ListSessions(ListOptions{SortBy: sortBy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sessions[0].ShortID != want {
+			t.Errorf("sort %s: first = %s, want %s", sortBy, sessions[0].ShortID, want)
+		}
+	}
+}
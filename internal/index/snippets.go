@@ -0,0 +1,86 @@
+package index
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/andyhtran/cct/internal/session"
+)
+
+// SnippetRow is an indexed code block joined to the session it appeared in.
+type SnippetRow struct {
+	SessionID string
+	Project   string
+	Snippet   session.Snippet
+}
+
+// Snippets returns every indexed snippet matching f, in lang when lang is
+// set, oldest first. A resumed session file replays the earlier file's
+// messages with their original timestamps; only the copy in the file
+// modified first is returned. nth tells identical blocks in one message
+// apart, so those are all kept.
+func (idx *Index) Snippets(f EventFilter, lang string) ([]SnippetRow, error) {
+	idx.syncForRead()
+
+	where, args := f.where("sn.at")
+	rows, err := idx.db.Query(`
+		SELECT s.id, s.project_name, sn.seq, sn.lang, sn.code, sn.lines, sn.symbols, sn.at, sn.byte_offset
+		FROM (
+			SELECT sn.*, ROW_NUMBER() OVER (
+				PARTITION BY sn.root, sn.at, sn.code, sn.nth ORDER BY julianday(s.modified_at), s.id
+			) AS copy
+			FROM (
+				SELECT sn.*, ROW_NUMBER() OVER (
+					PARTITION BY sn.root, sn.session_id, sn.byte_offset, sn.code ORDER BY sn.seq
+				) AS nth
+				FROM snippets sn
+			) sn
+			JOIN sessions s ON sn.root = s.root AND sn.session_id = s.id
+		) sn
+		JOIN sessions s ON sn.root = s.root AND sn.session_id = s.id
+		`+where+`
+		  AND (sn.copy = 1 OR COALESCE(sn.at, '') = '')
+		  AND (? = '' OR sn.lang = ?)
+		ORDER BY sn.at, s.root, s.id, sn.seq
+	`, append(args, lang, lang)...)
+	if err != nil {
+		return nil, err
+	}
+	return scanSnippets(rows)
+}
+
+// SessionSnippets returns the snippets of one session in order.
+func (idx *Index) SessionSnippets(root, sessionID string) ([]SnippetRow, error) {
+	idx.syncForRead()
+
+	rows, err := idx.db.Query(`
+		SELECT s.id, s.project_name, sn.seq, sn.lang, sn.code, sn.lines, sn.symbols, sn.at, sn.byte_offset
+		FROM snippets sn
+		JOIN sessions s ON sn.root = s.root AND sn.session_id = s.id
+		WHERE sn.root = ? AND sn.session_id = ?
+		ORDER BY sn.seq
+	`, root, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return scanSnippets(rows)
+}
+
+func scanSnippets(rows *sql.Rows) ([]SnippetRow, error) {
+	defer func() { _ = rows.Close() }()
+
+	var out []SnippetRow
+	for rows.Next() {
+		var r SnippetRow
+		var symbols, at string
+		sn := &r.Snippet
+		if err := rows.Scan(&r.SessionID, &r.Project, &sn.Seq, &sn.Lang, &sn.Code, &sn.Lines, &symbols, &at, &sn.Offset); err != nil {
+			return nil, err
+		}
+		sn.Symbols = strings.Fields(symbols)
+		sn.At, _ = time.Parse(time.RFC3339Nano, at)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
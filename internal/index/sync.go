@@ -8,10 +8,12 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/andyhtran/cct/internal/backup"
+	"github.com/andyhtran/cct/internal/paths"
 	"github.com/andyhtran/cct/internal/session"
 )
 
@@ -21,6 +23,7 @@ type fileInfo struct {
 }
 
 type indexedFile struct {
+	root       string
 	sessionID  string
 	modifiedAt time.Time
 	fileSize   int64
@@ -34,10 +37,36 @@ type indexedMessage struct {
 	byteLength int
 }
 
+// maxToolTarget caps the command or path kept per tool call; a command's
+// leading words are what permission rules match.
+const maxToolTarget = 1024
+
 type indexedSession struct {
-	session  *session.Session
-	messages []indexedMessage
-	fileSize int64
+	session   *session.Session
+	messages  []indexedMessage
+	toolCalls []*session.ToolCall
+	times     []messageTime
+	lineage   *session.Lineage
+	prompts   []humanPrompt
+	failures  []*session.Failure
+	links     []*session.Link
+	snippets  []*session.Snippet
+	fileSize  int64
+}
+
+// humanPrompt is a prompt a person typed, kept for cct prompts.
+type humanPrompt struct {
+	text       string
+	at         time.Time
+	byteOffset int64
+}
+
+// messageTime is the timestamp of one user or assistant record, kept for
+// activity and active-time stats.
+type messageTime struct {
+	role string
+	at   time.Time
+	uuid string
 }
 
 type SyncResult struct {
@@ -46,6 +75,7 @@ type SyncResult struct {
 	Adopted   int
 	Deleted   int
 	Unchanged int
+	skipped   bool // the last sync was recent enough that nothing was scanned
 }
 
 func (r *SyncResult) UpToDate() bool {
@@ -78,7 +108,7 @@ func (idx *Index) syncInternal(includeAgents bool, force bool, progress io.Write
 	defer idx.syncMu.Unlock()
 
 	if !force && idx.recentlySynced() {
-		return &SyncResult{}, nil
+		return &SyncResult{skipped: true}, nil
 	}
 
 	lock, err := acquireLock(idx.path + ".lock")
@@ -96,6 +126,15 @@ func (idx *Index) syncLocked(includeAgents bool, progress io.Writer) (*SyncResul
 	if err != nil {
 		return nil, fmt.Errorf("get indexed files: %w", err)
 	}
+	opts := currentOptions()
+	if !idx.builtWith(opts) {
+		// The settings changed since these sessions were indexed; an
+		// impossible size makes computeChanges treat every one as modified.
+		for path, f := range indexed {
+			f.fileSize = -1
+			indexed[path] = f
+		}
+	}
 
 	toAdd, toUpdate, toDelete := computeChanges(current, indexed)
 	unchanged := len(indexed) - len(toUpdate) - len(toDelete)
@@ -110,11 +149,11 @@ func (idx *Index) syncLocked(includeAgents bool, progress io.Writer) (*SyncResul
 	// reality.
 	deletedIDs := make(map[string]bool, len(toDelete))
 	for _, p := range toDelete {
-		deletedIDs[session.ExtractIDFromFilename(p)] = true
+		deletedIDs[fileKey(p)] = true
 	}
 	adopted := 0
 	for _, p := range toAdd {
-		if deletedIDs[session.ExtractIDFromFilename(p)] {
+		if deletedIDs[fileKey(p)] {
 			adopted++
 		}
 	}
@@ -127,7 +166,10 @@ func (idx *Index) syncLocked(includeAgents bool, progress io.Writer) (*SyncResul
 		Unchanged: unchanged,
 	}
 
+	idx.syncHistory()
+
 	if result.UpToDate() {
+		idx.recordOptions(opts)
 		idx.updateSyncTime()
 		return result, nil
 	}
@@ -150,7 +192,7 @@ func (idx *Index) syncLocked(includeAgents bool, progress io.Writer) (*SyncResul
 	allPaths := make([]string, 0, len(toAdd)+len(toUpdate))
 	allPaths = append(allPaths, toAdd...)
 	allPaths = append(allPaths, toUpdate...)
-	if err := idx.indexBatches(tx, allPaths, indexed, total, progress); err != nil {
+	if err := idx.indexBatches(tx, allPaths, indexed, total, opts, progress); err != nil {
 		return nil, err
 	}
 
@@ -158,30 +200,30 @@ func (idx *Index) syncLocked(includeAgents bool, progress io.Writer) (*SyncResul
 		return nil, err
 	}
 
+	idx.recordOptions(opts)
 	idx.updateSyncTime()
 	return result, nil
 }
 
-// discoverCurrentFiles returns every JSONL file we should index. Live files
-// under ~/.claude/projects/ are primary; backup files under the cct cache
-// dir are secondary. When the same session ID appears in both (the normal
-// post-backup state), the live path wins so the index tracks writes at their
-// source. When a live file has been cleaned up by Claude Code, the backup
-// path stands in — that's the whole point of the backup: the session stays
-// searchable even after upstream deletion.
+// discoverCurrentFiles returns every JSONL file we should index, across all
+// configured roots. Live files under <root>/projects/ are primary; backup
+// files under the cct cache dir are secondary. When the same session appears
+// in both (the normal post-backup state), the live path wins so the index
+// tracks writes at their source. When a live file has been cleaned up by
+// Claude Code, the backup path stands in — that's the whole point of the
+// backup: the session stays searchable even after upstream deletion.
 func discoverCurrentFiles(includeAgents bool) map[string]fileInfo {
-	live := session.DiscoverFiles("", includeAgents)
+	live := session.DiscoverFiles("", "", includeAgents)
 	backups := backup.DiscoverBackupSources()
 
 	chosen := make(map[string]string, len(live)+len(backups))
 	for _, path := range live {
-		id := session.ExtractIDFromFilename(path)
-		chosen[id] = path
+		chosen[fileKey(path)] = path
 	}
 	for _, path := range backups {
-		id := session.ExtractIDFromFilename(path)
-		if _, ok := chosen[id]; !ok {
-			chosen[id] = path
+		key := fileKey(path)
+		if _, ok := chosen[key]; !ok {
+			chosen[key] = path
 		}
 	}
 
@@ -199,6 +241,12 @@ func discoverCurrentFiles(includeAgents bool) map[string]fileInfo {
 	return current
 }
 
+// fileKey identifies the session a file holds. Session IDs are only unique
+// within a root, so the root is part of the key.
+func fileKey(path string) string {
+	return paths.RootOf(path) + "/" + session.ExtractIDFromFilename(path)
+}
+
 func computeChanges(current map[string]fileInfo, indexed map[string]indexedFile) (toAdd, toUpdate, toDelete []string) {
 	for path, info := range current {
 		if existing, ok := indexed[path]; !ok {
@@ -217,28 +265,29 @@ func computeChanges(current map[string]fileInfo, indexed map[string]indexedFile)
 
 func (idx *Index) deleteRemovedSessions(tx *sql.Tx, toDelete []string, indexed map[string]indexedFile) error {
 	for _, path := range toDelete {
-		sessionID := indexed[path].sessionID
-		if err := idx.deleteSessionData(tx, sessionID); err != nil {
+		f := indexed[path]
+		sessionID := f.sessionID
+		if err := idx.deleteSessionData(tx, f.root, sessionID); err != nil {
 			return fmt.Errorf("delete session %s: %w", sessionID, err)
 		}
 	}
 	return nil
 }
 
-func (idx *Index) indexBatches(tx *sql.Tx, allPaths []string, indexed map[string]indexedFile, total int, progress io.Writer) error {
+func (idx *Index) indexBatches(tx *sql.Tx, allPaths []string, indexed map[string]indexedFile, total int, opts indexOptions, progress io.Writer) error {
 	var processed int64
 	for i := 0; i < len(allPaths); i += batchSize {
 		end := min(i+batchSize, len(allPaths))
 		batch := allPaths[i:end]
 
-		results := parallelIndex(batch)
+		results := parallelIndex(batch, opts)
 
 		for _, r := range results {
 			if r.err != nil {
 				continue
 			}
 			if _, ok := indexed[r.session.session.FilePath]; ok {
-				if err := idx.deleteSessionData(tx, r.session.session.ID); err != nil {
+				if err := idx.deleteSessionData(tx, r.session.session.Root, r.session.session.ID); err != nil {
 					return fmt.Errorf("delete for update %s: %w", r.session.session.ID, err)
 				}
 			}
@@ -279,6 +328,7 @@ func (idx *Index) RebuildWithProgress(includeAgents bool, progress io.Writer) (*
 	if _, err := idx.db.Exec(`
 		CREATE TABLE content_map (
 			rowid INTEGER PRIMARY KEY,
+			root TEXT NOT NULL,
 			session_id TEXT NOT NULL,
 			role TEXT NOT NULL,
 			source TEXT,
@@ -288,12 +338,17 @@ func (idx *Index) RebuildWithProgress(includeAgents bool, progress io.Writer) (*
 	`); err != nil {
 		return nil, err
 	}
-	if _, err := idx.db.Exec("CREATE INDEX idx_content_map_session ON content_map(session_id)"); err != nil {
+	if _, err := idx.db.Exec("CREATE INDEX idx_content_map_session ON content_map(root, session_id)"); err != nil {
 		return nil, err
 	}
 	if _, err := idx.db.Exec("DELETE FROM sessions"); err != nil {
 		return nil, err
 	}
+	for _, table := range sessionTables {
+		if _, err := idx.db.Exec("DELETE FROM " + table); err != nil {
+			return nil, err
+		}
+	}
 	if _, err := idx.db.Exec(`
 		CREATE VIRTUAL TABLE content_fts USING fts5(
 			text,
@@ -304,7 +359,7 @@ func (idx *Index) RebuildWithProgress(includeAgents bool, progress io.Writer) (*
 	`); err != nil {
 		return nil, err
 	}
-	if _, err := idx.db.Exec("DELETE FROM index_meta WHERE key = 'last_sync_time'"); err != nil {
+	if _, err := idx.db.Exec("DELETE FROM index_meta WHERE key = 'last_sync_time' OR key LIKE 'history:%'"); err != nil {
 		return nil, err
 	}
 	if _, err := idx.db.Exec("VACUUM"); err != nil {
@@ -315,7 +370,7 @@ func (idx *Index) RebuildWithProgress(includeAgents bool, progress io.Writer) (*
 }
 
 func (idx *Index) getIndexedFiles() (map[string]indexedFile, error) {
-	rows, err := idx.db.Query("SELECT id, file_path, modified_at, file_size FROM sessions")
+	rows, err := idx.db.Query("SELECT root, id, file_path, modified_at, file_size FROM sessions")
 	if err != nil {
 		return nil, err
 	}
@@ -323,13 +378,14 @@ func (idx *Index) getIndexedFiles() (map[string]indexedFile, error) {
 
 	result := make(map[string]indexedFile)
 	for rows.Next() {
-		var id, path, modifiedStr string
+		var root, id, path, modifiedStr string
 		var size int64
-		if err := rows.Scan(&id, &path, &modifiedStr, &size); err != nil {
+		if err := rows.Scan(&root, &id, &path, &modifiedStr, &size); err != nil {
 			return nil, err
 		}
 		modified, _ := time.Parse(time.RFC3339, modifiedStr)
 		result[path] = indexedFile{
+			root:       root,
 			sessionID:  id,
 			modifiedAt: modified,
 			fileSize:   size,
@@ -340,7 +396,7 @@ func (idx *Index) getIndexedFiles() (map[string]indexedFile, error) {
 
 func (idx *Index) insertSession(tx *sql.Tx, s *indexedSession) error {
 	sess := s.session
-	projectDir := filepath.Base(filepath.Dir(sess.FilePath))
+	projectDir := projectDirOf(sess.FilePath)
 
 	var createdAt string
 	if !sess.Created.IsZero() {
@@ -348,22 +404,25 @@ func (idx *Index) insertSession(tx *sql.Tx, s *indexedSession) error {
 	}
 
 	_, err := tx.Exec(`
-		INSERT OR REPLACE INTO sessions (id, file_path, project_dir, project_name, project_path, is_agent, modified_at, file_size,
-			first_prompt, created_at, git_branch, message_count, custom_title, agent_type, agent_description)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, sess.ID, sess.FilePath, projectDir, sess.ProjectName, sess.ProjectPath, boolToInt(sess.IsAgent),
+		INSERT OR REPLACE INTO sessions (root, id, file_path, project_dir, project_name, project_path, is_agent, modified_at, file_size,
+			first_prompt, created_at, git_branch, message_count, custom_title, agent_type, agent_description,
+			model, context_tokens, peak_context_tokens, total_output_tokens, first_message_at, last_message_at, version, parent_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, sess.Root, sess.ID, sess.FilePath, projectDir, sess.ProjectName, sess.ProjectPath, boolToInt(sess.IsAgent),
 		sess.Modified.Format(time.RFC3339), s.fileSize,
 		sess.FirstPrompt, createdAt, sess.GitBranch, sess.MessageCount, sess.CustomTitle,
-		sess.AgentType, sess.AgentDescription)
+		sess.AgentType, sess.AgentDescription,
+		sess.Model, sess.ContextTokens, sess.PeakContextTokens, sess.TotalOutputTokens,
+		formatMessageTime(sess.FirstMessageAt), formatMessageTime(sess.LastMessageAt), sess.Version, sess.ParentID)
 	if err != nil {
 		return err
 	}
 
 	for _, m := range s.messages {
 		res, err := tx.Exec(`
-			INSERT INTO content_map (session_id, role, source, byte_offset, byte_length)
-			VALUES (?, ?, ?, ?, ?)
-		`, sess.ID, m.role, m.source, m.byteOffset, m.byteLength)
+			INSERT INTO content_map (root, session_id, role, source, byte_offset, byte_length)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, sess.Root, sess.ID, m.role, m.source, m.byteOffset, m.byteLength)
 		if err != nil {
 			return err
 		}
@@ -382,6 +441,122 @@ func (idx *Index) insertSession(tx *sql.Tx, s *indexedSession) error {
 		}
 	}
 
+	for _, c := range s.toolCalls {
+		var outputBytes any
+		if c.HasResult {
+			outputBytes = c.OutputBytes
+		}
+		target := session.ToolTarget(c.Input)
+		if len(target) > maxToolTarget {
+			target = strings.ToValidUTF8(target[:maxToolTarget], "")
+		}
+		denied := c.IsError && session.IsPermissionDenial(c.Output)
+		if _, err := tx.Exec(`
+			INSERT INTO tool_calls (root, session_id, tool_use_id, name, called_at, result_at, is_error, output_bytes, target, denied)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, sess.Root, sess.ID, c.ID, c.Name, formatMessageTime(c.CalledAt), formatMessageTime(c.ResultAt),
+			boolToInt(c.IsError), outputBytes, target, boolToInt(denied)); err != nil {
+			return err
+		}
+
+		if path := session.EditedPath(c); path != "" && !c.IsError {
+			if _, err := tx.Exec(`
+				INSERT INTO file_edits (root, session_id, tool_use_id, tool, path, edited_at, byte_offset)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+			`, sess.Root, sess.ID, c.ID, c.Name, path, formatMessageTime(c.CalledAt), c.Offset); err != nil {
+				return err
+			}
+		}
+		committedAt := c.ResultAt
+		if committedAt.IsZero() {
+			committedAt = c.CalledAt
+		}
+		for _, commit := range session.CommitsFromCall(c) {
+			if _, err := tx.Exec(`
+				INSERT INTO commits (root, session_id, tool_use_id, sha, branch, subject, committed_at, byte_offset)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			`, sess.Root, sess.ID, c.ID, commit.SHA, commit.Branch, commit.Subject,
+				formatMessageTime(committedAt), c.Offset); err != nil {
+				return err
+			}
+		}
+	}
+
+	if states := session.TodoStates(s.toolCalls); len(states) > 0 {
+		last := states[len(states)-1]
+		for i, t := range last.Todos {
+			if _, err := tx.Exec(`
+				INSERT INTO todos (root, session_id, position, content, active_form, status,
+					added_at, started_at, completed_at, updated_at, byte_offset)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, sess.Root, sess.ID, i, t.Content, t.ActiveForm, t.Status,
+				formatMessageTime(t.AddedAt), formatMessageTime(t.StartedAt), formatMessageTime(t.CompletedAt),
+				formatMessageTime(last.At), last.Offset); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, p := range s.prompts {
+		if _, err := tx.Exec(`
+			INSERT INTO human_prompts (root, session_id, at, text, byte_offset)
+			VALUES (?, ?, ?, ?, ?)
+		`, sess.Root, sess.ID, formatMessageTime(p.at), p.text, p.byteOffset); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range s.failures {
+		if _, err := tx.Exec(`
+			INSERT INTO failures (root, session_id, kind, tool, input, signature, text, at, byte_offset)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, sess.Root, sess.ID, f.Kind, f.Tool, f.Input, f.Signature, f.Text, formatMessageTime(f.At), f.Offset); err != nil {
+			return err
+		}
+	}
+
+	for _, l := range s.links {
+		if _, err := tx.Exec(`
+			INSERT INTO links (root, session_id, url, domain, title, source, context, at, byte_offset)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, sess.Root, sess.ID, l.URL, l.Domain, l.Title, l.Source, l.Context, formatMessageTime(l.At), l.Offset); err != nil {
+			return err
+		}
+	}
+
+	for _, sn := range s.snippets {
+		if _, err := tx.Exec(`
+			INSERT INTO snippets (root, session_id, seq, lang, code, lines, symbols, at, byte_offset)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, sess.Root, sess.ID, sn.Seq, sn.Lang, sn.Code, sn.Lines, strings.Join(sn.Symbols, " "), formatMessageTime(sn.At), sn.Offset); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range s.times {
+		if _, err := tx.Exec(`
+			INSERT INTO message_times (root, session_id, role, at, uuid)
+			VALUES (?, ?, ?, ?, ?)
+		`, sess.Root, sess.ID, t.role, formatMessageTime(t.at), t.uuid); err != nil {
+			return err
+		}
+	}
+
+	// Subagents replay nothing from other files; their lineage would only
+	// add rows.
+	if s.lineage != nil && !sess.IsAgent {
+		for kind, uuids := range map[string][]string{"message": s.lineage.UUIDs(), "ref": s.lineage.Refs()} {
+			for _, id := range uuids {
+				if _, err := tx.Exec(`
+					INSERT INTO lineage (root, session_id, uuid, kind)
+					VALUES (?, ?, ?, ?)
+				`, sess.Root, sess.ID, id, kind); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	// Index the agent sidecar description so search can hit agents by their
 	// task title, which is often absent from the JSONL body. byte_offset=0
 	// and byte_length=0 flag this as a synthetic row — the snippet path
@@ -389,9 +564,9 @@ func (idx *Index) insertSession(tx *sql.Tx, s *indexedSession) error {
 	// the file.
 	if sess.AgentDescription != "" {
 		res, err := tx.Exec(`
-			INSERT INTO content_map (session_id, role, source, byte_offset, byte_length)
-			VALUES (?, 'description', 'agent', 0, 0)
-		`, sess.ID)
+			INSERT INTO content_map (root, session_id, role, source, byte_offset, byte_length)
+			VALUES (?, ?, 'description', 'agent', 0, 0)
+		`, sess.Root, sess.ID)
 		if err != nil {
 			return err
 		}
@@ -411,6 +586,27 @@ func (idx *Index) insertSession(tx *sql.Tx, s *indexedSession) error {
 	return nil
 }
 
+// projectDirOf returns the encoded project directory name a session file
+// lives under, looking through the nested <parentID>/subagents/ layout so
+// project filters match subagents the same way discovery does.
+func projectDirOf(path string) string {
+	dir := filepath.Dir(path)
+	if filepath.Base(dir) == "subagents" {
+		dir = filepath.Dir(filepath.Dir(dir))
+	}
+	return filepath.Base(dir)
+}
+
+// formatMessageTime stores message timestamps in UTC with sub-second
+// precision so they compare as text and work with julianday(). The zero
+// time is stored as "".
+func formatMessageTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
 func boolToInt(b bool) int {
 	if b {
 		return 1
@@ -423,11 +619,11 @@ type indexResult struct {
 	err     error
 }
 
-func parallelIndex(files []string) []indexResult {
-	return parallelIndexWithProgress(files, nil)
+func parallelIndex(files []string, opts indexOptions) []indexResult {
+	return parallelIndexWithProgress(files, opts, nil)
 }
 
-func parallelIndexWithProgress(files []string, progress io.Writer) []indexResult {
+func parallelIndexWithProgress(files []string, opts indexOptions, progress io.Writer) []indexResult {
 	if len(files) == 0 {
 		return nil
 	}
@@ -449,7 +645,7 @@ func parallelIndexWithProgress(files []string, progress io.Writer) []indexResult
 		go func() {
 			defer wg.Done()
 			for path := range jobs {
-				s, err := indexSession(path)
+				s, err := indexSession(path, opts)
 				results <- indexResult{session: s, err: err}
 			}
 		}()
@@ -483,7 +679,7 @@ func parallelIndexWithProgress(files []string, progress io.Writer) []indexResult
 	return out
 }
 
-func indexSession(path string) (*indexedSession, error) {
+func indexSession(path string, opts indexOptions) (*indexedSession, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -497,20 +693,36 @@ func indexSession(path string) (*indexedSession, error) {
 
 	s := &session.Session{
 		ID:       session.ExtractIDFromFilename(path),
+		Root:     paths.RootOf(path),
 		FilePath: path,
 		Modified: info.ModTime(),
+		FileSize: info.Size(),
 	}
 	s.ShortID = session.ShortID(s.ID)
 	s.IsAgent = session.IsAgentSession(s.ID)
 	session.LoadAgentMeta(s, path)
+	session.LoadParentID(s, path)
 
 	scanner := session.NewOffsetScanner(f)
 	var messages []indexedMessage
 	var messageCount int
+	var times []messageTime
+	var prompts []humanPrompt
+	tools := session.NewToolTracker()
+	// Git commit output names the commit; error output says whether the
+	// call was denied permission.
+	tools.KeepOutput = func(c *session.ToolCall) bool { return c.IsError || session.IsGitCommit(c) }
+	toolNames := session.ToolNames{}
+	outputIndexed := map[string]int{} // bytes of output indexed per tool_use_id
+	lineage := session.NewLineage()
+	failures := session.NewFailureTracker()
+	links := session.NewLinkTracker()
+	snippets := session.NewSnippetTracker()
 
 	for scanner.Scan() {
 		line := scanner.Bytes()
 		lineType := session.FastExtractType(line)
+		lineage.Observe(line)
 
 		// custom-title records carry the /rename title and are rewritten
 		// each turn — latest wins. They don't contribute to FTS content.
@@ -530,6 +742,10 @@ func indexSession(path string) (*indexedSession, error) {
 		}
 
 		messageCount++
+		session.ExtractMessageMeta(s, line)
+		if lineType == "assistant" {
+			session.ExtractAssistantUsage(s, line)
+		}
 		byteOffset := scanner.Offset()
 		byteLength := scanner.Length()
 
@@ -540,32 +756,83 @@ func indexSession(path string) (*indexedSession, error) {
 
 		if lineType == "user" {
 			session.ExtractUserMetadata(s, obj)
+			if text := session.HumanPrompt(obj); text != "" {
+				prompts = append(prompts, humanPrompt{text: text, at: session.ParseTimestamp(obj), byteOffset: byteOffset})
+			}
 		}
+		if ts := session.ParseTimestamp(obj); !ts.IsZero() {
+			uuid, _ := obj["uuid"].(string)
+			times = append(times, messageTime{role: lineType, at: ts, uuid: uuid})
+		}
+		tools.Observe(obj, byteOffset)
+		failures.Observe(obj, byteOffset)
+		links.Observe(obj, byteOffset)
+		snippets.Observe(obj, byteOffset)
 
 		blocks := session.ExtractPromptBlocks(obj)
+		toolNames.Tag(blocks)
 		for _, block := range blocks {
-			if block.Text == "" {
+			text := block.Text
+			if block.ToolResult {
+				text = capToolOutput(text, opts.toolOutput.Limit(block.Source), block.ToolUseID, outputIndexed)
+			}
+			if text == "" {
 				continue
 			}
 			messages = append(messages, indexedMessage{
-				role:       lineType,
+				role:       block.Role(lineType),
 				source:     block.Source,
-				text:       block.Text,
+				text:       text,
 				byteOffset: byteOffset,
 				byteLength: byteLength,
 			})
 		}
+		if opts.thinking && lineType == "assistant" {
+			for _, text := range session.ThinkingBlocks(obj) {
+				messages = append(messages, indexedMessage{
+					role:       "thinking",
+					text:       text,
+					byteOffset: byteOffset,
+					byteLength: byteLength,
+				})
+			}
+		}
 	}
 
 	s.MessageCount = messageCount
 
 	return &indexedSession{
-		session:  s,
-		messages: messages,
-		fileSize: info.Size(),
+		session:   s,
+		messages:  messages,
+		toolCalls: tools.Calls(),
+		times:     times,
+		lineage:   lineage,
+		prompts:   prompts,
+		failures:  failures.Failures(),
+		links:     links.Links(),
+		snippets:  snippets.Snippets(),
+		fileSize:  info.Size(),
 	}, nil
 }
 
+// capToolOutput trims one block of a tool's output so the call's indexed
+// total stays within limit bytes, cutting on a rune boundary. used tracks
+// the bytes already indexed per call, as a result can span several blocks.
+func capToolOutput(text string, limit int, toolUseID string, used map[string]int) string {
+	if limit < 0 {
+		return text
+	}
+	room := limit - used[toolUseID]
+	if room <= 0 {
+		return ""
+	}
+	if len(text) > room {
+		text = strings.ToValidUTF8(text[:room], "")
+	}
+	used[toolUseID] += len(text)
+	return text
+}
+
 func (idx *Index) recentlySynced() bool {
 	if !idx.lastSyncTime.IsZero() && time.Since(idx.lastSyncTime) < syncCacheDuration {
 		return true
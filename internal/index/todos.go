@@ -0,0 +1,72 @@
+package index
+
+import (
+	"time"
+
+	"github.com/andyhtran/cct/internal/session"
+)
+
+// TodoRow is one item of the list a session's last TodoWrite call left.
+// UpdatedAt and Offset belong to that call.
+type TodoRow struct {
+	Session   *session.Session
+	Todo      session.Todo
+	Position  int
+	UpdatedAt time.Time
+	Offset    int64
+}
+
+// OpenTodos returns the items still pending or in progress at the end of
+// each matching session, most recently updated sessions first and list
+// order within a session. The window applies to the last TodoWrite call.
+// A resumed conversation replays its TodoWrite calls into the later file,
+// so only the last session of each resume chain is listed.
+func (idx *Index) OpenTodos(f EventFilter) ([]TodoRow, error) {
+	idx.syncForRead()
+
+	where, args := f.where("t.updated_at")
+	rows, err := idx.db.Query(`
+		SELECT `+sessionColumns+`, t.position, t.content, t.active_form, t.status,
+			t.added_at, t.started_at, t.completed_at, t.updated_at, t.byte_offset
+		FROM todos t
+		JOIN sessions s ON t.root = s.root AND t.session_id = s.id
+		`+where+`
+		  AND t.status != ?
+		ORDER BY t.updated_at DESC, s.root, s.id, t.position
+	`, append(args, session.TodoCompleted)...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	chains, err := idx.Chains(f.Root)
+	if err != nil {
+		return nil, err
+	}
+	superseded := map[string]bool{}
+	for _, chain := range chains {
+		for _, s := range chain[:len(chain)-1] {
+			superseded[s.Root+"/"+s.ID] = true
+		}
+	}
+
+	var out []TodoRow
+	for rows.Next() {
+		var r TodoRow
+		var added, started, completed, updated string
+		r.Session, err = scanSession(rows, &r.Position, &r.Todo.Content, &r.Todo.ActiveForm, &r.Todo.Status,
+			&added, &started, &completed, &updated, &r.Offset)
+		if err != nil {
+			return nil, err
+		}
+		if superseded[r.Session.Root+"/"+r.Session.ID] {
+			continue
+		}
+		r.Todo.AddedAt, _ = time.Parse(time.RFC3339Nano, added)
+		r.Todo.StartedAt, _ = time.Parse(time.RFC3339Nano, started)
+		r.Todo.CompletedAt, _ = time.Parse(time.RFC3339Nano, completed)
+		r.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updated)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
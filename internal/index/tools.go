@@ -0,0 +1,75 @@
+package index
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ToolCallRow is one indexed tool call joined to its session.
+type ToolCallRow struct {
+	SessionID   string
+	Project     string
+	ProjectPath string
+	Name        string
+	CalledAt    time.Time
+	ResultAt    time.Time
+	HasResult   bool
+	IsError     bool
+	OutputBytes int
+	Target      string // the command, path, URL, or pattern the call acted on
+	Denied      bool   // refused permission rather than run
+}
+
+// Latency is the tool_use → tool_result gap, or 0 when unknown.
+func (r *ToolCallRow) Latency() time.Duration {
+	if !r.HasResult || r.CalledAt.IsZero() || r.ResultAt.IsZero() {
+		return 0
+	}
+	return r.ResultAt.Sub(r.CalledAt)
+}
+
+// ToolCalls returns every indexed tool call matching f, oldest first. A
+// resumed session file repeats the earlier file's calls under the same
+// tool_use_id; only the copy in the file modified first is returned.
+func (idx *Index) ToolCalls(f EventFilter) ([]ToolCallRow, error) {
+	idx.syncForRead()
+
+	where, args := f.where("t.called_at")
+	rows, err := idx.db.Query(`
+		SELECT s.id, s.project_name, s.project_path, t.name, t.called_at, t.result_at, t.is_error, t.output_bytes, t.target, t.denied
+		FROM (
+			SELECT t.*, ROW_NUMBER() OVER (
+				PARTITION BY t.root, t.tool_use_id ORDER BY julianday(s.modified_at), s.id
+			) AS copy
+			FROM tool_calls t
+			JOIN sessions s ON t.root = s.root AND t.session_id = s.id
+		) t
+		JOIN sessions s ON t.root = s.root AND t.session_id = s.id
+		`+where+`
+		  AND (t.copy = 1 OR t.tool_use_id = '')
+		ORDER BY t.called_at
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []ToolCallRow
+	for rows.Next() {
+		var r ToolCallRow
+		var calledAt, resultAt string
+		var isError, denied int
+		var outputBytes sql.NullInt64
+		if err := rows.Scan(&r.SessionID, &r.Project, &r.ProjectPath, &r.Name, &calledAt, &resultAt, &isError, &outputBytes, &r.Target, &denied); err != nil {
+			return nil, err
+		}
+		r.CalledAt, _ = time.Parse(time.RFC3339Nano, calledAt)
+		r.ResultAt, _ = time.Parse(time.RFC3339Nano, resultAt)
+		r.HasResult = outputBytes.Valid
+		r.IsError = isError == 1
+		r.Denied = denied == 1
+		r.OutputBytes = int(outputBytes.Int64)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,724 @@
+// Package patch composes the edits a session made to a file into one
+// unified diff. Claude Code records each Edit, MultiEdit, and Write as a
+// small patch against the file as it was at that moment; the file itself
+// is usually never seen in full. File keeps a sparse model of it — the
+// lines the patches showed, with gaps for the rest — so later patches can
+// be applied on top of earlier ones and the net change printed.
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// ErrConflict means a hunk's context or removed lines don't match what
+// earlier patches showed at that position.
+var ErrConflict = errors.New("hunk does not match the file")
+
+// ErrUnknownContent means an operation needed the whole file, but only
+// parts of it are known.
+var ErrUnknownContent = errors.New("file content is not fully known")
+
+// Hunk is one hunk of a unified diff. OldStart is the 1-based line of the
+// first old line in the range (the line the insertion goes before when
+// OldLines is 0), as in jsdiff's structuredPatch. Lines carry their " ",
+// "-", or "+" prefix; "\\" lines mark a missing newline at end of file.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []string
+	// Display marks lines recorded the way Claude Code shows them, with
+	// every tab expanded to two spaces, rather than byte for byte.
+	Display bool
+}
+
+type kind int
+
+const (
+	kept    kind = iota // original line, unchanged
+	added               // line the edits introduced
+	deleted             // original line the edits removed; takes no place in the current file
+	gap                 // run of original lines no patch has shown
+)
+
+type elem struct {
+	kind   kind
+	text   string
+	orig   int  // 1-based original line number; for a gap, its first line
+	n      int  // gap length; -1 for the open run to end of file
+	noEOL  bool // last line without a trailing newline
+	approx bool // text rebuilt from display form; tabs are a guess
+}
+
+// size is how many lines of the current file e covers.
+func (e *elem) size() int {
+	switch e.kind {
+	case deleted:
+		return 0
+	case gap:
+		return e.n
+	}
+	return 1
+}
+
+// hunkLine is one parsed hunk line.
+type hunkLine struct {
+	op     byte
+	text   string
+	approx bool
+}
+
+// File is the sparse model of one file being edited.
+type File struct {
+	Path    string
+	created bool // did not exist before the first change
+	tabs    bool // indents with tabs, so display-form indentation maps back to tabs
+	seq     []*elem
+}
+
+// NewFile returns a file whose original content is unknown. Go sources and
+// makefiles are assumed to indent with tabs until their content says so.
+func NewFile(path string) *File {
+	base := filepath.Base(path)
+	tabs := filepath.Ext(base) == ".go" || base == "Makefile" || base == "go.mod" || base == "go.work"
+	return &File{Path: path, tabs: tabs, seq: []*elem{{kind: gap, orig: 1, n: -1}}}
+}
+
+// Created reports whether the edits created the file.
+func (f *File) Created() bool {
+	return f.created
+}
+
+// Approximate reports whether any line the diff shows was rebuilt from
+// display form, so its whitespace may not match the file.
+func (f *File) Approximate() bool {
+	for i, e := range f.seq {
+		if !e.approx {
+			continue
+		}
+		if e.kind != kept {
+			return true
+		}
+		for _, o := range f.seq[max(0, i-3):min(len(f.seq), i+4)] {
+			if o.kind == added || o.kind == deleted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Create records the file being written from scratch: the original is
+// empty and content is all new.
+func (f *File) Create(content string) {
+	f.created = true
+	f.seq = nil
+	for _, l := range splitLines(content) {
+		f.noteTabs(l.text)
+		f.seq = append(f.seq, &elem{kind: added, text: l.text, noEOL: l.noEOL})
+	}
+}
+
+// SetOriginal fills in the original content when no change has been made
+// yet, so the diff can show full context. It does nothing otherwise.
+func (f *File) SetOriginal(content string) {
+	if f.created || len(f.seq) != 1 || f.seq[0].kind != gap || f.seq[0].orig != 1 {
+		return
+	}
+	f.seq = nil
+	for i, l := range splitLines(content) {
+		f.noteTabs(l.text)
+		f.seq = append(f.seq, &elem{kind: kept, text: l.text, orig: i + 1, noEOL: l.noEOL})
+	}
+}
+
+// Observe records lines of the file as it is now, read byte for byte:
+// content holds the lines from 1-based line start on, and total, when
+// above zero, is the file's length in lines. Lines already known to differ
+// are left alone; the file may have changed in ways no patch recorded.
+func (f *File) Observe(start int, content string, total int) {
+	lines := splitLines(content)
+	for k, l := range lines {
+		f.noteTabs(l.text)
+		i, err := locate(&f.seq, start+k)
+		for err == nil && i < len(f.seq) && f.seq[i].kind == deleted {
+			i++
+		}
+		if err != nil || i == len(f.seq) {
+			return
+		}
+		switch e := f.seq[i]; {
+		case e.kind == gap:
+			splitGap(&f.seq, i, l.text, false)
+		case e.approx && display(e.text) == display(l.text):
+			e.text, e.approx = l.text, false
+		}
+	}
+	if total > 0 && start+len(lines)-1 == total {
+		i, err := locate(&f.seq, total+1)
+		if err == nil && i < len(f.seq) && f.seq[i].kind == gap && f.seq[i].n < 0 {
+			f.seq = append(f.seq[:i], f.seq[i+1:]...)
+		}
+	}
+}
+
+// Content returns the current file content, or ErrUnknownContent while
+// any of it is unknown.
+func (f *File) Content() (string, error) {
+	var b strings.Builder
+	for _, e := range f.seq {
+		switch e.kind {
+		case gap:
+			return "", ErrUnknownContent
+		case deleted:
+			continue
+		}
+		b.WriteString(e.text)
+		if !e.noEOL {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String(), nil
+}
+
+// Apply applies one hunk, in the coordinates of the file as the earlier
+// hunks left it. On error the file is unchanged.
+func (f *File) Apply(h Hunk) error {
+	lines, err := f.parse(h)
+	if err != nil {
+		return err
+	}
+	return f.apply(h.OldStart, lines)
+}
+
+// ApplyAll applies the hunks of one patch, all in the coordinates of the
+// file before the patch. Either every hunk applies or the file is left
+// unchanged.
+func (f *File) ApplyAll(hunks []Hunk) error {
+	saved := f.seq
+	// Last hunk first, so earlier hunks' line numbers still hold.
+	for i := len(hunks) - 1; i >= 0; i-- {
+		if err := f.Apply(hunks[i]); err != nil {
+			f.seq = saved
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyEdit applies the recorded patch of an Edit call that replaced
+// oldText with newText once. When the patch is in display form, the lines
+// oldText and newText cover are rebuilt byte for byte from them.
+func (f *File) ApplyEdit(hunks []Hunk, oldText, newText string) error {
+	f.noteTabs(oldText)
+	f.noteTabs(newText)
+	if len(hunks) != 1 || !hunks[0].Display {
+		return f.ApplyAll(hunks)
+	}
+	lines, err := f.parse(hunks[0])
+	if err != nil {
+		return err
+	}
+	f.resolve(hunks[0].OldStart, lines)
+	f.rebuildEdit(lines, oldText, newText)
+	return f.apply(hunks[0].OldStart, lines)
+}
+
+func (f *File) parse(h Hunk) ([]hunkLine, error) {
+	lines := make([]hunkLine, 0, len(h.Lines))
+	for _, hl := range h.Lines {
+		if hl == "" {
+			hl = " "
+		}
+		l := hunkLine{op: hl[0], text: hl[1:]}
+		switch l.op {
+		case ' ', '-', '+':
+			if h.Display {
+				l.text, l.approx = f.undisplay(l.text), true
+			} else {
+				f.noteTabs(l.text)
+			}
+		case '\\':
+		default:
+			return nil, fmt.Errorf("%w: bad line %q", ErrConflict, hl)
+		}
+		lines = append(lines, l)
+	}
+	return lines, nil
+}
+
+func (f *File) apply(start int, lines []hunkLine) error {
+	seq := make([]*elem, 0, len(f.seq)+len(lines))
+	for _, e := range f.seq {
+		c := *e
+		seq = append(seq, &c)
+	}
+
+	i, err := locate(&seq, start)
+	if err != nil {
+		return err
+	}
+	var last *elem
+	for _, l := range lines {
+		switch l.op {
+		case '\\':
+			if last != nil {
+				last.noEOL = true
+			}
+			continue
+		case '+':
+			e := &elem{kind: added, text: l.text, approx: l.approx}
+			seq = append(seq[:i], append([]*elem{e}, seq[i:]...)...)
+			i++
+			last = e
+			continue
+		}
+
+		for i < len(seq) && seq[i].kind == deleted {
+			i++
+		}
+		if i == len(seq) {
+			return fmt.Errorf("%w: past end of file", ErrConflict)
+		}
+		e := seq[i]
+		switch {
+		case e.kind == gap:
+			e = splitGap(&seq, i, l.text, l.approx)
+		case !sameLine(e, l):
+			return fmt.Errorf("%w at %q", ErrConflict, l.text)
+		case e.approx && !l.approx:
+			e.text, e.approx = l.text, false
+		}
+		last = e
+		if l.op == ' ' {
+			e.noEOL = false
+			i++
+			continue
+		}
+		if e.kind == added {
+			seq = append(seq[:i], seq[i+1:]...)
+			last = nil
+			continue
+		}
+		e.kind = deleted
+		i++
+	}
+	f.seq = seq
+	return nil
+}
+
+func sameLine(e *elem, l hunkLine) bool {
+	if e.approx || l.approx {
+		return display(e.text) == display(l.text)
+	}
+	return e.text == l.text
+}
+
+// display is a line as Claude Code shows it in a recorded patch.
+func display(s string) string {
+	return strings.ReplaceAll(s, "\t", "  ")
+}
+
+// undisplay guesses the bytes behind a display-form line: in a file that
+// indents with tabs, leading pairs of spaces were tabs.
+func (f *File) undisplay(s string) string {
+	if !f.tabs {
+		return s
+	}
+	n := 0
+	for strings.HasPrefix(s[2*n:], "  ") {
+		n++
+	}
+	return strings.Repeat("\t", n) + s[2*n:]
+}
+
+func (f *File) noteTabs(s string) {
+	if strings.HasPrefix(s, "\t") || strings.Contains(s, "\n\t") {
+		f.tabs = true
+	}
+}
+
+// rebuildEdit replaces the display-form text of the lines an Edit's
+// oldText and newText cover with their exact bytes. Lines around them,
+// and the parts of the first and last covered lines outside the edit,
+// keep their guessed text.
+func (f *File) rebuildEdit(lines []hunkLine, oldText, newText string) {
+	var oldSide, newSide []int
+	for j, l := range lines {
+		switch l.op {
+		case ' ':
+			oldSide = append(oldSide, j)
+			newSide = append(newSide, j)
+		case '-':
+			oldSide = append(oldSide, j)
+		case '+':
+			newSide = append(newSide, j)
+		}
+	}
+	joined := func(side []int) string {
+		texts := make([]string, len(side))
+		for k, j := range side {
+			texts[k] = display(lines[j].text)
+		}
+		return strings.Join(texts, "\n")
+	}
+	oldJoined, newJoined := joined(oldSide), joined(newSide)
+	dOld, dNew := display(oldText), display(newText)
+	at := strings.Index(oldJoined, dOld)
+	if at < 0 {
+		return
+	}
+	prefix, suffix := oldJoined[:at], oldJoined[at+len(dOld):]
+	if newJoined != prefix+dNew+suffix {
+		return
+	}
+
+	before := strings.Split(prefix, "\n")
+	after := strings.Split(suffix, "\n")
+	first := len(before) - 1
+	oldLines := strings.Split(oldText, "\n")
+
+	// The text around the edit on its first and last lines: exact when the
+	// file already showed those lines, guessed from display form otherwise.
+	head, headApprox := f.undisplay(before[first]), strings.Contains(before[first], "  ")
+	tail, tailApprox := after[0], strings.Contains(after[0], "  ")
+	if l := lines[oldSide[first]]; !l.approx {
+		if i := strings.Index(l.text, oldLines[0]); i >= 0 {
+			head, headApprox = l.text[:i], false
+			if len(oldLines) == 1 {
+				tail, tailApprox = l.text[i+len(oldText):], false
+			}
+		}
+	}
+	if l := lines[oldSide[first+len(oldLines)-1]]; len(oldLines) > 1 && !l.approx {
+		last := oldLines[len(oldLines)-1]
+		if strings.HasPrefix(l.text, last) {
+			tail, tailApprox = l.text[len(last):], false
+		}
+	}
+
+	rebuild := func(side []int, text string) {
+		exact := strings.Split(text, "\n")
+		for k, t := range exact {
+			approx := false
+			if k == 0 {
+				t = head + t
+				approx = headApprox
+			}
+			if k == len(exact)-1 {
+				t += tail
+				approx = approx || tailApprox
+			}
+			j := side[first+k]
+			lines[j].text, lines[j].approx = t, approx
+		}
+	}
+	rebuild(oldSide, oldText)
+	rebuild(newSide, newText)
+}
+
+// resolve replaces the guessed text of a hunk's context and removed lines
+// with the exact text of lines the file already showed.
+func (f *File) resolve(start int, lines []hunkLine) {
+	seq := make([]*elem, 0, len(f.seq)+1)
+	for _, e := range f.seq {
+		c := *e // locate may split a gap in place
+		seq = append(seq, &c)
+	}
+	i, err := locate(&seq, start)
+	if err != nil {
+		return
+	}
+	for j, l := range lines {
+		if l.op != ' ' && l.op != '-' {
+			continue
+		}
+		for i < len(seq) && seq[i].kind == deleted {
+			i++
+		}
+		if i == len(seq) || seq[i].kind == gap {
+			return
+		}
+		if e := seq[i]; !e.approx && display(e.text) == display(l.text) {
+			lines[j].text, lines[j].approx = e.text, false
+		}
+		i++
+	}
+}
+
+// locate returns the index where current line pos (1-based) starts,
+// splitting a gap if pos falls inside one.
+func locate(seq *[]*elem, pos int) (int, error) {
+	if pos < 1 {
+		pos = 1
+	}
+	line := 1
+	for i := 0; i < len(*seq); i++ {
+		e := (*seq)[i]
+		size := e.size()
+		if e.kind == gap && (e.n < 0 || pos < line+size) {
+			if off := pos - line; off > 0 {
+				head := &elem{kind: gap, orig: e.orig, n: off}
+				e.orig += off
+				if e.n > 0 {
+					e.n -= off
+				}
+				*seq = append((*seq)[:i], append([]*elem{head}, (*seq)[i:]...)...)
+				return i + 1, nil
+			}
+			return i, nil
+		}
+		if line == pos && size > 0 {
+			return i, nil
+		}
+		line += size
+	}
+	if pos == line {
+		return len(*seq), nil
+	}
+	return 0, fmt.Errorf("%w: line %d is past end of file", ErrConflict, pos)
+}
+
+// splitGap turns the first line of the gap at seq[i] into a known line.
+func splitGap(seq *[]*elem, i int, text string, approx bool) *elem {
+	g := (*seq)[i]
+	e := &elem{kind: kept, text: text, orig: g.orig, approx: approx}
+	g.orig++
+	if g.n > 0 {
+		g.n--
+	}
+	if g.n == 0 {
+		(*seq)[i] = e
+	} else {
+		*seq = append((*seq)[:i], append([]*elem{e}, (*seq)[i:]...)...)
+	}
+	return e
+}
+
+// Replace applies an Edit-style replacement of oldText with newText (every
+// occurrence when all is set) to a file whose content is fully known.
+func (f *File) Replace(oldText, newText string, all bool) error {
+	content, err := f.Content()
+	if err != nil {
+		return err
+	}
+	if oldText == "" || !strings.Contains(content, oldText) {
+		return fmt.Errorf("%w: old text not found", ErrConflict)
+	}
+	if f.created {
+		n := 1
+		if all {
+			n = -1
+		}
+		f.Create(strings.Replace(content, oldText, newText, n))
+		return nil
+	}
+	for from := 0; ; {
+		at := strings.Index(content[from:], oldText)
+		if at < 0 {
+			return nil
+		}
+		at += from
+		if err := f.Apply(replaceHunk(content, at, oldText, newText)); err != nil {
+			return err
+		}
+		content = content[:at] + newText + content[at+len(oldText):]
+		if !all {
+			return nil
+		}
+		from = at + len(newText)
+	}
+}
+
+// replaceHunk is the hunk replacing the whole lines around
+// content[at:at+len(oldText)].
+func replaceHunk(content string, at int, oldText, newText string) Hunk {
+	start := strings.LastIndexByte(content[:at], '\n') + 1
+	end := at + len(oldText)
+	if nl := strings.IndexByte(content[end:], '\n'); nl >= 0 {
+		end += nl + 1
+	} else {
+		end = len(content)
+	}
+	before := splitLines(content[start:end])
+	after := splitLines(content[start:at] + newText + content[at+len(oldText):end])
+
+	h := Hunk{OldStart: strings.Count(content[:start], "\n") + 1, OldLines: len(before), NewLines: len(after)}
+	h.NewStart = h.OldStart
+	for _, l := range before {
+		h.Lines = append(h.Lines, "-"+l.text)
+		if l.noEOL {
+			h.Lines = append(h.Lines, `\ No newline at end of file`)
+		}
+	}
+	for _, l := range after {
+		h.Lines = append(h.Lines, "+"+l.text)
+		if l.noEOL {
+			h.Lines = append(h.Lines, `\ No newline at end of file`)
+		}
+	}
+	return h
+}
+
+type splitLine struct {
+	text  string
+	noEOL bool
+}
+
+func splitLines(s string) []splitLine {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, "\n")
+	noEOL := parts[len(parts)-1] != ""
+	if !noEOL {
+		parts = parts[:len(parts)-1]
+	}
+	lines := make([]splitLine, len(parts))
+	for i, p := range parts {
+		lines[i] = splitLine{text: p}
+	}
+	lines[len(lines)-1].noEOL = noEOL
+	return lines
+}
+
+// Stat counts the lines the net change adds and removes.
+func (f *File) Stat() (additions, deletions int) {
+	for _, e := range f.seq {
+		switch e.kind {
+		case added:
+			additions++
+		case deleted:
+			deletions++
+		}
+	}
+	return additions, deletions
+}
+
+// Changed reports whether the edits left any net change.
+func (f *File) Changed() bool {
+	a, d := f.Stat()
+	return a > 0 || d > 0 || f.created
+}
+
+// WriteDiff writes the net change as a git-style unified diff with up to
+// context lines around each hunk, naming the file name on both sides.
+// Unchanged files write nothing.
+func (f *File) WriteDiff(w io.Writer, name string, context int) error {
+	if !f.Changed() {
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n", name, name)
+	if f.created {
+		b.WriteString("new file mode 100644\n")
+		b.WriteString("--- /dev/null\n")
+	} else {
+		fmt.Fprintf(&b, "--- a/%s\n", name)
+	}
+	fmt.Fprintf(&b, "+++ b/%s\n", name)
+
+	// Old and new line numbers at the start of every element.
+	oldAt := make([]int, len(f.seq)+1)
+	newAt := make([]int, len(f.seq)+1)
+	oldLine, newLine := 1, 1
+	for i, e := range f.seq {
+		oldAt[i], newAt[i] = oldLine, newLine
+		switch e.kind {
+		case kept:
+			oldLine++
+			newLine++
+		case added:
+			newLine++
+		case deleted:
+			oldLine++
+		case gap:
+			oldLine += max(e.n, 0)
+			newLine += max(e.n, 0)
+		}
+	}
+	oldAt[len(f.seq)], newAt[len(f.seq)] = oldLine, newLine
+
+	for i := 0; i < len(f.seq); {
+		if k := f.seq[i].kind; k != added && k != deleted {
+			i++
+			continue
+		}
+		start := i
+		for n := 0; n < context && start > 0 && f.seq[start-1].kind == kept; n++ {
+			start--
+		}
+		// Extend over changes and the kept lines between them, as long as
+		// the next change is within 2*context lines.
+		end := i
+		for {
+			for end < len(f.seq) && (f.seq[end].kind == added || f.seq[end].kind == deleted) {
+				end++
+			}
+			run := 0
+			for end+run < len(f.seq) && f.seq[end+run].kind == kept {
+				run++
+			}
+			next := end + run
+			if run <= 2*context && next < len(f.seq) && (f.seq[next].kind == added || f.seq[next].kind == deleted) {
+				end = next
+				continue
+			}
+			end += min(run, context)
+			break
+		}
+		writeHunk(&b, f.seq[start:end], oldAt[start], newAt[start])
+		i = end
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeHunk(b *strings.Builder, elems []*elem, oldStart, newStart int) {
+	var oldLines, newLines int
+	var body strings.Builder
+	for _, e := range elems {
+		switch e.kind {
+		case kept:
+			oldLines++
+			newLines++
+			body.WriteString(" ")
+		case added:
+			newLines++
+			body.WriteString("+")
+		case deleted:
+			oldLines++
+			body.WriteString("-")
+		}
+		body.WriteString(e.text)
+		body.WriteString("\n")
+		if e.noEOL {
+			body.WriteString("\\ No newline at end of file\n")
+		}
+	}
+	if oldLines == 0 {
+		oldStart--
+	}
+	if newLines == 0 {
+		newStart--
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(oldStart, oldLines), hunkRange(newStart, newLines))
+	b.WriteString(body.String())
+}
+
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// ObserveAll records the whole current content of the file.
+func (f *File) ObserveAll(content string) {
+	f.Observe(1, content, len(splitLines(content)))
+}
@@ -0,0 +1,178 @@
+package patch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func diff(t *testing.T, f *File) string {
+	t.Helper()
+	var b strings.Builder
+	if err := f.WriteDiff(&b, "main.go", 3); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestApply_ComposesHunks(t *testing.T) {
+	f := NewFile("/src/main.go")
+	steps := []Hunk{
+		{OldStart: 2, Lines: []string{" l2", " l3", " l4", "-l5", "+five", " l6", " l7", " l8"}},
+		{OldStart: 4, Lines: []string{" l4", " five", " l6", "+new", " l7", " l8", " l9"}},
+		{OldStart: 40, Lines: []string{" l40", "-l41", " l42"}},
+	}
+	for _, h := range steps {
+		if err := f.Apply(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -2,8 +2,9 @@
+ l2
+ l3
+ l4
+-l5
++five
+ l6
++new
+ l7
+ l8
+ l9
+@@ -39,3 +40,2 @@
+ l40
+-l41
+ l42
+`
+	if got := diff(t, f); got != want {
+		t.Errorf("diff:\n%s\nwant:\n%s", got, want)
+	}
+	if a, d := f.Stat(); a != 2 || d != 2 {
+		t.Errorf("Stat() = +%d -%d, want +2 -2", a, d)
+	}
+}
+
+func TestApply_ConflictLeavesFileUnchanged(t *testing.T) {
+	f := NewFile("main.go")
+	if err := f.Apply(Hunk{OldStart: 1, Lines: []string{" a", "-b", "+B"}}); err != nil {
+		t.Fatal(err)
+	}
+	before := diff(t, f)
+	err := f.Apply(Hunk{OldStart: 1, Lines: []string{" a", "-b", "+c"}})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("err = %v, want ErrConflict", err)
+	}
+	if after := diff(t, f); after != before {
+		t.Errorf("failed hunk changed the file:\n%s", after)
+	}
+}
+
+func TestApply_DropsAddedThenRemovedLines(t *testing.T) {
+	f := NewFile("main.go")
+	for _, h := range []Hunk{
+		{OldStart: 1, Lines: []string{" a", "+tmp", " b"}},
+		{OldStart: 1, Lines: []string{" a", "-tmp", " b"}},
+	} {
+		if err := f.Apply(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if f.Changed() {
+		t.Errorf("net change should be empty:\n%s", diff(t, f))
+	}
+}
+
+func TestCreate_Replace(t *testing.T) {
+	f := NewFile("main.go")
+	f.Create("package main\n\nfunc main() {}")
+	if err := f.Replace("func main() {}", "func main() {\n\tprintln(1)\n}\n", false); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `diff --git a/main.go b/main.go
+new file mode 100644
+--- /dev/null
++++ b/main.go
+@@ -0,0 +1,5 @@
++package main
++
++func main() {
++	println(1)
++}
+`
+	if got := diff(t, f); got != want {
+		t.Errorf("diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSetOriginal_Replace(t *testing.T) {
+	f := NewFile("main.go")
+	f.SetOriginal("a\nb\nc")
+	if err := f.Replace("c", "C", false); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,3 @@
+ a
+ b
+-c
+\ No newline at end of file
++C
+\ No newline at end of file
+`
+	if got := diff(t, f); got != want {
+		t.Errorf("diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestReplace_NeedsKnownContent(t *testing.T) {
+	f := NewFile("main.go")
+	if err := f.Replace("a", "b", false); !errors.Is(err, ErrUnknownContent) {
+		t.Errorf("err = %v, want ErrUnknownContent", err)
+	}
+}
+
+func TestApplyEdit_RebuildsTabs(t *testing.T) {
+	// Recorded the way Claude Code shows it: each tab as two spaces.
+	h := Hunk{OldStart: 1, Display: true, Lines: []string{
+		" func main() {",
+		"-  println(1)",
+		"+  if ok {",
+		"+    println(2)",
+		"+  }",
+		" }",
+	}}
+	edit := func(f *File) {
+		t.Helper()
+		if err := f.ApplyEdit([]Hunk{h}, "println(1)", "if ok {\n\t\tprintln(2)\n\t}"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n" +
+		"@@ -1,3 +1,5 @@\n func main() {\n-\tprintln(1)\n+\tif ok {\n+\t\tprintln(2)\n+\t}\n }\n"
+
+	guessed := NewFile("main.go")
+	edit(guessed)
+	if got := diff(t, guessed); got != want {
+		t.Errorf("diff:\n%q\nwant:\n%q", got, want)
+	}
+	if !guessed.Approximate() {
+		t.Error("guessed indentation should be reported as approximate")
+	}
+
+	read := NewFile("main.go")
+	read.Observe(1, "func main() {\n\tprintln(1)\n}\n", 3)
+	edit(read)
+	if got := diff(t, read); got != want {
+		t.Errorf("diff after Read:\n%q\nwant:\n%q", got, want)
+	}
+	if read.Approximate() {
+		t.Error("lines a Read returned should be exact")
+	}
+}
@@ -2,11 +2,25 @@
 package paths
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 )
 
+// DefaultRoot names the primary Claude data root — $CLAUDE_CONFIG_DIR when
+// set, otherwise ~/.claude.
+const DefaultRoot = "default"
+
+// ClaudeDir is the primary Claude data root. Honors CLAUDE_CONFIG_DIR the
+// same way Claude Code does, so a profile launched with a custom config dir
+// is what cct reads by default.
 func ClaudeDir() string {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return dir
+	}
 	return filepath.Join(os.Getenv("HOME"), ".claude")
 }
 
@@ -14,6 +28,159 @@ func ProjectsDir() string {
 	return filepath.Join(ClaudeDir(), "projects")
 }
 
+// Root is one named Claude data directory (a directory shaped like ~/.claude,
+// with projects/ underneath). Sessions from every root are scanned, indexed
+// and backed up side by side; Name tags them so IDs can't collide.
+type Root struct {
+	Name string `json:"name"`
+	Dir  string `json:"dir"`
+}
+
+func (r Root) ProjectsDir() string {
+	return filepath.Join(r.Dir, "projects")
+}
+
+// HistoryPath is Claude Code's global prompt history, one JSON line per
+// prompt submitted from any project.
+func (r Root) HistoryPath() string {
+	return filepath.Join(r.Dir, "history.jsonl")
+}
+
+// SettingsPath is the root's user-level settings.json.
+func (r Root) SettingsPath() string {
+	return filepath.Join(r.Dir, "settings.json")
+}
+
+// FileHistoryDir holds Claude Code's checkpoint backups, one directory per
+// session ID.
+func (r Root) FileHistoryDir() string {
+	return filepath.Join(r.Dir, "file-history")
+}
+
+// BackupProjectsDir is where this root's sessions are mirrored. ~/.claude
+// keeps the original BackupProjectsDir() location so backups taken before
+// roots existed stay valid; every other root gets its own subtree keyed by
+// name.
+func (r Root) BackupProjectsDir() string {
+	if r.Dir == filepath.Clean(filepath.Join(os.Getenv("HOME"), ".claude")) {
+		return BackupProjectsDir()
+	}
+	return filepath.Join(BackupDir(), "roots", r.Name, "projects")
+}
+
+// Roots returns every configured Claude data root. The primary root
+// (ClaudeDir) always comes first, named DefaultRoot. Extra roots come from
+// CCT_ROOTS, a list of name=dir entries separated like $PATH:
+//
+//	CCT_ROOTS=work=$HOME/.claude-work:laptop=/mnt/laptop/.claude
+//
+// An entry without "name=" is named after its directory. An entry pointing
+// at an already-listed directory renames that root instead of adding a
+// duplicate, so the same CCT_ROOTS gives every directory a stable name
+// whichever profile CLAUDE_CONFIG_DIR currently selects.
+//
+// The list is parsed, and bad entries warned about, once per distinct
+// environment: RootOf runs for every session file.
+func Roots() []Root {
+	key := [3]string{os.Getenv("HOME"), os.Getenv("CLAUDE_CONFIG_DIR"), os.Getenv("CCT_ROOTS")}
+	rootsCache.Lock()
+	defer rootsCache.Unlock()
+	if rootsCache.roots == nil || rootsCache.key != key {
+		rootsCache.key = key
+		rootsCache.roots = parseRoots(key[2])
+	}
+	return slices.Clone(rootsCache.roots)
+}
+
+// rootsCache holds the last Roots result and the environment it came from.
+var rootsCache struct {
+	sync.Mutex
+	key   [3]string
+	roots []Root
+}
+
+func parseRoots(env string) []Root {
+	roots := []Root{{Name: DefaultRoot, Dir: filepath.Clean(ClaudeDir())}}
+	if env == "" {
+		return roots
+	}
+	for _, entry := range filepath.SplitList(env) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, dir, ok := strings.Cut(entry, "=")
+		if !ok {
+			dir = entry
+			name = strings.TrimPrefix(filepath.Base(entry), ".")
+		}
+		dir = filepath.Clean(dir)
+		if name == "" || strings.ContainsAny(name, `/\:`) {
+			fmt.Fprintf(os.Stderr, "cct: ignoring CCT_ROOTS entry %q: invalid root name\n", entry)
+			continue
+		}
+		byDir := rootIndex(roots, func(r Root) bool { return r.Dir == dir })
+		if byDir >= 0 && (!ok || roots[byDir].Name == name) {
+			continue
+		}
+		if rootIndex(roots, func(r Root) bool { return r.Name == name }) >= 0 {
+			fmt.Fprintf(os.Stderr, "cct: ignoring CCT_ROOTS entry %q: duplicate root name\n", entry)
+			continue
+		}
+		if byDir >= 0 {
+			roots[byDir].Name = name
+			continue
+		}
+		roots = append(roots, Root{Name: name, Dir: dir})
+	}
+	return roots
+}
+
+func rootIndex(roots []Root, match func(Root) bool) int {
+	for i, r := range roots {
+		if match(r) {
+			return i
+		}
+	}
+	return -1
+}
+
+// LookupRoot returns the configured root with the given name.
+func LookupRoot(name string) (Root, bool) {
+	roots := Roots()
+	if i := rootIndex(roots, func(r Root) bool { return r.Name == name }); i >= 0 {
+		return roots[i], true
+	}
+	return Root{}, false
+}
+
+// RootNames lists configured root names in configuration order.
+func RootNames() []string {
+	roots := Roots()
+	names := make([]string, len(roots))
+	for i, r := range roots {
+		names[i] = r.Name
+	}
+	return names
+}
+
+// RootOf reports which root a session file belongs to, matching both the
+// live projects tree and the root's backup mirror. Returns "" for paths
+// outside every configured root (tests that walk custom directories).
+func RootOf(path string) string {
+	for _, r := range Roots() {
+		if isWithin(path, r.ProjectsDir()) || isWithin(path, r.BackupProjectsDir()) {
+			return r.Name
+		}
+	}
+	return ""
+}
+
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 func CacheDir() string {
 	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
 		return filepath.Join(xdg, "cct")
@@ -21,10 +188,38 @@ func CacheDir() string {
 	return filepath.Join(os.Getenv("HOME"), ".cache", "cct")
 }
 
+// DataDir holds what cct keeps that can't be rebuilt from session files,
+// such as the saved prompt library. Unlike CacheDir, it's safe from cache
+// cleaners.
+func DataDir() string {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, "cct")
+	}
+	return filepath.Join(os.Getenv("HOME"), ".local", "share", "cct")
+}
+
+// PromptLibraryPath stores prompts saved with `cct prompts save`.
+func PromptLibraryPath() string {
+	return filepath.Join(DataDir(), "prompts.json")
+}
+
 func IndexPath() string {
 	return filepath.Join(CacheDir(), "index.db")
 }
 
+// IndexThinkingPath, when present, makes the index include thinking blocks
+// (set by `cct index thinking on`). It sits beside the index so clearing
+// the cache resets both together.
+func IndexThinkingPath() string {
+	return filepath.Join(CacheDir(), "index-thinking")
+}
+
+// IndexToolOutputPath stores per-tool caps on indexed tool output set by
+// `cct index tool-output`, as a JSON object of tool name to bytes.
+func IndexToolOutputPath() string {
+	return filepath.Join(CacheDir(), "index-tool-output.json")
+}
+
 func ChangelogCachePath() string {
 	return filepath.Join(CacheDir(), "changelog.md")
 }
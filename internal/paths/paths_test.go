@@ -1,6 +1,11 @@
 package paths
 
-import "testing"
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
 
 func TestClaudeDir(t *testing.T) {
 	t.Setenv("HOME", "/tmp/fakehome")
@@ -17,3 +22,108 @@ func TestProjectsDir(t *testing.T) {
 		t.Errorf("ProjectsDir() = %q, want /tmp/fakehome/.claude/projects", got)
 	}
 }
+
+func TestClaudeDir_ConfigDir(t *testing.T) {
+	t.Setenv("HOME", "/tmp/fakehome")
+	t.Setenv("CLAUDE_CONFIG_DIR", "/tmp/work-claude")
+	if got := ClaudeDir(); got != "/tmp/work-claude" {
+		t.Errorf("ClaudeDir() = %q, want /tmp/work-claude", got)
+	}
+	if got := ProjectsDir(); got != "/tmp/work-claude/projects" {
+		t.Errorf("ProjectsDir() = %q, want /tmp/work-claude/projects", got)
+	}
+}
+
+func TestRoots(t *testing.T) {
+	t.Setenv("HOME", "/tmp/fakehome")
+	t.Setenv("XDG_CACHE_HOME", "/tmp/fakehome/.cache")
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+
+	t.Run("default only", func(t *testing.T) {
+		t.Setenv("CCT_ROOTS", "")
+		roots := Roots()
+		if len(roots) != 1 || roots[0].Name != DefaultRoot || roots[0].Dir != "/tmp/fakehome/.claude" {
+			t.Errorf("Roots() = %+v, want single default root", roots)
+		}
+	})
+
+	t.Run("extra roots", func(t *testing.T) {
+		t.Setenv("CCT_ROOTS", "work=/tmp/work/.claude:/mnt/laptop/.claude-laptop::bad/name=/x")
+		roots := Roots()
+		want := []Root{
+			{Name: DefaultRoot, Dir: "/tmp/fakehome/.claude"},
+			{Name: "work", Dir: "/tmp/work/.claude"},
+			{Name: "claude-laptop", Dir: "/mnt/laptop/.claude-laptop"},
+		}
+		if len(roots) != len(want) {
+			t.Fatalf("Roots() = %+v, want %+v", roots, want)
+		}
+		for i := range want {
+			if roots[i] != want[i] {
+				t.Errorf("Roots()[%d] = %+v, want %+v", i, roots[i], want[i])
+			}
+		}
+	})
+
+	t.Run("naming the primary dir renames default", func(t *testing.T) {
+		t.Setenv("CCT_ROOTS", "personal=/tmp/fakehome/.claude:work=/tmp/work/.claude")
+		roots := Roots()
+		if roots[0].Name != "personal" || len(roots) != 2 {
+			t.Errorf("Roots() = %+v, want personal first", roots)
+		}
+	})
+
+	t.Run("backup layout", func(t *testing.T) {
+		t.Setenv("CCT_ROOTS", "work=/tmp/work/.claude")
+		roots := Roots()
+		if got := roots[0].BackupProjectsDir(); got != BackupProjectsDir() {
+			t.Errorf("default BackupProjectsDir() = %q, want %q", got, BackupProjectsDir())
+		}
+		if got := roots[1].BackupProjectsDir(); got != "/tmp/fakehome/.cache/cct/backup/roots/work/projects" {
+			t.Errorf("work BackupProjectsDir() = %q", got)
+		}
+	})
+
+	t.Run("home with a trailing slash keeps the backup layout", func(t *testing.T) {
+		t.Setenv("HOME", "/tmp/fakehome/")
+		t.Setenv("CCT_ROOTS", "")
+		if got := Roots()[0].BackupProjectsDir(); got != BackupProjectsDir() {
+			t.Errorf("default BackupProjectsDir() = %q, want %q", got, BackupProjectsDir())
+		}
+	})
+
+	t.Run("bad entries warn once", func(t *testing.T) {
+		t.Setenv("CCT_ROOTS", "bad/name=/x")
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stderr := os.Stderr
+		os.Stderr = w
+		for range 3 {
+			RootOf("/tmp/fakehome/.claude/projects/-p/a.jsonl")
+		}
+		os.Stderr = stderr
+		_ = w.Close()
+		out, _ := io.ReadAll(r)
+		if n := strings.Count(string(out), "ignoring CCT_ROOTS entry"); n != 1 {
+			t.Errorf("warned %d times, want once:\n%s", n, out)
+		}
+	})
+
+	t.Run("root of path", func(t *testing.T) {
+		t.Setenv("CCT_ROOTS", "work=/tmp/work/.claude")
+		tests := map[string]string{
+			"/tmp/fakehome/.claude/projects/-p/a.jsonl":                      DefaultRoot,
+			"/tmp/work/.claude/projects/-p/a.jsonl":                          "work",
+			"/tmp/fakehome/.cache/cct/backup/roots/work/projects/-p/a.jsonl": "work",
+			"/tmp/fakehome/.cache/cct/backup/projects/-p/a.jsonl":            DefaultRoot,
+			"/elsewhere/a.jsonl":                                             "",
+		}
+		for path, want := range tests {
+			if got := RootOf(path); got != want {
+				t.Errorf("RootOf(%q) = %q, want %q", path, got, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,263 @@
+// Package permissions proposes permissions.allow rules for Claude Code
+// settings from the Bash and MCP calls sessions made. It reads settings
+// files to skip rules already in place but never writes them.
+package permissions
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Rules returns the allow rules a call needs to run without a prompt: one
+// "Bash(<prefix>:*)" per command of a compound Bash command, or the tool
+// name of an MCP tool. It returns nil for other tools and for commands
+// no prefix rule can describe, such as ones using command substitution.
+func Rules(tool, target string) []string {
+	if strings.HasPrefix(tool, "mcp__") {
+		return []string{tool}
+	}
+	if tool != "Bash" || target == "" {
+		return nil
+	}
+	if strings.Contains(target, "$(") || strings.Contains(target, "`") || strings.Contains(target, "<<") {
+		return nil
+	}
+	var rules []string
+	seen := map[string]bool{}
+	for _, segment := range splitCommands(target) {
+		prefix := commandPrefix(segment)
+		if prefix == "" {
+			return nil
+		}
+		rule := "Bash(" + prefix + ":*)"
+		if !seen[rule] {
+			seen[rule] = true
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// splitCommands splits a shell command at &&, ||, ;, |, and newlines
+// outside quotes, the way Claude Code checks each part of a compound
+// command against the rules separately.
+func splitCommands(cmd string) []string {
+	var parts []string
+	var cur strings.Builder
+	var quote rune
+	flush := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			parts = append(parts, s)
+		}
+		cur.Reset()
+	}
+	runes := []rune(cmd)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+			cur.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			cur.WriteRune(r)
+		case r == '\\' && i+1 < len(runes) && runes[i+1] == '\n':
+			i++
+		case r == ';' || r == '\n' || r == '|':
+			flush()
+		case r == '&' && i+1 < len(runes) && runes[i+1] == '&':
+			flush()
+			i++
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return parts
+}
+
+// subcommandTools take a subcommand whose meaning differs enough ("git
+// status" vs "git push") that a rule should name it.
+var subcommandTools = map[string]bool{
+	"git": true, "go": true, "npm": true, "pnpm": true, "yarn": true, "bun": true,
+	"cargo": true, "docker": true, "kubectl": true, "gh": true, "uv": true,
+	"pip": true, "pip3": true, "brew": true, "terraform": true, "helm": true,
+	"poetry": true, "dotnet": true, "mvn": true, "gradle": true, "swift": true,
+	"rustup": true, "deno": true, "make": true, "just": true, "mise": true,
+}
+
+// commandPrefix returns the words of one command a rule should match:
+// the program, plus its subcommand for the tools above (and gh's second
+// level, "gh pr view"). Leading VAR=value assignments are skipped. It
+// returns "" when a flag comes before the subcommand, since a rule with
+// the flag baked in would rarely match again.
+func commandPrefix(segment string) string {
+	words := strings.Fields(segment)
+	for len(words) > 0 && isAssignment(words[0]) {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return ""
+	}
+	prefix := words[:1]
+	if subcommandTools[words[0]] && len(words) > 1 {
+		if strings.HasPrefix(words[1], "-") {
+			return ""
+		}
+		prefix = words[:2]
+		if words[0] == "gh" && len(words) > 2 && !strings.HasPrefix(words[2], "-") {
+			prefix = words[:3]
+		}
+	}
+	for _, w := range prefix {
+		if strings.ContainsAny(w, `'"*>`) {
+			return ""
+		}
+	}
+	return strings.Join(prefix, " ")
+}
+
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	if !ok || name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// riskyCommands can delete or overwrite data, escalate privileges, reach
+// other machines, or run arbitrary code, so a standing rule for them is
+// never suggested however often they were approved.
+var riskyCommands = map[string]bool{
+	"rm": true, "rmdir": true, "mv": true, "cp": true, "dd": true, "shred": true, "truncate": true,
+	"sudo": true, "su": true, "doas": true, "chmod": true, "chown": true, "chgrp": true,
+	"mkfs": true, "mount": true, "umount": true, "kill": true, "killall": true, "pkill": true,
+	"shutdown": true, "reboot": true, "eval": true, "exec": true, "source": true, ".": true,
+	"sh": true, "bash": true, "zsh": true, "fish": true, "env": true, "xargs": true, "find": true,
+	"nohup": true, "timeout": true, "python": true, "python3": true, "node": true, "ruby": true,
+	"perl": true, "php": true, "osascript": true, "curl": true, "wget": true, "ssh": true,
+	"scp": true, "rsync": true, "sftp": true, "nc": true, "tee": true, "npx": true,
+	"crontab": true, "launchctl": true, "systemctl": true,
+}
+
+// riskySubcommands are the subcommands (for gh, the second level too)
+// that push, publish, delete, or discard work.
+var riskySubcommands = map[string]map[string]bool{
+	"git":       {"push": true, "reset": true, "clean": true, "rebase": true, "checkout": true, "restore": true, "rm": true, "filter-branch": true, "filter-repo": true, "gc": true, "prune": true, "update-ref": true, "reflog": true},
+	"npm":       {"publish": true, "unpublish": true, "exec": true, "x": true},
+	"pnpm":      {"publish": true, "dlx": true, "exec": true},
+	"yarn":      {"publish": true, "dlx": true},
+	"bun":       {"publish": true, "x": true},
+	"cargo":     {"publish": true, "yank": true},
+	"docker":    {"rm": true, "rmi": true, "kill": true, "system": true, "volume": true, "run": true, "exec": true, "push": true},
+	"kubectl":   {"delete": true, "apply": true, "exec": true, "edit": true, "patch": true, "scale": true, "drain": true, "replace": true, "create": true, "rollout": true},
+	"terraform": {"apply": true, "destroy": true, "import": true, "state": true},
+	"helm":      {"install": true, "uninstall": true, "upgrade": true, "rollback": true, "delete": true},
+	"brew":      {"uninstall": true, "remove": true},
+	"uv":        {"run": true, "publish": true},
+	"poetry":    {"publish": true, "run": true},
+	"gh":        {"api": true, "delete": true, "merge": true, "close": true, "archive": true, "secret": true, "auth": true},
+}
+
+// mcpWriteVerbs mark MCP tools that change something outside the session.
+var mcpWriteVerbs = []string{
+	"create", "update", "delete", "remove", "write", "edit", "send", "post", "merge",
+	"push", "close", "publish", "deploy", "exec", "run", "execute", "move", "rename",
+	"upload", "set", "add", "assign", "comment", "approve", "cancel", "trigger",
+}
+
+// Risky reports whether rule would allow commands or tools that change
+// things beyond the working tree's ordinary churn.
+func Risky(rule string) bool {
+	if strings.HasPrefix(rule, "mcp__") {
+		parts := strings.Split(rule, "__")
+		action := strings.ToLower(parts[len(parts)-1])
+		for w := range strings.FieldsFuncSeq(action, func(r rune) bool { return r == '_' || r == '-' }) {
+			if slices.Contains(mcpWriteVerbs, w) {
+				return true
+			}
+		}
+		return false
+	}
+	prefix, ok := strings.CutPrefix(rule, "Bash(")
+	if !ok {
+		return true
+	}
+	prefix = strings.TrimSuffix(prefix, ":*)")
+	words := strings.Fields(prefix)
+	if len(words) == 0 || riskyCommands[words[0]] || strings.Contains(words[0], "/") {
+		return true
+	}
+	for _, w := range words[1:] {
+		if riskySubcommands[words[0]][w] {
+			return true
+		}
+	}
+	return false
+}
+
+// Covered reports whether an allow rule in allowed already permits rule:
+// the same rule, "Bash" or a shorter Bash prefix, or an MCP server-wide
+// rule ("mcp__github" or "mcp__github__*").
+func Covered(rule string, allowed []string) bool {
+	for _, a := range allowed {
+		switch {
+		case a == rule:
+			return true
+		case strings.HasPrefix(rule, "Bash("):
+			if a == "Bash" || a == "Bash(*)" {
+				return true
+			}
+			ap, ok := strings.CutPrefix(a, "Bash(")
+			if !ok {
+				continue
+			}
+			ap = strings.TrimSuffix(strings.TrimSuffix(ap, ")"), ":*")
+			rp := strings.TrimSuffix(strings.TrimPrefix(rule, "Bash("), ":*)")
+			if rp == ap || strings.HasPrefix(rp, ap+" ") {
+				return true
+			}
+		case strings.HasPrefix(rule, "mcp__"):
+			server := strings.TrimSuffix(strings.TrimSuffix(a, "__*"), "*")
+			if strings.HasPrefix(a, "mcp__") && strings.Count(server, "__") == 1 && strings.HasPrefix(rule, server+"__") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LoadAllowRules reads permissions.allow from each settings file that
+// exists.
+func LoadAllowRules(paths ...string) ([]string, error) {
+	var rules []string
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		var settings struct {
+			Permissions struct {
+				Allow []string `json:"allow"`
+			} `json:"permissions"`
+		}
+		if err := json.Unmarshal(data, &settings); err != nil {
+			continue
+		}
+		rules = append(rules, settings.Permissions.Allow...)
+	}
+	return rules, nil
+}
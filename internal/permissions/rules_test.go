@@ -0,0 +1,126 @@
+package permissions
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		tool, target string
+		want         []string
+	}{
+		{"Bash", "go test ./...", []string{"Bash(go test:*)"}},
+		{"Bash", "cd web && CI=1 npm run build | tail -5", []string{"Bash(cd:*)", "Bash(npm run:*)", "Bash(tail:*)"}},
+		{"Bash", "gh pr view 12 --json title", []string{"Bash(gh pr view:*)"}},
+		{"Bash", `git commit -m "fix; again"`, []string{"Bash(git commit:*)"}},
+		{"Bash", "git -C ../other status", nil},
+		{"Bash", "echo $(date)", nil},
+		{"mcp__github__get_issue", "", []string{"mcp__github__get_issue"}},
+		{"Read", "/tmp/x", nil},
+	}
+	for _, tt := range tests {
+		if got := Rules(tt.tool, tt.target); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Rules(%q, %q) = %q, want %q", tt.tool, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestRisky(t *testing.T) {
+	tests := map[string]bool{
+		"Bash(go test:*)":             false,
+		"Bash(git status:*)":          false,
+		"Bash(git push:*)":            true,
+		"Bash(rm:*)":                  true,
+		"Bash(./deploy.sh:*)":         true,
+		"Bash(gh pr merge:*)":         true,
+		"mcp__github__get_issue":      false,
+		"mcp__github__create_issue":   true,
+		"mcp__linear__list_issues":    false,
+		"mcp__slack__send_message":    true,
+		"mcp__jira__search-issues":    false,
+		"mcp__notion__update-page":    true,
+		"mcp__postgres__query":        false,
+		"mcp__playwright__browser_go": false,
+	}
+	for rule, want := range tests {
+		if got := Risky(rule); got != want {
+			t.Errorf("Risky(%q) = %v, want %v", rule, got, want)
+		}
+	}
+}
+
+func TestCovered(t *testing.T) {
+	allowed := []string{"Bash(git:*)", "Bash(npm run test:*)", "mcp__github", "mcp__linear__*", "mcp__jira__search"}
+	tests := map[string]bool{
+		"Bash(git status:*)":       true,
+		"Bash(gitk:*)":             false,
+		"Bash(npm run test:*)":     true,
+		"Bash(npm run build:*)":    false,
+		"mcp__github__get_issue":   true,
+		"mcp__linear__list_issues": true,
+		"mcp__jira__search":        true,
+		"mcp__jira__get":           false,
+	}
+	for rule, want := range tests {
+		if got := Covered(rule, allowed); got != want {
+			t.Errorf("Covered(%q) = %v, want %v", rule, got, want)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	var calls []Call
+	for i, sid := range []string{"s1", "s1", "s2", "s3", "s3"} {
+		calls = append(calls, Call{Tool: "Bash", Target: "go test ./...", SessionID: sid, At: at.Add(time.Duration(i) * time.Minute), Ran: true})
+		calls = append(calls, Call{Tool: "Bash", Target: "git status", SessionID: sid, At: at, Ran: true})
+		calls = append(calls, Call{Tool: "Bash", Target: "rm -rf build", SessionID: sid, At: at, Ran: true})
+	}
+	calls = append(calls,
+		Call{Tool: "Bash", Target: "make deploy", SessionID: "s1", At: at, Ran: true},
+		Call{Tool: "Bash", Target: "make deploy", SessionID: "s2", At: at, Denied: true},
+		Call{Tool: "Bash", Target: "make deploy", SessionID: "s3", At: at, Denied: true},
+		Call{Tool: "Write", Target: "/etc/hosts", SessionID: "s3", At: at, Denied: true},
+		Call{Tool: "Bash", Target: "ls", SessionID: "s1", At: at},
+	)
+
+	report := Analyze(calls, AllowRules{User: []string{"Bash(git status:*)"}}, Thresholds{MinRuns: 3, MinSessions: 2, MinDenials: 2})
+	if len(report.Suggested) != 1 || report.Suggested[0].Rule != "Bash(go test:*)" {
+		t.Fatalf("suggested = %+v", report.Suggested)
+	}
+	got := report.Suggested[0]
+	if got.Runs != 5 || got.Sessions != 3 || len(got.Examples) != 3 || got.Examples[0].SessionID != "s3" ||
+		!got.Examples[0].At.Equal(at.Add(4*time.Minute)) || got.Examples[2].SessionID != "s1" {
+		t.Errorf("go test stats = %+v", got)
+	}
+	if report.AlreadyAllowed != 1 || report.Calls != 16 || report.Sessions != 3 {
+		t.Errorf("already allowed = %d, calls = %d, sessions = %d", report.AlreadyAllowed, report.Calls, report.Sessions)
+	}
+	if len(report.Denied) != 1 || report.Denied[0].Rule != "Bash(make deploy:*)" || report.Denied[0].Denied != 2 || report.Denied[0].Sessions != 2 {
+		t.Errorf("denied = %+v", report.Denied)
+	}
+
+	snippet := Snippet(report.Suggested)
+	if !reflect.DeepEqual(snippet, map[string]any{"permissions": map[string]any{"allow": []string{"Bash(go test:*)"}}}) {
+		t.Errorf("snippet = %v", snippet)
+	}
+}
+
+func TestLoadAllowRules(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "settings.json")
+	if err := os.WriteFile(p, []byte(`{"permissions":{"allow":["Bash(go test:*)"],"deny":["Bash(rm:*)"]}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadAllowRules(p, filepath.Join(dir, "missing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rules, []string{"Bash(go test:*)"}) {
+		t.Errorf("rules = %q", rules)
+	}
+}
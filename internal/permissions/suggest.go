@@ -0,0 +1,196 @@
+package permissions
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Call is one Bash or MCP tool call. Ran is set when it got a result
+// without being refused permission: it was allowed already, or approved.
+// Project is the directory the session ran in.
+type Call struct {
+	Tool      string
+	Target    string
+	SessionID string
+	Project   string
+	At        time.Time
+	Ran       bool
+	Denied    bool
+}
+
+// AllowRules are the permissions.allow rules settings grant: User ones
+// apply everywhere, Projects ones only to calls made in that directory.
+type AllowRules struct {
+	User     []string
+	Projects map[string][]string
+}
+
+// covers reports whether a already allows rule for every call in
+// projects.
+func (a AllowRules) covers(rule string, projects map[string]bool) bool {
+	if Covered(rule, a.User) {
+		return true
+	}
+	if len(projects) == 0 {
+		return false
+	}
+	for p := range projects {
+		if !Covered(rule, a.Projects[p]) {
+			return false
+		}
+	}
+	return true
+}
+
+// maxExamples caps the example sessions kept per rule.
+const maxExamples = 3
+
+// Example is a call that needed a rule, for showing where it came from.
+type Example struct {
+	SessionID string    `json:"session_id"`
+	Command   string    `json:"command,omitempty"`
+	At        time.Time `json:"at,omitzero"`
+}
+
+// RuleStats counts the calls that needed a rule. Examples are the latest
+// call from each of the most recent sessions, newest first.
+type RuleStats struct {
+	Rule     string    `json:"rule"`
+	Runs     int       `json:"runs"`
+	Denied   int       `json:"denied"`
+	Sessions int       `json:"sessions"`
+	Last     time.Time `json:"last,omitzero"`
+	Examples []Example `json:"examples"`
+
+	sessions map[string]bool
+	projects map[string]bool
+}
+
+// Thresholds decide which rules are worth suggesting and which denials
+// worth flagging.
+type Thresholds struct {
+	MinRuns     int
+	MinSessions int
+	MinDenials  int
+}
+
+// Report is the outcome of Analyze. Suggested holds rules never denied,
+// not risky, and not already allowed, most used first; AlreadyAllowed
+// counts the ones left out because settings cover them: the user's, or
+// those of every project the calls ran in. Denied holds the
+// rules (or, for calls no rule describes, the tool) refused at least
+// MinDenials times, most refused first.
+type Report struct {
+	Calls          int          `json:"calls"`
+	Sessions       int          `json:"sessions"`
+	Suggested      []*RuleStats `json:"suggestions"`
+	AlreadyAllowed int          `json:"already_allowed"`
+	Denied         []*RuleStats `json:"denied"`
+}
+
+// Analyze tallies calls by the rules they need and picks the suggestions
+// and repeated denials. calls should be oldest first.
+func Analyze(calls []Call, allowed AllowRules, t Thresholds) *Report {
+	report := &Report{Suggested: []*RuleStats{}, Denied: []*RuleStats{}}
+	byRule := map[string]*RuleStats{}
+	denials := map[string]*RuleStats{}
+	sessions := map[string]bool{}
+
+	get := func(m map[string]*RuleStats, rule string) *RuleStats {
+		s, ok := m[rule]
+		if !ok {
+			s = &RuleStats{Rule: rule, sessions: map[string]bool{}, projects: map[string]bool{}}
+			m[rule] = s
+		}
+		return s
+	}
+	record := func(s *RuleStats, c *Call) {
+		s.sessions[c.SessionID] = true
+		s.projects[c.Project] = true
+		if !c.At.Before(s.Last) {
+			s.Last = c.At
+		}
+		// Keep one example per session, the latest.
+		s.Examples = slices.DeleteFunc(s.Examples, func(e Example) bool { return e.SessionID == c.SessionID })
+		s.Examples = append([]Example{{SessionID: c.SessionID, Command: c.Target, At: c.At}}, s.Examples...)
+		if len(s.Examples) > maxExamples {
+			s.Examples = s.Examples[:maxExamples]
+		}
+	}
+
+	for i := range calls {
+		c := &calls[i]
+		rules := Rules(c.Tool, c.Target)
+		if c.Denied {
+			key := c.Tool
+			if len(rules) > 0 {
+				key = strings.Join(rules, " ")
+				for _, r := range rules {
+					get(byRule, r).Denied++
+				}
+			}
+			d := get(denials, key)
+			d.Denied++
+			record(d, c)
+			continue
+		}
+		if !c.Ran || len(rules) == 0 {
+			continue
+		}
+		report.Calls++
+		sessions[c.SessionID] = true
+		for _, r := range rules {
+			s := get(byRule, r)
+			s.Runs++
+			record(s, c)
+		}
+	}
+	report.Sessions = len(sessions)
+
+	for _, s := range finish(byRule) {
+		if s.Runs < t.MinRuns || s.Sessions < t.MinSessions || s.Denied > 0 || Risky(s.Rule) {
+			continue
+		}
+		if allowed.covers(s.Rule, s.projects) {
+			report.AlreadyAllowed++
+			continue
+		}
+		report.Suggested = append(report.Suggested, s)
+	}
+	sort.SliceStable(report.Suggested, func(i, j int) bool {
+		return report.Suggested[i].Runs > report.Suggested[j].Runs
+	})
+
+	for _, d := range finish(denials) {
+		if d.Denied >= t.MinDenials {
+			report.Denied = append(report.Denied, d)
+		}
+	}
+	sort.SliceStable(report.Denied, func(i, j int) bool {
+		return report.Denied[i].Denied > report.Denied[j].Denied
+	})
+	return report
+}
+
+// finish fills Sessions and returns the stats sorted by rule, so callers'
+// stable sorts give deterministic output.
+func finish(m map[string]*RuleStats) []*RuleStats {
+	out := make([]*RuleStats, 0, len(m))
+	for _, s := range m {
+		s.Sessions = len(s.sessions)
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Rule < out[j].Rule })
+	return out
+}
+
+// Snippet is the settings.json fragment allowing rules.
+func Snippet(rules []*RuleStats) map[string]any {
+	allow := make([]string, len(rules))
+	for i, r := range rules {
+		allow[i] = r.Rule
+	}
+	return map[string]any{"permissions": map[string]any{"allow": allow}}
+}
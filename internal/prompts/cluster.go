@@ -0,0 +1,296 @@
+// Package prompts finds prompts people keep retyping across sessions and
+// keeps a library of the ones worth saving.
+package prompts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Use is one time a prompt was typed.
+type Use struct {
+	Text      string    `json:"-"`
+	Root      string    `json:"root,omitempty"`
+	SessionID string    `json:"session_id"`
+	Project   string    `json:"project"`
+	At        time.Time `json:"at,omitzero"`
+	Offset    int64     `json:"offset"`
+}
+
+// Cluster is a group of near-identical prompts. Text is the variant typed
+// most often (the latest wording when tied), and ID identifies it.
+type Cluster struct {
+	ID       string    `json:"id"`
+	Text     string    `json:"text"`
+	Count    int       `json:"count"`
+	Sessions int       `json:"sessions"`
+	Variants int       `json:"variants"`
+	First    time.Time `json:"first,omitzero"`
+	Last     time.Time `json:"last,omitzero"`
+	Uses     []Use     `json:"uses"` // newest first
+
+	ids []string // IDs of every variant, for Matches
+}
+
+// DefaultThreshold is the shingle similarity at which two prompts count as
+// the same prompt reworded.
+const DefaultThreshold = 0.6
+
+// Minhash parameters: bands of rows hashed together pick candidate pairs
+// cheaply; candidates are then compared exactly. With 10 bands of 3 rows,
+// pairs at the default threshold are candidates ~91% of the time and pairs
+// at 0.7 ~98%.
+const (
+	minhashBands = 10
+	minhashRows  = 3
+)
+
+// ID identifies a prompt's wording: the first 8 hex digits of a hash of
+// its normalized text.
+func ID(text string) string {
+	sum := sha256.Sum256([]byte(Normalize(text)))
+	return hex.EncodeToString(sum[:4])
+}
+
+// Normalize lowercases text and reduces it to words separated by single
+// spaces, so formatting and punctuation don't split identical prompts.
+func Normalize(text string) string {
+	return strings.Join(words(text), " ")
+}
+
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Matches reports whether id (or a prefix of it) names any wording in the
+// cluster.
+func (c *Cluster) Matches(id string) bool {
+	for _, v := range c.ids {
+		if strings.HasPrefix(v, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether every word of query appears in some variant.
+func (c *Cluster) Contains(query string) bool {
+	terms := words(query)
+	for _, u := range c.Uses {
+		text := strings.ToLower(u.Text)
+		all := true
+		for _, t := range terms {
+			if !strings.Contains(text, t) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+// variant is every use of one normalized wording.
+type variant struct {
+	norm     string
+	shingles map[uint64]bool
+	uses     []Use
+}
+
+// Group clusters uses whose wordings have a shingle similarity of at least
+// threshold, ranked by the number of sessions they appear in, then uses,
+// then recency.
+func Group(uses []Use, threshold float64) []*Cluster {
+	byNorm := map[string]*variant{}
+	var variants []*variant
+	for _, u := range uses {
+		norm := Normalize(u.Text)
+		if norm == "" {
+			continue
+		}
+		v, ok := byNorm[norm]
+		if !ok {
+			v = &variant{norm: norm, shingles: shingles(norm)}
+			byNorm[norm] = v
+			variants = append(variants, v)
+		}
+		v.uses = append(v.uses, u)
+	}
+
+	parent := make([]int, len(variants))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	buckets := map[uint64][]int{}
+	for i, v := range variants {
+		sig := signature(v.shingles)
+		for b := range minhashBands {
+			h := fnv.New64a()
+			var buf [8]byte
+			for r := range minhashRows {
+				x := sig[b*minhashRows+r]
+				for k := range buf {
+					buf[k] = byte(x >> (8 * k))
+				}
+				_, _ = h.Write(buf[:])
+			}
+			key := h.Sum64() ^ uint64(b)
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+	for _, members := range buckets {
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				a, b := find(members[x]), find(members[y])
+				if a == b {
+					continue
+				}
+				if jaccard(variants[members[x]].shingles, variants[members[y]].shingles) >= threshold {
+					parent[a] = b
+				}
+			}
+		}
+	}
+
+	groups := map[int][]*variant{}
+	var order []int
+	for i, v := range variants {
+		r := find(i)
+		if _, ok := groups[r]; !ok {
+			order = append(order, r)
+		}
+		groups[r] = append(groups[r], v)
+	}
+
+	out := make([]*Cluster, 0, len(groups))
+	for _, r := range order {
+		out = append(out, newCluster(groups[r]))
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Sessions != b.Sessions {
+			return a.Sessions > b.Sessions
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Last.After(b.Last)
+	})
+	return out
+}
+
+func newCluster(vs []*variant) *Cluster {
+	c := &Cluster{Variants: len(vs)}
+	sessions := map[string]bool{}
+	var best *variant
+	var bestLast time.Time
+	for _, v := range vs {
+		var last time.Time
+		for _, u := range v.uses {
+			c.Uses = append(c.Uses, u)
+			sessions[u.Root+"/"+u.SessionID] = true
+			if u.At.After(last) {
+				last = u.At
+			}
+		}
+		if best == nil || len(v.uses) > len(best.uses) || (len(v.uses) == len(best.uses) && last.After(bestLast)) {
+			best, bestLast = v, last
+		}
+		c.ids = append(c.ids, ID(v.norm))
+	}
+	sort.SliceStable(c.Uses, func(i, j int) bool { return c.Uses[i].At.After(c.Uses[j].At) })
+	c.Count = len(c.Uses)
+	c.Sessions = len(sessions)
+	c.Last = c.Uses[0].At
+	c.First = c.Uses[len(c.Uses)-1].At
+	c.ID = ID(best.norm)
+	for _, u := range c.Uses {
+		if Normalize(u.Text) == best.norm {
+			c.Text = u.Text
+			break
+		}
+	}
+	return c
+}
+
+// shingles hashes each pair of adjacent words; a one-word text is its own
+// shingle.
+func shingles(norm string) map[uint64]bool {
+	ws := strings.Fields(norm)
+	out := make(map[uint64]bool, len(ws))
+	if len(ws) == 1 {
+		out[hashString(ws[0])] = true
+		return out
+	}
+	for i := 0; i+1 < len(ws); i++ {
+		out[hashString(ws[i]+" "+ws[i+1])] = true
+	}
+	return out
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// signature is the minhash of a shingle set under minhashBands*minhashRows
+// hash functions derived from each shingle's hash.
+func signature(set map[uint64]bool) []uint64 {
+	sig := make([]uint64, minhashBands*minhashRows)
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for s := range set {
+		for i := range sig {
+			if h := mix(s + uint64(i)*0x9e3779b97f4a7c15); h < sig[i] {
+				sig[i] = h
+			}
+		}
+	}
+	return sig
+}
+
+// mix is the splitmix64 finalizer.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func jaccard(a, b map[uint64]bool) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	inter := 0
+	for s := range a {
+		if b[s] {
+			inter++
+		}
+	}
+	union := len(a) + len(b) - inter
+	if union == 0 {
+		return 0
+	}
+	return float64(inter) / float64(union)
+}
@@ -0,0 +1,68 @@
+package prompts
+
+import (
+	"testing"
+	"time"
+)
+
+func use(text, sessionID string, at time.Time) Use {
+	return Use{Text: text, SessionID: sessionID, Project: "proj", At: at}
+}
+
+func TestGroup(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	uses := []Use{
+		use("Review this PR for bugs, security issues, and missing tests.", "s1", t0),
+		use("review this PR for bugs, security issues and missing tests", "s2", t0.Add(time.Hour)),
+		use("Review this PR for bugs, security issues, and missing unit tests please", "s3", t0.Add(2*time.Hour)),
+		use("Write a commit message for the staged changes", "s1", t0.Add(3*time.Hour)),
+		use("Write a commit message for the staged changes", "s1", t0.Add(4*time.Hour)),
+		use("Explain how the sync loop handles deleted files", "s4", t0.Add(5*time.Hour)),
+	}
+	clusters := Group(uses, DefaultThreshold)
+	if len(clusters) != 3 {
+		for _, c := range clusters {
+			t.Logf("%s %d %q", c.ID, c.Count, c.Text)
+		}
+		t.Fatalf("got %d clusters, want 3", len(clusters))
+	}
+
+	review := clusters[0]
+	if review.Count != 3 || review.Sessions != 3 || review.Variants != 2 {
+		t.Errorf("review cluster = count %d, sessions %d, variants %d; want 3, 3, 2", review.Count, review.Sessions, review.Variants)
+	}
+	// The two identically-normalized wordings outnumber the third; the
+	// later of those is the text shown.
+	if review.Text != "review this PR for bugs, security issues and missing tests" {
+		t.Errorf("review text = %q", review.Text)
+	}
+	if review.ID != ID(review.Text) {
+		t.Errorf("review ID = %s, want %s", review.ID, ID(review.Text))
+	}
+	if !review.Matches(ID(uses[2].Text)[:6]) {
+		t.Error("cluster should match the ID of any variant")
+	}
+	if !review.Last.Equal(t0.Add(2*time.Hour)) || !review.First.Equal(t0) {
+		t.Errorf("review span = %v .. %v", review.First, review.Last)
+	}
+
+	commit := clusters[1]
+	if commit.Count != 2 || commit.Sessions != 1 {
+		t.Errorf("commit cluster = count %d, sessions %d; want 2, 1", commit.Count, commit.Sessions)
+	}
+	if !commit.Contains("COMMIT staged") || commit.Contains("commit pushed") {
+		t.Error("Contains should match every query word case-insensitively")
+	}
+	if clusters[2].Count != 1 {
+		t.Errorf("last cluster count = %d, want 1", clusters[2].Count)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize("  Fix the   BUG!\n(in main.go)"); got != "fix the bug in main go" {
+		t.Errorf("Normalize = %q", got)
+	}
+	if ID("Fix the bug.") != ID("fix the bug") {
+		t.Error("ID should ignore case and punctuation")
+	}
+}
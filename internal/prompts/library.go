@@ -0,0 +1,164 @@
+package prompts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Saved is a prompt kept in the library under a name. The name doubles as
+// the slash command it exports to.
+type Saved struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Text        string    `json:"text"`
+	SavedAt     time.Time `json:"saved_at"`
+	// Uses and Sessions record how often the prompt had been typed when it
+	// was saved.
+	Uses     int `json:"uses,omitempty"`
+	Sessions int `json:"sessions,omitempty"`
+}
+
+// Library is the saved prompt collection, stored as one JSON file.
+type Library struct {
+	Prompts []*Saved `json:"prompts"`
+
+	path string
+}
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidName reports whether name works as a slash command file name:
+// lowercase letters, digits, '-' and '_'.
+func ValidName(name string) bool {
+	return validName.MatchString(name)
+}
+
+// LoadLibrary reads path. A missing file is an empty library.
+func LoadLibrary(path string) (*Library, error) {
+	lib := &Library{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return lib, nil
+		}
+		return nil, fmt.Errorf("read prompt library %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, lib); err != nil {
+		return nil, fmt.Errorf("parse prompt library %s: %w", path, err)
+	}
+	lib.path = path
+	return lib, nil
+}
+
+// Get returns the saved prompt called name.
+func (l *Library) Get(name string) (*Saved, bool) {
+	for _, p := range l.Prompts {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Put adds p, replacing any prompt with the same name, and keeps the
+// library sorted by name.
+func (l *Library) Put(p *Saved) {
+	for i, old := range l.Prompts {
+		if old.Name == p.Name {
+			l.Prompts[i] = p
+			return
+		}
+	}
+	l.Prompts = append(l.Prompts, p)
+	sort.Slice(l.Prompts, func(i, j int) bool { return l.Prompts[i].Name < l.Prompts[j].Name })
+}
+
+// Remove deletes the prompt called name and reports whether it was there.
+func (l *Library) Remove(name string) bool {
+	for i, p := range l.Prompts {
+		if p.Name == name {
+			l.Prompts = append(l.Prompts[:i], l.Prompts[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Save writes the library atomically via temp + rename, creating the
+// parent directory if needed.
+func (l *Library) Save() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+	if l.Prompts == nil {
+		l.Prompts = []*Saved{}
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), ".prompts-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, l.path); err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
+
+// SlashCommand renders p as a Claude Code custom slash command: markdown
+// with a description in YAML front matter. Saved as <name>.md under
+// .claude/commands/, it runs as /<name>.
+func (p *Saved) SlashCommand() string {
+	desc := p.Description
+	if desc == "" {
+		desc = firstLine(p.Text, 80)
+	}
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "description: %s\n", yamlString(desc))
+	b.WriteString("---\n\n")
+	b.WriteString(strings.TrimRight(p.Text, "\n"))
+	b.WriteString("\n")
+	return b.String()
+}
+
+func firstLine(text string, limit int) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	line = strings.TrimSpace(line)
+	if r := []rune(line); len(r) > limit {
+		line = strings.TrimSpace(string(r[:limit-1])) + "…"
+	}
+	return line
+}
+
+// yamlString quotes s when a plain YAML scalar would misread it.
+func yamlString(s string) string {
+	if s == "" || strings.ContainsAny(s, ":#'\"{}[],&*!|>%@`") || strings.ContainsAny(s[:1], "-?") || strings.TrimSpace(s) != s {
+		b, _ := json.Marshal(s)
+		return string(b)
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package prompts
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLibrary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cct", "prompts.json")
+	lib, err := LoadLibrary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lib.Prompts) != 0 {
+		t.Fatalf("missing file should load empty, got %d prompts", len(lib.Prompts))
+	}
+
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	lib.Put(&Saved{Name: "review", Text: "Review this PR", SavedAt: at})
+	lib.Put(&Saved{Name: "commit", Text: "Write a commit message", SavedAt: at})
+	lib.Put(&Saved{Name: "review", Text: "Review this PR carefully", SavedAt: at})
+	if err := lib.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	lib, err = LoadLibrary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lib.Prompts) != 2 || lib.Prompts[0].Name != "commit" || lib.Prompts[1].Name != "review" {
+		t.Fatalf("prompts = %+v, want commit, review", lib.Prompts)
+	}
+	if p, ok := lib.Get("review"); !ok || p.Text != "Review this PR carefully" {
+		t.Errorf("Get(review) = %+v, %v", p, ok)
+	}
+	if !lib.Remove("commit") || lib.Remove("commit") {
+		t.Error("Remove should report whether the prompt was there")
+	}
+}
+
+func TestSlashCommand(t *testing.T) {
+	p := &Saved{Name: "review", Text: "Review: check for bugs\nand missing tests\n"}
+	want := "---\ndescription: \"Review: check for bugs\"\n---\n\nReview: check for bugs\nand missing tests\n"
+	if got := p.SlashCommand(); got != want {
+		t.Errorf("SlashCommand =\n%s\nwant\n%s", got, want)
+	}
+	p.Description = "Review the PR"
+	if got := p.SlashCommand(); !strings.HasPrefix(got, "---\ndescription: Review the PR\n---\n") {
+		t.Errorf("SlashCommand with description =\n%s", got)
+	}
+}
+
+func TestValidName(t *testing.T) {
+	for name, want := range map[string]bool{"review": true, "pr-review_2": true, "Review": false, "-x": false, "a/b": false, "": false} {
+		if ValidName(name) != want {
+			t.Errorf("ValidName(%q) = %v, want %v", name, !want, want)
+		}
+	}
+}
@@ -16,8 +16,39 @@ type Options struct {
 	MaxToolChars       int
 	IncludeToolResults bool
 	Limit              int
+	// Agents maps tool_use_id to a linked subagent; those transcripts are
+	// rendered, indented, right after the message that spawned them.
+	Agents map[string]*session.AgentSpawn
+	// AfterLastCompact drops the messages before the last context
+	// compaction.
+	AfterLastCompact bool
+	// Thinking includes the model's thinking blocks, quoted and labelled
+	// apart from its replies.
+	Thinking bool
+	// Selection keeps a slice of the messages, applied before Limit.
+	Selection session.Selection
 }
 
+var (
+	userStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("12")).
+			Bold(true)
+	assistantStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("5")).
+			Bold(true)
+	agentStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("6")).
+			Bold(true)
+	compactStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("3")).
+			Bold(true)
+	thinkingStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("8")).
+			Italic(true)
+	separatorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("8"))
+)
+
 func RenderSession(s *session.Session, opts Options) error {
 	renderer, err := glamour.NewTermRenderer(
 		glamour.WithAutoStyle(),
@@ -42,40 +73,95 @@ func RenderSession(s *session.Session, opts Options) error {
 
 	messages := parseMessages(f, opts)
 
+	if opts.AfterLastCompact {
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].compaction != nil {
+				messages = messages[i:]
+				break
+			}
+		}
+	}
+	marks := make([]session.MessageMark, len(messages))
+	for i, m := range messages {
+		marks[i] = m.mark
+	}
+	lo, hi, err := opts.Selection.Window(marks)
+	if err != nil {
+		return err
+	}
+	messages = messages[lo:hi]
 	if opts.Limit > 0 && len(messages) > opts.Limit {
 		messages = messages[len(messages)-opts.Limit:]
 	}
 
-	userStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("12")).
-		Bold(true)
-	assistantStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("5")).
-		Bold(true)
-	separatorStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8"))
+	printMessages(renderer, messages, opts, "")
+	return nil
+}
 
+// printMessages prints messages with every line prefixed by indent, which
+// grows by a rule for each level of subagent nesting.
+func printMessages(renderer *glamour.TermRenderer, messages []message, opts Options, indent string) {
+	emit := func(s string) {
+		for _, line := range strings.Split(s, "\n") {
+			fmt.Println(indent + line)
+		}
+	}
 	for _, msg := range messages {
-		var header string
-		if msg.role == "user" {
-			header = userStyle.Render("▌ User")
-		} else {
-			header = assistantStyle.Render("▌ Assistant")
+		switch {
+		case msg.compaction != nil:
+			title := "▌ Context compacted"
+			if label := msg.compaction.Label(); label != "" {
+				title += " · " + label
+			}
+			emit(compactStyle.Render(title))
+		case msg.role == "user":
+			emit(userStyle.Render("▌ User"))
+		case msg.role == "thinking":
+			emit(thinkingStyle.Render("▌ Thinking"))
+		default:
+			emit(assistantStyle.Render("▌ Assistant"))
 		}
-		fmt.Println(header)
-		fmt.Println()
+		emit("")
 
 		rendered, err := renderer.Render(msg.text)
 		if err != nil {
-			fmt.Print(msg.text)
-		} else {
+			rendered = msg.text
+		}
+		if indent == "" {
 			fmt.Print(rendered)
+		} else {
+			emit(strings.TrimRight(rendered, "\n"))
+		}
+
+		for _, id := range msg.toolUseIDs {
+			if sp, ok := opts.Agents[id]; ok {
+				printAgent(renderer, sp, opts, indent+separatorStyle.Render("│ "))
+			}
 		}
-		fmt.Println(separatorStyle.Render(strings.Repeat("─", 80)))
-		fmt.Println()
+		if indent == "" {
+			fmt.Println(separatorStyle.Render(strings.Repeat("─", 80)))
+		}
+		emit("")
 	}
+}
 
-	return nil
+func printAgent(renderer *glamour.TermRenderer, sp *session.AgentSpawn, opts Options, indent string) {
+	f, err := os.Open(sp.Agent.FilePath)
+	if err != nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	title := "▌ Subagent " + sp.Agent.ShortID
+	if sp.SubagentType != "" {
+		title += " · " + sp.SubagentType
+	}
+	if sp.Description != "" {
+		title += " — " + sp.Description
+	}
+	fmt.Println(indent + agentStyle.Render(title))
+	fmt.Println(indent)
+	printMessages(renderer, parseMessages(f, opts), opts, indent)
 }
 
 func renderHeader(s *session.Session) string {
@@ -96,8 +182,11 @@ func renderHeader(s *session.Session) string {
 }
 
 type message struct {
-	role string
-	text string
+	role       string
+	text       string
+	toolUseIDs []string
+	compaction *session.Compaction // set on compaction dividers; text is the summary
+	mark       session.MessageMark // the record the message came from
 }
 
 func parseMessages(r *os.File, opts Options) []message {
@@ -105,12 +194,15 @@ func parseMessages(r *os.File, opts Options) []message {
 	var messages []message
 
 	roles := map[string]bool{"user": true, "assistant": true}
+	marker := session.NewMarker(opts.Selection.Turn != 0)
 
 	for scanner.Scan() {
 		line := scanner.Bytes()
 		lineType := session.FastExtractType(line)
+		marker.Observe(lineType, line)
 
-		if !roles[lineType] {
+		compaction := session.MayBeCompaction(line)
+		if !roles[lineType] && !compaction {
 			continue
 		}
 
@@ -118,6 +210,35 @@ func parseMessages(r *os.File, opts Options) []message {
 		if err := json.Unmarshal(line, &obj); err != nil {
 			continue
 		}
+		uuid, _ := obj["uuid"].(string)
+		mark := marker.Mark(uuid, scanner.Offset(), session.ParseTimestamp(obj))
+		if compaction {
+			switch {
+			case session.IsCompactBoundary(obj):
+				messages = append(messages, message{role: "compact", compaction: session.NewCompaction(obj), mark: mark})
+				continue
+			case session.IsCompactSummary(obj):
+				summary := session.CompactSummaryText(obj)
+				if n := len(messages); n > 0 && messages[n-1].compaction != nil && messages[n-1].text == "" {
+					messages[n-1].text = summary
+				} else {
+					messages = append(messages, message{role: "compact", text: summary, compaction: &session.Compaction{}, mark: mark})
+				}
+				continue
+			}
+			if !roles[lineType] {
+				continue
+			}
+		}
+
+		if opts.Thinking && lineType == "assistant" {
+			for _, thought := range session.ThinkingBlocks(obj) {
+				if opts.MaxChars > 0 && len(thought) > opts.MaxChars {
+					thought = thought[:opts.MaxChars] + fmt.Sprintf("\n\n... (%d chars truncated)", len(thought)-opts.MaxChars)
+				}
+				messages = append(messages, message{role: "thinking", text: Quote(thought), mark: mark})
+			}
+		}
 
 		text := extractContent(obj, opts.IncludeToolResults, opts.MaxToolChars)
 		if text == "" {
@@ -128,7 +249,11 @@ func parseMessages(r *os.File, opts Options) []message {
 			text = text[:opts.MaxChars] + fmt.Sprintf("\n\n... (%d chars truncated)", len(text)-opts.MaxChars)
 		}
 
-		messages = append(messages, message{role: lineType, text: text})
+		msg := message{role: lineType, text: text, mark: mark}
+		if len(opts.Agents) > 0 {
+			msg.toolUseIDs = session.ToolUseIDs(obj)
+		}
+		messages = append(messages, msg)
 	}
 
 	return messages
@@ -186,6 +311,19 @@ func extractContent(obj map[string]any, includeToolResults bool, maxToolChars in
 	return strings.Join(parts, "\n\n")
 }
 
+// Quote renders text as a markdown blockquote.
+func Quote(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 func FormatToolUse(block map[string]any) string {
 	name, _ := block["name"].(string)
 	input, _ := block["input"].(map[string]any)
@@ -0,0 +1,99 @@
+package session
+
+import (
+	"os"
+	"slices"
+	"time"
+)
+
+// DefaultIdleThreshold is the longest gap between messages still counted
+// as continuous work. Long tool runs write progress records well inside
+// it; a longer silence means nobody was at the keyboard.
+const DefaultIdleThreshold = 15 * time.Minute
+
+// Segment is a run of messages with no gap longer than the idle threshold.
+// A single isolated message is a zero-length segment.
+type Segment struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Messages int       `json:"messages"`
+}
+
+func (s Segment) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Segments splits message timestamps into work segments at every gap
+// longer than idle. times need not be sorted; zero times are skipped.
+func Segments(times []time.Time, idle time.Duration) []Segment {
+	sorted := make([]time.Time, 0, len(times))
+	for _, t := range times {
+		if !t.IsZero() {
+			sorted = append(sorted, t)
+		}
+	}
+	slices.SortFunc(sorted, func(a, b time.Time) int { return a.Compare(b) })
+
+	var segs []Segment
+	for _, t := range sorted {
+		if n := len(segs); n > 0 && t.Sub(segs[n-1].End) <= idle {
+			segs[n-1].End = t
+			segs[n-1].Messages++
+			continue
+		}
+		segs = append(segs, Segment{Start: t, End: t, Messages: 1})
+	}
+	return segs
+}
+
+// MergeSegments unions overlapping segments, e.g. from sessions run in
+// parallel, so their time isn't counted twice. The result is sorted.
+func MergeSegments(segs []Segment) []Segment {
+	sorted := slices.Clone(segs)
+	slices.SortFunc(sorted, func(a, b Segment) int { return a.Start.Compare(b.Start) })
+
+	var out []Segment
+	for _, s := range sorted {
+		if n := len(out); n > 0 && !s.Start.After(out[n-1].End) {
+			if s.End.After(out[n-1].End) {
+				out[n-1].End = s.End
+			}
+			out[n-1].Messages += s.Messages
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// ActiveTime sums the segment durations.
+func ActiveTime(segs []Segment) time.Duration {
+	var total time.Duration
+	for _, s := range segs {
+		total += s.Duration()
+	}
+	return total
+}
+
+// ReadMessageTimes returns the timestamp of every user and assistant
+// record in a session file, in file order.
+func ReadMessageTimes(path string) ([]time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var times []time.Time
+	scanner := NewOffsetScanner(f)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if t := FastExtractType(line); t != "user" && t != "assistant" {
+			continue
+		}
+		if ts := FastExtractTimestamp(line); !ts.IsZero() {
+			times = append(times, ts)
+		}
+	}
+	return times, scanner.Err()
+}
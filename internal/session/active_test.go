@@ -0,0 +1,52 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSegments(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	at := func(min int) time.Time { return base.Add(time.Duration(min) * time.Minute) }
+
+	// Out of order on purpose; the zero time is skipped.
+	times := []time.Time{at(5), at(0), at(10), {}, at(60), at(70), at(200)}
+	segs := Segments(times, 15*time.Minute)
+	if len(segs) != 3 {
+		t.Fatalf("got %d segments, want 3: %+v", len(segs), segs)
+	}
+	if segs[0].Duration() != 10*time.Minute || segs[0].Messages != 3 {
+		t.Errorf("segment 0 = %+v", segs[0])
+	}
+	if segs[1].Duration() != 10*time.Minute || segs[1].Messages != 2 {
+		t.Errorf("segment 1 = %+v", segs[1])
+	}
+	if segs[2].Duration() != 0 || segs[2].Messages != 1 {
+		t.Errorf("segment 2 = %+v", segs[2])
+	}
+	if got := ActiveTime(segs); got != 20*time.Minute {
+		t.Errorf("ActiveTime = %v, want 20m", got)
+	}
+
+	// A gap exactly at the threshold does not split.
+	if segs := Segments([]time.Time{at(0), at(15)}, 15*time.Minute); len(segs) != 1 {
+		t.Errorf("gap == idle: got %d segments, want 1", len(segs))
+	}
+}
+
+func TestMergeSegments(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	seg := func(from, to int) Segment {
+		return Segment{Start: base.Add(time.Duration(from) * time.Minute), End: base.Add(time.Duration(to) * time.Minute), Messages: 1}
+	}
+	merged := MergeSegments([]Segment{seg(30, 40), seg(0, 20), seg(10, 25), seg(25, 28)})
+	if len(merged) != 2 {
+		t.Fatalf("got %d segments, want 2: %+v", len(merged), merged)
+	}
+	if merged[0].Duration() != 28*time.Minute || merged[0].Messages != 3 {
+		t.Errorf("merged[0] = %+v", merged[0])
+	}
+	if got := ActiveTime(merged); got != 38*time.Minute {
+		t.Errorf("ActiveTime = %v, want 38m", got)
+	}
+}
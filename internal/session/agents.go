@@ -0,0 +1,333 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IsTaskTool reports whether name is the tool that spawns a subagent.
+// Newer Claude Code releases renamed Task to Agent.
+func IsTaskTool(name string) bool {
+	return name == "Task" || name == "Agent"
+}
+
+// AgentSpawn is one Task tool_use in a transcript, linked (when the file
+// can be found) to the subagent transcript it started.
+type AgentSpawn struct {
+	ToolUseID    string        `json:"tool_use_id"`
+	Offset       int64         `json:"offset"`
+	SubagentType string        `json:"subagent_type,omitempty"`
+	Description  string        `json:"description,omitempty"`
+	Prompt       string        `json:"prompt"`
+	CalledAt     time.Time     `json:"called_at,omitzero"`
+	DurationMS   int64         `json:"duration_ms,omitempty"`
+	TotalTokens  int           `json:"total_tokens,omitempty"`
+	Status       string        `json:"status"`
+	Result       string        `json:"result,omitempty"`
+	AgentID      string        `json:"agent_id,omitempty"`
+	Agent        *Session      `json:"agent,omitempty"`
+	Children     []*AgentSpawn `json:"children,omitempty"`
+}
+
+// Duration prefers the runtime Claude Code reported for the agent and
+// falls back to the agent transcript's own span.
+func (a *AgentSpawn) Duration() time.Duration {
+	if a.DurationMS > 0 {
+		return time.Duration(a.DurationMS) * time.Millisecond
+	}
+	if a.Agent != nil {
+		return a.Agent.Duration()
+	}
+	return 0
+}
+
+// Tokens prefers Claude Code's reported total and falls back to the agent
+// transcript's output tokens.
+func (a *AgentSpawn) Tokens() int {
+	if a.TotalTokens > 0 {
+		return a.TotalTokens
+	}
+	if a.Agent != nil {
+		return a.Agent.TotalOutputTokens
+	}
+	return 0
+}
+
+// agentIDRe finds the "agentId: <id>" trailer Claude Code appends to Task
+// results so the agent can be resumed.
+var agentIDRe = regexp.MustCompile(`agentId: ([\w-]+)`)
+
+// toolUseResult is the structured result Claude Code stores next to a Task
+// tool_result on the user record.
+type toolUseResult struct {
+	AgentID         string `json:"agentId"`
+	Status          string `json:"status"`
+	TotalDurationMS int64  `json:"totalDurationMs"`
+	TotalTokens     int    `json:"totalTokens"`
+}
+
+// ReadSpawns returns the Task calls in a transcript, in call order, with
+// their results but without linked agent transcripts.
+func ReadSpawns(path string) ([]*AgentSpawn, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	tracker := NewToolTracker()
+	tracker.KeepOutput = func(c *ToolCall) bool { return IsTaskTool(c.Name) }
+	results := map[string]toolUseResult{}
+
+	scanner := NewOffsetScanner(f)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		lineType := FastExtractType(line)
+		if lineType != "user" && lineType != "assistant" {
+			continue
+		}
+		var obj map[string]any
+		if json.Unmarshal(line, &obj) != nil {
+			continue
+		}
+		tracker.Observe(obj, scanner.Offset())
+		if lineType == "user" {
+			if id, r, ok := extractToolUseResult(obj); ok {
+				results[id] = r
+			}
+		}
+	}
+
+	var spawns []*AgentSpawn
+	for _, c := range tracker.Calls() {
+		if !IsTaskTool(c.Name) {
+			continue
+		}
+		sp := &AgentSpawn{
+			ToolUseID: c.ID,
+			Offset:    c.Offset,
+			CalledAt:  c.CalledAt,
+			Result:    strings.TrimSpace(c.Output),
+			Status:    "no result",
+		}
+		sp.SubagentType, _ = c.Input["subagent_type"].(string)
+		sp.Description, _ = c.Input["description"].(string)
+		sp.Prompt, _ = c.Input["prompt"].(string)
+		switch {
+		case c.IsError:
+			sp.Status = "error"
+		case c.HasResult:
+			sp.Status = "completed"
+			if latency := c.Latency(); latency > 0 {
+				sp.DurationMS = latency.Milliseconds()
+			}
+		}
+		if r, ok := results[c.ID]; ok {
+			sp.AgentID = r.AgentID
+			if r.Status != "" {
+				sp.Status = r.Status
+			}
+			if r.TotalDurationMS > 0 {
+				sp.DurationMS = r.TotalDurationMS
+			}
+			sp.TotalTokens = r.TotalTokens
+		}
+		if sp.AgentID == "" {
+			if m := agentIDRe.FindStringSubmatch(sp.Result); m != nil {
+				sp.AgentID = m[1]
+			}
+		}
+		spawns = append(spawns, sp)
+	}
+	return spawns, scanner.Err()
+}
+
+// extractToolUseResult pairs a user record's toolUseResult with the
+// tool_use_id of the tool_result it accompanies.
+func extractToolUseResult(obj map[string]any) (string, toolUseResult, bool) {
+	raw, ok := obj["toolUseResult"].(map[string]any)
+	if !ok {
+		return "", toolUseResult{}, false
+	}
+	msg, _ := obj["message"].(map[string]any)
+	blocks, _ := msg["content"].([]any)
+	var id string
+	for _, item := range blocks {
+		if block, ok := item.(map[string]any); ok && block["type"] == "tool_result" {
+			id, _ = block["tool_use_id"].(string)
+			break
+		}
+	}
+	if id == "" {
+		return "", toolUseResult{}, false
+	}
+	var r toolUseResult
+	data, _ := json.Marshal(raw)
+	if json.Unmarshal(data, &r) != nil {
+		return "", toolUseResult{}, false
+	}
+	return id, r, true
+}
+
+// AgentFiles returns the subagent transcripts that may belong to parent:
+// its nested <id>/subagents/ directory, flat legacy agent-*.jsonl siblings
+// whose records name it as their sessionId, and — when parent is itself a
+// nested agent — its sibling agents.
+func AgentFiles(parent *Session) []string {
+	dir := filepath.Dir(parent.FilePath)
+	var files []string
+	if filepath.Base(dir) == "subagents" {
+		files, _ = filepath.Glob(filepath.Join(dir, "agent-*.jsonl"))
+	} else {
+		files, _ = filepath.Glob(filepath.Join(dir, parent.ID, "subagents", "agent-*.jsonl"))
+		flat, _ := filepath.Glob(filepath.Join(dir, "agent-*.jsonl"))
+		for _, f := range flat {
+			if readSessionID(f) == parent.ID {
+				files = append(files, f)
+			}
+		}
+	}
+	var out []string
+	for _, f := range files {
+		if f != parent.FilePath {
+			out = append(out, f)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+var sessionIDPrefix = []byte(`"sessionId":"`)
+
+// readSessionID returns the sessionId of the first record that has one.
+func readSessionID(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+	r := bufio.NewReader(f)
+	for range 5 {
+		line, err := r.ReadBytes('\n')
+		if id := fastExtractString(line, sessionIDPrefix); id != "" {
+			return id
+		}
+		if err != nil {
+			return ""
+		}
+	}
+	return ""
+}
+
+// LinkSpawns reads parent's Task calls and links each to its subagent
+// transcript: by the agentId Claude Code recorded, then by an identical
+// prompt, then by the sidecar description. Agents that spawn agents of
+// their own are linked recursively into Children.
+func LinkSpawns(parent *Session) ([]*AgentSpawn, error) {
+	pool := map[string]*Session{}
+	for _, path := range AgentFiles(parent) {
+		s := ParseFullSession(path)
+		if s == nil {
+			continue
+		}
+		s.Root = parent.Root
+		pool[path] = s
+	}
+	return linkSpawns(parent.FilePath, pool, 0)
+}
+
+// maxAgentDepth bounds recursion in case a malformed file links to itself.
+const maxAgentDepth = 5
+
+func linkSpawns(path string, pool map[string]*Session, depth int) ([]*AgentSpawn, error) {
+	spawns, err := ReadSpawns(path)
+	if err != nil || depth >= maxAgentDepth {
+		return spawns, err
+	}
+
+	claim := func(sp *AgentSpawn, match func(*Session) bool) {
+		if sp.Agent != nil {
+			return
+		}
+		paths := make([]string, 0, len(pool))
+		for p := range pool {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		for _, p := range paths {
+			if match(pool[p]) {
+				sp.Agent = pool[p]
+				delete(pool, p)
+				return
+			}
+		}
+	}
+	for _, sp := range spawns {
+		if sp.AgentID != "" {
+			id := "agent-" + sp.AgentID
+			claim(sp, func(s *Session) bool { return s.ID == id })
+		}
+	}
+	for _, sp := range spawns {
+		prompt := strings.TrimSpace(sp.Prompt)
+		claim(sp, func(s *Session) bool { return prompt != "" && strings.TrimSpace(s.FirstPrompt) == prompt })
+	}
+	for _, sp := range spawns {
+		claim(sp, func(s *Session) bool {
+			return sp.Description != "" && s.AgentDescription == sp.Description
+		})
+	}
+
+	for _, sp := range spawns {
+		if sp.Agent == nil {
+			continue
+		}
+		if sp.AgentID == "" {
+			sp.AgentID = strings.TrimPrefix(sp.Agent.ID, "agent-")
+		}
+		children, err := linkSpawns(sp.Agent.FilePath, pool, depth+1)
+		if err != nil {
+			return spawns, err
+		}
+		sp.Children = children
+	}
+	return spawns, nil
+}
+
+// SpawnsByToolUse flattens a spawn tree into a map keyed by tool_use_id,
+// for renderers that inline agent transcripts at their spawn point.
+func SpawnsByToolUse(spawns []*AgentSpawn) map[string]*AgentSpawn {
+	out := map[string]*AgentSpawn{}
+	var walk func([]*AgentSpawn)
+	walk = func(list []*AgentSpawn) {
+		for _, sp := range list {
+			if sp.Agent != nil {
+				out[sp.ToolUseID] = sp
+			}
+			walk(sp.Children)
+		}
+	}
+	walk(spawns)
+	return out
+}
+
+// ToolUseIDs returns the ids of the tool_use blocks in a parsed record.
+func ToolUseIDs(obj map[string]any) []string {
+	msg, _ := obj["message"].(map[string]any)
+	blocks, _ := msg["content"].([]any)
+	var ids []string
+	for _, item := range blocks {
+		if block, ok := item.(map[string]any); ok && block["type"] == "tool_use" {
+			if id, _ := block["id"].(string); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,133 @@
+//go:build darwin || linux
+
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLinkSpawns(t *testing.T) {
+	projDir := t.TempDir()
+	parentID := "11111111-2222-3333-4444-555555555555"
+	subDir := filepath.Join(projDir, parentID, "subagents")
+
+	writeSessionFile(t, projDir, parentID, []string{
+		`{"type":"user","sessionId":"` + parentID + `","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"audit the repo"}}`,
+		`{"type":"assistant","timestamp":"2026-03-01T10:00:05Z","message":{"role":"assistant","content":[` +
+			`{"type":"tool_use","id":"t1","name":"Task","input":{"subagent_type":"Explore","description":"Find loaders","prompt":"Find config loaders"}},` +
+			`{"type":"tool_use","id":"t2","name":"Agent","input":{"description":"Check tests","prompt":"Check the tests"}},` +
+			`{"type":"tool_use","id":"t3","name":"Task","input":{"description":"Legacy","prompt":"Old style agent"}},` +
+			`{"type":"tool_use","id":"t5","name":"Task","input":{"description":"Lost","prompt":"Never written"}}]}}`,
+		`{"type":"user","timestamp":"2026-03-01T10:01:05Z","toolUseResult":{"agentId":"aaa111","status":"completed","totalDurationMs":60000,"totalTokens":1234},"message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"Loaders are in config/"}]}]}}`,
+		`{"type":"user","timestamp":"2026-03-01T10:00:35Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t2","content":"Tests pass\nagentId: bbb222 (for resuming)"}]}}`,
+		`{"type":"user","timestamp":"2026-03-01T10:00:15Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t3","content":"legacy done"}]}}`,
+	})
+	writeSessionFile(t, subDir, "agent-aaa111", []string{
+		`{"type":"user","isSidechain":true,"sessionId":"` + parentID + `","timestamp":"2026-03-01T10:00:06Z","message":{"role":"user","content":"Find config loaders"}}`,
+		`{"type":"assistant","timestamp":"2026-03-01T10:00:10Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t4","name":"Task","input":{"description":"Dig deeper","prompt":"Look in vendor"}}]}}`,
+		`{"type":"user","timestamp":"2026-03-01T10:00:50Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t4","content":"nothing there"}]}}`,
+	})
+	writeSessionFile(t, subDir, "agent-bbb222", []string{
+		`{"type":"user","isSidechain":true,"sessionId":"` + parentID + `","timestamp":"2026-03-01T10:00:06Z","message":{"role":"user","content":"Check the tests"}}`,
+	})
+	// Matched only by its sidecar description; its prompt differs.
+	writeSessionFile(t, subDir, "agent-ddd444", []string{
+		`{"type":"user","isSidechain":true,"sessionId":"` + parentID + `","timestamp":"2026-03-01T10:00:11Z","message":{"role":"user","content":"Look in vendor/ please"}}`,
+	})
+	writeMetaSidecar(t, subDir, "agent-ddd444", "general-purpose", "Dig deeper")
+	// Flat legacy agents: one belongs to the parent, one to another session.
+	writeSessionFile(t, projDir, "agent-ccc333", []string{
+		`{"type":"user","isSidechain":true,"sessionId":"` + parentID + `","timestamp":"2026-03-01T10:00:06Z","message":{"role":"user","content":"Old style agent"}}`,
+	})
+	writeSessionFile(t, projDir, "agent-eee555", []string{
+		`{"type":"user","isSidechain":true,"sessionId":"other","timestamp":"2026-03-01T10:00:06Z","message":{"role":"user","content":"Never written"}}`,
+	})
+
+	parent := &Session{ID: parentID, FilePath: filepath.Join(projDir, parentID+".jsonl")}
+	spawns, err := LinkSpawns(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spawns) != 4 {
+		t.Fatalf("got %d spawns, want 4", len(spawns))
+	}
+
+	linked := func(sp *AgentSpawn) string {
+		if sp.Agent == nil {
+			return ""
+		}
+		return sp.Agent.ID
+	}
+	tests := []struct {
+		spawn  *AgentSpawn
+		agent  string
+		status string
+	}{
+		{spawns[0], "agent-aaa111", "completed"},
+		{spawns[1], "agent-bbb222", "completed"},
+		{spawns[2], "agent-ccc333", "completed"},
+		{spawns[3], "", "no result"},
+	}
+	for _, tt := range tests {
+		if got := linked(tt.spawn); got != tt.agent {
+			t.Errorf("%s linked to %q, want %q", tt.spawn.ToolUseID, got, tt.agent)
+		}
+		if tt.spawn.Status != tt.status {
+			t.Errorf("%s status = %q, want %q", tt.spawn.ToolUseID, tt.spawn.Status, tt.status)
+		}
+	}
+
+	first := spawns[0]
+	if first.SubagentType != "Explore" || first.Prompt != "Find config loaders" || first.Result != "Loaders are in config/" {
+		t.Errorf("spawn fields = %+v", first)
+	}
+	if first.DurationMS != 60000 || first.Tokens() != 1234 {
+		t.Errorf("duration/tokens = %d/%d, want 60000/1234", first.DurationMS, first.Tokens())
+	}
+	if spawns[1].AgentID != "bbb222" || spawns[1].DurationMS != 30000 {
+		t.Errorf("agentId from result text = %q, duration %d", spawns[1].AgentID, spawns[1].DurationMS)
+	}
+	if len(first.Children) != 1 || linked(first.Children[0]) != "agent-ddd444" {
+		t.Fatalf("nested spawn not linked: %+v", first.Children)
+	}
+
+	byID := SpawnsByToolUse(spawns)
+	if len(byID) != 4 || byID["t4"] == nil || byID["t5"] != nil {
+		t.Errorf("SpawnsByToolUse keys = %v", byID)
+	}
+}
+
+func TestAgentFiles_NoAgents(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "solo.jsonl")
+	if err := os.WriteFile(path, []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if files := AgentFiles(&Session{ID: "solo", FilePath: path}); len(files) != 0 {
+		t.Errorf("AgentFiles = %v, want none", files)
+	}
+}
+
+func TestLoadParentID(t *testing.T) {
+	projDir := t.TempDir()
+	subDir := filepath.Join(projDir, "parent-uuid", "subagents")
+	writeSessionFile(t, subDir, "agent-nested", []string{`{"type":"user","sessionId":"ignored"}`})
+	writeSessionFile(t, projDir, "agent-flat", []string{`{"type":"user","sessionId":"legacy-parent"}`})
+	writeSessionFile(t, projDir, "plain", []string{`{"type":"user","sessionId":"plain"}`})
+
+	tests := []struct {
+		path, want string
+	}{
+		{filepath.Join(subDir, "agent-nested.jsonl"), "parent-uuid"},
+		{filepath.Join(projDir, "agent-flat.jsonl"), "legacy-parent"},
+		{filepath.Join(projDir, "plain.jsonl"), ""},
+	}
+	for _, tt := range tests {
+		s := ExtractMetadata(tt.path)
+		if s.ParentID != tt.want {
+			t.Errorf("%s: ParentID = %q, want %q", filepath.Base(tt.path), s.ParentID, tt.want)
+		}
+	}
+}
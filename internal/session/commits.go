@@ -0,0 +1,80 @@
+package session
+
+import (
+	"regexp"
+	"strings"
+)
+
+// gitCommitRe matches a git commit invocation anywhere in a shell command,
+// including after `cd x &&`, `git -C dir`, or `git -c k=v`.
+var gitCommitRe = regexp.MustCompile(`(?:^|[\s;&|(])git(?:\s+-[Cc]\s+\S+)*\s+commit\b`)
+
+// commitLineRe matches git's summary line: "[main 1a2b3c4] Subject" or
+// "[main (root-commit) 1a2b3c4] Subject". Branch names never contain spaces.
+var commitLineRe = regexp.MustCompile(`(?m)^\[(\S+)(?: \([^)]*\))? ([0-9a-f]{7,40})\] (.*)$`)
+
+// commitMessageRe matches the first -m argument (also as a combined flag
+// like -am or -qm), double- or single-quoted.
+var commitMessageRe = regexp.MustCompile(`\s-[a-zA-Z]*m\s*(?:"((?:[^"\\]|\\.)*)"|'([^']*)')`)
+
+// Commit is a git commit made from a Bash tool call, read from the summary
+// line git prints on success. SHA and Branch are empty when git printed no
+// summary (commit -q); Subject then comes from the -m argument.
+type Commit struct {
+	SHA     string `json:"sha,omitempty"`
+	Branch  string `json:"branch,omitempty"`
+	Subject string `json:"subject"`
+}
+
+// IsGitCommit reports whether c is a Bash call that runs git commit.
+func IsGitCommit(c *ToolCall) bool {
+	if c.Name != "Bash" {
+		return false
+	}
+	cmd, _ := c.Input["command"].(string)
+	return gitCommitRe.MatchString(cmd)
+}
+
+// ParseCommitOutput extracts the commits git reported in a tool_result.
+// A chained command (`git commit ... && git commit ...`) can yield several.
+func ParseCommitOutput(output string) []Commit {
+	var out []Commit
+	for _, m := range commitLineRe.FindAllStringSubmatch(output, -1) {
+		out = append(out, Commit{Branch: m[1], SHA: m[2], Subject: strings.TrimSpace(m[3])})
+	}
+	return out
+}
+
+// CommitsFromCall returns the commits a successful git commit call made.
+func CommitsFromCall(c *ToolCall) []Commit {
+	if !IsGitCommit(c) || !c.HasResult || c.IsError {
+		return nil
+	}
+	if commits := ParseCommitOutput(c.Output); len(commits) > 0 {
+		return commits
+	}
+	cmd, _ := c.Input["command"].(string)
+	if subject := commitSubject(cmd); subject != "" {
+		return []Commit{{Subject: subject}}
+	}
+	return nil
+}
+
+// commitSubject returns the first line of the -m message in a git commit
+// command, unwrapping the "$(cat <<'EOF' ... EOF)" heredoc form.
+func commitSubject(cmd string) string {
+	m := commitMessageRe.FindStringSubmatch(cmd)
+	if m == nil {
+		return ""
+	}
+	msg := m[1] + m[2]
+	if strings.HasPrefix(msg, "$(cat <<") {
+		_, msg, _ = strings.Cut(msg, "\n")
+	}
+	for line := range strings.SplitSeq(msg, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return strings.ReplaceAll(line, `\"`, `"`)
+		}
+	}
+	return ""
+}
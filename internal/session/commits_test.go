@@ -0,0 +1,60 @@
+package session
+
+import "testing"
+
+func TestIsGitCommit(t *testing.T) {
+	tests := map[string]bool{
+		`git commit -m "x"`:                     true,
+		`git add -A && git commit -qm "x"`:      true,
+		`cd repo; git -C sub commit --amend`:    true,
+		`(git commit -m x)`:                     true,
+		`git log --grep commit`:                 false,
+		`echo "git commitment"`:                 false,
+		`legit commit`:                          false,
+		`gh pr create --title "git commit fix"`: false,
+	}
+	for cmd, want := range tests {
+		c := &ToolCall{Name: "Bash", Input: map[string]any{"command": cmd}}
+		if got := IsGitCommit(c); got != want {
+			t.Errorf("IsGitCommit(%q) = %v, want %v", cmd, got, want)
+		}
+	}
+	if IsGitCommit(&ToolCall{Name: "Read", Input: map[string]any{"command": "git commit"}}) {
+		t.Error("non-Bash tool matched")
+	}
+}
+
+func TestParseCommitOutput(t *testing.T) {
+	out := "[main 1a2b3c4] Fix the thing\n 2 files changed, 10 insertions(+)\n" +
+		"[feature/x (root-commit) deadbeefcafe] Initial commit\n"
+	commits := ParseCommitOutput(out)
+	if len(commits) != 2 {
+		t.Fatalf("got %d commits, want 2: %+v", len(commits), commits)
+	}
+	if commits[0] != (Commit{SHA: "1a2b3c4", Branch: "main", Subject: "Fix the thing"}) {
+		t.Errorf("commit 0 = %+v", commits[0])
+	}
+	if commits[1].Branch != "feature/x" || commits[1].SHA != "deadbeefcafe" {
+		t.Errorf("commit 1 = %+v", commits[1])
+	}
+}
+
+func TestCommitsFromCall(t *testing.T) {
+	call := func(cmd, output string, isError bool) *ToolCall {
+		return &ToolCall{Name: "Bash", Input: map[string]any{"command": cmd}, HasResult: true, IsError: isError, Output: output}
+	}
+
+	if got := CommitsFromCall(call(`git commit -m "x"`, "[main abc1234] x", false)); len(got) != 1 || got[0].SHA != "abc1234" {
+		t.Errorf("summary line: %+v", got)
+	}
+	if got := CommitsFromCall(call(`git commit -qm "Quiet \"one\""`, "", false)); len(got) != 1 || got[0].Subject != `Quiet "one"` {
+		t.Errorf("quiet commit: %+v", got)
+	}
+	heredoc := "git commit -m \"$(cat <<'EOF'\nAdd heredoc support\n\nBody text.\nEOF\n)\""
+	if got := CommitsFromCall(call(heredoc, "", false)); len(got) != 1 || got[0].Subject != "Add heredoc support" {
+		t.Errorf("heredoc commit: %+v", got)
+	}
+	if got := CommitsFromCall(call(`git commit -m 'x'`, "nothing to commit", true)); got != nil {
+		t.Errorf("failed commit: %+v", got)
+	}
+}
@@ -0,0 +1,144 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	compactBoundaryMarker = []byte(`"compact_boundary"`)
+	compactSummaryMarker  = []byte(`"isCompactSummary":true`)
+)
+
+// Compaction is one context compaction: Claude Code replaced the
+// conversation so far with a generated summary. The JSONL records it as a
+// system compact_boundary record carrying compactMetadata, followed by a
+// user record flagged isCompactSummary that holds the summary text.
+type Compaction struct {
+	At         time.Time `json:"at"`
+	Trigger    string    `json:"trigger,omitempty"` // "auto" or "manual"
+	PreTokens  int       `json:"pre_tokens,omitempty"`
+	PostTokens int       `json:"post_tokens,omitempty"`
+	DurationMS int64     `json:"duration_ms,omitempty"`
+	Summary    string    `json:"-"`
+}
+
+// Label describes the compaction in one line, e.g.
+// "auto · 169k → 12k tokens · 49s".
+func (c *Compaction) Label() string {
+	var parts []string
+	if c.Trigger != "" {
+		parts = append(parts, c.Trigger)
+	}
+	switch {
+	case c.PreTokens > 0 && c.PostTokens > 0:
+		parts = append(parts, fmt.Sprintf("%s → %s tokens", kiloTokens(c.PreTokens), kiloTokens(c.PostTokens)))
+	case c.PreTokens > 0:
+		parts = append(parts, kiloTokens(c.PreTokens)+" tokens")
+	}
+	if c.DurationMS > 0 {
+		parts = append(parts, (time.Duration(c.DurationMS) * time.Millisecond).Round(time.Second).String())
+	}
+	return strings.Join(parts, " · ")
+}
+
+func kiloTokens(n int) string {
+	if n < 1000 {
+		return fmt.Sprintf("%d", n)
+	}
+	return fmt.Sprintf("%.0fk", float64(n)/1000)
+}
+
+// MayBeCompaction reports whether a raw JSONL line could be a compact
+// boundary or summary record, so callers can skip the JSON decode.
+func MayBeCompaction(line []byte) bool {
+	return bytes.Contains(line, compactBoundaryMarker) || bytes.Contains(line, compactSummaryMarker)
+}
+
+// IsCompactBoundary reports whether a decoded record marks a compaction.
+func IsCompactBoundary(obj map[string]any) bool {
+	return obj["type"] == "system" && obj["subtype"] == "compact_boundary"
+}
+
+// IsCompactSummary reports whether a decoded record is the generated
+// summary that opens the compacted context. It is a user record, but no
+// person typed it.
+func IsCompactSummary(obj map[string]any) bool {
+	flag, _ := obj["isCompactSummary"].(bool)
+	return flag && obj["type"] == "user"
+}
+
+// NewCompaction reads a compact_boundary record.
+func NewCompaction(obj map[string]any) *Compaction {
+	c := &Compaction{At: ParseTimestamp(obj)}
+	meta, _ := obj["compactMetadata"].(map[string]any)
+	c.Trigger, _ = meta["trigger"].(string)
+	if n, ok := meta["preTokens"].(float64); ok {
+		c.PreTokens = int(n)
+	}
+	if n, ok := meta["postTokens"].(float64); ok {
+		c.PostTokens = int(n)
+	}
+	if n, ok := meta["durationMs"].(float64); ok {
+		c.DurationMS = int64(n)
+	}
+	return c
+}
+
+// CompactSummaryText returns the summary text of an isCompactSummary record.
+func CompactSummaryText(obj map[string]any) string {
+	msg, _ := obj["message"].(map[string]any)
+	switch content := msg["content"].(type) {
+	case string:
+		return content
+	case []any:
+		var parts []string
+		for _, item := range content {
+			if block, ok := item.(map[string]any); ok && block["type"] == "text" {
+				if s, _ := block["text"].(string); s != "" {
+					parts = append(parts, s)
+				}
+			}
+		}
+		return strings.Join(parts, "\n\n")
+	}
+	return ""
+}
+
+// ReadCompactions returns the compactions recorded in a session file, in
+// file order, each with the summary that followed its boundary.
+func ReadCompactions(path string) ([]*Compaction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var out []*Compaction
+	scanner := NewOffsetScanner(f)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !MayBeCompaction(line) {
+			continue
+		}
+		var obj map[string]any
+		if json.Unmarshal(line, &obj) != nil {
+			continue
+		}
+		switch {
+		case IsCompactBoundary(obj):
+			out = append(out, NewCompaction(obj))
+		case IsCompactSummary(obj):
+			if n := len(out); n > 0 && out[n-1].Summary == "" {
+				out[n-1].Summary = CompactSummaryText(obj)
+			} else {
+				out = append(out, &Compaction{At: ParseTimestamp(obj), Summary: CompactSummaryText(obj)})
+			}
+		}
+	}
+	return out, scanner.Err()
+}
@@ -0,0 +1,47 @@
+//go:build darwin || linux
+
+package session
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReadCompactions(t *testing.T) {
+	dir := t.TempDir()
+	writeSessionFile(t, dir, "compact1", []string{
+		`{"type":"user","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"build the parser"}}`,
+		`{"type":"system","subtype":"compact_boundary","parentUuid":null,"timestamp":"2026-03-01T11:00:00Z","compactMetadata":{"trigger":"auto","preTokens":168968,"postTokens":11638,"durationMs":49246}}`,
+		`{"type":"user","isCompactSummary":true,"timestamp":"2026-03-01T11:00:01Z","message":{"role":"user","content":"This session is being continued. Summary: parser half done."}}`,
+		`{"type":"assistant","timestamp":"2026-03-01T11:00:05Z","message":{"role":"assistant","content":"Continuing with the parser."}}`,
+		`{"type":"system","subtype":"compact_boundary","timestamp":"2026-03-01T12:00:00Z","compactMetadata":{"trigger":"manual","preTokens":90000}}`,
+	})
+
+	got, err := ReadCompactions(filepath.Join(dir, "compact1.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d compactions, want 2", len(got))
+	}
+	if got[0].Summary != "This session is being continued. Summary: parser half done." {
+		t.Errorf("summary = %q", got[0].Summary)
+	}
+	if label := got[0].Label(); label != "auto · 169k → 12k tokens · 49s" {
+		t.Errorf("label = %q", label)
+	}
+	if got[1].Trigger != "manual" || got[1].Summary != "" || got[1].Label() != "manual · 90k tokens" {
+		t.Errorf("second compaction = %+v", got[1])
+	}
+}
+
+func TestHumanPrompt_SkipsCompactSummary(t *testing.T) {
+	obj := map[string]any{
+		"type":             "user",
+		"isCompactSummary": true,
+		"message":          map[string]any{"role": "user", "content": "This session is being continued."},
+	}
+	if got := HumanPrompt(obj); got != "" {
+		t.Errorf("HumanPrompt = %q, want empty", got)
+	}
+}
@@ -0,0 +1,282 @@
+package session
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Failure kinds.
+const (
+	FailureToolError = "tool_error"
+	FailureAPIError  = "api_error"
+	FailureInterrupt = "interrupt"
+	FailureDenied    = "permission_denied"
+)
+
+// maxFailureText caps the error text kept per failure; the signature and
+// the first lines are what identify it.
+const maxFailureText = 2000
+
+// Failure is one thing that went wrong in a session: a tool call that
+// returned an error, an API error Claude Code wrote as a synthetic
+// assistant turn, the user interrupting a response, or a tool call that
+// was refused permission.
+type Failure struct {
+	Kind      string    `json:"kind"`
+	Tool      string    `json:"tool,omitempty"`
+	Input     string    `json:"input,omitempty"` // the call's command, path, URL, or pattern
+	Signature string    `json:"signature"`
+	Text      string    `json:"text"`
+	At        time.Time `json:"at,omitzero"`
+	Offset    int64     `json:"offset"`
+}
+
+// FailureTracker collects the failures in a session. Feed it every user
+// and assistant record in file order.
+type FailureTracker struct {
+	failures []*Failure
+	calls    map[string]failedCall
+	// toolInterrupt is set when a tool result recorded an interrupt, so
+	// the "[Request interrupted by user for tool use]" record Claude Code
+	// writes right after isn't counted twice.
+	toolInterrupt bool
+}
+
+type failedCall struct {
+	name  string
+	input string
+}
+
+func NewFailureTracker() *FailureTracker {
+	return &FailureTracker{calls: make(map[string]failedCall)}
+}
+
+// Observe records the failures in a parsed record read from byte offset
+// in the file.
+func (t *FailureTracker) Observe(obj map[string]any, offset int64) {
+	ts := ParseTimestamp(obj)
+	switch obj["type"] {
+	case "assistant":
+		t.toolInterrupt = false
+		if text := apiErrorText(obj); text != "" {
+			t.add(&Failure{Kind: FailureAPIError, Text: text, At: ts, Offset: offset})
+		}
+	case "user":
+		if text := interruptText(obj); text != "" {
+			if !t.toolInterrupt {
+				t.add(&Failure{Kind: FailureInterrupt, Text: text, At: ts, Offset: offset})
+			}
+			t.toolInterrupt = false
+		}
+	}
+
+	msg, ok := obj["message"].(map[string]any)
+	if !ok {
+		return
+	}
+	blocks, ok := msg["content"].([]any)
+	if !ok {
+		return
+	}
+	for _, item := range blocks {
+		block, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		switch block["type"] {
+		case "tool_use":
+			id, _ := block["id"].(string)
+			name, _ := block["name"].(string)
+			input, _ := block["input"].(map[string]any)
+			if id != "" {
+				t.calls[id] = failedCall{name: name, input: ToolTarget(input)}
+			}
+		case "tool_result":
+			if isErr, _ := block["is_error"].(bool); !isErr {
+				continue
+			}
+			id, _ := block["tool_use_id"].(string)
+			call := t.calls[id]
+			text := strings.TrimSpace(ExtractTextFromContent(block["content"]))
+			kind := toolFailureKind(text)
+			if kind == FailureInterrupt {
+				t.toolInterrupt = true
+			}
+			t.add(&Failure{Kind: kind, Tool: call.name, Input: call.input, Text: text, At: ts, Offset: offset})
+		}
+	}
+}
+
+func (t *FailureTracker) add(f *Failure) {
+	f.Text = strings.TrimSpace(f.Text)
+	if len(f.Text) > maxFailureText {
+		f.Text = strings.ToValidUTF8(f.Text[:maxFailureText], "")
+	}
+	f.Signature = FailureSignature(f.Kind, f.Text)
+	t.failures = append(t.failures, f)
+}
+
+// Failures returns every failure seen so far, in file order.
+func (t *FailureTracker) Failures() []*Failure {
+	return t.failures
+}
+
+// apiErrorText returns the message of a synthetic assistant turn holding
+// an API error, or "".
+func apiErrorText(obj map[string]any) string {
+	isErr, _ := obj["isApiErrorMessage"].(bool)
+	msg, _ := obj["message"].(map[string]any)
+	if msg == nil {
+		return ""
+	}
+	text := strings.TrimSpace(ExtractTextFromContent(msg["content"]))
+	if !isErr && (msg["model"] != "<synthetic>" || !strings.HasPrefix(text, "API Error")) {
+		return ""
+	}
+	if text == "" {
+		text = "API Error"
+	}
+	return text
+}
+
+// interruptText returns the "[Request interrupted by user…]" marker a user
+// record holds, or "".
+func interruptText(obj map[string]any) string {
+	msg, _ := obj["message"].(map[string]any)
+	if msg == nil {
+		return ""
+	}
+	var texts []string
+	switch content := msg["content"].(type) {
+	case string:
+		texts = []string{content}
+	case []any:
+		for _, item := range content {
+			if block, ok := item.(map[string]any); ok && block["type"] == "text" {
+				s, _ := block["text"].(string)
+				texts = append(texts, s)
+			}
+		}
+	}
+	for _, s := range texts {
+		if s = strings.TrimSpace(s); strings.HasPrefix(s, "[Request interrupted") {
+			return s
+		}
+	}
+	return ""
+}
+
+// Phrases in an errored tool result that say the call was refused rather
+// than run and failed: the user rejecting the permission prompt, a deny
+// rule, a call that needed approval nobody could give, or the sandbox.
+var deniedPhrases = []string{
+	"doesn't want to proceed with this tool use",
+	"permission to use",
+	"has been denied",
+	"haven't granted",
+	"requires approval",
+	"operation not permitted",
+	"sandbox",
+}
+
+func toolFailureKind(text string) string {
+	lower := strings.ToLower(text)
+	if strings.HasPrefix(lower, "[request interrupted") || strings.Contains(lower, "doesn't want to take this action right now") {
+		return FailureInterrupt
+	}
+	if IsPermissionDenial(text) {
+		return FailureDenied
+	}
+	return FailureToolError
+}
+
+// IsPermissionDenial reports whether the text of an errored tool result
+// says the call was refused permission rather than run.
+func IsPermissionDenial(text string) bool {
+	lower := strings.ToLower(text)
+	for _, p := range deniedPhrases {
+		if strings.Contains(lower, p) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	signatureTags   = regexp.MustCompile(`</?[a-z_]+>`)
+	signatureQuoted = regexp.MustCompile("\"[^\"]*\"|'[^']*'|`[^`]*`")
+	signaturePath   = regexp.MustCompile(`(^|[\s(=:])(?:~|\.{1,2})?/[^\s:,;)'"]+`)
+	signatureURL    = regexp.MustCompile(`https?://\S+`)
+	signatureID     = regexp.MustCompile(`\b[0-9a-f]{8}-[0-9a-f-]{27,}\b|\b[0-9a-f]{7,}\b`)
+	signatureNumber = regexp.MustCompile(`\d+(?:\.\d+)?`)
+	signatureExit   = regexp.MustCompile(`^(?i:exit code) \d+$`)
+	signatureSpaces = regexp.MustCompile(`\s+`)
+)
+
+const maxSignatureRunes = 100
+
+// FailureSignature reduces an error to the part that recurs: its first
+// meaningful line with paths, URLs, quoted strings, IDs, and numbers
+// replaced by placeholders, so the same failure groups together however
+// its details vary. API errors reduce to their status and error type.
+func FailureSignature(kind, text string) string {
+	if kind == FailureAPIError {
+		if sig := apiErrorSignature(text); sig != "" {
+			return sig
+		}
+	}
+	line := ""
+	for l := range strings.SplitSeq(signatureTags.ReplaceAllString(text, ""), "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" || signatureExit.MatchString(l) {
+			continue
+		}
+		line = l
+		break
+	}
+	if line == "" {
+		line = strings.TrimSpace(text)
+	}
+	line = signatureURL.ReplaceAllString(line, "<url>")
+	line = signatureQuoted.ReplaceAllString(line, "<str>")
+	line = signaturePath.ReplaceAllString(line, "${1}<path>")
+	line = signatureID.ReplaceAllString(line, "<id>")
+	line = signatureNumber.ReplaceAllString(line, "<n>")
+	line = signatureSpaces.ReplaceAllString(line, " ")
+	if utf8.RuneCountInString(line) > maxSignatureRunes {
+		line = string([]rune(line)[:maxSignatureRunes-1]) + "…"
+	}
+	return line
+}
+
+// apiErrorSignature turns `API Error: 529 {"type":"error","error":{"type":
+// "overloaded_error",…}}` into "API Error: 529 overloaded_error".
+func apiErrorSignature(text string) string {
+	start := strings.Index(text, "{")
+	if start < 0 {
+		return ""
+	}
+	var body struct {
+		Error struct {
+			Type string `json:"type"`
+		} `json:"error"`
+	}
+	if json.Unmarshal([]byte(text[start:]), &body) != nil || body.Error.Type == "" {
+		return ""
+	}
+	return strings.TrimSpace(text[:start]) + " " + body.Error.Type
+}
+
+// ToolTarget returns what a tool call acted on, for labelling it: the
+// command, file path, URL, or search pattern from its input.
+func ToolTarget(input map[string]any) string {
+	for _, key := range []string{"command", "file_path", "notebook_path", "url", "pattern", "query", "path"} {
+		if s, _ := input[key].(string); s != "" {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package session
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFailureTracker(t *testing.T) {
+	lines := []string{
+		`{"type":"assistant","timestamp":"2026-02-01T08:00:00Z","message":{"content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./..."}},{"type":"tool_use","id":"t2","name":"Bash","input":{"command":"rm -rf build"}}]}}`,
+		`{"type":"user","timestamp":"2026-02-01T08:00:04Z","message":{"content":[{"type":"tool_result","tool_use_id":"t1","is_error":true,"content":"Exit code 1\n--- FAIL: TestX (0.01s)"},{"type":"tool_result","tool_use_id":"t2","is_error":true,"content":"The user doesn't want to proceed with this tool use. The tool use was rejected."}]}}`,
+		`{"type":"assistant","timestamp":"2026-02-01T08:01:00Z","isApiErrorMessage":true,"message":{"model":"<synthetic>","content":[{"type":"text","text":"API Error: 529 {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}"}]}}`,
+		`{"type":"assistant","timestamp":"2026-02-01T08:02:00Z","message":{"content":[{"type":"tool_use","id":"t3","name":"Read","input":{"file_path":"/a"}}]}}`,
+		`{"type":"user","timestamp":"2026-02-01T08:02:01Z","message":{"content":[{"type":"tool_result","tool_use_id":"t3","is_error":true,"content":"The user doesn't want to take this action right now. STOP what you are doing."}]}}`,
+		`{"type":"user","timestamp":"2026-02-01T08:02:01Z","message":{"content":[{"type":"text","text":"[Request interrupted by user for tool use]"}]}}`,
+		`{"type":"assistant","timestamp":"2026-02-01T08:03:00Z","message":{"content":[{"type":"text","text":"Working on it"}]}}`,
+		`{"type":"user","timestamp":"2026-02-01T08:03:05Z","message":{"content":"[Request interrupted by user]"}}`,
+	}
+
+	tr := NewFailureTracker()
+	var offset int64
+	for _, line := range lines {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(line), &obj); err != nil {
+			t.Fatal(err)
+		}
+		tr.Observe(obj, offset)
+		offset += int64(len(line)) + 1
+	}
+
+	got := tr.Failures()
+	want := []struct{ kind, tool, input, signature string }{
+		{FailureToolError, "Bash", "go test ./...", "--- FAIL: TestX (<n>s)"},
+		{FailureDenied, "Bash", "rm -rf build", "The user doesn't want to proceed with this tool use. The tool use was rejected."},
+		{FailureAPIError, "", "", "API Error: 529 overloaded_error"},
+		{FailureInterrupt, "Read", "/a", "The user doesn't want to take this action right now. STOP what you are doing."},
+		{FailureInterrupt, "", "", "[Request interrupted by user]"},
+	}
+	if len(got) != len(want) {
+		for _, f := range got {
+			t.Logf("%+v", f)
+		}
+		t.Fatalf("got %d failures, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		f := got[i]
+		if f.Kind != w.kind || f.Tool != w.tool || f.Input != w.input || f.Signature != w.signature {
+			t.Errorf("failure %d = %+v, want %+v", i, f, w)
+		}
+	}
+	if got[0].Offset == 0 || got[0].At.IsZero() {
+		t.Errorf("failure 0 position = %d at %v", got[0].Offset, got[0].At)
+	}
+}
+
+func TestFailureSignature(t *testing.T) {
+	tests := []struct {
+		kind, text, want string
+	}{
+		{FailureToolError, "<tool_use_error>File does not exist: /Users/me/src/app/main.go</tool_use_error>", "File does not exist: <path>"},
+		{FailureToolError, "Error: connect ECONNREFUSED 127.0.0.1:5432", "Error: connect ECONNREFUSED <n>.<n>:<n>"},
+		{FailureToolError, "MCP error -32603: session 3f2a9c1d8e7b not found", "MCP error -<n>: session <id> not found"},
+		{FailureToolError, "Exit code 2\n\nfatal: not a git repository", "fatal: not a git repository"},
+		{FailureAPIError, "API Error: Request timed out.", "API Error: Request timed out."},
+	}
+	for _, tt := range tests {
+		if got := FailureSignature(tt.kind, tt.text); got != tt.want {
+			t.Errorf("FailureSignature(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// FileOp is one Read, Edit, MultiEdit, or Write call that succeeded, with
+// the toolUseResult Claude Code recorded for it: the lines a Read returned,
+// or structuredPatch hunks against the file as it stood before a change.
+type FileOp struct {
+	Tool    string
+	Path    string
+	Input   map[string]any
+	Result  map[string]any // nil when the log has no toolUseResult
+	Message int            // 1-based user/assistant record number of the call
+	Offset  int64
+	At      time.Time
+}
+
+// ReadFileOps returns the successful Read, Edit, MultiEdit, and Write
+// calls in a session file, in call order. Calls that errored or never got
+// a result are left out: they may not have touched the file.
+func ReadFileOps(path string) ([]*FileOp, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var ops []*FileOp
+	pending := map[string]*FileOp{}
+	done := map[*FileOp]bool{}
+	message := 0
+	scanner := NewOffsetScanner(f)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		lineType := FastExtractType(line)
+		if lineType != "user" && lineType != "assistant" {
+			continue
+		}
+		message++
+		var obj map[string]any
+		if json.Unmarshal(line, &obj) != nil {
+			continue
+		}
+		msg, _ := obj["message"].(map[string]any)
+		blocks, _ := msg["content"].([]any)
+		for _, item := range blocks {
+			block, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			switch block["type"] {
+			case "tool_use":
+				name, _ := block["name"].(string)
+				if name != "Read" && name != "Edit" && name != "MultiEdit" && name != "Write" {
+					continue
+				}
+				id, _ := block["id"].(string)
+				input, _ := block["input"].(map[string]any)
+				op := &FileOp{Tool: name, Input: input, Message: message, Offset: scanner.Offset(), At: ParseTimestamp(obj)}
+				op.Path, _ = input["file_path"].(string)
+				if id == "" || op.Path == "" {
+					continue
+				}
+				pending[id] = op
+				ops = append(ops, op)
+			case "tool_result":
+				id, _ := block["tool_use_id"].(string)
+				op, ok := pending[id]
+				if !ok {
+					continue
+				}
+				delete(pending, id)
+				if isErr, _ := block["is_error"].(bool); isErr {
+					continue
+				}
+				op.Result, _ = obj["toolUseResult"].(map[string]any)
+				done[op] = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	out := ops[:0]
+	for _, op := range ops {
+		if done[op] {
+			out = append(out, op)
+		}
+	}
+	return out, nil
+}
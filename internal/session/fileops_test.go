@@ -0,0 +1,35 @@
+//go:build darwin || linux
+
+package session
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReadFileOps(t *testing.T) {
+	dir := t.TempDir()
+	writeSessionFile(t, dir, "fileops1", []string{
+		`{"type":"user","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"fix main.go"}}`,
+		`{"type":"assistant","timestamp":"2026-03-01T10:00:01Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Read","input":{"file_path":"/p/main.go"}},{"type":"tool_use","id":"t2","name":"Bash","input":{"command":"ls"}}]}}`,
+		`{"type":"user","timestamp":"2026-03-01T10:00:02Z","toolUseResult":{"type":"text","file":{"filePath":"/p/main.go","content":"package main","startLine":1,"numLines":1,"totalLines":1}},"message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"package main"}]}}`,
+		`{"type":"assistant","timestamp":"2026-03-01T10:00:03Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t3","name":"Edit","input":{"file_path":"/p/main.go","old_string":"x","new_string":"y"}}]}}`,
+		`{"type":"user","timestamp":"2026-03-01T10:00:04Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t3","is_error":true,"content":"String to replace not found"}]}}`,
+		`{"type":"assistant","timestamp":"2026-03-01T10:00:05Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t4","name":"Write","input":{"file_path":"/p/new.go","content":"package main\n"}}]}}`,
+	})
+
+	ops, err := ReadFileOps(filepath.Join(dir, "fileops1.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 1 {
+		t.Fatalf("got %d ops, want only the Read (the Edit errored, the Write has no result)", len(ops))
+	}
+	op := ops[0]
+	if op.Tool != "Read" || op.Path != "/p/main.go" || op.Message != 2 {
+		t.Errorf("op = %+v", op)
+	}
+	if file, _ := op.Result["file"].(map[string]any); file["content"] != "package main" {
+		t.Errorf("result = %v", op.Result)
+	}
+}
@@ -0,0 +1,73 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HistoryEntry is one prompt from Claude Code's global prompt history
+// (history.jsonl beside projects/), the list up-arrow recall walks. It is
+// written independently of the session files, so it keeps prompts whose
+// sessions were cleaned up. SessionID is empty in entries written by older
+// Claude Code releases.
+type HistoryEntry struct {
+	Display   string    `json:"display"`
+	Pasted    []string  `json:"pasted,omitempty"` // text pasted with the prompt, shown in Display as "[Pasted text #1 ...]"
+	Project   string    `json:"project"`
+	SessionID string    `json:"session_id,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+// Text is the prompt with any pasted text, for indexing.
+func (e *HistoryEntry) Text() string {
+	if len(e.Pasted) == 0 {
+		return e.Display
+	}
+	return e.Display + "\n" + strings.Join(e.Pasted, "\n")
+}
+
+// ReadHistory returns the entries of a history.jsonl file in file order.
+// Lines that don't parse, or carry no prompt, are skipped.
+func ReadHistory(path string) ([]*HistoryEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var out []*HistoryEntry
+	scanner := NewOffsetScanner(f)
+	for scanner.Scan() {
+		var raw struct {
+			Display        string                    `json:"display"`
+			PastedContents map[string]map[string]any `json:"pastedContents"`
+			Timestamp      int64                     `json:"timestamp"`
+			Project        string                    `json:"project"`
+			SessionID      string                    `json:"sessionId"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &raw) != nil || strings.TrimSpace(raw.Display) == "" {
+			continue
+		}
+		e := &HistoryEntry{
+			Display:   raw.Display,
+			Project:   raw.Project,
+			SessionID: raw.SessionID,
+			At:        time.UnixMilli(raw.Timestamp).UTC(),
+		}
+		keys := make([]string, 0, len(raw.PastedContents))
+		for k := range raw.PastedContents {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if text, _ := raw.PastedContents[k]["content"].(string); text != "" {
+				e.Pasted = append(e.Pasted, text)
+			}
+		}
+		out = append(out, e)
+	}
+	return out, scanner.Err()
+}
@@ -0,0 +1,56 @@
+package session
+
+var (
+	uuidPrefix              = []byte(`"uuid":"`)
+	parentUUIDPrefix        = []byte(`"parentUuid":"`)
+	logicalParentUUIDPrefix = []byte(`"logicalParentUuid":"`)
+	leafUUIDPrefix          = []byte(`"leafUuid":"`)
+)
+
+// Lineage collects the message uuids a session file holds and the uuids it
+// points at — parentUuid and logicalParentUuid links, and the leafUuid of
+// summary records. `claude --resume` and `--continue` start a new file that
+// replays the earlier messages (sharing their uuids) or links back to them,
+// so these are what tie one conversation's files into a chain.
+type Lineage struct {
+	uuids []string
+	held  map[string]bool
+	links []string
+}
+
+func NewLineage() *Lineage {
+	return &Lineage{held: make(map[string]bool)}
+}
+
+// Observe records the uuid fields of one raw JSONL line.
+func (l *Lineage) Observe(line []byte) {
+	if id := fastExtractString(line, uuidPrefix); id != "" && !l.held[id] {
+		l.held[id] = true
+		l.uuids = append(l.uuids, id)
+	}
+	for _, prefix := range [][]byte{parentUUIDPrefix, logicalParentUUIDPrefix, leafUUIDPrefix} {
+		if id := fastExtractString(line, prefix); id != "" {
+			l.links = append(l.links, id)
+		}
+	}
+}
+
+// UUIDs returns the uuids of the records in the file, in file order.
+func (l *Lineage) UUIDs() []string {
+	return l.uuids
+}
+
+// Refs returns the linked uuids the file doesn't hold itself: pointers
+// into another session file.
+func (l *Lineage) Refs() []string {
+	var refs []string
+	seen := map[string]bool{}
+	for _, id := range l.links {
+		if l.held[id] || seen[id] {
+			continue
+		}
+		seen[id] = true
+		refs = append(refs, id)
+	}
+	return refs
+}
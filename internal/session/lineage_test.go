@@ -0,0 +1,26 @@
+package session
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLineage(t *testing.T) {
+	l := NewLineage()
+	for _, line := range []string{
+		`{"type":"summary","summary":"Fix login","leafUuid":"old-2"}`,
+		`{"type":"user","uuid":"new-1","parentUuid":"old-2","message":{"role":"user","content":"continue"}}`,
+		`{"type":"assistant","uuid":"new-2","parentUuid":"new-1","message":{"role":"assistant","content":"ok"}}`,
+		`{"type":"system","uuid":"new-3","parentUuid":null,"logicalParentUuid":"new-2"}`,
+		`{"type":"user","uuid":"new-1","parentUuid":"old-2"}`,
+	} {
+		l.Observe([]byte(line))
+	}
+
+	if got, want := l.UUIDs(), []string{"new-1", "new-2", "new-3"}; !slices.Equal(got, want) {
+		t.Errorf("UUIDs() = %v, want %v", got, want)
+	}
+	if got, want := l.Refs(), []string{"old-2"}; !slices.Equal(got, want) {
+		t.Errorf("Refs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,192 @@
+package session
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Link sources besides the web tools' names.
+const (
+	LinkFromPrompt = "prompt"
+	LinkFromReply  = "reply"
+)
+
+// maxLinkContext caps the text kept around a link.
+const maxLinkContext = 200
+
+// Link is a URL a session touched: pasted into a prompt, written in a
+// reply, fetched with WebFetch, or returned by WebSearch. Context is the
+// search query, the fetch prompt, or the line the URL appeared on.
+type Link struct {
+	URL     string    `json:"url"`
+	Domain  string    `json:"domain"`
+	Title   string    `json:"title,omitempty"`
+	Source  string    `json:"source"`
+	Context string    `json:"context,omitempty"`
+	At      time.Time `json:"at,omitzero"`
+	Offset  int64     `json:"offset"`
+}
+
+// LinkTracker collects the links in a session. Feed it every user and
+// assistant record in file order.
+type LinkTracker struct {
+	links    []*Link
+	searches map[string]string // WebSearch tool_use_id → query
+}
+
+func NewLinkTracker() *LinkTracker {
+	return &LinkTracker{searches: make(map[string]string)}
+}
+
+// Observe records the links in a parsed record read from byte offset in
+// the file.
+func (t *LinkTracker) Observe(obj map[string]any, offset int64) {
+	ts := ParseTimestamp(obj)
+	if obj["type"] == "user" {
+		if text := HumanPrompt(obj); text != "" {
+			t.addText(text, LinkFromPrompt, ts, offset)
+		}
+	}
+	msg, ok := obj["message"].(map[string]any)
+	if !ok {
+		return
+	}
+	blocks, ok := msg["content"].([]any)
+	if !ok {
+		return
+	}
+	for _, item := range blocks {
+		block, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		switch block["type"] {
+		case "text":
+			if obj["type"] == "assistant" {
+				text, _ := block["text"].(string)
+				t.addText(text, LinkFromReply, ts, offset)
+			}
+		case "tool_use":
+			id, _ := block["id"].(string)
+			input, _ := block["input"].(map[string]any)
+			switch block["name"] {
+			case "WebFetch":
+				u, _ := input["url"].(string)
+				prompt, _ := input["prompt"].(string)
+				t.add(u, "", "WebFetch", prompt, ts, offset)
+			case "WebSearch":
+				query, _ := input["query"].(string)
+				t.searches[id] = query
+			}
+		case "tool_result":
+			id, _ := block["tool_use_id"].(string)
+			query, ok := t.searches[id]
+			if !ok {
+				continue
+			}
+			delete(t.searches, id)
+			for _, r := range searchResults(ExtractTextFromContent(block["content"])) {
+				t.add(r.URL, r.Title, "WebSearch", query, ts, offset)
+			}
+		}
+	}
+}
+
+// Links returns every link seen so far, in file order.
+func (t *LinkTracker) Links() []*Link {
+	return t.links
+}
+
+// addText records each distinct URL in text, with the line it is on as
+// context.
+func (t *LinkTracker) addText(text, source string, at time.Time, offset int64) {
+	seen := map[string]bool{}
+	for line := range strings.SplitSeq(text, "\n") {
+		for _, u := range ExtractURLs(line) {
+			if !seen[u] {
+				seen[u] = true
+				t.add(u, "", source, line, at, offset)
+			}
+		}
+	}
+}
+
+func (t *LinkTracker) add(rawURL, title, source, context string, at time.Time, offset int64) {
+	domain := LinkDomain(rawURL)
+	if domain == "" {
+		return
+	}
+	context = strings.Join(strings.Fields(context), " ")
+	if len(context) > maxLinkContext {
+		context = strings.ToValidUTF8(context[:maxLinkContext], "") + "…"
+	}
+	t.links = append(t.links, &Link{
+		URL:     rawURL,
+		Domain:  domain,
+		Title:   strings.TrimSpace(title),
+		Source:  source,
+		Context: context,
+		At:      at,
+		Offset:  offset,
+	})
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `\)\]\}|]+`)
+
+// ExtractURLs returns the http(s) URLs in text, without the punctuation
+// that usually ends the sentence or markdown around them.
+func ExtractURLs(text string) []string {
+	var out []string
+	for _, u := range urlPattern.FindAllString(text, -1) {
+		u = strings.TrimRight(u, ".,;:!?*_~")
+		if LinkDomain(u) != "" {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// LinkDomain returns the lowercased host of rawURL without a leading
+// "www.", or "" when it isn't an http(s) URL with a host.
+func LinkDomain(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+type searchResult struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// searchResults reads the links out of a WebSearch result, which lists
+// them as a JSON array after "Links: ". Results in another shape fall
+// back to the bare URLs in the text.
+func searchResults(text string) []searchResult {
+	var out []searchResult
+	rest := text
+	for {
+		_, after, ok := strings.Cut(rest, "Links: ")
+		if !ok {
+			break
+		}
+		var batch []searchResult
+		dec := json.NewDecoder(strings.NewReader(after))
+		if dec.Decode(&batch) == nil {
+			out = append(out, batch...)
+		}
+		rest = after
+	}
+	if len(out) > 0 {
+		return out
+	}
+	for _, u := range ExtractURLs(text) {
+		out = append(out, searchResult{URL: u})
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package session
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestLinkTracker(t *testing.T) {
+	lines := []string{
+		`{"type":"user","timestamp":"2026-02-01T08:00:00Z","message":{"role":"user","content":"why does https://github.com/org/repo/issues/12 fail?\nsee also (https://www.example.com/a)."}}`,
+		`{"type":"assistant","timestamp":"2026-02-01T08:00:05Z","message":{"content":[{"type":"tool_use","id":"s1","name":"WebSearch","input":{"query":"sqlite fts5 contentless delete"}},{"type":"tool_use","id":"f1","name":"WebFetch","input":{"url":"https://sqlite.org/fts5.html","prompt":"How does contentless_delete work?"}}]}}`,
+		`{"type":"user","timestamp":"2026-02-01T08:00:09Z","message":{"content":[{"type":"tool_result","tool_use_id":"s1","content":"Web search results for query: \"sqlite fts5 contentless delete\"\n\nLinks: [{\"title\":\"SQLite FTS5 Extension\",\"url\":\"https://sqlite.org/fts5.html\"},{\"title\":\"Forum thread\",\"url\":\"https://sqlite.org/forum/info/abc\"}]\n\nSummary mentions https://ignored.example"},{"type":"tool_result","tool_use_id":"f1","content":"page text https://not-indexed.example"}]}}`,
+		`{"type":"assistant","timestamp":"2026-02-01T08:01:00Z","message":{"content":[{"type":"text","text":"Per [the docs](https://sqlite.org/fts5.html#contentless_delete_tables), yes."}]}}`,
+	}
+
+	tr := NewLinkTracker()
+	var offset int64
+	for _, line := range lines {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(line), &obj); err != nil {
+			t.Fatal(err)
+		}
+		tr.Observe(obj, offset)
+		offset += int64(len(line)) + 1
+	}
+
+	type link struct{ url, domain, title, source, context string }
+	var got []link
+	for _, l := range tr.Links() {
+		got = append(got, link{l.URL, l.Domain, l.Title, l.Source, l.Context})
+	}
+	want := []link{
+		{"https://github.com/org/repo/issues/12", "github.com", "", LinkFromPrompt, "why does https://github.com/org/repo/issues/12 fail?"},
+		{"https://www.example.com/a", "example.com", "", LinkFromPrompt, "see also (https://www.example.com/a)."},
+		{"https://sqlite.org/fts5.html", "sqlite.org", "", "WebFetch", "How does contentless_delete work?"},
+		{"https://sqlite.org/fts5.html", "sqlite.org", "SQLite FTS5 Extension", "WebSearch", "sqlite fts5 contentless delete"},
+		{"https://sqlite.org/forum/info/abc", "sqlite.org", "Forum thread", "WebSearch", "sqlite fts5 contentless delete"},
+		{"https://sqlite.org/fts5.html#contentless_delete_tables", "sqlite.org", "", LinkFromReply, "Per [the docs](https://sqlite.org/fts5.html#contentless_delete_tables), yes."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("links:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestExtractURLs(t *testing.T) {
+	got := ExtractURLs(`See <https://a.dev/x>, "http://b.io/y?q=1". Also ftp://c.org and https://`)
+	want := []string{"https://a.dev/x", "http://b.io/y?q=1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractURLs = %q, want %q", got, want)
+	}
+}
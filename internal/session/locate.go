@@ -0,0 +1,106 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+)
+
+// HumanPrompt returns the text a person typed in a user record, or "" when
+// the record is a tool result, a meta record, a compaction summary, or
+// injected command output.
+func HumanPrompt(obj map[string]any) string {
+	if obj["type"] != "user" {
+		return ""
+	}
+	if meta, _ := obj["isMeta"].(bool); meta {
+		return ""
+	}
+	if IsCompactSummary(obj) {
+		return ""
+	}
+	msg, ok := obj["message"].(map[string]any)
+	if !ok {
+		return ""
+	}
+	var text string
+	switch content := msg["content"].(type) {
+	case string:
+		text = content
+	case []any:
+		var parts []string
+		for _, item := range content {
+			block, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			switch block["type"] {
+			case "tool_result":
+				return ""
+			case "text":
+				if s, _ := block["text"].(string); s != "" {
+					parts = append(parts, s)
+				}
+			}
+		}
+		text = strings.Join(parts, "\n")
+	}
+	text = strings.TrimSpace(text)
+	// Slash-command expansions and local command output are wrapped in
+	// pseudo-XML tags by Claude Code; they aren't something the user typed.
+	if strings.HasPrefix(text, "<command-") || strings.HasPrefix(text, "<local-command-") {
+		return ""
+	}
+	return text
+}
+
+// Position locates a record within a session: its 1-based number among the
+// user and assistant messages and the human prompt that preceded it.
+type Position struct {
+	Offset   int64     `json:"offset"`
+	Message  int       `json:"message"`
+	At       time.Time `json:"at,omitzero"`
+	Prompt   string    `json:"prompt,omitempty"`
+	PromptAt time.Time `json:"prompt_at,omitzero"`
+}
+
+// PositionAt reads a session file up to the record at byte offset and
+// returns its Position. The record itself counts as the latest prompt
+// when it is one.
+func PositionAt(path string, offset int64) (Position, error) {
+	pos := Position{Offset: offset}
+	f, err := os.Open(path)
+	if err != nil {
+		return pos, err
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := NewOffsetScanner(f)
+	for scanner.Scan() {
+		if scanner.Offset() > offset {
+			break
+		}
+		line := scanner.Bytes()
+		lineType := FastExtractType(line)
+		if lineType != "user" && lineType != "assistant" {
+			continue
+		}
+		pos.Message++
+		if scanner.Offset() == offset {
+			pos.At = FastExtractTimestamp(line)
+		}
+		if lineType != "user" {
+			continue
+		}
+		var obj map[string]any
+		if json.Unmarshal(line, &obj) != nil {
+			continue
+		}
+		if prompt := HumanPrompt(obj); prompt != "" {
+			pos.Prompt = prompt
+			pos.PromptAt = ParseTimestamp(obj)
+		}
+	}
+	return pos, scanner.Err()
+}
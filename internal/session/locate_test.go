@@ -0,0 +1,62 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHumanPrompt(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{`{"type":"user","message":{"content":"  fix the bug  "}}`, "fix the bug"},
+		{`{"type":"user","message":{"content":[{"type":"text","text":"look"},{"type":"image"}]}}`, "look"},
+		{`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"x","content":"ok"}]}}`, ""},
+		{`{"type":"user","isMeta":true,"message":{"content":"Caveat: ..."}}`, ""},
+		{`{"type":"user","message":{"content":"<command-name>/clear</command-name>"}}`, ""},
+		{`{"type":"assistant","message":{"content":"hi"}}`, ""},
+	}
+	for _, tt := range tests {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(tt.line), &obj); err != nil {
+			t.Fatal(err)
+		}
+		if got := HumanPrompt(obj); got != tt.want {
+			t.Errorf("HumanPrompt(%s) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestPositionAt(t *testing.T) {
+	lines := []string{
+		`{"type":"summary","summary":"x"}`,
+		`{"type":"user","message":{"content":"first ask"},"timestamp":"2026-02-01T08:00:00Z"}`,
+		`{"type":"assistant","message":{"content":"ok"},"timestamp":"2026-02-01T08:00:01Z"}`,
+		`{"type":"user","message":{"content":"second ask"},"timestamp":"2026-02-01T08:05:00Z"}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t","name":"Edit","input":{}}]},"timestamp":"2026-02-01T08:05:02Z"}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t","content":"ok"}]},"timestamp":"2026-02-01T08:05:03Z"}`,
+	}
+	path := filepath.Join(t.TempDir(), "s.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var offset int64
+	for _, l := range lines[:4] {
+		offset += int64(len(l)) + 1
+	}
+
+	pos, err := PositionAt(path, offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pos.Message != 4 || pos.Prompt != "second ask" {
+		t.Errorf("pos = %+v, want message 4 after %q", pos, "second ask")
+	}
+	if pos.At.IsZero() || pos.PromptAt.IsZero() || !pos.PromptAt.Before(pos.At) {
+		t.Errorf("timestamps = %v / %v", pos.PromptAt, pos.At)
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/andyhtran/cct/internal/paths"
 )
 
 const (
@@ -21,6 +23,7 @@ const (
 var (
 	typePrefix      = []byte(`"type":"`)
 	timestampPrefix = []byte(`"timestamp":"`)
+	versionPrefix   = []byte(`"version":"`)
 	// typeUser and typeAssistant are searched first because these values only
 	// appear at the top level. The generic typePrefix search can match nested
 	// types like "type":"message" in the message object, which appears before
@@ -49,17 +52,46 @@ func FastExtractType(line []byte) string {
 }
 
 func FastExtractTimestamp(line []byte) time.Time {
-	idx := bytes.Index(line, timestampPrefix)
-	if idx < 0 {
+	ts := fastExtractString(line, timestampPrefix)
+	if ts == "" {
 		return time.Time{}
 	}
-	rest := line[idx+len(timestampPrefix):]
+	t, _ := time.Parse(time.RFC3339Nano, ts)
+	return t
+}
+
+// fastExtractString returns the string value following the first occurrence
+// of prefix (a `"key":"` byte pattern), or "" when absent.
+func fastExtractString(line, prefix []byte) string {
+	idx := bytes.Index(line, prefix)
+	if idx < 0 {
+		return ""
+	}
+	rest := line[idx+len(prefix):]
 	end := bytes.IndexByte(rest, '"')
 	if end < 0 {
-		return time.Time{}
+		return ""
+	}
+	return string(rest[:end])
+}
+
+// ExtractMessageMeta updates the timeline fields from a raw user or
+// assistant line: FirstMessageAt/LastMessageAt widen to include its
+// timestamp, and Version takes the writing Claude Code release (top-level
+// "version" precedes "message" in the record, so the fast scan doesn't hit
+// nested tool input).
+func ExtractMessageMeta(s *Session, line []byte) {
+	if ts := FastExtractTimestamp(line); !ts.IsZero() {
+		if s.FirstMessageAt.IsZero() || ts.Before(s.FirstMessageAt) {
+			s.FirstMessageAt = ts
+		}
+		if ts.After(s.LastMessageAt) {
+			s.LastMessageAt = ts
+		}
+	}
+	if v := fastExtractString(line, versionPrefix); v != "" {
+		s.Version = v
 	}
-	t, _ := time.Parse(time.RFC3339Nano, string(rest[:end]))
-	return t
 }
 
 func ExtractPromptText(obj map[string]any) string {
@@ -88,11 +120,71 @@ var SkipTypes = map[string]bool{
 
 const maxExtractDepth = 10
 
+// ThinkingBlocks returns the text of a message's thinking blocks, which
+// SkipTypes keeps out of everything else. Redacted thinking carries only an
+// encrypted payload and is never returned.
+func ThinkingBlocks(obj map[string]any) []string {
+	msg, ok := obj["message"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	arr, ok := msg["content"].([]any)
+	if !ok {
+		return nil
+	}
+	var out []string
+	for _, item := range arr {
+		block, ok := item.(map[string]any)
+		if !ok || block["type"] != "thinking" {
+			continue
+		}
+		if text, _ := block["thinking"].(string); strings.TrimSpace(text) != "" {
+			out = append(out, text)
+		}
+	}
+	return out
+}
+
 // ContentBlock holds extracted text from a single content block along with its source.
 // Source is empty for regular text blocks, or the tool name for tool_use blocks.
 type ContentBlock struct {
 	Text   string
 	Source string
+	// ToolUseID links a tool_use block and the tool_result text answering
+	// it. ToolNames.Tag copies the tool name onto results as their Source.
+	ToolUseID  string
+	ToolResult bool
+}
+
+// RoleToolResult is the match role of text from a tool_result block.
+const RoleToolResult = "tool_result"
+
+// Role is the match role of the block in a record of type lineType: the
+// record type, except tool output, which gets RoleToolResult.
+func (b ContentBlock) Role(lineType string) string {
+	if b.ToolResult {
+		return RoleToolResult
+	}
+	return lineType
+}
+
+// ToolNames maps tool_use IDs to tool names across a session, so tool
+// results, which carry only the ID, can be labelled with their tool.
+type ToolNames map[string]string
+
+// Tag records the tool_use blocks in blocks and sets Source on tool
+// results whose call has been seen.
+func (n ToolNames) Tag(blocks []ContentBlock) {
+	for i, b := range blocks {
+		switch {
+		case b.ToolResult:
+			if name := n[b.ToolUseID]; name != "" {
+				blocks[i].Source = name
+			}
+		case b.ToolUseID != "" && b.Source != "":
+			n[b.ToolUseID] = b.Source
+		}
+	}
 }
 
 // ExtractTextFromContent recursively extracts searchable text from message content.
@@ -147,7 +239,8 @@ func extractBlockEntries(block map[string]any, depth int) []ContentBlock {
 			return nil
 		}
 		name, _ := block["name"].(string)
-		return []ContentBlock{{Text: text, Source: name}}
+		id, _ := block["id"].(string)
+		return []ContentBlock{{Text: text, Source: name, ToolUseID: id}}
 	}
 	var blocks []ContentBlock
 	if text, ok := block["text"].(string); ok && text != "" && !isBase64Like(text) {
@@ -156,6 +249,14 @@ func extractBlockEntries(block map[string]any, depth int) []ContentBlock {
 	if c, exists := block["content"]; exists {
 		blocks = append(blocks, extractBlocks(c, depth+1)...)
 	}
+	if blockType == "tool_result" {
+		id, _ := block["tool_use_id"].(string)
+		for i := range blocks {
+			blocks[i].ToolUseID = id
+			blocks[i].ToolResult = true
+			blocks[i].Source = ""
+		}
+	}
 	return blocks
 }
 
@@ -228,11 +329,11 @@ func isBase64Like(s string) bool {
 	return len(s) > 1000 && !strings.Contains(s[:1000], " ")
 }
 
-// extractAssistantUsage pulls .message.usage and .message.model out of a raw
+// ExtractAssistantUsage pulls .message.usage and .message.model out of a raw
 // assistant JSONL line and updates the session's rolling context totals.
 // Synthetic turns (model "<synthetic>") never hit the API, carry zero usage,
 // and are skipped so they don't blank out a real last-turn value.
-func extractAssistantUsage(s *Session, line []byte) {
+func ExtractAssistantUsage(s *Session, line []byte) {
 	var obj struct {
 		Message struct {
 			Model string `json:"model"`
@@ -313,6 +414,23 @@ func LoadAgentMeta(s *Session, path string) {
 	s.AgentDescription = meta.Description
 }
 
+// LoadParentID populates ParentID for subagents. Nested agents live at
+// <projectDir>/<parentID>/subagents/agent-<id>.jsonl, so the parent is read
+// off the path; flat legacy agents name their parent in each record's
+// sessionId.
+func LoadParentID(s *Session, path string) {
+	if !s.IsAgent {
+		return
+	}
+	if dir := filepath.Dir(path); filepath.Base(dir) == "subagents" {
+		s.ParentID = filepath.Base(filepath.Dir(dir))
+		return
+	}
+	if id := readSessionID(path); id != s.ID {
+		s.ParentID = id
+	}
+}
+
 // OffsetScanner wraps a reader to track byte offsets for each line.
 // Unlike bufio.Scanner, there is no fixed line-length ceiling — the underlying
 // bufio.Reader.ReadBytes grows as needed. A per-line sanity cap (scanMaxLine)
@@ -432,10 +550,13 @@ func parseSession(path string, full bool) *Session {
 		ID:       ExtractIDFromFilename(path),
 		FilePath: path,
 		Modified: info.ModTime(),
+		FileSize: info.Size(),
 	}
 	s.ShortID = ShortID(s.ID)
 	s.IsAgent = IsAgentSession(s.ID)
+	s.Root = paths.RootOf(path)
 	LoadAgentMeta(s, path)
+	LoadParentID(s, path)
 
 	scanner := NewOffsetScanner(f)
 
@@ -451,6 +572,7 @@ func parseSession(path string, full bool) *Session {
 		case "user":
 			if full {
 				s.MessageCount++
+				ExtractMessageMeta(s, line)
 			}
 			var obj map[string]any
 			if json.Unmarshal(line, &obj) != nil {
@@ -461,12 +583,13 @@ func parseSession(path string, full bool) *Session {
 		case "assistant":
 			if full {
 				s.MessageCount++
+				ExtractMessageMeta(s, line)
 			}
 			if ts := FastExtractTimestamp(line); !ts.IsZero() && s.Created.IsZero() {
 				s.Created = ts
 			}
 			if full {
-				extractAssistantUsage(s, line)
+				ExtractAssistantUsage(s, line)
 			}
 
 		case "custom-title":
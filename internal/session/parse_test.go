@@ -646,3 +646,42 @@ func TestParseSession_LargeUserLine(t *testing.T) {
 		t.Errorf("MessageCount = %d, want 2 (user + assistant after the huge line)", s.MessageCount)
 	}
 }
+
+func TestThinkingBlocks(t *testing.T) {
+	obj := map[string]any{"message": map[string]any{"content": []any{
+		map[string]any{"type": "thinking", "thinking": "weigh the options"},
+		map[string]any{"type": "redacted_thinking", "data": "opaque"},
+		map[string]any{"type": "thinking", "thinking": "  "},
+		map[string]any{"type": "text", "text": "answer"},
+	}}}
+	got := ThinkingBlocks(obj)
+	if len(got) != 1 || got[0] != "weigh the options" {
+		t.Errorf("ThinkingBlocks = %q, want [weigh the options]", got)
+	}
+}
+
+func TestToolNames_Tag(t *testing.T) {
+	use := map[string]any{"message": map[string]any{"content": []any{
+		map[string]any{"type": "tool_use", "id": "toolu_1", "name": "Bash", "input": map[string]any{"command": "go test ./..."}},
+	}}}
+	result := map[string]any{"message": map[string]any{"content": []any{
+		map[string]any{"type": "tool_result", "tool_use_id": "toolu_1", "content": []any{
+			map[string]any{"type": "text", "text": "FAIL TestParse"},
+		}},
+		map[string]any{"type": "text", "text": "why did that fail?"},
+	}}}
+
+	names := ToolNames{}
+	names.Tag(ExtractPromptBlocks(use))
+	blocks := ExtractPromptBlocks(result)
+	names.Tag(blocks)
+	if len(blocks) != 2 {
+		t.Fatalf("got %d blocks, want 2", len(blocks))
+	}
+	if b := blocks[0]; b.Role("user") != RoleToolResult || b.Source != "Bash" || b.Text != "FAIL TestParse" {
+		t.Errorf("tool result block = %+v", b)
+	}
+	if b := blocks[1]; b.Role("user") != "user" || b.Source != "" {
+		t.Errorf("typed text block = %+v", b)
+	}
+}
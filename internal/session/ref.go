@@ -0,0 +1,74 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// refUUIDLen is how much of a message uuid a ref keeps. Eight hex digits
+// are unique within any real session and keep refs short enough to type.
+const refUUIDLen = 8
+
+// MessageRef names one message so search results can be opened where they
+// matched: the session's short ID, "#", then the start of the record's
+// uuid, or its byte offset in the file when the record has no uuid.
+func MessageRef(shortID, uuid string, offset int64) string {
+	if uuid != "" {
+		return shortID + "#" + uuid[:min(len(uuid), refUUIDLen)]
+	}
+	return shortID + "#" + strconv.FormatInt(offset, 10)
+}
+
+// SplitRef splits a message ref into its session ID and anchor. A plain
+// session ID has no anchor.
+func SplitRef(ref string) (id, anchor string) {
+	id, anchor, _ = strings.Cut(ref, "#")
+	return id, anchor
+}
+
+// Anchor is the record a message ref points at.
+type Anchor struct {
+	UUID   string
+	Offset int64
+}
+
+// LocateAnchor finds the record anchor names in the session file at path:
+// for a number, the record starting at that byte offset or, failing that,
+// the first whose uuid starts with anchor. Offsets win because a uuid
+// prefix of digits is ambiguous, while an offset either starts a record
+// or doesn't.
+func LocateAnchor(path, anchor string) (*Anchor, error) {
+	if anchor == "" {
+		return nil, fmt.Errorf("empty message ref")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	offset, numErr := strconv.ParseInt(anchor, 10, 64)
+	var byUUID *Anchor
+	scanner := NewOffsetScanner(f)
+	for scanner.Scan() {
+		uuid := fastExtractString(scanner.Bytes(), uuidPrefix)
+		if numErr == nil && scanner.Offset() == offset {
+			return &Anchor{UUID: uuid, Offset: offset}, nil
+		}
+		if byUUID == nil && uuid != "" && strings.HasPrefix(uuid, anchor) {
+			if numErr != nil {
+				return &Anchor{UUID: uuid, Offset: scanner.Offset()}, nil
+			}
+			byUUID = &Anchor{UUID: uuid, Offset: scanner.Offset()}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if byUUID != nil {
+		return byUUID, nil
+	}
+	return nil, fmt.Errorf("no message %q in session %s", anchor, ShortID(ExtractIDFromFilename(path)))
+}
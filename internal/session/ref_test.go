@@ -0,0 +1,155 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessageRef(t *testing.T) {
+	if got := MessageRef("abcd1234", "9f3e2a1b-0000-4000-8000-000000000000", 512); got != "abcd1234#9f3e2a1b" {
+		t.Errorf("with uuid = %q", got)
+	}
+	if got := MessageRef("abcd1234", "", 512); got != "abcd1234#512" {
+		t.Errorf("without uuid = %q", got)
+	}
+	if id, anchor := SplitRef("abcd1234#9f3e"); id != "abcd1234" || anchor != "9f3e" {
+		t.Errorf("SplitRef = %q, %q", id, anchor)
+	}
+	if id, anchor := SplitRef("abcd1234"); id != "abcd1234" || anchor != "" {
+		t.Errorf("SplitRef plain = %q, %q", id, anchor)
+	}
+}
+
+func TestLocateAnchor(t *testing.T) {
+	lines := []string{
+		`{"type":"summary","summary":"x"}`,
+		`{"type":"user","uuid":"11111111-aaaa","message":{"role":"user","content":"hi"}}`,
+		`{"type":"assistant","uuid":"22222222-bbbb","message":{"role":"assistant","content":"hello"}}`,
+		`{"type":"user","uuid":"33000000-cccc","message":{"role":"user","content":"again"}}`,
+	}
+	path := filepath.Join(t.TempDir(), "abcd1234-0000-0000-0000-000000000000.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	second := int64(len(lines[0]) + 1)
+	third := second + int64(len(lines[1])+1)
+
+	a, err := LocateAnchor(path, "2222")
+	if err != nil || a.UUID != "22222222-bbbb" || a.Offset != third {
+		t.Errorf("by uuid = %+v, %v", a, err)
+	}
+	// A number that is also a uuid prefix names the uuid.
+	a, err = LocateAnchor(path, "11111111")
+	if err != nil || a.Offset != second {
+		t.Errorf("numeric uuid prefix = %+v, %v", a, err)
+	}
+	// A record starting at that offset wins over a uuid of digits.
+	a, err = LocateAnchor(path, strconv.FormatInt(second, 10))
+	if err != nil || a.UUID != "11111111-aaaa" || a.Offset != second {
+		t.Errorf("offset shadowed by a uuid = %+v, %v", a, err)
+	}
+	a, err = LocateAnchor(path, "0")
+	if err != nil || a.Offset != 0 || a.UUID != "" {
+		t.Errorf("by offset = %+v, %v", a, err)
+	}
+	if _, err := LocateAnchor(path, "ffff"); err == nil {
+		t.Error("expected an error for an unknown anchor")
+	}
+}
+
+func TestSelectionWindow(t *testing.T) {
+	marks := []MessageMark{
+		{UUID: "a", Offset: 0},
+		{UUID: "b", Offset: 10},
+		{UUID: "c", Offset: 20}, // thinking and reply from one record
+		{UUID: "c", Offset: 20},
+		{UUID: "d", Offset: 40},
+		{UUID: "e", Offset: 50},
+	}
+	tests := []struct {
+		name   string
+		sel    Selection
+		lo, hi int
+	}{
+		{"everything", Selection{}, 0, 6},
+		{"around uuid", Selection{Around: &Anchor{UUID: "c", Offset: 20}, Context: 1}, 1, 5},
+		{"no context", Selection{Around: &Anchor{UUID: "c", Offset: 20}}, 2, 4},
+		{"clamped", Selection{Around: &Anchor{UUID: "a"}, Context: 3}, 0, 4},
+		{"filtered record lands on the next", Selection{Around: &Anchor{UUID: "x", Offset: 30}}, 4, 5},
+	}
+	for _, tt := range tests {
+		lo, hi, err := tt.sel.Window(marks)
+		if err != nil || lo != tt.lo || hi != tt.hi {
+			t.Errorf("%s: Window = [%d, %d) %v, want [%d, %d)", tt.name, lo, hi, err, tt.lo, tt.hi)
+		}
+	}
+	if _, _, err := (Selection{Around: &Anchor{Offset: 99}}).Window(marks); err != ErrAnchorNotShown {
+		t.Errorf("past the end: err = %v", err)
+	}
+}
+
+func TestSelectionRange(t *testing.T) {
+	at := func(min int) time.Time { return time.Date(2026, 1, 31, 14, min, 0, 0, time.UTC) }
+	marks := []MessageMark{
+		{UUID: "a", Offset: 0, Index: 1, Turn: 1, At: at(0)},
+		{UUID: "b", Offset: 10, Index: 2, Turn: 1, At: at(1)},
+		{UUID: "c", Offset: 20, Index: 4, Turn: 1, At: at(2)}, // after a skipped tool result
+		{UUID: "d", Offset: 40, Index: 5, Turn: 2, At: at(10)},
+		{UUID: "d", Offset: 40, Index: 5, Turn: 2, At: at(10)},
+		{UUID: "e", Offset: 50, Index: 6, Turn: 2},
+		{UUID: "f", Offset: 60, Index: 7, Turn: 3, At: at(20)},
+	}
+	tests := []struct {
+		name   string
+		sel    Selection
+		lo, hi int
+	}{
+		{"from", Selection{From: 3}, 2, 7},
+		{"from to", Selection{From: 2, To: 5}, 1, 5},
+		{"since", Selection{Since: at(2)}, 2, 7},
+		{"until", Selection{Until: at(10)}, 0, 3},
+		{"untimed kept inside", Selection{Since: at(5), Until: at(15)}, 3, 6},
+		{"turn", Selection{Turn: 2}, 3, 6},
+		{"last turn", Selection{Turn: -1}, 6, 7},
+		{"turn from the end", Selection{Turn: -2}, 3, 6},
+		{"start and end", Selection{Start: &Anchor{UUID: "b"}, End: &Anchor{UUID: "d"}}, 1, 5},
+		{"end on a filtered record", Selection{End: &Anchor{Offset: 30}}, 0, 3},
+		{"around within range", Selection{Around: &Anchor{UUID: "c"}, Context: 3, To: 4}, 0, 3},
+	}
+	for _, tt := range tests {
+		lo, hi, err := tt.sel.Window(marks)
+		if err != nil || lo != tt.lo || hi != tt.hi {
+			t.Errorf("%s: Window = [%d, %d) %v, want [%d, %d)", tt.name, lo, hi, err, tt.lo, tt.hi)
+		}
+	}
+	if _, _, err := (Selection{From: 8}).Window(marks); err != ErrEmptySelection {
+		t.Errorf("past the end: err = %v", err)
+	}
+}
+
+func TestMarkerTurns(t *testing.T) {
+	lines := []struct{ typ, line string }{
+		{"user", `{"type":"user","message":{"role":"user","content":"first"}}`},
+		{"assistant", `{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"ok"}]}}`},
+		{"user", `{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"out"}]}}`},
+		{"summary", `{"type":"summary","summary":"x"}`},
+		{"user", `{"type":"user","message":{"role":"user","content":"second"}}`},
+	}
+	m := NewMarker(true)
+	var got []MessageMark
+	for _, l := range lines {
+		m.Observe(l.typ, []byte(l.line))
+		got = append(got, m.Mark("", 0, time.Time{}))
+	}
+	wantIndex := []int{1, 2, 3, 3, 4}
+	wantTurn := []int{1, 1, 1, 1, 2}
+	for i := range got {
+		if got[i].Index != wantIndex[i] || got[i].Turn != wantTurn[i] {
+			t.Errorf("line %d: index %d turn %d, want %d, %d", i, got[i].Index, got[i].Turn, wantIndex[i], wantTurn[i])
+		}
+	}
+}
@@ -13,40 +13,62 @@ import (
 	"github.com/andyhtran/cct/internal/paths"
 )
 
-// DiscoverFiles walks ~/.claude/projects/ at two depths:
+// DiscoverFiles walks the projects/ tree of every configured root (see
+// paths.Roots) at two depths:
 //   - flat parent sessions: <projects>/<projectDir>/<sessionID>.jsonl
 //   - nested subagents:     <projects>/<projectDir>/<parentSessionID>/subagents/agent-*.jsonl
 //
 // Claude Code moved subagents from the flat layout to the nested one at some
 // point; both can coexist. Nested scanning only runs when includeAgents=true,
-// since every nested entry is an agent by construction.
+// since every nested entry is an agent by construction. A non-empty root
+// restricts the walk to that named root.
 //
 // Returns live files only — the backup mirror is not included. User-facing
 // lookup paths should call DiscoverFilesWithBackups instead so adopted
 // sessions remain findable after upstream deletion.
-func DiscoverFiles(projectFilter string, includeAgents bool) []string {
-	return discoverAt(paths.ProjectsDir(), projectFilter, includeAgents)
+func DiscoverFiles(root, projectFilter string, includeAgents bool) []string {
+	var files []string
+	for _, r := range selectRoots(root) {
+		files = append(files, discoverAt(r.ProjectsDir(), projectFilter, includeAgents)...)
+	}
+	return files
 }
 
 // DiscoverFilesWithBackups unions the live tree with cct's backup mirror,
-// deduplicating by session ID (live path wins). This is what user-facing
-// lookup commands (info, resume, export, list, search) should use so a
-// session deleted by the upstream cleanup bug stays findable through the
-// backup copy.
-func DiscoverFilesWithBackups(projectFilter string, includeAgents bool) []string {
-	live := discoverAt(paths.ProjectsDir(), projectFilter, includeAgents)
-	seen := make(map[string]bool, len(live))
-	for _, p := range live {
-		seen[ExtractIDFromFilename(p)] = true
-	}
-	for _, bp := range discoverAt(paths.BackupProjectsDir(), projectFilter, includeAgents) {
-		id := ExtractIDFromFilename(bp)
-		if !seen[id] {
-			live = append(live, bp)
-			seen[id] = true
+// deduplicating by session ID within each root (live path wins). This is
+// what user-facing lookup commands (info, resume, export, list, search)
+// should use so a session deleted by the upstream cleanup bug stays
+// findable through the backup copy.
+func DiscoverFilesWithBackups(root, projectFilter string, includeAgents bool) []string {
+	var files []string
+	for _, r := range selectRoots(root) {
+		live := discoverAt(r.ProjectsDir(), projectFilter, includeAgents)
+		seen := make(map[string]bool, len(live))
+		for _, p := range live {
+			seen[ExtractIDFromFilename(p)] = true
+		}
+		for _, bp := range discoverAt(r.BackupProjectsDir(), projectFilter, includeAgents) {
+			id := ExtractIDFromFilename(bp)
+			if !seen[id] {
+				live = append(live, bp)
+				seen[id] = true
+			}
 		}
+		files = append(files, live...)
 	}
-	return live
+	return files
+}
+
+// selectRoots returns the configured roots, narrowed to the one named root
+// when root is non-empty. An unknown name yields no roots.
+func selectRoots(root string) []paths.Root {
+	if root == "" {
+		return paths.Roots()
+	}
+	if r, ok := paths.LookupRoot(root); ok {
+		return []paths.Root{r}
+	}
+	return nil
 }
 
 func discoverAt(dir, projectFilter string, includeAgents bool) []string {
@@ -124,8 +146,8 @@ func discoverNestedSubagents(sessionDir string) []string {
 // (list, info, export, resume) need adopted sessions to stay findable, so
 // the backup mirror is included by default. Internal filesystem operations
 // that must see only live files should call DiscoverFiles directly.
-func ScanAll(projectFilter string, fullParse bool, includeAgents bool) []*Session {
-	files := DiscoverFilesWithBackups(projectFilter, includeAgents)
+func ScanAll(root, projectFilter string, fullParse bool, includeAgents bool) []*Session {
+	files := DiscoverFilesWithBackups(root, projectFilter, includeAgents)
 	return ScanFiles(files, fullParse)
 }
 
@@ -198,6 +220,8 @@ func searchOneFile(path, keyLower string, snippetWidth int, maxMatches int) *Sea
 	}
 	s.ShortID = ShortID(s.ID)
 	s.IsAgent = IsAgentSession(s.ID)
+	s.Root = paths.RootOf(path)
+	LoadParentID(s, path)
 
 	terms := strings.Fields(keyLower)
 	isPhrase := len(terms) <= 1
@@ -210,6 +234,7 @@ func searchOneFile(path, keyLower string, snippetWidth int, maxMatches int) *Sea
 		termSeen = make([]bool, len(terms))
 	}
 
+	tools := ToolNames{}
 	for scanner.Scan() {
 		line := scanner.Bytes()
 		lineType := FastExtractType(line)
@@ -226,13 +251,12 @@ func searchOneFile(path, keyLower string, snippetWidth int, maxMatches int) *Sea
 		if lineType == "user" {
 			ExtractUserMetadata(s, obj)
 		}
-
-		if maxMatches > 0 && len(matches) >= maxMatches {
-			continue
-		}
+		uuid, _ := obj["uuid"].(string)
+		offset := scanner.Offset()
 
 		blocks := ExtractPromptBlocks(obj)
-		if len(blocks) == 0 {
+		tools.Tag(blocks)
+		if len(blocks) == 0 || (maxMatches > 0 && len(matches) >= maxMatches) {
 			continue
 		}
 
@@ -244,7 +268,8 @@ func searchOneFile(path, keyLower string, snippetWidth int, maxMatches int) *Sea
 			text := block.Text
 			textLower := strings.ToLower(text)
 
-			roleWidth := len(lineType) + 3 // "[x] " prefix
+			role := block.Role(lineType)
+			roleWidth := len(role) + 3 // "[x] " prefix
 			if block.Source != "" {
 				roleWidth += len(block.Source) + 1 // ":Tool" suffix
 			}
@@ -253,7 +278,7 @@ func searchOneFile(path, keyLower string, snippetWidth int, maxMatches int) *Sea
 			if isPhrase {
 				if strings.Contains(textLower, keyLower) {
 					snippet := output.ExtractSnippet(text, keyLower, sw)
-					matches = append(matches, Match{Role: lineType, Source: block.Source, Snippet: snippet})
+					matches = append(matches, Match{Role: role, Source: block.Source, Snippet: snippet, Ref: MessageRef(s.ShortID, uuid, offset), UUID: uuid, Offset: offset})
 				}
 				continue
 			}
@@ -270,7 +295,7 @@ func searchOneFile(path, keyLower string, snippetWidth int, maxMatches int) *Sea
 			}
 			if bestTerm != "" {
 				snippet := output.ExtractSnippet(text, bestTerm, sw)
-				matches = append(matches, Match{Role: lineType, Source: block.Source, Snippet: snippet})
+				matches = append(matches, Match{Role: role, Source: block.Source, Snippet: snippet, Ref: MessageRef(s.ShortID, uuid, offset), UUID: uuid, Offset: offset})
 			}
 		}
 	}
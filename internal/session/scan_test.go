@@ -51,28 +51,28 @@ func TestDiscoverFiles(t *testing.T) {
 	})
 
 	t.Run("no filter", func(t *testing.T) {
-		files := DiscoverFiles("", true)
+		files := DiscoverFiles("", "", true)
 		if len(files) != 3 {
 			t.Errorf("expected 3 files, got %d", len(files))
 		}
 	})
 
 	t.Run("project filter", func(t *testing.T) {
-		files := DiscoverFiles("myproject", true)
+		files := DiscoverFiles("", "myproject", true)
 		if len(files) != 2 {
 			t.Errorf("expected 2 files for myproject filter, got %d", len(files))
 		}
 	})
 
 	t.Run("filter no match", func(t *testing.T) {
-		files := DiscoverFiles("nonexistent", true)
+		files := DiscoverFiles("", "nonexistent", true)
 		if len(files) != 0 {
 			t.Errorf("expected 0 files for nonexistent filter, got %d", len(files))
 		}
 	})
 
 	t.Run("case insensitive", func(t *testing.T) {
-		files := DiscoverFiles("MyProject", true)
+		files := DiscoverFiles("", "MyProject", true)
 		if len(files) != 2 {
 			t.Errorf("expected 2 files for case-insensitive filter, got %d", len(files))
 		}
@@ -90,14 +90,14 @@ func TestDiscoverFiles_AgentFiltering(t *testing.T) {
 	})
 
 	t.Run("excludes agents", func(t *testing.T) {
-		files := DiscoverFiles("", false)
+		files := DiscoverFiles("", "", false)
 		if len(files) != 1 {
 			t.Errorf("expected 1 file (no agents), got %d", len(files))
 		}
 	})
 
 	t.Run("includes agents", func(t *testing.T) {
-		files := DiscoverFiles("", true)
+		files := DiscoverFiles("", "", true)
 		if len(files) != 2 {
 			t.Errorf("expected 2 files (with agents), got %d", len(files))
 		}
@@ -143,7 +143,7 @@ func TestDiscoverFiles_NestedSubagents(t *testing.T) {
 	}
 
 	t.Run("includeAgents=true picks up nested agent", func(t *testing.T) {
-		files := DiscoverFiles("", true)
+		files := DiscoverFiles("", "", true)
 		if len(files) != 2 {
 			t.Fatalf("expected 2 files (parent + nested agent), got %d: %v", len(files), files)
 		}
@@ -162,7 +162,7 @@ func TestDiscoverFiles_NestedSubagents(t *testing.T) {
 	})
 
 	t.Run("includeAgents=false excludes nested agent", func(t *testing.T) {
-		files := DiscoverFiles("", false)
+		files := DiscoverFiles("", "", false)
 		if len(files) != 1 {
 			t.Fatalf("expected 1 file (parent only), got %d: %v", len(files), files)
 		}
@@ -225,7 +225,7 @@ func TestDiscoverFilesWithBackups(t *testing.T) {
 		`{"type":"user","message":{"role":"user","content":"backup stale"}}`,
 	})
 
-	files := DiscoverFilesWithBackups("", true)
+	files := DiscoverFilesWithBackups("", "", true)
 	if len(files) != 3 {
 		t.Fatalf("expected 3 unique sessions, got %d: %v", len(files), files)
 	}
@@ -257,7 +257,7 @@ func TestDiscoverFiles_MissingDir(t *testing.T) {
 	home := setupTestHome(t)
 	_ = home
 
-	files := DiscoverFiles("", true)
+	files := DiscoverFiles("", "", true)
 	if files != nil {
 		t.Errorf("expected nil for missing dir, got %v", files)
 	}
@@ -272,7 +272,7 @@ func TestScanAll(t *testing.T) {
 	})
 
 	t.Run("quick scan", func(t *testing.T) {
-		sessions := ScanAll("", false, true)
+		sessions := ScanAll("", "", false, true)
 		if len(sessions) != 1 {
 			t.Fatalf("expected 1 session, got %d", len(sessions))
 		}
@@ -289,7 +289,7 @@ func TestScanAll(t *testing.T) {
 	})
 
 	t.Run("full parse", func(t *testing.T) {
-		sessions := ScanAll("", true, true)
+		sessions := ScanAll("", "", true, true)
 		if len(sessions) != 1 {
 			t.Fatalf("expected 1 session, got %d", len(sessions))
 		}
@@ -300,11 +300,11 @@ func TestScanAll(t *testing.T) {
 	})
 
 	t.Run("with project filter", func(t *testing.T) {
-		sessions := ScanAll("proj", false, true)
+		sessions := ScanAll("", "proj", false, true)
 		if len(sessions) != 1 {
 			t.Fatalf("expected 1 session, got %d", len(sessions))
 		}
-		sessions = ScanAll("nonexistent", false, true)
+		sessions = ScanAll("", "nonexistent", false, true)
 		if len(sessions) != 0 {
 			t.Errorf("expected 0 sessions for nonexistent filter, got %d", len(sessions))
 		}
@@ -323,7 +323,7 @@ func TestSearchFiles(t *testing.T) {
 		`{"type":"user","message":{"role":"user","content":"add authentication"},"cwd":"/test","sessionId":"srch-002","timestamp":"2026-01-11T08:00:00Z"}`,
 	})
 
-	files := DiscoverFiles("", true)
+	files := DiscoverFiles("", "", true)
 
 	t.Run("keyword found", func(t *testing.T) {
 		results := SearchFiles(files, "database", 80, 3)
@@ -359,7 +359,7 @@ func TestSearchFiles_ToolResult(t *testing.T) {
 		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"tu1","name":"Read","input":{}},{"type":"tool_result","tool_use_id":"tu1","content":"database_url=postgres://localhost"}]},"timestamp":"2026-01-10T08:00:05Z"}`,
 	})
 
-	files := DiscoverFiles("", true)
+	files := DiscoverFiles("", "", true)
 
 	t.Run("finds text in tool_result content", func(t *testing.T) {
 		results := SearchFiles(files, "postgres", 80, 3)
@@ -389,7 +389,7 @@ func TestSearchFiles_ToolUse(t *testing.T) {
 		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"tu1","name":"Read","input":{"file_path":"/path/to/config.go"}},{"type":"tool_result","tool_use_id":"tu1","content":"package config"}]},"timestamp":"2026-01-10T08:00:05Z"}`,
 	})
 
-	files := DiscoverFiles("", true)
+	files := DiscoverFiles("", "", true)
 
 	t.Run("finds file path in tool_use input", func(t *testing.T) {
 		results := SearchFiles(files, "config.go", 80, 3)
@@ -422,7 +422,7 @@ func TestSearchFiles_ToolUse(t *testing.T) {
 			`{"type":"user","message":{"role":"user","content":"compile the project"},"cwd":"/test","sessionId":"tool-use-002","timestamp":"2026-01-10T08:00:00Z"}`,
 			`{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"tu2","name":"Bash","input":{"command":"go build ./..."}}]},"timestamp":"2026-01-10T08:00:05Z"}`,
 		})
-		files2 := DiscoverFiles("", true)
+		files2 := DiscoverFiles("", "", true)
 		results := SearchFiles(files2, "go build", 80, 3)
 		if len(results) != 1 {
 			t.Fatalf("expected 1 result, got %d", len(results))
@@ -456,7 +456,7 @@ func TestSearchFiles_MaxMatches(t *testing.T) {
 	}
 	writeSessionFile(t, projDir, "srch-max", lines)
 
-	files := DiscoverFiles("", true)
+	files := DiscoverFiles("", "", true)
 	results := SearchFiles(files, "keyword", 80, 3)
 	if len(results) != 1 {
 		t.Fatalf("expected 1 result, got %d", len(results))
@@ -0,0 +1,213 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// MessageMark is where a transcript message came from. A record can yield
+// several messages (thinking and the reply), which then share a mark.
+type MessageMark struct {
+	UUID   string
+	Offset int64
+	// Index numbers the file's user and assistant records from 1, the
+	// records message_count counts.
+	Index int
+	// Turn is how many prompts had been typed by this record, counting it:
+	// a typed prompt starts a new turn. 0 before the first.
+	Turn int
+	At   time.Time
+}
+
+// Marker numbers a session file's records as they are read. Observe every
+// line before filtering any out, then Mark each message kept.
+type Marker struct {
+	turns bool
+	index int
+	turn  int
+}
+
+// NewMarker returns a Marker. Finding typed prompts means parsing every
+// user record, so turns are only counted when asked for.
+func NewMarker(turns bool) *Marker {
+	return &Marker{turns: turns}
+}
+
+// Observe counts a line of type lineType read from the file.
+func (m *Marker) Observe(lineType string, line []byte) {
+	if lineType != "user" && lineType != "assistant" {
+		return
+	}
+	m.index++
+	if m.turns && lineType == "user" {
+		var obj map[string]any
+		if json.Unmarshal(line, &obj) == nil && HumanPrompt(obj) != "" {
+			m.turn++
+		}
+	}
+}
+
+// Mark returns the mark for a message from the line observed last.
+func (m *Marker) Mark(uuid string, offset int64, at time.Time) MessageMark {
+	return MessageMark{UUID: uuid, Offset: offset, Index: m.index, Turn: m.turn, At: at}
+}
+
+// Selection picks a slice of a transcript's messages. Every bound set
+// narrows it; the zero value keeps them all.
+type Selection struct {
+	// Around keeps the messages from the record Around points at, with
+	// Context messages on either side.
+	Around  *Anchor
+	Context int
+	// From and To bound the record Index, inclusive.
+	From, To int
+	// Since and Until bound the time, half-open. A message without a
+	// timestamp goes by the one before it.
+	Since, Until time.Time
+	// Turn keeps one turn: the nth typed prompt through the messages before
+	// the next. Negative counts from the end, -1 being the last.
+	Turn int
+	// Start and End bound the records, inclusive.
+	Start, End *Anchor
+}
+
+var (
+	// ErrAnchorNotShown is returned when the record a ref points at, and
+	// every record after it, was filtered out of the transcript.
+	ErrAnchorNotShown = errors.New("the referenced message isn't in the selected messages")
+	// ErrEmptySelection is returned when the bounds leave no messages.
+	ErrEmptySelection = errors.New("no messages in the selected range")
+)
+
+// IsZero reports whether the selection keeps everything.
+func (s Selection) IsZero() bool {
+	return s == Selection{}
+}
+
+// Window returns the half-open range [lo, hi) of marks the selection keeps.
+func (s Selection) Window(marks []MessageMark) (lo, hi int, err error) {
+	lo, hi = 0, len(marks)
+	if s.IsZero() {
+		return lo, hi, nil
+	}
+	if s.Around != nil {
+		first, last := anchorSpan(marks, s.Around)
+		if first < 0 {
+			return 0, 0, ErrAnchorNotShown
+		}
+		ctx := max(s.Context, 0)
+		lo, hi = max(first-ctx, 0), min(last+1+ctx, len(marks))
+	}
+
+	turn := s.Turn
+	if turn < 0 {
+		last := 0
+		for _, m := range marks {
+			last = max(last, m.Turn)
+		}
+		turn = max(last+1+turn, 1)
+	}
+	at := messageTimes(marks)
+	after := func(i int) bool { // not before the lower bounds
+		return marks[i].Index >= s.From &&
+			(s.Since.IsZero() || at[i].IsZero() || !at[i].Before(s.Since)) &&
+			(turn == 0 || marks[i].Turn >= turn)
+	}
+	before := func(i int) bool { // not past the upper bounds
+		return (s.To == 0 || marks[i].Index <= s.To) &&
+			(s.Until.IsZero() || at[i].IsZero() || at[i].Before(s.Until)) &&
+			(turn == 0 || marks[i].Turn <= turn)
+	}
+	for lo < hi && !after(lo) {
+		lo++
+	}
+	for hi > lo && !before(hi-1) {
+		hi--
+	}
+	if s.Start != nil {
+		first, _ := anchorSpan(marks, s.Start)
+		if first < 0 {
+			return 0, 0, ErrAnchorNotShown
+		}
+		lo = max(lo, first)
+	}
+	if s.End != nil {
+		hi = min(hi, anchorEnd(marks, s.End))
+	}
+	if lo >= hi {
+		return 0, 0, ErrEmptySelection
+	}
+	return lo, hi, nil
+}
+
+// messageTimes returns each mark's time, an untimed one taking the time of
+// the message before it, or of the first timed message at the start.
+func messageTimes(marks []MessageMark) []time.Time {
+	at := make([]time.Time, len(marks))
+	var last time.Time
+	for i, m := range marks {
+		if !m.At.IsZero() {
+			last = m.At
+		}
+		at[i] = last
+	}
+	for i := range at {
+		if !at[i].IsZero() {
+			for j := range i {
+				at[j] = at[i]
+			}
+			break
+		}
+	}
+	return at
+}
+
+// anchorSpan returns the first and last index of the messages from a's
+// record. When that record was filtered out, it falls back to the next
+// record shown, so a hit in a skipped tool result lands on what followed.
+func anchorSpan(marks []MessageMark, a *Anchor) (first, last int) {
+	first = -1
+	if a.UUID != "" {
+		for i, m := range marks {
+			if m.UUID == a.UUID {
+				first = i
+				break
+			}
+		}
+	}
+	if first < 0 {
+		for i, m := range marks {
+			if m.Offset >= a.Offset {
+				first = i
+				break
+			}
+		}
+	}
+	if first < 0 {
+		return -1, -1
+	}
+	last = first
+	for last+1 < len(marks) && marks[last+1] == marks[first] {
+		last++
+	}
+	return first, last
+}
+
+// anchorEnd returns one past the last message from a's record or, when it
+// was filtered out, from the records before it.
+func anchorEnd(marks []MessageMark, a *Anchor) int {
+	if a.UUID != "" {
+		for i := len(marks) - 1; i >= 0; i-- {
+			if marks[i].UUID == a.UUID {
+				return i + 1
+			}
+		}
+	}
+	for i := len(marks) - 1; i >= 0; i-- {
+		if marks[i].Offset <= a.Offset {
+			return i + 1
+		}
+	}
+	return 0
+}
@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/andyhtran/cct/internal/paths"
 )
 
 var (
@@ -18,6 +20,7 @@ var (
 type Session struct {
 	ID           string    `json:"id"`
 	ShortID      string    `json:"short_id"`
+	Root         string    `json:"root,omitempty"`
 	IsAgent      bool      `json:"is_agent"`
 	ProjectPath  string    `json:"project_path"`
 	ProjectName  string    `json:"project_name"`
@@ -28,6 +31,7 @@ type Session struct {
 	Created      time.Time `json:"created"`
 	Modified     time.Time `json:"modified"`
 	MessageCount int       `json:"message_count"`
+	FileSize     int64     `json:"file_size,omitempty"`
 
 	// Token usage (populated only by ParseFullSession, not ExtractMetadata).
 	// Model is the last non-synthetic assistant model seen. ContextTokens is
@@ -40,10 +44,56 @@ type Session struct {
 	PeakContextTokens int    `json:"peak_context_tokens,omitempty"`
 	TotalOutputTokens int    `json:"total_output_tokens,omitempty"`
 
+	// Timeline (populated by ParseFullSession and the index). FirstMessageAt
+	// and LastMessageAt bracket the user/assistant messages; Version is the
+	// Claude Code release that wrote the most recent message.
+	FirstMessageAt time.Time `json:"first_message_at,omitzero"`
+	LastMessageAt  time.Time `json:"last_message_at,omitzero"`
+	Version        string    `json:"version,omitempty"`
+
+	// Active time (populated by cct info). ActiveSeconds sums the work
+	// segments — runs of messages no more than an idle threshold apart —
+	// so a session left open overnight isn't billed as one long stretch.
+	ActiveSeconds int64 `json:"active_seconds,omitempty"`
+	WorkSegments  int   `json:"work_segments,omitempty"`
+
+	// Compactions (populated by cct info): each time the context was
+	// replaced by a generated summary, oldest first.
+	Compactions []*Compaction `json:"compactions,omitempty"`
+
 	// Subagent sidecar fields — populated from <projectDir>/<parentID>/subagents/agent-<id>.meta.json
 	// when present. Empty for flat legacy agents and for non-agent sessions.
 	AgentType        string `json:"agent_type,omitempty"`
 	AgentDescription string `json:"agent_description,omitempty"`
+
+	// ParentID is the session that spawned a subagent: the <parentID>
+	// directory of the nested layout, or the sessionId flat legacy agents
+	// record. Empty for non-agent sessions.
+	ParentID string `json:"parent_id,omitempty"`
+
+	// Resume chain (populated by cct info and list --collapse-chains): the
+	// other session files `claude --resume` and `--continue` split this
+	// conversation across, oldest first.
+	Predecessors []string `json:"predecessors,omitempty"`
+	Successors   []string `json:"successors,omitempty"`
+}
+
+// Duration is the wall-clock span from the first to the last message, or 0
+// when either timestamp is unknown.
+func (s *Session) Duration() time.Duration {
+	if s.FirstMessageAt.IsZero() || s.LastMessageAt.IsZero() {
+		return 0
+	}
+	return s.LastMessageAt.Sub(s.FirstMessageAt)
+}
+
+// End is when the last message was written, or the file's modification
+// time when no message had a timestamp.
+func (s *Session) End() time.Time {
+	if !s.LastMessageAt.IsZero() {
+		return s.LastMessageAt
+	}
+	return s.Modified
 }
 
 // ContextWindow returns the effective max context window for a model. Defaults
@@ -52,10 +102,16 @@ func ContextWindow(model string) int {
 	return 200_000
 }
 
+// Match is one hit in a session. Ref names the message it is in (see
+// MessageRef); it is empty for hits outside the transcript, such as an
+// agent's description.
 type Match struct {
 	Role    string `json:"role"`
 	Source  string `json:"source,omitempty"`
 	Snippet string `json:"snippet"`
+	Ref     string `json:"ref,omitempty"`
+	UUID    string `json:"uuid,omitempty"`
+	Offset  int64  `json:"offset"`
 }
 
 type SearchResult struct {
@@ -82,14 +138,36 @@ func ShortID(id string) string {
 	return id
 }
 
-func FindByPrefix(prefix string) (*Session, error) {
-	sessions := ScanAll("", false, true)
+// FindByPrefix resolves a session by full ID, short ID, custom title, or
+// UUID prefix by scanning every session file. A non-empty root restricts
+// the lookup to that named root; otherwise a prefix that exists in several
+// roots is reported as ambiguous.
+func FindByPrefix(root, prefix string) (*Session, error) {
+	return Resolve(ScanAll(root, "", false, true), prefix)
+}
 
-	// Exact match on full ID or 8-char short ID wins outright.
+// Resolve picks the session prefix refers to from a candidate set, applying
+// the same precedence as FindByPrefix: exact ID or short ID, then exact
+// custom title, then UUID prefix. Candidates may be a superset — the index
+// passes only rows whose ID or title could match.
+func Resolve(sessions []*Session, prefix string) (*Session, error) {
+	// Exact match on full ID or 8-char short ID wins outright — unless the
+	// same ID lives in more than one root, which only --root can settle.
+	var exact []*Session
 	for _, s := range sessions {
 		if s.ID == prefix || s.ShortID == prefix {
-			return s, nil
+			exact = append(exact, s)
+		}
+	}
+	if len(exact) == 1 {
+		return exact[0], nil
+	}
+	if len(exact) > 1 {
+		var ids []string
+		for _, s := range exact {
+			ids = append(ids, fmt.Sprintf("  %s  %s", s.ShortID, rootLabel(s)))
 		}
+		return nil, fmt.Errorf("session %q exists in several roots (use --root):\n%s: %w", prefix, strings.Join(ids, "\n"), ErrMultipleMatches)
 	}
 
 	// Next: exact custom-title match (case-insensitive). Titles set via
@@ -111,7 +189,7 @@ func FindByPrefix(prefix string) (*Session, error) {
 	if len(titleMatches) > 1 {
 		var ids []string
 		for _, s := range titleMatches {
-			ids = append(ids, fmt.Sprintf("  %s  %s  (%s)", s.ShortID, s.ProjectName, s.CustomTitle))
+			ids = append(ids, fmt.Sprintf("  %s  %s  (%s)", s.ShortID, rootLabel(s), s.CustomTitle))
 		}
 		return nil, fmt.Errorf("multiple sessions share title %q:\n%s: %w", prefix, strings.Join(ids, "\n"), ErrMultipleMatches)
 	}
@@ -132,19 +210,35 @@ func FindByPrefix(prefix string) (*Session, error) {
 	default:
 		var ids []string
 		for _, s := range matches {
-			ids = append(ids, fmt.Sprintf("  %s  %s", s.ShortID, s.ProjectName))
+			ids = append(ids, fmt.Sprintf("  %s  %s", s.ShortID, rootLabel(s)))
 		}
 		return nil, fmt.Errorf("multiple sessions match %q:\n%s: %w", prefix, strings.Join(ids, "\n"), ErrMultipleMatches)
 	}
 }
 
-func FindByPrefixFull(prefix string) (*Session, error) {
-	s, err := FindByPrefix(prefix)
+// rootLabel names a session's project for ambiguity listings, qualified
+// with its root when several roots are configured so identical IDs in two
+// roots can be told apart.
+func rootLabel(s *Session) string {
+	if s.Root == "" || len(paths.Roots()) < 2 {
+		return s.ProjectName
+	}
+	return s.ProjectName + " [" + s.Root + "]"
+}
+
+func FindByPrefixFull(root, prefix string) (*Session, error) {
+	s, err := FindByPrefix(root, prefix)
 	if err != nil {
 		return nil, err
 	}
+	return LoadFull(s), nil
+}
+
+// LoadFull re-parses a resolved session in full mode (message count, token
+// usage). It returns s unchanged when the file can no longer be read.
+func LoadFull(s *Session) *Session {
 	if full := ParseFullSession(s.FilePath); full != nil {
-		return full, nil
+		return full
 	}
-	return s, nil
+	return s
 }
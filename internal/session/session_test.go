@@ -63,7 +63,7 @@ func setupSessionFixtures(t *testing.T) {
 func TestFindByPrefix_ExactMatch(t *testing.T) {
 	setupSessionFixtures(t)
 
-	s, err := FindByPrefix("bbbb1111-2222-3333-4444-555555555555")
+	s, err := FindByPrefix("", "bbbb1111-2222-3333-4444-555555555555")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -75,7 +75,7 @@ func TestFindByPrefix_ExactMatch(t *testing.T) {
 func TestFindByPrefix_ShortID(t *testing.T) {
 	setupSessionFixtures(t)
 
-	s, err := FindByPrefix("bbbb1111")
+	s, err := FindByPrefix("", "bbbb1111")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -87,7 +87,7 @@ func TestFindByPrefix_ShortID(t *testing.T) {
 func TestFindByPrefix_NoMatch(t *testing.T) {
 	setupSessionFixtures(t)
 
-	_, err := FindByPrefix("zzzz9999")
+	_, err := FindByPrefix("", "zzzz9999")
 	if err == nil {
 		t.Fatal("expected error for no match")
 	}
@@ -100,7 +100,7 @@ func TestFindByPrefix_MultipleMatches(t *testing.T) {
 	setupSessionFixtures(t)
 
 	// "aaaa" matches both aaaa1111 and aaaa2222.
-	_, err := FindByPrefix("aaaa")
+	_, err := FindByPrefix("", "aaaa")
 	if err == nil {
 		t.Fatal("expected error for multiple matches")
 	}
@@ -151,7 +151,7 @@ func TestFindByPrefix_CustomTitle(t *testing.T) {
 	}
 
 	t.Run("exact title match", func(t *testing.T) {
-		s, err := FindByPrefix("fix-keyboard-dictation-return")
+		s, err := FindByPrefix("", "fix-keyboard-dictation-return")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -161,7 +161,7 @@ func TestFindByPrefix_CustomTitle(t *testing.T) {
 	})
 
 	t.Run("title match is case-insensitive", func(t *testing.T) {
-		s, err := FindByPrefix("FIX-Keyboard-Dictation-RETURN")
+		s, err := FindByPrefix("", "FIX-Keyboard-Dictation-RETURN")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -171,7 +171,7 @@ func TestFindByPrefix_CustomTitle(t *testing.T) {
 	})
 
 	t.Run("unknown title still errors", func(t *testing.T) {
-		_, err := FindByPrefix("not-a-real-title")
+		_, err := FindByPrefix("", "not-a-real-title")
 		if !errors.Is(err, ErrNotFound) {
 			t.Errorf("err = %v, want ErrNotFound", err)
 		}
@@ -181,7 +181,7 @@ func TestFindByPrefix_CustomTitle(t *testing.T) {
 func TestFindByPrefixFull(t *testing.T) {
 	setupSessionFixtures(t)
 
-	s, err := FindByPrefixFull("bbbb1111")
+	s, err := FindByPrefixFull("", "bbbb1111")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -251,7 +251,7 @@ func TestFindByPrefix_Agent(t *testing.T) {
 		}
 	}
 
-	s, err := FindByPrefix("agent-abcdef12")
+	s, err := FindByPrefix("", "agent-abcdef12")
 	if err != nil {
 		t.Fatalf("FindByPrefix agent: %v", err)
 	}
@@ -259,7 +259,7 @@ func TestFindByPrefix_Agent(t *testing.T) {
 		t.Error("expected IsAgent=true for agent session")
 	}
 
-	s, err = FindByPrefix("sess-1111")
+	s, err = FindByPrefix("", "sess-1111")
 	if err != nil {
 		t.Fatalf("FindByPrefix regular: %v", err)
 	}
@@ -284,3 +284,39 @@ func TestExtractIDFromFilename(t *testing.T) {
 		}
 	}
 }
+
+func TestFindByPrefix_MultipleRoots(t *testing.T) {
+	setupSessionFixtures(t)
+	home := os.Getenv("HOME")
+	workDir := filepath.Join(home, ".claude-work")
+	t.Setenv("CCT_ROOTS", "work="+workDir)
+
+	projDir := filepath.Join(workDir, "projects", "-Users-test-proj")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	line := `{"type":"user","message":{"role":"user","content":"work copy"},"cwd":"/test/proj","timestamp":"2026-01-13T08:00:00Z"}`
+	if err := os.WriteFile(filepath.Join(projDir, "bbbb1111-2222-3333-4444-555555555555.jsonl"), []byte(line+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := FindByPrefix("", "bbbb1111"); !errors.Is(err, ErrMultipleMatches) {
+		t.Errorf("unfiltered err = %v, want ErrMultipleMatches", err)
+	}
+
+	s, err := FindByPrefixFull("work", "bbbb1111")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Root != "work" || s.FirstPrompt != "work copy" {
+		t.Errorf("got root %q prompt %q, want work / work copy", s.Root, s.FirstPrompt)
+	}
+
+	s, err = FindByPrefix("default", "bbbb1111")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Root != "default" {
+		t.Errorf("Root = %q, want default", s.Root)
+	}
+}
@@ -0,0 +1,119 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+)
+
+var snapshotMarker = []byte(`"file-history-snapshot"`)
+
+// Checkpoint is the file state Claude Code saved before a user message, the
+// point the interactive rewind returns to. The JSONL records it as a
+// file-history-snapshot record keyed by the message's uuid; records flagged
+// isSnapshotUpdate add files first edited later in the same turn.
+type Checkpoint struct {
+	MessageID string          `json:"message_id"`
+	At        time.Time       `json:"at"`
+	Message   int             `json:"message,omitempty"` // number of the user message, 0 when not in this file
+	Prompt    string          `json:"prompt,omitempty"`
+	Files     []*SnapshotFile `json:"files"`
+}
+
+// SnapshotFile is one tracked file in a checkpoint. BackupFile names the
+// copy under <root>/file-history/<session-id>/; it is empty when the file
+// did not exist yet at that checkpoint.
+type SnapshotFile struct {
+	Path       string    `json:"path"`
+	BackupFile string    `json:"backup_file,omitempty"`
+	Version    int       `json:"version,omitempty"`
+	BackupTime time.Time `json:"backup_time,omitzero"`
+}
+
+// ReadCheckpoints returns the checkpoints recorded in a session file, in
+// file order, each with its files sorted by path.
+func ReadCheckpoints(path string) ([]*Checkpoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var out []*Checkpoint
+	byID := map[string]*Checkpoint{}
+	files := map[*Checkpoint]map[string]*SnapshotFile{}
+	type userMsg struct {
+		n      int
+		prompt string
+	}
+	users := map[string]userMsg{}
+	message := 0
+	scanner := NewOffsetScanner(f)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		switch FastExtractType(line) {
+		case "user", "assistant":
+			message++
+			var obj map[string]any
+			if json.Unmarshal(line, &obj) != nil || obj["type"] != "user" {
+				continue
+			}
+			if uuid, _ := obj["uuid"].(string); uuid != "" {
+				users[uuid] = userMsg{n: message, prompt: HumanPrompt(obj)}
+			}
+			continue
+		}
+		if !bytes.Contains(line, snapshotMarker) {
+			continue
+		}
+		var obj map[string]any
+		if json.Unmarshal(line, &obj) != nil || obj["type"] != "file-history-snapshot" {
+			continue
+		}
+		snap, _ := obj["snapshot"].(map[string]any)
+		id, _ := snap["messageId"].(string)
+		if id == "" {
+			id, _ = obj["messageId"].(string)
+		}
+		if id == "" {
+			continue
+		}
+		cp, ok := byID[id]
+		if !ok {
+			cp = &Checkpoint{MessageID: id, At: ParseTimestamp(snap)}
+			byID[id] = cp
+			files[cp] = map[string]*SnapshotFile{}
+			out = append(out, cp)
+		}
+		backups, _ := snap["trackedFileBackups"].(map[string]any)
+		for p, v := range backups {
+			b, _ := v.(map[string]any)
+			sf := &SnapshotFile{Path: p}
+			sf.BackupFile, _ = b["backupFileName"].(string)
+			if n, ok := b["version"].(float64); ok {
+				sf.Version = int(n)
+			}
+			if s, ok := b["backupTime"].(string); ok {
+				sf.BackupTime, _ = time.Parse(time.RFC3339Nano, s)
+			}
+			files[cp][p] = sf
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, cp := range out {
+		if u, ok := users[cp.MessageID]; ok {
+			cp.Message, cp.Prompt = u.n, u.prompt
+		}
+		cp.Files = make([]*SnapshotFile, 0, len(files[cp]))
+		for _, sf := range files[cp] {
+			cp.Files = append(cp.Files, sf)
+		}
+		sort.Slice(cp.Files, func(i, j int) bool { return cp.Files[i].Path < cp.Files[j].Path })
+	}
+	return out, nil
+}
@@ -0,0 +1,39 @@
+//go:build darwin || linux
+
+package session
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReadCheckpoints(t *testing.T) {
+	dir := t.TempDir()
+	writeSessionFile(t, dir, "snap1", []string{
+		`{"type":"file-history-snapshot","messageId":"m1","snapshot":{"messageId":"m1","trackedFileBackups":{},"timestamp":"2026-03-01T10:00:00Z"},"isSnapshotUpdate":false}`,
+		`{"type":"user","uuid":"m1","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"add a parser"}}`,
+		`{"type":"file-history-snapshot","messageId":"m1","snapshot":{"messageId":"m1","trackedFileBackups":{"/p/parse.go":{"backupFileName":null,"version":1,"backupTime":"2026-03-01T10:00:03Z"}},"timestamp":"2026-03-01T10:00:00Z"},"isSnapshotUpdate":true}`,
+		`{"type":"assistant","uuid":"a1","timestamp":"2026-03-01T10:00:05Z","message":{"role":"assistant","content":"Added."}}`,
+		`{"type":"file-history-snapshot","messageId":"m2","snapshot":{"messageId":"m2","trackedFileBackups":{"/p/parse.go":{"backupFileName":"abc@v2","version":2,"backupTime":"2026-03-01T10:01:00Z"},"/p/main.go":{"backupFileName":"def@v1","version":1,"backupTime":"2026-03-01T10:01:00Z"}},"timestamp":"2026-03-01T10:01:00Z"},"isSnapshotUpdate":false}`,
+		`{"type":"user","uuid":"m2","timestamp":"2026-03-01T10:01:00Z","message":{"role":"user","content":"now wire it in"}}`,
+	})
+
+	got, err := ReadCheckpoints(filepath.Join(dir, "snap1.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d checkpoints, want 2 (updates merge into their message)", len(got))
+	}
+	first := got[0]
+	if first.MessageID != "m1" || first.Message != 1 || first.Prompt != "add a parser" {
+		t.Errorf("first = %+v", first)
+	}
+	if len(first.Files) != 1 || first.Files[0].BackupFile != "" {
+		t.Errorf("first files = %+v, want parse.go not yet created", first.Files)
+	}
+	second := got[1]
+	if second.Message != 3 || len(second.Files) != 2 || second.Files[0].Path != "/p/main.go" || second.Files[1].BackupFile != "abc@v2" {
+		t.Errorf("second = %+v files %+v", second, second.Files)
+	}
+}
@@ -0,0 +1,188 @@
+package session
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Snippet is a fenced code block from an assistant reply. Seq numbers the
+// session's snippets from 1 in file order; Symbols are the names the code
+// defines (functions, types, classes), for finding a definition by name.
+type Snippet struct {
+	Seq     int       `json:"seq"`
+	Lang    string    `json:"lang"`
+	Code    string    `json:"code"`
+	Lines   int       `json:"lines"`
+	Symbols []string  `json:"symbols,omitempty"`
+	At      time.Time `json:"at,omitzero"`
+	Offset  int64     `json:"offset"`
+}
+
+// SnippetTracker collects the code blocks in a session's assistant
+// replies. Feed it every user and assistant record in file order.
+type SnippetTracker struct {
+	snippets []*Snippet
+}
+
+func NewSnippetTracker() *SnippetTracker {
+	return &SnippetTracker{}
+}
+
+// Observe records the code blocks in a parsed record read from byte
+// offset in the file.
+func (t *SnippetTracker) Observe(obj map[string]any, offset int64) {
+	if obj["type"] != "assistant" {
+		return
+	}
+	msg, ok := obj["message"].(map[string]any)
+	if !ok {
+		return
+	}
+	blocks, ok := msg["content"].([]any)
+	if !ok {
+		return
+	}
+	ts := ParseTimestamp(obj)
+	for _, item := range blocks {
+		block, ok := item.(map[string]any)
+		if !ok || block["type"] != "text" {
+			continue
+		}
+		text, _ := block["text"].(string)
+		for _, b := range CodeBlocks(text) {
+			t.snippets = append(t.snippets, &Snippet{
+				Seq:     len(t.snippets) + 1,
+				Lang:    b.Lang,
+				Code:    b.Code,
+				Lines:   strings.Count(b.Code, "\n") + 1,
+				Symbols: DefinedSymbols(b.Code),
+				At:      ts,
+				Offset:  offset,
+			})
+		}
+	}
+}
+
+// Snippets returns every snippet seen so far, in file order.
+func (t *SnippetTracker) Snippets() []*Snippet {
+	return t.snippets
+}
+
+// CodeBlock is one fenced block of markdown.
+type CodeBlock struct {
+	Lang string
+	Code string
+}
+
+// CodeBlocks returns the non-empty fenced code blocks in markdown text.
+// Fences follow CommonMark: three or more backticks or tildes indented at
+// most three spaces, closed by a run of the same character at least as
+// long. A block left open runs to the end of the text.
+func CodeBlocks(text string) []CodeBlock {
+	var out []CodeBlock
+	var (
+		open   bool
+		char   byte
+		length int
+		indent int
+		lang   string
+		body   []string
+	)
+	flush := func() {
+		code := strings.Trim(strings.Join(body, "\n"), "\n")
+		if strings.TrimSpace(code) != "" {
+			out = append(out, CodeBlock{Lang: lang, Code: code})
+		}
+		open, body = false, nil
+	}
+	for line := range strings.SplitSeq(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		n := len(line) - len(strings.TrimLeft(line, " "))
+		rest := line[n:]
+		if !open {
+			if n > 3 || len(rest) < 3 || (rest[0] != '`' && rest[0] != '~') {
+				continue
+			}
+			run := len(rest) - len(strings.TrimLeft(rest, rest[:1]))
+			info := strings.TrimSpace(rest[run:])
+			if run < 3 || (rest[0] == '`' && strings.Contains(info, "`")) {
+				continue
+			}
+			open, char, length, indent = true, rest[0], run, n
+			lang = ""
+			if f := strings.Fields(info); len(f) > 0 {
+				lang = NormalizeLang(strings.Trim(f[0], "{}."))
+			}
+			continue
+		}
+		if n <= 3 && len(rest) >= length && rest[0] == char {
+			run := len(rest) - len(strings.TrimLeft(rest, rest[:1]))
+			if run >= length && strings.TrimSpace(rest[run:]) == "" {
+				flush()
+				continue
+			}
+		}
+		// Content lines lose up to the opening fence's indentation.
+		strip := min(indent, len(line)-len(strings.TrimLeft(line, " ")))
+		body = append(body, line[strip:])
+	}
+	if open {
+		flush()
+	}
+	return out
+}
+
+var langAliases = map[string]string{
+	"golang":        "go",
+	"py":            "python",
+	"python3":       "python",
+	"js":            "javascript",
+	"jsx":           "javascript",
+	"mjs":           "javascript",
+	"ts":            "typescript",
+	"tsx":           "typescript",
+	"sh":            "bash",
+	"shell":         "bash",
+	"zsh":           "bash",
+	"console":       "bash",
+	"shell-session": "bash",
+	"yml":           "yaml",
+	"rs":            "rust",
+	"rb":            "ruby",
+	"md":            "markdown",
+	"c++":           "cpp",
+	"cs":            "csharp",
+	"kt":            "kotlin",
+	"text":          "",
+	"plaintext":     "",
+	"txt":           "",
+}
+
+// NormalizeLang lowercases a fence's language tag and maps common aliases
+// to one name, so "golang" and "go", or "sh" and "bash", match.
+func NormalizeLang(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if alias, ok := langAliases[lang]; ok {
+		return alias
+	}
+	return lang
+}
+
+// definitionPattern matches the declarations most languages share a shape
+// for: a keyword, then the name. Go method receivers are skipped.
+var definitionPattern = regexp.MustCompile(`(?m)^[ \t]*(?:export[ \t]+)?(?:default[ \t]+)?(?:pub(?:\([\w:]+\))?[ \t]+)?(?:(?:public|private|protected|static|abstract|final|async|unsafe)[ \t]+)*(?:func|function|def|fn|class|struct|enum|trait|interface|type|impl|module|object)[ \t]+(?:\([^)]*\)[ \t]*)?\*?([A-Za-z_]\w*)`)
+
+// DefinedSymbols returns the names code declares as functions, methods,
+// types, or classes, in order of appearance and without repeats.
+func DefinedSymbols(code string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, m := range definitionPattern.FindAllStringSubmatch(code, -1) {
+		if name := m[1]; !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	return out
+}
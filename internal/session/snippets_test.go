@@ -0,0 +1,67 @@
+package session
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCodeBlocks(t *testing.T) {
+	text := "Try this:\n\n```golang title=\"main.go\"\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n```\n\n" +
+		"````markdown\n```sh\nls\n```\n````\n\n" +
+		"  ~~~\n  indented\n    more\n  ~~~\n\n" +
+		"```\n\n```\n\n" +
+		"inline ```not a fence``` here\n\n" +
+		"```py\nprint(1)\n"
+	got := CodeBlocks(text)
+	want := []CodeBlock{
+		{Lang: "go", Code: "func main() {\n\tfmt.Println(\"hi\")\n}"},
+		{Lang: "markdown", Code: "```sh\nls\n```"},
+		{Lang: "", Code: "indented\n  more"},
+		{Lang: "python", Code: "print(1)"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CodeBlocks:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestDefinedSymbols(t *testing.T) {
+	code := "type Server struct{}\n\nfunc (s *Server) Start() error {\n\treturn nil\n}\n\nfunc NewServer() *Server { return nil }\n" +
+		"export async function loadUser() {}\nclass Cache:\n    def get(self): pass\npub fn parse() {}\nfunc NewServer() {}\n"
+	got := DefinedSymbols(code)
+	want := []string{"Server", "Start", "NewServer", "loadUser", "Cache", "get", "parse"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DefinedSymbols = %q, want %q", got, want)
+	}
+}
+
+func TestSnippetTracker(t *testing.T) {
+	lines := []string{
+		`{"type":"user","timestamp":"2026-02-01T08:00:00Z","message":{"role":"user","content":"write it\n` + "```go\\nfunc user() {}\\n```" + `"}}`,
+		`{"type":"assistant","timestamp":"2026-02-01T08:00:05Z","message":{"content":[{"type":"text","text":"` + "```go\\nfunc A() {}\\n```\\nand\\n```bash\\ngo test ./...\\n```" + `"},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls"}}]}}`,
+		`{"type":"assistant","timestamp":"2026-02-01T08:01:00Z","message":{"content":[{"type":"text","text":"` + "```ts\\nconst a = 1\\nconst b = 2\\n```" + `"}]}}`,
+	}
+	tr := NewSnippetTracker()
+	var offset int64
+	for _, line := range lines {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(line), &obj); err != nil {
+			t.Fatal(err)
+		}
+		tr.Observe(obj, offset)
+		offset += int64(len(line)) + 1
+	}
+	got := tr.Snippets()
+	if len(got) != 3 {
+		t.Fatalf("got %d snippets, want 3", len(got))
+	}
+	if s := got[0]; s.Seq != 1 || s.Lang != "go" || s.Lines != 1 || !reflect.DeepEqual(s.Symbols, []string{"A"}) || s.Offset != int64(len(lines[0])+1) {
+		t.Errorf("snippet 1 = %+v", s)
+	}
+	if s := got[1]; s.Seq != 2 || s.Lang != "bash" || s.Code != "go test ./..." {
+		t.Errorf("snippet 2 = %+v", s)
+	}
+	if s := got[2]; s.Seq != 3 || s.Lang != "typescript" || s.Lines != 2 || s.At.IsZero() {
+		t.Errorf("snippet 3 = %+v", s)
+	}
+}
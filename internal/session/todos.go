@@ -0,0 +1,120 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// Todo statuses TodoWrite records.
+const (
+	TodoPending    = "pending"
+	TodoInProgress = "in_progress"
+	TodoCompleted  = "completed"
+)
+
+// Todo is one item of an agent's TodoWrite list. The times come from the
+// TodoWrite calls that first listed the item, first marked it in progress,
+// and marked it completed.
+type Todo struct {
+	Content     string    `json:"content"`
+	ActiveForm  string    `json:"active_form,omitempty"`
+	Status      string    `json:"status"`
+	AddedAt     time.Time `json:"added_at,omitzero"`
+	StartedAt   time.Time `json:"started_at,omitzero"`
+	CompletedAt time.Time `json:"completed_at,omitzero"`
+}
+
+// Open reports whether the item was still pending or in progress.
+func (t *Todo) Open() bool {
+	return t.Status != TodoCompleted
+}
+
+// TodoState is the whole list as one TodoWrite call set it. Each call
+// replaces the list, so the last state is what the session ended with.
+type TodoState struct {
+	At     time.Time `json:"at,omitzero"`
+	Offset int64     `json:"offset"`
+	Todos  []*Todo   `json:"todos"`
+}
+
+// TodoStates reads the successive lists from a session's TodoWrite calls,
+// in call order. Items carry over their times from earlier states by
+// content, so each state says when its items were added, started, and
+// completed.
+func TodoStates(calls []*ToolCall) []*TodoState {
+	var states []*TodoState
+	seen := map[string]*Todo{}
+	for _, c := range calls {
+		if c.Name != "TodoWrite" || c.IsError {
+			continue
+		}
+		list, ok := c.Input["todos"].([]any)
+		if !ok {
+			continue
+		}
+		state := &TodoState{At: c.CalledAt, Offset: c.Offset, Todos: []*Todo{}}
+		for _, item := range list {
+			m, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			t := &Todo{}
+			t.Content, _ = m["content"].(string)
+			t.ActiveForm, _ = m["activeForm"].(string)
+			t.Status, _ = m["status"].(string)
+			if t.Content == "" {
+				continue
+			}
+			if prev, ok := seen[t.Content]; ok {
+				t.AddedAt, t.StartedAt, t.CompletedAt = prev.AddedAt, prev.StartedAt, prev.CompletedAt
+			} else {
+				t.AddedAt = c.CalledAt
+			}
+			switch t.Status {
+			case TodoInProgress:
+				if t.StartedAt.IsZero() {
+					t.StartedAt = c.CalledAt
+				}
+				t.CompletedAt = time.Time{}
+			case TodoCompleted:
+				if t.CompletedAt.IsZero() {
+					t.CompletedAt = c.CalledAt
+				}
+			default:
+				t.CompletedAt = time.Time{}
+			}
+			seen[t.Content] = t
+			state.Todos = append(state.Todos, t)
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// ReadTodoStates returns the TodoWrite history of a session file.
+func ReadTodoStates(path string) ([]*TodoState, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	tracker := NewToolTracker()
+	scanner := NewOffsetScanner(f)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if t := FastExtractType(line); t != "user" && t != "assistant" {
+			continue
+		}
+		var obj map[string]any
+		if json.Unmarshal(line, &obj) != nil {
+			continue
+		}
+		tracker.Observe(obj, scanner.Offset())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return TodoStates(tracker.Calls()), nil
+}
@@ -0,0 +1,49 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func todoCall(at string, todos ...map[string]any) *ToolCall {
+	list := make([]any, len(todos))
+	for i, t := range todos {
+		list[i] = t
+	}
+	ts, _ := time.Parse(time.RFC3339, at)
+	return &ToolCall{Name: "TodoWrite", Input: map[string]any{"todos": list}, CalledAt: ts}
+}
+
+func TestTodoStates(t *testing.T) {
+	item := func(content, status string) map[string]any {
+		return map[string]any{"content": content, "status": status, "activeForm": content + "ing"}
+	}
+	calls := []*ToolCall{
+		todoCall("2026-03-01T10:00:00Z", item("parse", "in_progress"), item("test", "pending")),
+		{Name: "Bash", Input: map[string]any{"command": "go test"}},
+		todoCall("2026-03-01T10:05:00Z", item("parse", "completed"), item("test", "in_progress")),
+		todoCall("2026-03-01T10:09:00Z", item("parse", "completed"), item("test", "in_progress"), item("docs", "pending")),
+	}
+	states := TodoStates(calls)
+	if len(states) != 3 {
+		t.Fatalf("got %d states, want 3", len(states))
+	}
+	final := states[2].Todos
+	if len(final) != 3 {
+		t.Fatalf("final list has %d items, want 3", len(final))
+	}
+	parse, test, docs := final[0], final[1], final[2]
+	if parse.Open() || parse.CompletedAt.Format("15:04") != "10:05" || parse.StartedAt.Format("15:04") != "10:00" {
+		t.Errorf("parse = %+v, want completed at 10:05 (first marked), started 10:00", parse)
+	}
+	if !test.Open() || test.StartedAt.Format("15:04") != "10:05" || !test.CompletedAt.IsZero() {
+		t.Errorf("test = %+v", test)
+	}
+	if docs.AddedAt.Format("15:04") != "10:09" || docs.ActiveForm != "docsing" {
+		t.Errorf("docs = %+v", docs)
+	}
+	// Earlier states keep their own view of each item.
+	if states[0].Todos[0].Status != TodoInProgress || !states[0].Todos[0].CompletedAt.IsZero() {
+		t.Errorf("first state mutated: %+v", states[0].Todos[0])
+	}
+}
@@ -0,0 +1,130 @@
+package session
+
+import (
+	"strings"
+	"time"
+)
+
+// ToolCall is one tool_use block paired with the tool_result that answered
+// it. ResultAt is zero (and HasResult false) when the session ended, or was
+// interrupted, before a result was written.
+type ToolCall struct {
+	ID          string
+	Name        string
+	Input       map[string]any
+	Offset      int64 // byte offset of the assistant record holding the tool_use
+	CalledAt    time.Time
+	ResultAt    time.Time
+	HasResult   bool
+	IsError     bool
+	OutputBytes int
+	Output      string // result text; only kept for calls selected by ToolTracker.KeepOutput
+}
+
+// Latency is the gap between the tool_use and tool_result records, or 0
+// when either timestamp is missing.
+func (c *ToolCall) Latency() time.Duration {
+	if !c.HasResult || c.CalledAt.IsZero() || c.ResultAt.IsZero() {
+		return 0
+	}
+	return c.ResultAt.Sub(c.CalledAt)
+}
+
+// editPathKeys maps the tools that change a file to the input key naming it.
+var editPathKeys = map[string]string{
+	"Edit":         "file_path",
+	"MultiEdit":    "file_path",
+	"Write":        "file_path",
+	"NotebookEdit": "notebook_path",
+}
+
+// EditedPath returns the file an Edit, MultiEdit, Write, or NotebookEdit
+// call changed, or "" for any other tool.
+func EditedPath(c *ToolCall) string {
+	key, ok := editPathKeys[c.Name]
+	if !ok {
+		return ""
+	}
+	path, _ := c.Input[key].(string)
+	return path
+}
+
+// MCPServer returns the server segment of an MCP tool name
+// ("mcp__github__create_issue" → "github"), or "" for built-in tools.
+func MCPServer(name string) string {
+	rest, ok := strings.CutPrefix(name, "mcp__")
+	if !ok {
+		return ""
+	}
+	server, _, _ := strings.Cut(rest, "__")
+	return server
+}
+
+// ToolTracker pairs tool_use blocks in assistant records with tool_result
+// blocks in later user records, matching on tool_use_id. Feed it every
+// user and assistant record in file order.
+type ToolTracker struct {
+	// KeepOutput, when set, selects the calls whose result text is kept in
+	// ToolCall.Output. Results are otherwise only measured, since holding
+	// every output of a long session in memory adds up quickly.
+	KeepOutput func(*ToolCall) bool
+
+	calls   []*ToolCall
+	pending map[string]*ToolCall
+}
+
+func NewToolTracker() *ToolTracker {
+	return &ToolTracker{pending: make(map[string]*ToolCall)}
+}
+
+// Observe records the tool_use or tool_result blocks in a parsed record
+// read from byte offset in the file. Results with no matching tool_use
+// (e.g. a file truncated at the front) are ignored.
+func (t *ToolTracker) Observe(obj map[string]any, offset int64) {
+	msg, ok := obj["message"].(map[string]any)
+	if !ok {
+		return
+	}
+	blocks, ok := msg["content"].([]any)
+	if !ok {
+		return
+	}
+	ts := ParseTimestamp(obj)
+	for _, item := range blocks {
+		block, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		switch block["type"] {
+		case "tool_use":
+			id, _ := block["id"].(string)
+			name, _ := block["name"].(string)
+			input, _ := block["input"].(map[string]any)
+			c := &ToolCall{ID: id, Name: name, Input: input, Offset: offset, CalledAt: ts}
+			t.calls = append(t.calls, c)
+			if id != "" {
+				t.pending[id] = c
+			}
+		case "tool_result":
+			id, _ := block["tool_use_id"].(string)
+			c, ok := t.pending[id]
+			if !ok {
+				continue
+			}
+			delete(t.pending, id)
+			c.HasResult = true
+			c.ResultAt = ts
+			c.IsError, _ = block["is_error"].(bool)
+			text := ExtractTextFromContent(block["content"])
+			c.OutputBytes = len(text)
+			if t.KeepOutput != nil && t.KeepOutput(c) {
+				c.Output = text
+			}
+		}
+	}
+}
+
+// Calls returns every tool call seen so far, in call order.
+func (t *ToolTracker) Calls() []*ToolCall {
+	return t.calls
+}
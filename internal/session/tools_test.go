@@ -0,0 +1,86 @@
+package session
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestToolTracker(t *testing.T) {
+	lines := []string{
+		`{"type":"assistant","timestamp":"2026-02-01T08:00:00Z","message":{"content":[{"type":"text","text":"running"},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./..."}},{"type":"tool_use","id":"t2","name":"mcp__github__get_issue","input":{}}]}}`,
+		`{"type":"user","timestamp":"2026-02-01T08:00:04Z","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"ok  \tpkg\t0.1s"}]}}`,
+		`{"type":"user","timestamp":"2026-02-01T08:00:10Z","message":{"content":[{"type":"tool_result","tool_use_id":"t2","is_error":true,"content":[{"type":"text","text":"not found"}]},{"type":"tool_result","tool_use_id":"unknown","content":"x"}]}}`,
+		`{"type":"assistant","timestamp":"2026-02-01T08:01:00Z","message":{"content":[{"type":"tool_use","id":"t3","name":"Read","input":{"file_path":"/a"}}]}}`,
+	}
+
+	tr := NewToolTracker()
+	var offset int64
+	for _, line := range lines {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(line), &obj); err != nil {
+			t.Fatal(err)
+		}
+		tr.Observe(obj, offset)
+		offset += int64(len(line)) + 1
+	}
+
+	calls := tr.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("got %d calls, want 3", len(calls))
+	}
+
+	bash := calls[0]
+	if bash.Name != "Bash" || bash.Input["command"] != "go test ./..." {
+		t.Errorf("bash = %+v", bash)
+	}
+	if !bash.HasResult || bash.IsError || bash.OutputBytes != len("ok  \tpkg\t0.1s") {
+		t.Errorf("bash result = %+v", bash)
+	}
+	if bash.Latency() != 4*time.Second {
+		t.Errorf("bash latency = %v, want 4s", bash.Latency())
+	}
+
+	mcp := calls[1]
+	if !mcp.IsError || mcp.OutputBytes != len("not found") || mcp.Latency() != 10*time.Second {
+		t.Errorf("mcp = %+v", mcp)
+	}
+
+	if calls[0].Offset != 0 || calls[2].Offset == 0 {
+		t.Errorf("offsets = %d, %d", calls[0].Offset, calls[2].Offset)
+	}
+	if calls[2].HasResult || calls[2].Latency() != 0 {
+		t.Errorf("unanswered call = %+v", calls[2])
+	}
+}
+
+func TestMCPServer(t *testing.T) {
+	tests := map[string]string{
+		"mcp__github__create_issue": "github",
+		"mcp__plugin_x__tool":       "plugin_x",
+		"mcp__solo":                 "solo",
+		"Bash":                      "",
+	}
+	for name, want := range tests {
+		if got := MCPServer(name); got != want {
+			t.Errorf("MCPServer(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestEditedPath(t *testing.T) {
+	tests := []struct {
+		call *ToolCall
+		want string
+	}{
+		{&ToolCall{Name: "Edit", Input: map[string]any{"file_path": "/a.go"}}, "/a.go"},
+		{&ToolCall{Name: "Write", Input: map[string]any{"file_path": "/b.go"}}, "/b.go"},
+		{&ToolCall{Name: "NotebookEdit", Input: map[string]any{"notebook_path": "/c.ipynb"}}, "/c.ipynb"},
+		{&ToolCall{Name: "Read", Input: map[string]any{"file_path": "/a.go"}}, ""},
+	}
+	for _, tt := range tests {
+		if got := EditedPath(tt.call); got != tt.want {
+			t.Errorf("EditedPath(%s) = %q, want %q", tt.call.Name, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package tui
 import (
 	"encoding/json"
 	"io"
+	"os"
 	"strings"
 	"time"
 
@@ -16,6 +17,9 @@ const (
 	KindAssistant
 	KindToolCall
 	KindToolResult
+	KindAgent   // start of an inlined subagent transcript; Text is its heading
+	KindCompact // a context compaction; Text is the summary that replaced the earlier context
+	KindThinking
 )
 
 type Message struct {
@@ -23,7 +27,14 @@ type Message struct {
 	Text      string
 	ToolName  string
 	ToolInput map[string]any
+	ToolUseID string
 	Timestamp time.Time
+	Depth     int // subagent nesting level; 0 for the session's own messages
+	// UUID and Offset locate the record the message came from.
+	UUID   string
+	Offset int64
+	// Compaction is set on KindCompact messages.
+	Compaction *session.Compaction
 }
 
 func ParseMessages(r io.Reader) []Message {
@@ -34,7 +45,11 @@ func ParseMessages(r io.Reader) []Message {
 		line := scanner.Bytes()
 		lineType := session.FastExtractType(line)
 
-		if lineType != "user" && lineType != "assistant" {
+		if lineType != "user" && lineType != "assistant" && lineType != "system" {
+			continue
+		}
+		compaction := session.MayBeCompaction(line)
+		if lineType == "system" && !compaction {
 			continue
 		}
 
@@ -44,13 +59,71 @@ func ParseMessages(r io.Reader) []Message {
 		}
 
 		ts := session.ParseTimestamp(obj)
-		extracted := extractMessages(obj, lineType, ts)
-		messages = append(messages, extracted...)
+		if compaction {
+			switch {
+			case session.IsCompactBoundary(obj):
+				messages = append(messages, Message{Kind: KindCompact, Timestamp: ts, Compaction: session.NewCompaction(obj)})
+				continue
+			case session.IsCompactSummary(obj):
+				summary := session.CompactSummaryText(obj)
+				if n := len(messages); n > 0 && messages[n-1].Kind == KindCompact && messages[n-1].Text == "" {
+					messages[n-1].Text = summary
+				} else {
+					messages = append(messages, Message{Kind: KindCompact, Text: summary, Timestamp: ts, Compaction: &session.Compaction{At: ts}})
+				}
+				continue
+			}
+			if lineType == "system" {
+				continue
+			}
+		}
+		uuid, _ := obj["uuid"].(string)
+		for _, m := range extractMessages(obj, lineType, ts) {
+			m.UUID, m.Offset = uuid, scanner.Offset()
+			messages = append(messages, m)
+		}
 	}
 
 	return messages
 }
 
+// InlineAgents returns messages with each linked subagent's transcript
+// spliced in after the tool call that spawned it: a KindAgent heading
+// followed by the agent's messages one Depth deeper.
+func InlineAgents(messages []Message, spawns map[string]*session.AgentSpawn) []Message {
+	if len(spawns) == 0 {
+		return messages
+	}
+	var out []Message
+	for _, msg := range messages {
+		out = append(out, msg)
+		sp, ok := spawns[msg.ToolUseID]
+		if msg.Kind != KindToolCall || !ok {
+			continue
+		}
+		f, err := os.Open(sp.Agent.FilePath)
+		if err != nil {
+			continue
+		}
+		agent := InlineAgents(ParseMessages(f), spawns)
+		_ = f.Close()
+
+		title := "Subagent " + sp.Agent.ShortID
+		if sp.SubagentType != "" {
+			title += " · " + sp.SubagentType
+		}
+		if sp.Description != "" {
+			title += " — " + sp.Description
+		}
+		out = append(out, Message{Kind: KindAgent, Text: title, Timestamp: sp.CalledAt, Depth: msg.Depth})
+		for _, m := range agent {
+			m.Depth += msg.Depth + 1
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
 func extractMessages(obj map[string]any, role string, ts time.Time) []Message {
 	msg, ok := obj["message"].(map[string]any)
 	if !ok {
@@ -96,17 +169,26 @@ func extractMessages(obj map[string]any, role string, ts time.Time) []Message {
 			}
 			messages = append(messages, Message{Kind: kind, Text: text, Timestamp: ts})
 
-		case "thinking", "redacted_thinking":
+		case "thinking":
+			text, _ := block["thinking"].(string)
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			messages = append(messages, Message{Kind: KindThinking, Text: text, Timestamp: ts})
+
+		case "redacted_thinking":
 			continue
 
 		case "tool_use":
 			name, _ := block["name"].(string)
 			input, _ := block["input"].(map[string]any)
 			desc := extractToolDescription(input)
+			id, _ := block["id"].(string)
 			messages = append(messages, Message{
 				Kind:      KindToolCall,
 				ToolName:  name,
 				ToolInput: input,
+				ToolUseID: id,
 				Text:      desc,
 				Timestamp: ts,
 			})
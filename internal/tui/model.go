@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/andyhtran/cct/internal/render"
 	"github.com/andyhtran/cct/internal/session"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -32,16 +33,45 @@ var (
 
 	helpStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("8"))
+
+	agentStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("6"))
+
+	compactStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("3")).
+			Bold(true)
+
+	thinkingStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("8")).
+			Italic(true)
 )
 
+// Options configures the session viewer.
+type Options struct {
+	// Agents maps tool_use_id to a linked subagent whose transcript is
+	// inlined at its spawn point.
+	Agents map[string]*session.AgentSpawn
+	// ExpandAgents starts with subagent transcripts expanded; 'a' toggles.
+	ExpandAgents bool
+	// Thinking starts with thinking blocks shown; 't' toggles.
+	Thinking bool
+	// Anchor opens the viewer scrolled to the message from this record.
+	Anchor *session.Anchor
+}
+
 type Model struct {
-	session  *session.Session
-	messages []Message
-	viewport viewport.Model
-	renderer *glamour.TermRenderer
-	ready    bool
-	width    int
-	height   int
+	session      *session.Session
+	messages     []Message
+	viewport     viewport.Model
+	renderer     *glamour.TermRenderer
+	ready        bool
+	width        int
+	height       int
+	hasAgents    bool
+	expandAgents bool
+	hasThinking  bool
+	showThinking bool
+	anchor       *session.Anchor
 }
 
 func NewModel(s *session.Session, messages []Message) Model {
@@ -50,11 +80,20 @@ func NewModel(s *session.Session, messages []Message) Model {
 		glamour.WithWordWrap(0),
 	)
 
-	return Model{
+	m := Model{
 		session:  s,
 		messages: messages,
 		renderer: renderer,
 	}
+	for _, msg := range messages {
+		switch msg.Kind {
+		case KindAgent:
+			m.hasAgents = true
+		case KindThinking:
+			m.hasThinking = true
+		}
+	}
+	return m
 }
 
 func (m Model) Init() tea.Cmd {
@@ -73,6 +112,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport.GotoTop()
 		case "G":
 			m.viewport.GotoBottom()
+		case "a":
+			if m.hasAgents && m.ready {
+				m.expandAgents = !m.expandAgents
+				m.viewport.SetContent(m.renderContent())
+			}
+		case "t":
+			if m.hasThinking && m.ready {
+				m.showThinking = !m.showThinking
+				m.viewport.SetContent(m.renderContent())
+			}
 		}
 
 	case tea.WindowSizeMsg:
@@ -85,7 +134,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if !m.ready {
 			m.viewport = viewport.New(msg.Width, msg.Height-headerHeight-footerHeight)
 			m.viewport.YPosition = headerHeight
-			m.viewport.SetContent(m.renderContent())
+			content, line := m.render()
+			m.viewport.SetContent(content)
+			if line > 0 {
+				m.viewport.SetYOffset(line)
+			}
 			m.ready = true
 		} else {
 			m.viewport.Width = msg.Width
@@ -123,9 +176,15 @@ func (m Model) renderHeader() string {
 }
 
 func (m Model) renderFooter() string {
-	info := fmt.Sprintf(" %d messages ", len(m.messages))
+	info := fmt.Sprintf(" %d messages ", len(m.visibleMessages()))
 	scroll := fmt.Sprintf(" %3.f%% ", m.viewport.ScrollPercent()*100)
 	help := " q: quit • j/k: scroll • g/G: top/bottom "
+	if m.hasAgents {
+		help += "• a: agents "
+	}
+	if m.hasThinking {
+		help += "• t: thinking "
+	}
 
 	gap := m.width - len(info) - len(scroll) - len(help)
 	if gap < 0 {
@@ -135,29 +194,79 @@ func (m Model) renderFooter() string {
 	return helpStyle.Render(info + strings.Repeat(" ", gap) + help + scroll)
 }
 
+// visibleMessages hides subagent transcripts while they are collapsed,
+// leaving only their KindAgent headings, and thinking blocks unless shown.
+func (m Model) visibleMessages() []Message {
+	if (m.expandAgents || !m.hasAgents) && (m.showThinking || !m.hasThinking) {
+		return m.messages
+	}
+	var out []Message
+	for _, msg := range m.messages {
+		if msg.Depth > 0 && !m.expandAgents {
+			continue
+		}
+		if msg.Kind == KindThinking && !m.showThinking {
+			continue
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
 func (m Model) renderContent() string {
+	content, _ := m.render()
+	return content
+}
+
+// render returns the content for the viewport and the line the anchored
+// message starts on (-1 without an anchor): the first shown message from
+// the anchor's record or a later one, so a hit in a tool result lands on
+// what followed it.
+func (m Model) render() (string, int) {
 	var b strings.Builder
 
-	for i, msg := range m.messages {
+	anchorLine := -1
+	messages := m.visibleMessages()
+	for i, msg := range messages {
+		if m.anchor != nil && anchorLine < 0 && msg.Depth == 0 && msg.Kind != KindToolResult && msg.Offset >= m.anchor.Offset {
+			anchorLine = strings.Count(b.String(), "\n")
+		}
+		var block string
 		switch msg.Kind {
 		case KindUser:
-			b.WriteString(userStyle.Render("▌ User"))
-			b.WriteString("\n\n")
-			rendered := m.renderMarkdown(msg.Text)
-			b.WriteString(rendered)
+			block = userStyle.Render("▌ User") + "\n\n" + m.renderMarkdown(msg.Text)
 
 		case KindAssistant:
-			b.WriteString(assistantStyle.Render("▌ Assistant"))
-			b.WriteString("\n\n")
-			rendered := m.renderMarkdown(msg.Text)
-			b.WriteString(rendered)
+			block = assistantStyle.Render("▌ Assistant") + "\n\n" + m.renderMarkdown(msg.Text)
+
+		case KindThinking:
+			block = thinkingStyle.Render("▌ Thinking") + "\n\n" + m.renderMarkdown(render.Quote(msg.Text))
+
+		case KindCompact:
+			title := "▌ Context compacted"
+			if label := msg.Compaction.Label(); label != "" {
+				title += " · " + label
+			}
+			block = compactStyle.Render(title)
+			if msg.Text != "" {
+				block += "\n\n" + toolStyle.Render("Summary carried into the new context:") + "\n\n" + m.renderMarkdown(msg.Text)
+			}
 
 		case KindToolCall:
 			toolLine := fmt.Sprintf("  ▸ %s", toolNameStyle.Render(msg.ToolName))
 			if msg.Text != "" {
 				toolLine += toolStyle.Render(fmt.Sprintf(" — %s", truncate(msg.Text, 60)))
 			}
-			b.WriteString(toolStyle.Render(toolLine))
+			b.WriteString(indentLines(toolStyle.Render(toolLine), msg.Depth))
+			b.WriteString("\n")
+			continue
+
+		case KindAgent:
+			line := agentStyle.Render("  ▾ " + msg.Text)
+			if !m.expandAgents {
+				line = agentStyle.Render("  ▸ "+msg.Text) + helpStyle.Render("  (a: expand)")
+			}
+			b.WriteString(indentLines(line, msg.Depth))
 			b.WriteString("\n")
 			continue
 
@@ -165,14 +274,29 @@ func (m Model) renderContent() string {
 			continue
 		}
 
-		if i < len(m.messages)-1 {
+		b.WriteString(indentLines(block, msg.Depth))
+		if i < len(messages)-1 {
 			b.WriteString("\n")
-			b.WriteString(separatorStyle.Render(strings.Repeat("─", m.width)))
+			b.WriteString(indentLines(separatorStyle.Render(strings.Repeat("─", max(0, m.width-2*msg.Depth))), msg.Depth))
 			b.WriteString("\n\n")
 		}
 	}
 
-	return b.String()
+	return b.String(), anchorLine
+}
+
+// indentLines prefixes every line of s with a rule per nesting level, so
+// subagent transcripts read as a block under their spawn point.
+func indentLines(s string, depth int) string {
+	if depth == 0 {
+		return s
+	}
+	prefix := separatorStyle.Render(strings.Repeat("│ ", depth))
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
 }
 
 func (m Model) renderMarkdown(text string) string {
@@ -193,19 +317,22 @@ func truncate(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-func Run(s *session.Session) error {
+func Run(s *session.Session, opts Options) error {
 	f, err := os.Open(s.FilePath)
 	if err != nil {
 		return fmt.Errorf("cannot open session file: %w", err)
 	}
 	defer func() { _ = f.Close() }()
 
-	messages := ParseMessages(f)
+	messages := InlineAgents(ParseMessages(f), opts.Agents)
 	if len(messages) == 0 {
 		return fmt.Errorf("no messages found in session")
 	}
 
 	m := NewModel(s, messages)
+	m.expandAgents = opts.ExpandAgents
+	m.showThinking = opts.Thinking
+	m.anchor = opts.Anchor
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
 	_, err = p.Run()